@@ -83,6 +83,14 @@ func main() {
 	documentHandler := handlers.NewDocumentHandler(db)
 	companyHandler := handlers.NewCompanyHandler(db)
 	uploadHandler := handlers.NewUploadHandler(fileStore)
+	fineHandler := handlers.NewFineHandler(db, fileStore)
+	renewalCaseHandler := handlers.NewRenewalCaseHandler(db)
+	exitChecklistHandler := handlers.NewExitChecklistHandler(db)
+	abscondingCaseHandler := handlers.NewAbscondingCaseHandler(db, fileStore)
+	searchHandler := handlers.NewSearchHandler(db)
+	leaveHandler := handlers.NewLeaveHandler(db)
+	dependentHandler := handlers.NewDependentHandler(db)
+	contractHandler := handlers.NewContractHandler(db)
 	salaryHandler := handlers.NewSalaryHandler(db)
 	activityHandler := handlers.NewActivityHandler(db)
 	notificationHandler := handlers.NewNotificationHandler(db)
@@ -90,7 +98,9 @@ func main() {
 	userMgmtHandler := handlers.NewUserManagementHandler(db)
 
 	// Start background cron jobs
+	cron.StartStatusRefresher(db)
 	cron.StartNotifier(db)
+	cron.StartTransferScheduler(db, handlers.ApplyDueTransfers)
 
 	// 6. Public routes (no authentication required)
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
@@ -128,6 +138,30 @@ func main() {
 		r.Get("/api/dashboard/expiring", dashboardHandler.GetExpiryAlerts)
 		r.Get("/api/dashboard/company-summary", dashboardHandler.GetCompanySummary)
 		r.Get("/api/dashboard/compliance", dashboardHandler.GetComplianceStats)
+		r.Get("/api/dashboard/fine-forecast", dashboardHandler.GetFineForecast)
+		r.Post("/api/dashboard/fine-forecast", dashboardHandler.SimulateFineForecast)
+		r.Get("/api/dashboard/renewal-budget", dashboardHandler.GetRenewalBudget)
+		r.Get("/api/dashboard/trends", dashboardHandler.GetComplianceTrend)
+		r.Get("/api/dashboard/emiratisation", dashboardHandler.GetEmiratisation)
+
+		// Fine settlement ledger (read)
+		r.Get("/api/fines/settlements", fineHandler.ListSettlements)
+		r.Get("/api/fines/reconciliation", fineHandler.GetReconciliation)
+
+		// Renewal cases (read)
+		r.Get("/api/renewal-cases", renewalCaseHandler.ListCases)
+		r.Get("/api/renewal-cases/{id}", renewalCaseHandler.GetCase)
+
+		// Exit checklists (read)
+		r.Get("/api/exit-checklist", exitChecklistHandler.List)
+
+		// Absconding cases (read)
+		r.Get("/api/absconding-cases", abscondingCaseHandler.List)
+		r.Get("/api/absconding-cases/{id}", abscondingCaseHandler.Get)
+
+		// Leave (read)
+		r.Get("/api/leave-types", leaveHandler.ListTypes)
+		r.Get("/api/leave-requests", leaveHandler.List)
 
 		// Notifications (user-scoped)
 		r.Get("/api/notifications", notificationHandler.List)
@@ -142,14 +176,24 @@ func main() {
 		r.Get("/api/companies", companyHandler.List)
 		r.Get("/api/companies/{id}", companyHandler.GetByID)
 
+		// Search (employees and document numbers)
+		r.Get("/api/search", searchHandler.Search)
+
 		// Employees (read)
 		r.Get("/api/employees", employeeHandler.List)
 		r.Get("/api/employees/export", employeeHandler.Export)
 		r.Route("/api/employees/{id}", func(r chi.Router) {
 			r.Get("/", employeeHandler.GetByID)
 			r.Get("/documents", documentHandler.ListByEmployee)
+			r.Get("/documents/validity", documentHandler.SuggestExpiry)
 			r.Get("/dependency-alerts", dashboardHandler.GetDependencyAlerts)
 			r.Get("/salary", salaryHandler.ListByEmployee)
+			r.Get("/exit-checklist", exitChecklistHandler.GetByEmployee)
+			r.Get("/sponsorships", employeeHandler.Sponsorships)
+			r.Get("/timeline", employeeHandler.Timeline)
+			r.Get("/leave-balances", leaveHandler.Balances)
+			r.Get("/dependents", dependentHandler.List)
+			r.Get("/contracts", contractHandler.ListByEmployee)
 		})
 
 		// Salary & documents (read)
@@ -161,6 +205,7 @@ func main() {
 
 		// Document types (read — needed for forms)
 		r.Get("/api/document-types", adminHandler.ListDocumentTypes)
+		r.Get("/api/custom-fields", adminHandler.ListCustomFields)
 
 		// ── Writer endpoints (company_owner + admin + super_admin) ──────
 		r.Group(func(r chi.Router) {
@@ -172,6 +217,18 @@ func main() {
 			r.Delete("/api/employees/{id}", employeeHandler.Delete)
 			r.Post("/api/employees/batch-delete", employeeHandler.BatchDelete)
 			r.Patch("/api/employees/{id}/exit", employeeHandler.Exit)
+			r.Post("/api/employees/{id}/transfer", employeeHandler.Transfer)
+			r.Delete("/api/employees/{id}/transfer", employeeHandler.CancelTransfer)
+
+			// Dependent write
+			r.Post("/api/employees/{id}/dependents", dependentHandler.Create)
+			r.Put("/api/dependents/{id}", dependentHandler.Update)
+			r.Delete("/api/dependents/{id}", dependentHandler.Delete)
+
+			// Contract write
+			r.Post("/api/employees/{id}/contracts", contractHandler.Create)
+			r.Put("/api/contracts/{id}", contractHandler.Update)
+			r.Delete("/api/contracts/{id}", contractHandler.Delete)
 
 			// Document write
 			r.Post("/api/employees/{employeeId}/documents", documentHandler.Create)
@@ -181,8 +238,32 @@ func main() {
 				r.Delete("/", documentHandler.Delete)
 				r.Patch("/primary", documentHandler.TogglePrimary)
 				r.Post("/renew", documentHandler.Renew)
+				r.Post("/renewal-case", renewalCaseHandler.OpenCase)
 			})
 
+			// Fine settlement write
+			r.Post("/api/fines/settlements", fineHandler.CreateSettlement)
+
+			// Renewal case write
+			r.Patch("/api/renewal-cases/{id}", renewalCaseHandler.UpdateCase)
+			r.Post("/api/renewal-cases/{id}/comments", renewalCaseHandler.AddComment)
+			r.Post("/api/renewal-cases/{id}/cancel", renewalCaseHandler.CancelCase)
+
+			// Exit checklist write
+			r.Patch("/api/exit-checklist/{id}", exitChecklistHandler.UpdateItem)
+
+			// Absconding case write
+			r.Patch("/api/absconding-cases/{id}", abscondingCaseHandler.Update)
+			r.Post("/api/absconding-cases/{id}/withdraw", abscondingCaseHandler.Withdraw)
+			r.Post("/api/absconding-cases/{id}/files", abscondingCaseHandler.UploadFile)
+			r.Delete("/api/absconding-cases/{id}/files/{fileId}", abscondingCaseHandler.DeleteFile)
+
+			// Leave write
+			r.Post("/api/leave-requests", leaveHandler.Create)
+			r.Post("/api/leave-requests/{id}/approve", leaveHandler.Approve)
+			r.Post("/api/leave-requests/{id}/reject", leaveHandler.Reject)
+			r.Post("/api/leave-requests/{id}/cancel", leaveHandler.Cancel)
+
 			// Salary write
 			r.Post("/api/salary/generate", salaryHandler.Generate)
 			r.Patch("/api/salary/bulk-status", salaryHandler.BulkUpdateStatus)
@@ -198,6 +279,9 @@ func main() {
 			r.Put("/api/companies/{id}", companyHandler.Update)
 			r.Delete("/api/companies/{id}", companyHandler.Delete)
 
+			// Fine settlement correction (admin-only)
+			r.Delete("/api/fines/settlements/{id}", fineHandler.DeleteSettlement)
+
 			// User management
 			r.Get("/api/users", userMgmtHandler.List)
 			r.Put("/api/users/{id}/role", userMgmtHandler.UpdateRole)
@@ -219,6 +303,62 @@ func main() {
 			r.Post("/api/admin/dependencies", adminHandler.CreateDependency)
 			r.Put("/api/admin/dependencies/{id}", adminHandler.UpdateDependency)
 			r.Delete("/api/admin/dependencies/{id}", adminHandler.DeleteDependency)
+
+			// Admin settings: linked expiry between document types
+			r.Get("/api/admin/document-links", adminHandler.ListDocumentLinks)
+			r.Post("/api/admin/document-links", adminHandler.CreateDocumentLink)
+			r.Put("/api/admin/document-links/{id}", adminHandler.UpdateDocumentLink)
+			r.Delete("/api/admin/document-links/{id}", adminHandler.DeleteDocumentLink)
+
+			// Admin settings: conditional mandatory documents
+			r.Get("/api/admin/requirement-conditions", adminHandler.ListRequirementConditions)
+			r.Post("/api/admin/requirement-conditions", adminHandler.CreateRequirementCondition)
+			r.Put("/api/admin/requirement-conditions/{id}", adminHandler.UpdateRequirementCondition)
+			r.Delete("/api/admin/requirement-conditions/{id}", adminHandler.DeleteRequirementCondition)
+
+			// Admin settings: regulatory authority profiles
+			r.Get("/api/admin/authority-profiles", adminHandler.ListAuthorityProfiles)
+			r.Post("/api/admin/authority-profiles", adminHandler.CreateAuthorityProfile)
+			r.Get("/api/admin/authority-profiles/{code}", adminHandler.GetAuthorityProfile)
+			r.Put("/api/admin/authority-profiles/{code}", adminHandler.UpdateAuthorityProfile)
+			r.Get("/api/admin/authority-profiles/{code}/versions", adminHandler.ListAuthorityProfileVersions)
+			r.Get("/api/admin/authority-profiles/{code}/preview", adminHandler.PreviewAuthorityProfile)
+			r.Post("/api/admin/authority-profiles/{code}/apply", adminHandler.ApplyAuthorityProfile)
+
+			// Admin settings: public holidays (working-day grace periods)
+			r.Get("/api/admin/public-holidays", adminHandler.ListPublicHolidays)
+			r.Post("/api/admin/public-holidays", adminHandler.CreatePublicHoliday)
+			r.Delete("/api/admin/public-holidays/{id}", adminHandler.DeletePublicHoliday)
+
+			// Admin settings: Emiratisation quota targets
+			r.Get("/api/admin/emiratisation-targets", adminHandler.ListEmiratisationTargets)
+			r.Post("/api/admin/emiratisation-targets", adminHandler.CreateEmiratisationTarget)
+			r.Put("/api/admin/emiratisation-targets/{id}", adminHandler.UpdateEmiratisationTarget)
+			r.Delete("/api/admin/emiratisation-targets/{id}", adminHandler.DeleteEmiratisationTarget)
+
+			// Admin settings: renewal cost catalog
+			r.Get("/api/admin/renewal-costs", adminHandler.ListRenewalCosts)
+			r.Post("/api/admin/renewal-costs", adminHandler.CreateRenewalCost)
+			r.Put("/api/admin/renewal-costs/{id}", adminHandler.UpdateRenewalCost)
+			r.Delete("/api/admin/renewal-costs/{id}", adminHandler.DeleteRenewalCost)
+
+			// Admin settings: compliance score weights
+			r.Get("/api/admin/score-weights", adminHandler.GetScoreWeights)
+			r.Put("/api/admin/score-weights", adminHandler.UpdateScoreWeights)
+
+			// Admin settings: exit checklist deadlines and fines
+			r.Get("/api/admin/exit-checklist-rules", adminHandler.ListExitChecklistRules)
+			r.Put("/api/admin/exit-checklist-rules", adminHandler.SaveExitChecklistRule)
+			r.Delete("/api/admin/exit-checklist-rules/{id}", adminHandler.DeleteExitChecklistRule)
+
+			// Custom employee fields
+			r.Post("/api/admin/custom-fields", adminHandler.CreateCustomField)
+			r.Put("/api/admin/custom-fields/{id}", adminHandler.UpdateCustomField)
+			r.Delete("/api/admin/custom-fields/{id}", adminHandler.DeleteCustomField)
+
+			// Admin settings: configuration bundle export/import
+			r.Get("/api/admin/config/export", adminHandler.ExportConfig)
+			r.Post("/api/admin/config/import", adminHandler.ImportConfig)
 		})
 	})
 
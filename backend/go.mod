@@ -14,6 +14,7 @@ require (
 	github.com/joho/godotenv v1.5.1
 	golang.org/x/crypto v0.48.0
 	golang.org/x/time v0.14.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,23 @@
+package compliance
+
+import "time"
+
+// ── Absconding ───────────────────────────────────────────────────
+// An absconding report can be filed once the worker has been absent for
+// AbscondingMinAbsenceDays consecutive days after the last working day, and
+// must be filed within AbscondingFilingDays of the last working day.
+
+const (
+	AbscondingMinAbsenceDays = 7
+	AbscondingFilingDays     = 15
+)
+
+// AbscondingEarliestFiling returns the first day the report can be filed.
+func AbscondingEarliestFiling(lastWorkingDay time.Time) time.Time {
+	return truncateToDay(lastWorkingDay).AddDate(0, 0, AbscondingMinAbsenceDays+1)
+}
+
+// AbscondingFilingDeadline returns the last day to file the report.
+func AbscondingFilingDeadline(lastWorkingDay time.Time) time.Time {
+	return truncateToDay(lastWorkingDay).AddDate(0, 0, AbscondingFilingDays)
+}
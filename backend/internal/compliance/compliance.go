@@ -6,12 +6,15 @@ package compliance
 import (
 	"math"
 	"strings"
+	"sync"
 	"time"
+	_ "time/tzdata" // company timezones must resolve on hosts without zoneinfo
 )
 
 // ── Document Status Constants ────────────────────────────────────
-// Status is always computed from (expiryDate, graceDays, docNumber, now).
-// It is never stored in the database.
+// Status is always computed from (expiryDate, graceDays, docNumber, now) by
+// this package. The results are persisted (document_status, migration 016)
+// so SQL readers never re-derive them.
 
 const (
 	StatusIncomplete    = "incomplete"     // Missing document_number or expiry_date
@@ -19,8 +22,24 @@ const (
 	StatusExpiringSoon  = "expiring_soon"  // Expiry within 30 days
 	StatusInGrace       = "in_grace"       // Expired but within grace period
 	StatusPenaltyActive = "penalty_active" // Past grace — fines accumulating
+
+	// StatusNone is the employee rollup when no documents are required.
+	StatusNone = "none"
 )
 
+// ExpiringSoonDays is the window (in days) in which a document counts as
+// expiring soon.
+const ExpiringSoonDays = 30
+
+// statusSeverity orders document statuses for the employee rollup.
+var statusSeverity = map[string]int{
+	StatusValid:         1,
+	StatusIncomplete:    2,
+	StatusExpiringSoon:  3,
+	StatusInGrace:       4,
+	StatusPenaltyActive: 5,
+}
+
 // ── Fine Type Constants ──────────────────────────────────────────
 
 const (
@@ -64,6 +83,96 @@ var displayNames = map[string]string{
 	"trade_license":    "Trade License",
 }
 
+// ── Company Calendar ─────────────────────────────────────────────
+// "Today" is always the date in the company's timezone, never the server's.
+// Working-day grace periods are converted to calendar days in SQL
+// (grace_days_for, migration 015) before they reach these functions;
+// GraceCalendarDays is the same rule in Go.
+
+// DefaultTimezone is used for companies without a (valid) timezone.
+const DefaultTimezone = "Asia/Dubai"
+
+// Grace period units (compliance_rules.grace_period_unit).
+const (
+	GraceUnitCalendar = "calendar"
+	GraceUnitWorking  = "working"
+)
+
+var (
+	locationMu    sync.RWMutex
+	locationCache = map[string]*time.Location{}
+)
+
+// Location resolves an IANA timezone name, falling back to DefaultTimezone
+// (or a fixed UTC+4 zone) when the name is empty or unknown.
+func Location(name string) *time.Location {
+	if name == "" {
+		name = DefaultTimezone
+	}
+	locationMu.RLock()
+	loc, ok := locationCache[name]
+	locationMu.RUnlock()
+	if ok {
+		return loc
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		if name != DefaultTimezone {
+			return Location(DefaultTimezone)
+		}
+		loc = time.FixedZone("GST", 4*60*60)
+	}
+	locationMu.Lock()
+	locationCache[name] = loc
+	locationMu.Unlock()
+	return loc
+}
+
+// ValidTimezone reports whether name is a loadable IANA timezone.
+func ValidTimezone(name string) bool {
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// Today returns now converted to the given timezone. Pass the result as
+// `now` to ComputeStatus, ComputeFine, DaysRemaining, etc.
+func Today(now time.Time, timezone string) time.Time {
+	return now.In(Location(timezone))
+}
+
+// GraceCalendarDays converts a grace period into calendar days after expiry,
+// as grace_days_for does. Calendar-unit grace is returned unchanged;
+// working-unit grace skips weekend days (ISO numbers, 1 = Monday … 7 =
+// Sunday) and holidays (keyed "2006-01-02"). With no working days in the
+// week the grace is counted in calendar days.
+func GraceCalendarDays(expiry time.Time, grace int, unit string, weekend []int, holidays map[string]bool) int {
+	if grace <= 0 {
+		return 0
+	}
+	if unit != GraceUnitWorking || len(weekend) >= 7 {
+		return grace
+	}
+
+	isWeekend := map[time.Weekday]bool{}
+	for _, d := range weekend {
+		isWeekend[time.Weekday(d%7)] = true
+	}
+
+	start := truncateToDay(expiry)
+	day := start
+	for left := grace; left > 0; {
+		day = day.AddDate(0, 0, 1)
+		if !isWeekend[day.Weekday()] && !holidays[day.Format("2006-01-02")] {
+			left--
+		}
+	}
+	return int(day.Sub(start).Hours() / 24)
+}
+
 // ── Status Computation ───────────────────────────────────────────
 
 // ComputeStatus derives the compliance status of a document.
@@ -91,7 +200,7 @@ func ComputeStatus(expiryDate *time.Time, graceDays int, docNumber string, now t
 	case daysUntilExpiry <= 0 && graceDays > 0 && -daysUntilExpiry <= graceDays:
 		// Expired but within grace window
 		return StatusInGrace
-	case daysUntilExpiry > 0 && daysUntilExpiry <= 30:
+	case daysUntilExpiry > 0 && daysUntilExpiry <= ExpiringSoonDays:
 		return StatusExpiringSoon
 	}
 
@@ -102,6 +211,19 @@ func ComputeStatus(expiryDate *time.Time, graceDays int, docNumber string, now t
 	return StatusValid
 }
 
+// RollupStatus returns the most severe of an employee's required document
+// statuses: penalty_active > in_grace > expiring_soon > incomplete > valid.
+// Returns StatusNone when no statuses are given.
+func RollupStatus(statuses []string) string {
+	worst := StatusNone
+	for _, s := range statuses {
+		if statusSeverity[s] > statusSeverity[worst] {
+			worst = s
+		}
+	}
+	return worst
+}
+
 // ── Fine Calculation ─────────────────────────────────────────────
 
 // ComputeFine calculates the estimated accumulated fine for a document.
@@ -153,6 +275,39 @@ func ComputeFine(expiryDate time.Time, graceDays int, finePerDay float64, fineTy
 	return math.Round(fine*100) / 100 // Round to 2 decimal places
 }
 
+// FineAt returns the fine owed on date `at` for a document that is not
+// renewed. If renewedOn is set and not after `at`, accrual stops at renewal:
+// the fine is what had accrued by renewedOn (fines already incurred are still
+// paid when the document is renewed). Used for forecasts and what-if savings.
+func FineAt(expiryDate time.Time, graceDays int, finePerDay float64, fineType string, fineCap float64, at time.Time, renewedOn *time.Time) float64 {
+	if renewedOn != nil && !truncateToDay(*renewedOn).After(truncateToDay(at)) {
+		at = *renewedOn
+	}
+	return ComputeFine(expiryDate, graceDays, finePerDay, fineType, fineCap, at)
+}
+
+// OutstandingFine returns the fine accrued by `now` that a settlement has
+// not covered. settledThrough is the last day covered by recorded payments;
+// what had accrued by then is treated as paid. One-time fines are therefore
+// fully cleared by any settlement after the penalty started.
+func OutstandingFine(expiryDate time.Time, graceDays int, finePerDay float64, fineType string, fineCap float64, now time.Time, settledThrough *time.Time) float64 {
+	fine := ComputeFine(expiryDate, graceDays, finePerDay, fineType, fineCap, now)
+	if settledThrough == nil || fine == 0 {
+		return fine
+	}
+	settled := ComputeFine(expiryDate, graceDays, finePerDay, fineType, fineCap, *settledThrough)
+	if settled >= fine {
+		return 0
+	}
+	return math.Round((fine-settled)*100) / 100
+}
+
+// PenaltyStart returns the first day on which a fine accrues
+// (the day after expiry + grace).
+func PenaltyStart(expiryDate time.Time, graceDays int) time.Time {
+	return truncateToDay(expiryDate).AddDate(0, 0, graceDays+1)
+}
+
 // ── Helper Computations ──────────────────────────────────────────
 
 // DaysRemaining returns the number of days until expiry.
@@ -235,7 +390,9 @@ func IsMandatoryType(docType string) bool {
 
 // ── Internal Helpers ─────────────────────────────────────────────
 
-// truncateToDay strips the time component, keeping only the date.
+// truncateToDay strips the time component, keeping only the calendar date
+// as seen in t's own location. The result is in UTC so dates from different
+// locations (DB dates are UTC, now is company-local) subtract cleanly.
 func truncateToDay(t time.Time) time.Time {
-	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
 }
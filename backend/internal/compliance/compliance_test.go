@@ -0,0 +1,232 @@
+package compliance
+
+import (
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestLocation(t *testing.T) {
+	tests := []struct {
+		name string
+		tz   string
+		want string
+	}{
+		{"empty uses default", "", DefaultTimezone},
+		{"unknown falls back to default", "Mars/Olympus_Mons", DefaultTimezone},
+		{"valid zone", "Asia/Kolkata", "Asia/Kolkata"},
+		{"utc", "UTC", "UTC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Location(tt.tz).String(); got != tt.want {
+				t.Errorf("Location(%q) = %s, want %s", tt.tz, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToday(t *testing.T) {
+	tests := []struct {
+		name string
+		now  string // RFC 3339, UTC
+		tz   string
+		want string
+	}{
+		{"before Dubai midnight", "2026-03-01T19:59:59Z", "Asia/Dubai", "2026-03-01"},
+		{"at Dubai midnight", "2026-03-01T20:00:00Z", "Asia/Dubai", "2026-03-02"},
+		{"Dubai is a day ahead of UTC", "2026-03-01T23:30:00Z", "Asia/Dubai", "2026-03-02"},
+		{"same instant in UTC", "2026-03-01T23:30:00Z", "UTC", "2026-03-01"},
+		{"year rollover", "2026-12-31T20:00:00Z", "Asia/Dubai", "2027-01-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now, _ := time.Parse(time.RFC3339, tt.now)
+			if got := Today(now, tt.tz).Format("2006-01-02"); got != tt.want {
+				t.Errorf("Today(%s, %s) = %s, want %s", tt.now, tt.tz, got, tt.want)
+			}
+		})
+	}
+}
+
+// A document expiring on 2 March is still "expiring soon" on a UTC clock at
+// 00:30 Dubai time, but in penalty on the company's own date.
+func TestComputeStatusAtMidnightBoundary(t *testing.T) {
+	expiry := date("2026-03-02")
+	now, _ := time.Parse(time.RFC3339, "2026-03-01T20:30:00Z")
+
+	tests := []struct {
+		tz   string
+		want string
+	}{
+		{"UTC", StatusExpiringSoon},
+		{"Asia/Dubai", StatusPenaltyActive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tz, func(t *testing.T) {
+			if got := ComputeStatus(&expiry, 0, "784-1", Today(now, tt.tz)); got != tt.want {
+				t.Errorf("ComputeStatus in %s = %s, want %s", tt.tz, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGraceCalendarDays(t *testing.T) {
+	satSun := []int{6, 7}
+	nationalDay := map[string]bool{"2026-12-01": true, "2026-12-02": true, "2026-12-03": true}
+
+	tests := []struct {
+		name     string
+		expiry   string
+		grace    int
+		unit     string
+		weekend  []int
+		holidays map[string]bool
+		want     int
+	}{
+		{"calendar unit unchanged", "2026-01-08", 5, GraceUnitCalendar, satSun, nil, 5},
+		{"no grace", "2026-01-08", 0, GraceUnitWorking, satSun, nil, 0},
+		{"negative grace", "2026-01-08", -3, GraceUnitWorking, satSun, nil, 0},
+		{"weekdays only", "2026-01-05", 3, GraceUnitWorking, satSun, nil, 3},
+		// Thu 8 Jan: Fri 9 counts, Sat/Sun skipped, Mon 12 counts
+		{"across a weekend", "2026-01-08", 2, GraceUnitWorking, satSun, nil, 4},
+		{"across a weekend and a holiday", "2026-01-08", 2, GraceUnitWorking, satSun, map[string]bool{"2026-01-12": true}, 5},
+		// Mon 30 Nov: 1–3 Dec holidays, Fri 4, Mon 7, Tue 8
+		{"across National Day", "2026-11-30", 3, GraceUnitWorking, satSun, nationalDay, 8},
+		{"Friday weekend", "2026-01-08", 1, GraceUnitWorking, []int{5}, nil, 2},
+		{"holiday on expiry day is not counted", "2026-12-01", 1, GraceUnitWorking, satSun, nationalDay, 3},
+		{"no working days falls back to calendar", "2026-01-08", 4, GraceUnitWorking, []int{1, 2, 3, 4, 5, 6, 7}, nil, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GraceCalendarDays(date(tt.expiry), tt.grace, tt.unit, tt.weekend, tt.holidays)
+			if got != tt.want {
+				t.Errorf("GraceCalendarDays(%s, %d, %s) = %d, want %d", tt.expiry, tt.grace, tt.unit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRollupStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string
+		want     string
+	}{
+		{"no required documents", nil, StatusNone},
+		{"all valid", []string{StatusValid, StatusValid}, StatusValid},
+		{"incomplete beats valid", []string{StatusValid, StatusIncomplete}, StatusIncomplete},
+		{"expiring beats incomplete", []string{StatusIncomplete, StatusExpiringSoon}, StatusExpiringSoon},
+		{"grace beats expiring", []string{StatusExpiringSoon, StatusInGrace, StatusValid}, StatusInGrace},
+		{"penalty is worst", []string{StatusInGrace, StatusPenaltyActive, StatusIncomplete}, StatusPenaltyActive},
+		{"unknown status ignored", []string{"archived", StatusValid}, StatusValid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RollupStatus(tt.statuses); got != tt.want {
+				t.Errorf("RollupStatus(%v) = %s, want %s", tt.statuses, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeFine(t *testing.T) {
+	tests := []struct {
+		name     string
+		grace    int
+		rate     float64
+		fineType string
+		cap      float64
+		now      string
+		want     float64
+	}{
+		{"not yet expired", 0, 50, FineTypeDaily, 0, "2025-12-31", 0},
+		{"expiry day", 0, 50, FineTypeDaily, 0, "2026-01-01", 0},
+		{"daily", 0, 50, FineTypeDaily, 0, "2026-01-11", 500},
+		{"daily capped", 30, 20, FineTypeDaily, 1000, "2026-06-01", 1000},
+		{"daily just under the cap", 30, 20, FineTypeDaily, 1000, "2026-03-21", 980},
+		{"last grace day", 30, 20, FineTypeDaily, 1000, "2026-01-31", 0},
+		{"first penalty day", 30, 20, FineTypeDaily, 1000, "2026-02-01", 20},
+		{"monthly rounds up", 0, 100, FineTypeMonthly, 0, "2026-02-01", 200},
+		{"monthly capped", 0, 100, FineTypeMonthly, 250, "2026-06-01", 250},
+		{"one time", 50, 500, FineTypeOneTime, 500, "2026-03-01", 500},
+		{"no rate", 0, 0, FineTypeDaily, 0, "2026-06-01", 0},
+		{"unknown type is daily", 0, 10, "weekly", 0, "2026-01-04", 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeFine(date("2026-01-01"), tt.grace, tt.rate, tt.fineType, tt.cap, date(tt.now))
+			if got != tt.want {
+				t.Errorf("ComputeFine(..., %s) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFineAt(t *testing.T) {
+	renewed := func(s string) *time.Time {
+		d := date(s)
+		return &d
+	}
+	tests := []struct {
+		name      string
+		cap       float64
+		at        string
+		renewedOn *time.Time
+		want      float64
+	}{
+		{"not renewed", 0, "2026-02-01", nil, 1550},
+		{"renewal stops accrual", 0, "2026-02-01", renewed("2026-01-11"), 500},
+		{"renewal on the date", 0, "2026-01-11", renewed("2026-01-11"), 500},
+		{"renewal after the date changes nothing", 0, "2026-02-01", renewed("2026-03-01"), 1550},
+		{"renewal before expiry saves everything", 0, "2026-02-01", renewed("2025-12-20"), 0},
+		{"capped", 1000, "2026-03-01", nil, 1000},
+		{"capped fine is not reduced by a late renewal", 1000, "2026-03-01", renewed("2026-02-25"), 1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FineAt(date("2026-01-01"), 0, 50, FineTypeDaily, tt.cap, date(tt.at), tt.renewedOn)
+			if got != tt.want {
+				t.Errorf("FineAt(..., %s) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutstandingFine(t *testing.T) {
+	settled := func(s string) *time.Time {
+		d := date(s)
+		return &d
+	}
+	tests := []struct {
+		name     string
+		fineType string
+		rate     float64
+		cap      float64
+		through  *time.Time
+		want     float64
+	}{
+		{"nothing settled", FineTypeDaily, 50, 0, nil, 1550},
+		{"settled part of the period", FineTypeDaily, 50, 0, settled("2026-01-11"), 1050},
+		{"settled through today", FineTypeDaily, 50, 0, settled("2026-02-01"), 0},
+		{"settled before the penalty", FineTypeDaily, 50, 0, settled("2025-12-15"), 1550},
+		{"cap reached after settlement", FineTypeDaily, 50, 1000, settled("2026-01-11"), 500},
+		{"cap reached before settlement", FineTypeDaily, 50, 1000, settled("2026-01-25"), 0},
+		{"one time cleared by any settlement", FineTypeOneTime, 400, 400, settled("2026-01-02"), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := OutstandingFine(date("2026-01-01"), 0, tt.rate, tt.fineType, tt.cap, date("2026-02-01"), tt.through)
+			if got != tt.want {
+				t.Errorf("OutstandingFine = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package compliance
+
+import "time"
+
+// ── Document Dependencies ────────────────────────────────────────
+// A dependency says the blocking document must be valid (for at least
+// MinValidityDays) before the blocked document can be renewed, e.g. a
+// passport needs 180 days left to renew a residence visa.
+
+// DependencyEdge is one blocking → blocked relation between doc types.
+type DependencyEdge struct {
+	Blocking string
+	Blocked  string
+}
+
+// MeetsMinValidity reports whether a blocking document with the given expiry
+// satisfies a minimum-validity threshold on `now`, and how many days it has left.
+// A missing expiry never satisfies the threshold. A threshold of 0 only
+// requires the document not to be expired.
+func MeetsMinValidity(blockingExpiry *time.Time, minValidityDays int, now time.Time) (bool, *int) {
+	days := DaysRemaining(blockingExpiry, now)
+	if days == nil {
+		return false, nil
+	}
+	if minValidityDays < 0 {
+		minValidityDays = 0
+	}
+	return *days >= minValidityDays, days
+}
+
+// FindDependencyCycle returns the doc types forming a cycle in the dependency
+// graph (first type repeated at the end, e.g. [visa emirates_id visa]),
+// or nil if the graph is acyclic.
+func FindDependencyCycle(edges []DependencyEdge) []string {
+	graph := map[string][]string{}
+	nodes := []string{}
+	seenNode := map[string]bool{}
+	for _, e := range edges {
+		graph[e.Blocking] = append(graph[e.Blocking], e.Blocked)
+		for _, n := range []string{e.Blocking, e.Blocked} {
+			if !seenNode[n] {
+				seenNode[n] = true
+				nodes = append(nodes, n)
+			}
+		}
+	}
+
+	const (
+		unvisited = 0
+		visiting  = 1
+		done      = 2
+	)
+	state := map[string]int{}
+	var stack []string
+
+	var visit func(n string) []string
+	visit = func(n string) []string {
+		state[n] = visiting
+		stack = append(stack, n)
+		for _, next := range graph[n] {
+			switch state[next] {
+			case visiting:
+				// Cycle: slice the stack from the first occurrence of next
+				for i, s := range stack {
+					if s == next {
+						cycle := append([]string{}, stack[i:]...)
+						return append(cycle, next)
+					}
+				}
+			case unvisited:
+				if cycle := visit(next); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[n] = done
+		return nil
+	}
+
+	for _, n := range nodes {
+		if state[n] == unvisited {
+			if cycle := visit(n); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
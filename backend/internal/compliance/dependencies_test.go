@@ -0,0 +1,75 @@
+package compliance
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMeetsMinValidity(t *testing.T) {
+	expiry := date("2026-07-01")
+	tests := []struct {
+		name     string
+		noExpiry bool
+		min      int
+		now      string
+		wantOK   bool
+		wantDays *int
+	}{
+		{"missing expiry", true, 0, "2026-01-01", false, nil},
+		{"enough validity", false, 180, "2026-01-01", true, intPtr(181)},
+		{"exactly the minimum", false, 181, "2026-01-01", true, intPtr(181)},
+		{"one day short", false, 182, "2026-01-01", false, intPtr(181)},
+		{"zero only needs unexpired", false, 0, "2026-07-01", true, intPtr(0)},
+		{"expired", false, 0, "2026-07-02", false, intPtr(-1)},
+		{"negative minimum treated as zero", false, -30, "2026-07-02", false, intPtr(-1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exp := &expiry
+			if tt.noExpiry {
+				exp = nil
+			}
+			ok, days := MeetsMinValidity(exp, tt.min, date(tt.now))
+			if ok != tt.wantOK || !reflect.DeepEqual(days, tt.wantDays) {
+				t.Errorf("MeetsMinValidity = (%v, %v), want (%v, %v)", ok, deref(days), tt.wantOK, deref(tt.wantDays))
+			}
+		})
+	}
+}
+
+func TestFindDependencyCycle(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []DependencyEdge
+		want  []string
+	}{
+		{"no edges", nil, nil},
+		{"chain", []DependencyEdge{{"passport", "visa"}, {"visa", "emirates_id"}}, nil},
+		{"diamond", []DependencyEdge{
+			{"passport", "visa"}, {"passport", "emirates_id"},
+			{"visa", "work_permit"}, {"emirates_id", "work_permit"},
+		}, nil},
+		{"self dependency", []DependencyEdge{{"visa", "visa"}}, []string{"visa", "visa"}},
+		{"two-way", []DependencyEdge{{"visa", "emirates_id"}, {"emirates_id", "visa"}}, []string{"visa", "emirates_id", "visa"}},
+		{"cycle behind a chain", []DependencyEdge{
+			{"passport", "visa"}, {"visa", "emirates_id"},
+			{"emirates_id", "work_permit"}, {"work_permit", "visa"},
+		}, []string{"visa", "emirates_id", "work_permit", "visa"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindDependencyCycle(tt.edges); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindDependencyCycle = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func intPtr(n int) *int { return &n }
+
+func deref(n *int) interface{} {
+	if n == nil {
+		return nil
+	}
+	return *n
+}
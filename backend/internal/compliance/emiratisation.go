@@ -0,0 +1,82 @@
+package compliance
+
+import (
+	"math"
+	"time"
+)
+
+// ── Emiratisation Quota ──────────────────────────────────────────
+// MOHRE checks each company's share of UAE nationals at mid-year and
+// year-end checkpoints (migration 033). The quota is projected from the
+// current headcount: what the company would owe if nothing changed before
+// the checkpoint.
+
+// Quota statuses for one checkpoint.
+const (
+	QuotaExempt    = "exempt"    // not a MOHRE company, or workforce below the target's minimum
+	QuotaMet       = "met"       // enough UAE nationals today
+	QuotaShortfall = "shortfall" // fewer UAE nationals than required
+)
+
+// QuotaTarget is the Emiratisation target in force at one checkpoint.
+type QuotaTarget struct {
+	Checkpoint     time.Time
+	TargetPercent  float64
+	MinHeadcount   int
+	MinWorkforce   int
+	FinePerMissing float64
+}
+
+// QuotaResult is a company's projected position at a checkpoint.
+type QuotaResult struct {
+	Status        string
+	Required      int     // UAE nationals required for the current workforce
+	Missing       int     // Required - nationals, if positive
+	HiresNeeded   int     // UAE nationals to hire (each hire also grows the workforce)
+	ProjectedFine float64 // Missing × FinePerMissing
+	DaysUntil     int     // calendar days from today to the checkpoint
+}
+
+// QuotaRequired returns the UAE nationals a workforce must include under t:
+// the target share rounded down, but at least MinHeadcount. Workforces
+// below MinWorkforce need none.
+func QuotaRequired(t QuotaTarget, workforce int) int {
+	if workforce < t.MinWorkforce {
+		return 0
+	}
+	required := int(math.Floor(float64(workforce)*t.TargetPercent/100 + 1e-9))
+	if required < t.MinHeadcount {
+		required = t.MinHeadcount
+	}
+	return required
+}
+
+// EvaluateQuota projects the current workforce and UAE-national headcount
+// onto target t as of today (company local date).
+func EvaluateQuota(t QuotaTarget, workforce, nationals int, today time.Time) QuotaResult {
+	res := QuotaResult{
+		Status:    QuotaExempt,
+		DaysUntil: int(truncateToDay(t.Checkpoint).Sub(truncateToDay(today)).Hours() / 24),
+	}
+	if workforce < t.MinWorkforce {
+		return res
+	}
+
+	res.Required = QuotaRequired(t, workforce)
+	if nationals >= res.Required {
+		res.Status = QuotaMet
+		return res
+	}
+
+	res.Status = QuotaShortfall
+	res.Missing = res.Required - nationals
+	res.ProjectedFine = float64(res.Missing) * t.FinePerMissing
+	res.HiresNeeded = res.Missing
+	if t.TargetPercent >= 100 {
+		return res
+	}
+	for nationals+res.HiresNeeded < QuotaRequired(t, workforce+res.HiresNeeded) {
+		res.HiresNeeded++
+	}
+	return res
+}
@@ -0,0 +1,56 @@
+package compliance
+
+import "time"
+
+// ── Exit Checklist ───────────────────────────────────────────────
+// When an employee resigns or is terminated, the company must cancel the
+// work permit and visa and settle final dues by legal deadlines. Each
+// checklist item is due on a fixed day after the exit date; the due date is
+// the last day allowed and fines accrue from the day after.
+// Documents of exited employees are tracked against the cancellation item
+// of their type instead of their expiry.
+
+// Exit checklist items.
+const (
+	ExitCancelWorkPermit = "cancel_work_permit"
+	ExitCancelVisa       = "cancel_visa"
+	ExitFinalSalary      = "final_salary"
+	ExitGratuity         = "gratuity"
+	ExitCompanyAssets    = "company_assets"
+)
+
+// ExitChecklistItems lists the items in the order they are shown.
+var ExitChecklistItems = []string{
+	ExitCancelWorkPermit, ExitCancelVisa, ExitFinalSalary, ExitGratuity, ExitCompanyAssets,
+}
+
+// Document tracking modes stored in document_status.tracking.
+const (
+	TrackingRenewal      = "renewal"      // active employee: status from expiry
+	TrackingCancellation = "cancellation" // exited: status from the cancellation item
+	TrackingNone         = "none"         // exited, no item for the type: not tracked
+)
+
+// ExitItemStatus returns the status of a checklist item on the document
+// status scale: valid once done, expiring_soon within ExpiringSoonDays of
+// the due date, penalty_active after it.
+func ExitItemStatus(due time.Time, done bool, now time.Time) string {
+	if done {
+		return StatusValid
+	}
+	today := truncateToDay(now)
+	due = truncateToDay(due)
+	switch {
+	case today.After(due):
+		return StatusPenaltyActive
+	case !due.After(today.AddDate(0, 0, ExpiringSoonDays)):
+		return StatusExpiringSoon
+	}
+	return StatusValid
+}
+
+// ExitItemFine returns the fine a checklist item has accrued by now. An
+// item completed late keeps the fine accrued up to its completion date.
+func ExitItemFine(due time.Time, completedOn *time.Time, finePerDay float64, fineType string, fineCap float64, now time.Time) float64 {
+	return FineAt(due, 0, finePerDay, fineType, fineCap, now, completedOn)
+}
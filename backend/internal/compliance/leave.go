@@ -0,0 +1,102 @@
+package compliance
+
+import (
+	"math"
+	"time"
+)
+
+// ── Annual Leave ─────────────────────────────────────────────────
+// UAE Labour Law: 2 days per completed month of service during the first
+// year, then 30 days per year (2.5 per completed month). Balances run per
+// calendar year; at year end up to the leave type's carry-over cap moves to
+// the next year and the rest is forfeited. A negative balance (leave taken
+// in advance) is carried in full.
+
+const (
+	AnnualLeaveFirstYearDaysPerMonth = 2.0
+	AnnualLeaveDaysPerYear           = 30.0
+)
+
+// LeaveYear is the annual leave account for one calendar year.
+type LeaveYear struct {
+	Year       int
+	CarriedIn  float64
+	Accrued    float64
+	Taken      float64
+	Balance    float64 // CarriedIn + Accrued - Taken
+	CarriedOut float64 // closed years only
+	Forfeited  float64 // closed years only
+}
+
+// completedMonths returns the whole months of service from joining to asOf.
+func completedMonths(joining, asOf time.Time) int {
+	joining, asOf = truncateToDay(joining), truncateToDay(asOf)
+	if asOf.Before(joining) {
+		return 0
+	}
+	months := (asOf.Year()-joining.Year())*12 + int(asOf.Month()-joining.Month())
+	if asOf.Day() < joining.Day() {
+		months--
+	}
+	if months < 0 {
+		return 0
+	}
+	return months
+}
+
+// AnnualLeaveAccrued returns the annual leave earned from joining to asOf.
+func AnnualLeaveAccrued(joining, asOf time.Time) float64 {
+	m := float64(completedMonths(joining, asOf))
+	if m <= 12 {
+		return m * AnnualLeaveFirstYearDaysPerMonth
+	}
+	return 12*AnnualLeaveFirstYearDaysPerMonth + (m-12)*AnnualLeaveDaysPerYear/12
+}
+
+// AnnualLeaveYears runs the annual leave account from the joining year to
+// asOf's year. takenByYear holds the annual leave days taken per calendar
+// year; carryCap is the most that moves into the next year (negative =
+// no cap). The last entry is the current year and stays open.
+func AnnualLeaveYears(joining, asOf time.Time, takenByYear map[int]float64, carryCap float64) []LeaveYear {
+	joining, asOf = truncateToDay(joining), truncateToDay(asOf)
+	years := []LeaveYear{}
+	carried := 0.0
+	for y := joining.Year(); y <= asOf.Year(); y++ {
+		end := time.Date(y, 12, 31, 0, 0, 0, 0, time.UTC)
+		if end.After(asOf) {
+			end = asOf
+		}
+		prevEnd := time.Date(y-1, 12, 31, 0, 0, 0, 0, time.UTC)
+		ly := LeaveYear{
+			Year:      y,
+			CarriedIn: carried,
+			Accrued:   roundDays(AnnualLeaveAccrued(joining, end) - AnnualLeaveAccrued(joining, prevEnd)),
+			Taken:     takenByYear[y],
+		}
+		ly.Balance = roundDays(ly.CarriedIn + ly.Accrued - ly.Taken)
+		if y < asOf.Year() {
+			ly.CarriedOut = ly.Balance
+			if carryCap >= 0 && ly.Balance > carryCap {
+				ly.CarriedOut = carryCap
+			}
+			ly.Forfeited = roundDays(ly.Balance - ly.CarriedOut)
+			carried = ly.CarriedOut
+		}
+		years = append(years, ly)
+	}
+	return years
+}
+
+// LeaveDays returns the calendar days from start to end, both inclusive.
+func LeaveDays(start, end time.Time) int {
+	days := int(truncateToDay(end).Sub(truncateToDay(start)).Hours()/24) + 1
+	if days < 0 {
+		return 0
+	}
+	return days
+}
+
+// roundDays rounds to half days, the unit balances are shown in.
+func roundDays(d float64) float64 {
+	return math.Round(d*2) / 2
+}
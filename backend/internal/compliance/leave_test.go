@@ -0,0 +1,58 @@
+package compliance
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnnualLeaveAccrued(t *testing.T) {
+	joining := date("2025-06-15")
+	tests := []struct {
+		name string
+		asOf string
+		want float64
+	}{
+		{"before joining", "2025-06-01", 0},
+		{"first month not completed", "2025-07-14", 0},
+		{"first month", "2025-07-15", 2},
+		{"end of first calendar year", "2025-12-31", 12},
+		{"first year completed", "2026-06-15", 24},
+		{"day before the second-year rate", "2026-07-14", 24},
+		{"first month of the second year", "2026-07-15", 26.5},
+		{"second year completed", "2027-06-15", 54},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AnnualLeaveAccrued(joining, date(tt.asOf)); got != tt.want {
+				t.Errorf("AnnualLeaveAccrued(2025-06-15, %s) = %v, want %v", tt.asOf, got, tt.want)
+			}
+		})
+	}
+}
+
+// The 2026 accrual spans the end of the first year: 6 months at 2 days,
+// then 6 months at 2.5.
+func TestAnnualLeaveYears(t *testing.T) {
+	tests := []struct {
+		name     string
+		carryCap float64
+		want     []LeaveYear
+	}{
+		{"capped carry-over", 5, []LeaveYear{
+			{Year: 2025, Accrued: 12, Taken: 4, Balance: 8, CarriedOut: 5, Forfeited: 3},
+			{Year: 2026, CarriedIn: 5, Accrued: 27, Taken: 10, Balance: 22},
+		}},
+		{"no cap", -1, []LeaveYear{
+			{Year: 2025, Accrued: 12, Taken: 4, Balance: 8, CarriedOut: 8},
+			{Year: 2026, CarriedIn: 8, Accrued: 27, Taken: 10, Balance: 25},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AnnualLeaveYears(date("2025-06-15"), date("2026-12-31"), map[int]float64{2025: 4, 2026: 10}, tt.carryCap)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AnnualLeaveYears = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package compliance
+
+import "time"
+
+// ── Linked Expiry ────────────────────────────────────────────────
+// A link says renewing the source document fixes the dates of the target,
+// e.g. an Emirates ID expires with the residence visa, ILOE insurance runs
+// one year from issue.
+
+// Link modes and actions.
+const (
+	LinkModeSourceExpiry = "source_expiry" // target expires on source expiry + offset
+	LinkModeFromIssue    = "from_issue"    // target runs a fixed validity from issue
+
+	LinkActionPropose = "propose" // renewal is suggested in the Renew response
+	LinkActionAuto    = "auto"    // renewal is created in the same transaction as the source's
+)
+
+// ExpiryLink is the date part of a link rule.
+type ExpiryLink struct {
+	Mode           string
+	OffsetDays     int
+	ValidityMonths int
+	ValidityDays   int
+}
+
+// LinkedDates returns the issue and expiry dates the target document gets
+// when its source is renewed with the given issue and expiry dates. The
+// target is issued with the source.
+func LinkedDates(link ExpiryLink, sourceIssue, sourceExpiry time.Time) (issue, expiry time.Time) {
+	issue = truncateToDay(sourceIssue)
+	switch link.Mode {
+	case LinkModeFromIssue:
+		expiry = issue.AddDate(0, link.ValidityMonths, link.ValidityDays)
+	default:
+		expiry = truncateToDay(sourceExpiry).AddDate(0, 0, link.OffsetDays)
+	}
+	return issue, expiry
+}
@@ -0,0 +1,30 @@
+package compliance
+
+import "testing"
+
+func TestLinkedDates(t *testing.T) {
+	tests := []struct {
+		name       string
+		link       ExpiryLink
+		wantIssue  string
+		wantExpiry string
+	}{
+		{"with source expiry", ExpiryLink{Mode: LinkModeSourceExpiry}, "2026-03-10", "2028-03-09"},
+		{"source expiry minus offset", ExpiryLink{Mode: LinkModeSourceExpiry, OffsetDays: -30}, "2026-03-10", "2028-02-08"},
+		{"source expiry plus offset", ExpiryLink{Mode: LinkModeSourceExpiry, OffsetDays: 15}, "2026-03-10", "2028-03-24"},
+		{"months from issue", ExpiryLink{Mode: LinkModeFromIssue, ValidityMonths: 12}, "2026-03-10", "2027-03-10"},
+		{"months and days from issue", ExpiryLink{Mode: LinkModeFromIssue, ValidityMonths: 1, ValidityDays: 5}, "2026-03-10", "2026-04-15"},
+		{"unknown mode follows source expiry", ExpiryLink{Mode: "", OffsetDays: 1}, "2026-03-10", "2028-03-10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issue, expiry := LinkedDates(tt.link, date("2026-03-10"), date("2028-03-09"))
+			if got := issue.Format("2006-01-02"); got != tt.wantIssue {
+				t.Errorf("issue = %s, want %s", got, tt.wantIssue)
+			}
+			if got := expiry.Format("2006-01-02"); got != tt.wantExpiry {
+				t.Errorf("expiry = %s, want %s", got, tt.wantExpiry)
+			}
+		})
+	}
+}
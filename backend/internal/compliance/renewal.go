@@ -0,0 +1,88 @@
+package compliance
+
+import "time"
+
+// ── Renewal Cases ────────────────────────────────────────────────
+// A renewal case tracks one document's renewal through the government
+// process. Each stage has a standard turnaround (SLA); the case must reach
+// a stage by the earlier of "entered the previous stage + SLA" and the date
+// that still leaves the later stages their SLA before the deadline (last
+// day of the grace period).
+
+// Renewal stages, in order.
+const (
+	StageRequested      = "requested"
+	StageMedicalDone    = "medical_done"
+	StageBiometricsDone = "biometrics_done"
+	StageSubmitted      = "submitted"
+	StageApproved       = "approved"
+	StageCompleted      = "completed"
+)
+
+// RenewalStages lists the stages in order.
+var RenewalStages = []string{
+	StageRequested, StageMedicalDone, StageBiometricsDone,
+	StageSubmitted, StageApproved, StageCompleted,
+}
+
+// RenewalStageSLA is the standard number of days to reach each stage from
+// the one before it.
+var RenewalStageSLA = map[string]int{
+	StageMedicalDone:    7,
+	StageBiometricsDone: 5,
+	StageSubmitted:      3,
+	StageApproved:       7,
+	StageCompleted:      3,
+}
+
+// RenewalStageIndex returns the position of stage in RenewalStages, or -1.
+func RenewalStageIndex(stage string) int {
+	for i, s := range RenewalStages {
+		if s == stage {
+			return i
+		}
+	}
+	return -1
+}
+
+// NextRenewalStage returns the stage after stage ("" after completed).
+func NextRenewalStage(stage string) string {
+	i := RenewalStageIndex(stage)
+	if i < 0 || i+1 >= len(RenewalStages) {
+		return ""
+	}
+	return RenewalStages[i+1]
+}
+
+// RenewalDeadline is the last day the renewal can complete without a fine:
+// the end of the grace period. Documents without an expiry get the total
+// SLA from the day the case was opened.
+func RenewalDeadline(expiry *time.Time, graceDays int, opened time.Time) time.Time {
+	if expiry == nil {
+		total := 0
+		for _, days := range RenewalStageSLA {
+			total += days
+		}
+		return truncateToDay(opened).AddDate(0, 0, total)
+	}
+	return PenaltyStart(*expiry, graceDays).AddDate(0, 0, -1)
+}
+
+// RenewalStageDue returns the date by which a case that entered its current
+// stage on entered must reach stage. Returns the zero time for unknown
+// stages and requested.
+func RenewalStageDue(stage string, entered, deadline time.Time) time.Time {
+	i := RenewalStageIndex(stage)
+	if i <= 0 {
+		return time.Time{}
+	}
+	latest := truncateToDay(deadline)
+	for _, later := range RenewalStages[i+1:] {
+		latest = latest.AddDate(0, 0, -RenewalStageSLA[later])
+	}
+	due := truncateToDay(entered).AddDate(0, 0, RenewalStageSLA[stage])
+	if latest.Before(due) {
+		return latest
+	}
+	return due
+}
@@ -0,0 +1,95 @@
+package compliance
+
+import "math"
+
+// ── Compliance Score ─────────────────────────────────────────────
+// A 0–100 score (100 = nothing outstanding) for an employee or a company.
+// Every document carries a weight from its fine rate and mandatory flag;
+// its status decides how much of that weight is lost:
+//
+//	score = 100 × (1 − Σ statusWeight × docWeight / Σ maxStatusWeight × docWeight)
+//
+// Company scores aggregate the same sums over all their documents rather
+// than averaging employee scores, so large headcounts weigh more.
+
+// ScoreWeights are the admin-configurable scoring weights (migration 020).
+type ScoreWeights struct {
+	// Status weights (0–10): the share of a document's weight lost in each status
+	Penalty    float64
+	Grace      float64
+	Expiring   float64
+	Incomplete float64
+
+	FineRate float64 // extra document weight per log(1 + fine per day in AED)
+	Optional float64 // weight of non-mandatory documents relative to mandatory (0–1)
+}
+
+// DefaultScoreWeights apply until an admin changes them.
+var DefaultScoreWeights = ScoreWeights{
+	Penalty:    1,
+	Grace:      0.6,
+	Expiring:   0.3,
+	Incomplete: 0.5,
+	FineRate:   0.25,
+	Optional:   0.25,
+}
+
+// DocumentWeight is how much one document counts towards the score. The fine
+// rate is on a log scale so a one-time 500 AED fine does not dwarf a
+// 50 AED/day one.
+func (w ScoreWeights) DocumentWeight(finePerDay float64, mandatory bool) float64 {
+	weight := 1 + w.FineRate*math.Log1p(math.Max(finePerDay, 0))
+	if !mandatory {
+		weight *= w.Optional
+	}
+	return weight
+}
+
+// StatusWeight is the share of a document's weight lost in the given status.
+func (w ScoreWeights) StatusWeight(status string) float64 {
+	switch status {
+	case StatusPenaltyActive:
+		return w.Penalty
+	case StatusInGrace:
+		return w.Grace
+	case StatusExpiringSoon:
+		return w.Expiring
+	case StatusIncomplete:
+		return w.Incomplete
+	}
+	return 0
+}
+
+// maxStatusWeight is the worst status weight, i.e. a document's full weight.
+func (w ScoreWeights) maxStatusWeight() float64 {
+	return math.Max(math.Max(w.Penalty, w.Grace), math.Max(w.Expiring, w.Incomplete))
+}
+
+// Score accumulates weighted documents. Lost and Max are persisted so scores
+// can be summed across employees and companies.
+type Score struct {
+	Lost float64
+	Max  float64
+}
+
+// Add folds one document into the score.
+func (s *Score) Add(w ScoreWeights, status string, finePerDay float64, mandatory bool) {
+	weight := w.DocumentWeight(finePerDay, mandatory)
+	s.Lost += w.StatusWeight(status) * weight
+	s.Max += w.maxStatusWeight() * weight
+}
+
+// Value returns the 0–100 score, rounded to one decimal. With nothing to
+// score (no documents, or all weights zero) the score is 100.
+func (s Score) Value() float64 {
+	return ScoreValue(s.Lost, s.Max)
+}
+
+// ScoreValue converts summed lost/max weights to a 0–100 score.
+func ScoreValue(lost, max float64) float64 {
+	if max <= 0 {
+		return 100
+	}
+	v := 100 * (1 - lost/max)
+	return math.Round(math.Max(0, math.Min(100, v))*10) / 10
+}
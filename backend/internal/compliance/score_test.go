@@ -0,0 +1,61 @@
+package compliance
+
+import "testing"
+
+func TestScore(t *testing.T) {
+	type doc struct {
+		status     string
+		finePerDay float64
+		mandatory  bool
+	}
+	tests := []struct {
+		name    string
+		weights ScoreWeights
+		docs    []doc
+		want    float64
+	}{
+		{"no documents", DefaultScoreWeights, nil, 100},
+		{"all valid", DefaultScoreWeights, []doc{{StatusValid, 50, true}, {StatusValid, 0, true}}, 100},
+		{"one of two in penalty", DefaultScoreWeights, []doc{{StatusPenaltyActive, 0, true}, {StatusValid, 0, true}}, 50},
+		{"in grace", DefaultScoreWeights, []doc{{StatusInGrace, 0, true}}, 40},
+		{"expiring soon", DefaultScoreWeights, []doc{{StatusExpiringSoon, 0, true}}, 70},
+		{"incomplete", DefaultScoreWeights, []doc{{StatusIncomplete, 0, true}}, 50},
+		{"optional documents weigh less", DefaultScoreWeights, []doc{{StatusPenaltyActive, 0, false}, {StatusValid, 0, true}}, 80},
+		// 1 + 0.25·ln(51) ≈ 1.983 against 1
+		{"higher fines weigh more", DefaultScoreWeights, []doc{{StatusPenaltyActive, 50, true}, {StatusValid, 0, true}}, 33.5},
+		{"all weights zero", ScoreWeights{}, []doc{{StatusPenaltyActive, 50, true}}, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Score
+			for _, d := range tt.docs {
+				s.Add(tt.weights, d.status, d.finePerDay, d.mandatory)
+			}
+			if got := s.Value(); got != tt.want {
+				t.Errorf("Score.Value() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		lost, max float64
+		want      float64
+	}{
+		{"nothing to score", 0, 0, 100},
+		{"nothing lost", 0, 4, 100},
+		{"everything lost", 4, 4, 0},
+		{"rounded to one decimal", 1, 3, 66.7},
+		{"clamped at zero", 5, 4, 0},
+		{"clamped at 100", -1, 4, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScoreValue(tt.lost, tt.max); got != tt.want {
+				t.Errorf("ScoreValue(%v, %v) = %v, want %v", tt.lost, tt.max, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package compliance
+
+import (
+	"math"
+	"time"
+)
+
+// ── Standard Validity ────────────────────────────────────────────
+// Most document types are issued for a fixed term (2-year visa, 1-year
+// insurance). The term gives the expected expiry for an issue date, and an
+// entered expiry far from it is most likely a typo.
+
+// Validity units.
+const (
+	ValidityMonths = "months"
+	ValidityDays   = "days"
+)
+
+// Validity is a document type's standard term. Period 0 = no standard term.
+type Validity struct {
+	Period int
+	Unit   string
+}
+
+// IsSet reports whether a standard term is configured.
+func (v Validity) IsSet() bool {
+	return v.Period > 0
+}
+
+// ExpiryFrom returns the expected expiry for a document issued on issue,
+// counted the same way as linked from-issue expiries.
+func (v Validity) ExpiryFrom(issue time.Time) time.Time {
+	issue = truncateToDay(issue)
+	if v.Unit == ValidityDays {
+		return issue.AddDate(0, 0, v.Period)
+	}
+	return issue.AddDate(0, v.Period, 0)
+}
+
+// ToleranceDays is how far an entered expiry may be from the expected one
+// before it is flagged: 10% of the term, at least a week.
+func (v Validity) ToleranceDays(issue time.Time) int {
+	term := v.ExpiryFrom(issue).Sub(truncateToDay(issue)).Hours() / 24
+	return int(math.Max(7, math.Round(term/10)))
+}
+
+// ExpiryDeviation compares an entered expiry with the standard term. It
+// returns the expected expiry, the signed difference in days (entered minus
+// expected) and whether the difference exceeds the tolerance.
+func (v Validity) ExpiryDeviation(issue, expiry time.Time) (expected time.Time, days int, off bool) {
+	expected = v.ExpiryFrom(issue)
+	days = int(math.Round(truncateToDay(expiry).Sub(expected).Hours() / 24))
+	tolerance := v.ToleranceDays(issue)
+	return expected, days, days > tolerance || days < -tolerance
+}
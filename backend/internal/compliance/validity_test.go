@@ -0,0 +1,43 @@
+package compliance
+
+import "testing"
+
+func TestValidity(t *testing.T) {
+	tests := []struct {
+		name          string
+		validity      Validity
+		expiry        string // entered expiry for 2026-03-10 issue
+		wantExpected  string
+		wantDays      int
+		wantOff       bool
+		wantTolerance int
+	}{
+		{"two years, exact", Validity{24, ValidityMonths}, "2028-03-10", "2028-03-10", 0, false, 73},
+		{"two years, a day early", Validity{24, ValidityMonths}, "2028-03-09", "2028-03-10", -1, false, 73},
+		{"two years, typo in year", Validity{24, ValidityMonths}, "2027-03-10", "2028-03-10", -366, true, 73},
+		{"two years, at the tolerance", Validity{24, ValidityMonths}, "2028-05-22", "2028-03-10", 73, false, 73},
+		{"two years, past the tolerance", Validity{24, ValidityMonths}, "2028-05-23", "2028-03-10", 74, true, 73},
+		{"30 days has a week's tolerance", Validity{30, ValidityDays}, "2026-04-17", "2026-04-09", 8, true, 7},
+		{"30 days within a week", Validity{30, ValidityDays}, "2026-04-02", "2026-04-09", -7, false, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issue := date("2026-03-10")
+			if !tt.validity.IsSet() {
+				t.Fatalf("%v should be set", tt.validity)
+			}
+			if got := tt.validity.ToleranceDays(issue); got != tt.wantTolerance {
+				t.Errorf("ToleranceDays = %d, want %d", got, tt.wantTolerance)
+			}
+			expected, days, off := tt.validity.ExpiryDeviation(issue, date(tt.expiry))
+			if got := expected.Format("2006-01-02"); got != tt.wantExpected || days != tt.wantDays || off != tt.wantOff {
+				t.Errorf("ExpiryDeviation = (%s, %d, %v), want (%s, %d, %v)",
+					got, days, off, tt.wantExpected, tt.wantDays, tt.wantOff)
+			}
+		})
+	}
+
+	if (Validity{}).IsSet() {
+		t.Error("zero Validity should not be set")
+	}
+}
@@ -6,8 +6,12 @@ import (
 	"log"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
+
 	"manpower-backend/internal/compliance"
 	"manpower-backend/internal/database"
+	"manpower-backend/internal/docstatus"
+	"manpower-backend/internal/emiratisation"
 )
 
 // StartNotifier launches a background goroutine that runs once per day
@@ -28,42 +32,87 @@ func StartNotifier(db database.Service) {
 	log.Println("[cron] compliance notifier started – runs every 24 h")
 }
 
-// runCycle queries documents that need attention and inserts a notification
-// for each relevant user. Notifications are de-duplicated by
-// (user_id, entity_type, entity_id) on the same day.
+// Timeouts for one notifier cycle. The status refresh covers every company
+// and gets its own, longer deadline; each notification pass has its own so a
+// slow pass cannot cancel the rest of the run.
+const (
+	statusRefreshTimeout = 10 * time.Minute
+	notifyPassTimeout    = 30 * time.Second
+)
+
+// runCycle refreshes stale document statuses and then runs each
+// notification pass.
 func runCycle(db database.Service) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	pool := db.GetPool()
+
+	// Make sure statuses are computed for today before reading them
+	refreshCtx, cancel := context.WithTimeout(context.Background(), statusRefreshTimeout)
+	if err := docstatus.RefreshStale(refreshCtx, pool); err != nil {
+		log.Printf("[cron] document status refresh error: %v", err)
+	}
+	cancel()
+
+	// Renewal cases past their stage due date
+	runPass(pool, escalateRenewalCases, "renewal case escalation", "escalated %d renewal cases")
+	// Exit checklist items that do not cancel a document
+	runPass(pool, notifyExitChecklist, "exit checklist notification", "sent %d exit checklist notifications")
+	// Absconding reports not yet filed with the authority
+	runPass(pool, notifyAbscondingFilings, "absconding filing notification", "sent %d absconding filing notifications")
+	// Labour contracts ending or past their end, probation ending
+	runPass(pool, notifyContracts, "contract notification", "sent %d contract notifications")
+	// Companies short of their next Emiratisation checkpoint
+	runPass(pool, notifyEmiratisation, "Emiratisation notification", "sent %d Emiratisation notifications")
+	// Documents expiring soon, in grace or in penalty
+	runPass(pool, notifyDocuments, "document notification", "compliance check complete – %d new notifications")
+}
+
+// runPass runs one notification pass under notifyPassTimeout and logs its
+// outcome; done is a format with one %d for the number of notifications.
+func runPass(pool *pgxpool.Pool, pass func(context.Context, *pgxpool.Pool) (int, error), name, done string) {
+	ctx, cancel := context.WithTimeout(context.Background(), notifyPassTimeout)
 	defer cancel()
 
-	pool := db.GetPool()
-	now := time.Now()
+	if n, err := pass(ctx, pool); err != nil {
+		log.Printf("[cron] %s error: %v", name, err)
+	} else if n > 0 {
+		log.Printf("[cron] "+done, n)
+	}
+}
 
-	// ─── 1. Fetch documents expiring within 30 days or already expired ───
+// notifyDocuments queries documents that need attention and inserts a
+// notification for each relevant user. Notifications are de-duplicated by
+// (user_id, entity_type, entity_id) on the same day in the company's
+// timezone.
+func notifyDocuments(ctx context.Context, pool *pgxpool.Pool) (int, error) {
+	// ─── 1. Fetch documents expiring soon, in grace or in penalty ───
+	// An open renewal case means the renewal is under way: skip "expiring
+	// soon" for it (grace and penalty alerts still go out). Documents of
+	// exited employees are tracked against their cancellation deadline.
+	// A dependent's documents are attributed to the sponsoring employee.
 	rows, err := pool.Query(ctx, `
 		SELECT
-			d.id, d.employee_id, d.document_type, d.expiry_date,
-			COALESCE(cr.grace_period_days, gr.grace_period_days, 0) AS grace_period_days,
-			COALESCE(cr.fine_per_day, gr.fine_per_day, 0) AS fine_per_day,
-			d.document_number,
-			COALESCE(cr.fine_type, gr.fine_type, 'daily') AS fine_type,
-			COALESCE(cr.fine_cap, gr.fine_cap, 0) AS fine_cap,
-			e.name AS employee_name,
+			ds.document_id, ds.employee_id, ds.document_type, ds.status, ds.tracking,
+			COALESCE(ds.days_remaining, 0),
+			COALESCE(ds.grace_days_remaining, 0),
+			ds.estimated_fine::float8,
+			e.name || COALESCE(' – dependent ' || dp.name, '') AS employee_name,
+			c.id   AS company_id,
 			c.name AS company_name,
 			u.id   AS user_id
-		FROM documents d
-		JOIN employees e ON d.employee_id = e.id
+		FROM document_status ds
+		JOIN documents d ON d.id          = ds.document_id
+		JOIN employees e ON ds.employee_id = e.id
 		JOIN companies c ON e.company_id  = c.id
 		JOIN users     u ON c.user_id     = u.id
-		LEFT JOIN compliance_rules cr ON cr.doc_type = d.document_type AND cr.company_id = e.company_id
-		LEFT JOIN compliance_rules gr ON gr.doc_type = d.document_type AND gr.company_id IS NULL
-		WHERE d.expiry_date IS NOT NULL
-		  AND d.expiry_date <= (NOW() + INTERVAL '30 days')
+		LEFT JOIN dependents dp ON dp.id  = d.dependent_id
+		WHERE ds.status IN ('expiring_soon', 'in_grace', 'penalty_active')
 		  AND d.file_url    IS NOT NULL
 		  AND d.file_url    != ''
+		  AND NOT (ds.status = 'expiring_soon' AND EXISTS (
+		      SELECT 1 FROM renewal_cases rc WHERE rc.document_id = ds.document_id AND rc.status = 'open'))
 	`)
 	if err != nil {
-		log.Printf("[cron] error querying documents: %v", err)
-		return
+		return 0, err
 	}
 	defer rows.Close()
 
@@ -71,13 +120,13 @@ func runCycle(db database.Service) {
 		DocID       string
 		EmpID       string
 		DocType     string
-		ExpiryDate  time.Time
-		GraceDays   int
-		FinePerDay  float64
-		DocNumber   *string
-		FineType    string
-		FineCap     float64
+		Status      string
+		Tracking    string
+		DaysRem     int
+		GraceRem    int
+		Fine        float64
 		EmpName     string
+		CompanyID   string
 		CompanyName string
 		UserID      string
 	}
@@ -86,10 +135,9 @@ func runCycle(db database.Service) {
 	for rows.Next() {
 		var a alertRow
 		if err := rows.Scan(
-			&a.DocID, &a.EmpID, &a.DocType, &a.ExpiryDate,
-			&a.GraceDays, &a.FinePerDay, &a.DocNumber,
-			&a.FineType, &a.FineCap,
-			&a.EmpName, &a.CompanyName, &a.UserID,
+			&a.DocID, &a.EmpID, &a.DocType, &a.Status, &a.Tracking,
+			&a.DaysRem, &a.GraceRem, &a.Fine,
+			&a.EmpName, &a.CompanyID, &a.CompanyName, &a.UserID,
 		); err != nil {
 			log.Printf("[cron] scan error: %v", err)
 			continue
@@ -99,57 +147,52 @@ func runCycle(db database.Service) {
 
 	if len(alerts) == 0 {
 		log.Println("[cron] no expiring / expired documents found")
-		return
+		return 0, nil
 	}
 
 	// ─── 2. Build & insert notifications (skip if already sent today) ────
 	inserted := 0
-	today := now.Format("2006-01-02")
 
 	for _, a := range alerts {
-		expiry := a.ExpiryDate // copy so we can take its address
-		docNum := ""
-		if a.DocNumber != nil {
-			docNum = *a.DocNumber
-		}
-
-		status := compliance.ComputeStatus(&expiry, a.GraceDays, docNum, now)
-		daysRemPtr := compliance.DaysRemaining(&expiry, now)
+		var title, message, nType string
+		switch {
+		case a.Tracking == compliance.TrackingCancellation && a.Status == compliance.StatusPenaltyActive:
+			title = fmt.Sprintf("🚨 %s – CANCELLATION OVERDUE", a.DocType)
+			message = fmt.Sprintf(
+				"%s (%s): %s cancellation is %d days overdue. Estimated fine: %.0f AED.",
+				a.EmpName, a.CompanyName, a.DocType, -a.DaysRem, a.Fine,
+			)
+			nType = "document_penalty"
 
-		daysRem := 0
-		if daysRemPtr != nil {
-			daysRem = *daysRemPtr
-		}
+		case a.Tracking == compliance.TrackingCancellation && a.Status == compliance.StatusExpiringSoon:
+			title = fmt.Sprintf("📋 %s – Cancellation Due", a.DocType)
+			message = fmt.Sprintf(
+				"%s (%s): employee has exited. Cancel %s within %d days to avoid fines.",
+				a.EmpName, a.CompanyName, a.DocType, a.DaysRem,
+			)
+			nType = "document_cancellation"
 
-		var title, message, nType string
-		switch status {
-		case compliance.StatusPenaltyActive:
-			fine := compliance.ComputeFine(expiry, a.GraceDays, a.FinePerDay, a.FineType, a.FineCap, now)
+		case a.Status == compliance.StatusPenaltyActive:
 			title = fmt.Sprintf("🚨 %s – PENALTY ACTIVE", a.DocType)
 			message = fmt.Sprintf(
 				"%s (%s): %s expired %d days ago. Estimated fine: %.0f AED.",
-				a.EmpName, a.CompanyName, a.DocType, -daysRem, fine,
+				a.EmpName, a.CompanyName, a.DocType, -a.DaysRem, a.Fine,
 			)
 			nType = "document_penalty"
 
-		case compliance.StatusInGrace:
-			graceRemPtr := compliance.GraceDaysRemaining(&expiry, a.GraceDays, now)
-			graceRem := 0
-			if graceRemPtr != nil {
-				graceRem = *graceRemPtr
-			}
+		case a.Status == compliance.StatusInGrace:
 			title = fmt.Sprintf("⚠️ %s – In Grace Period", a.DocType)
 			message = fmt.Sprintf(
 				"%s (%s): %s grace period active. Renew within %d days to avoid fines.",
-				a.EmpName, a.CompanyName, a.DocType, graceRem,
+				a.EmpName, a.CompanyName, a.DocType, a.GraceRem,
 			)
 			nType = "document_grace"
 
-		case compliance.StatusExpiringSoon:
+		case a.Status == compliance.StatusExpiringSoon:
 			title = fmt.Sprintf("📋 %s – Expiring Soon", a.DocType)
 			message = fmt.Sprintf(
 				"%s (%s): %s expires in %d days. Please renew promptly.",
-				a.EmpName, a.CompanyName, a.DocType, daysRem,
+				a.EmpName, a.CompanyName, a.DocType, a.DaysRem,
 			)
 			nType = "document_expiring"
 
@@ -158,7 +201,7 @@ func runCycle(db database.Service) {
 		}
 
 		// De-duplicate: skip if we already sent a notification for this
-		// exact document + user today.
+		// exact document + user today (company local date).
 		var exists bool
 		_ = pool.QueryRow(ctx, `
 			SELECT EXISTS(
@@ -166,9 +209,9 @@ func runCycle(db database.Service) {
 				WHERE user_id     = $1
 				  AND entity_type = 'document'
 				  AND entity_id   = $2
-				  AND created_at::date = $3::date
+				  AND created_at >= company_today($3::uuid)::timestamp AT TIME ZONE company_timezone($3::uuid)
 			)
-		`, a.UserID, a.DocID, today).Scan(&exists)
+		`, a.UserID, a.DocID, a.CompanyID).Scan(&exists)
 
 		if exists {
 			continue
@@ -185,5 +228,265 @@ func runCycle(db database.Service) {
 		inserted++
 	}
 
-	log.Printf("[cron] compliance check complete – %d new notifications from %d alerts", inserted, len(alerts))
+	return inserted, nil
+}
+
+// escalateRenewalCases notifies the company owner and the assignee of every
+// open renewal case whose current stage is past its due date, once per
+// stage (escalated_at is cleared when the stage changes).
+func escalateRenewalCases(ctx context.Context, pool *pgxpool.Pool) (int, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT rc.id, rc.document_type, rc.stage, rc.stage_due::text, rc.deadline::text,
+		       e.name, c.name, c.user_id::text, rc.assignee_id::text
+		FROM renewal_cases rc
+		JOIN employees e ON e.id = rc.employee_id
+		JOIN companies c ON c.id = rc.company_id
+		WHERE rc.status = 'open'
+		  AND rc.escalated_at IS NULL
+		  AND rc.stage_due < company_today(rc.company_id)
+	`)
+	if err != nil {
+		return 0, err
+	}
+
+	type caseRow struct {
+		ID, DocType, Stage, StageDue, Deadline string
+		EmpName, CompanyName                   string
+		OwnerID, AssigneeID                    *string
+	}
+	var cases []caseRow
+	for rows.Next() {
+		var c caseRow
+		if err := rows.Scan(&c.ID, &c.DocType, &c.Stage, &c.StageDue, &c.Deadline,
+			&c.EmpName, &c.CompanyName, &c.OwnerID, &c.AssigneeID); err != nil {
+			log.Printf("[cron] renewal case scan error: %v", err)
+			continue
+		}
+		cases = append(cases, c)
+	}
+	rows.Close()
+
+	escalated := 0
+	for _, c := range cases {
+		next := compliance.NextRenewalStage(c.Stage)
+		body := fmt.Sprintf("Stage %s was due by %s (deadline %s)", next, c.StageDue, c.Deadline)
+
+		tx, err := pool.Begin(ctx)
+		if err != nil {
+			return escalated, err
+		}
+		tag, err := tx.Exec(ctx, `
+			UPDATE renewal_cases SET escalated_at = NOW(), updated_at = NOW()
+			WHERE id = $1 AND escalated_at IS NULL
+		`, c.ID)
+		if err == nil && tag.RowsAffected() == 0 {
+			tx.Rollback(ctx) // escalated concurrently
+			continue
+		}
+		if err == nil {
+			_, err = tx.Exec(ctx, `
+				INSERT INTO renewal_case_events (case_id, kind, stage, body)
+				VALUES ($1, 'escalation', $2, $3)
+			`, c.ID, c.Stage, body)
+		}
+		recipients := map[string]bool{}
+		for _, userID := range []*string{c.OwnerID, c.AssigneeID} {
+			if userID != nil {
+				recipients[*userID] = true
+			}
+		}
+		for userID := range recipients {
+			if err != nil {
+				break
+			}
+			_, err = tx.Exec(ctx, `
+				INSERT INTO notifications (user_id, title, message, type, entity_type, entity_id)
+				VALUES ($1, $2, $3, 'renewal_escalation', 'renewal_case', $4)
+			`, userID,
+				fmt.Sprintf("⏰ %s renewal overdue", c.DocType),
+				fmt.Sprintf("%s (%s): %s renewal is stuck at %s. %s.", c.EmpName, c.CompanyName, c.DocType, c.Stage, body),
+				c.ID)
+		}
+		if err != nil {
+			tx.Rollback(ctx)
+			log.Printf("[cron] renewal case %s escalation error: %v", c.ID, err)
+			continue
+		}
+		if err := tx.Commit(ctx); err != nil {
+			log.Printf("[cron] renewal case %s escalation commit error: %v", c.ID, err)
+			continue
+		}
+		escalated++
+	}
+	return escalated, nil
+}
+
+// notifyExitChecklist alerts the company owner about exit checklist items
+// that do not cancel a document (final salary, gratuity, assets) and are
+// due within three days or overdue, once per item per day. Items that cancel
+// documents are alerted through their documents' cancellation tracking.
+func notifyExitChecklist(ctx context.Context, pool *pgxpool.Pool) (int, error) {
+	tag, err := pool.Exec(ctx, `
+		INSERT INTO notifications (user_id, title, message, type, entity_type, entity_id)
+		SELECT c.user_id,
+			CASE WHEN x.due_date < company_today(x.company_id)
+			     THEN '🚨 Exit checklist overdue – ' || replace(x.item, '_', ' ')
+			     ELSE '📋 Exit checklist due – ' || replace(x.item, '_', ' ') END,
+			e.name || ' (' || c.name || '): ' || replace(x.item, '_', ' ') || ' is due by ' || x.due_date::text || '.',
+			'exit_checklist', 'exit_checklist_item', x.id
+		FROM exit_checklist_items x
+		JOIN employees e ON e.id = x.employee_id
+		JOIN companies c ON c.id = x.company_id
+		WHERE x.status = 'pending'
+		  AND cardinality(x.doc_types) = 0
+		  AND x.due_date <= company_today(x.company_id) + 3
+		  AND c.user_id IS NOT NULL
+		  AND NOT EXISTS (
+		      SELECT 1 FROM notifications n
+		      WHERE n.user_id = c.user_id
+		        AND n.entity_type = 'exit_checklist_item'
+		        AND n.entity_id = x.id
+		        AND n.created_at >= company_today(x.company_id)::timestamp AT TIME ZONE company_timezone(x.company_id)
+		  )
+	`)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// notifyAbscondingFilings alerts the company owner about absconding cases
+// not yet filed with MOHRE/GDRFA whose filing deadline is within three days
+// or past, once per case per day.
+func notifyAbscondingFilings(ctx context.Context, pool *pgxpool.Pool) (int, error) {
+	tag, err := pool.Exec(ctx, `
+		INSERT INTO notifications (user_id, title, message, type, entity_type, entity_id)
+		SELECT c.user_id,
+			CASE WHEN ac.filing_deadline < company_today(ac.company_id)
+			     THEN '🚨 Absconding report overdue – ' || e.name
+			     ELSE '📋 Absconding report due – ' || e.name END,
+			e.name || ' (' || c.name || '): file the absconding report with ' || upper(ac.authority) ||
+				' by ' || ac.filing_deadline::text || '.',
+			'absconding_filing', 'absconding_case', ac.id
+		FROM absconding_cases ac
+		JOIN employees e ON e.id = ac.employee_id
+		JOIN companies c ON c.id = ac.company_id
+		WHERE ac.status = 'reported'
+		  AND ac.filing_deadline <= company_today(ac.company_id) + 3
+		  AND c.user_id IS NOT NULL
+		  AND NOT EXISTS (
+		      SELECT 1 FROM notifications n
+		      WHERE n.user_id = c.user_id
+		        AND n.entity_type = 'absconding_case'
+		        AND n.entity_id = ac.id
+		        AND n.created_at >= company_today(ac.company_id)::timestamp AT TIME ZONE company_timezone(ac.company_id)
+		  )
+	`)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// notifyContracts alerts the company owner about current labour contracts
+// (migration 032) ending within compliance.ExpiringSoonDays or already past
+// their end, and probation periods ending within the same window, once per
+// contract and alert type per day. Contracts left with a former company
+// after a transfer, and those of exited employees, are skipped.
+func notifyContracts(ctx context.Context, pool *pgxpool.Pool) (int, error) {
+	tag, err := pool.Exec(ctx, `
+		INSERT INTO notifications (user_id, title, message, type, entity_type, entity_id)
+		SELECT a.user_id, a.title, a.message, a.type, 'contract', a.id
+		FROM (
+			SELECT c.user_id, ec.id, ec.company_id, 'contract_expiring' AS type,
+				CASE WHEN ec.end_date < company_today(ec.company_id)
+				     THEN '🚨 Labour contract expired – ' || e.name
+				     ELSE '📋 Labour contract expiring – ' || e.name END AS title,
+				e.name || ' (' || c.name || '): ' || ec.contract_type || ' contract ' ||
+					CASE WHEN ec.end_date < company_today(ec.company_id)
+					     THEN 'ended on ' || ec.end_date::text || ' and has not been renewed.'
+					     ELSE 'ends on ' || ec.end_date::text || '. Renew it or give notice (' ||
+					          ec.notice_period_days || ' days).' END AS message
+			FROM employee_contracts ec
+			JOIN employees e ON e.id = ec.employee_id AND e.company_id = ec.company_id
+			JOIN companies c ON c.id = ec.company_id
+			WHERE ec.status = 'current' AND e.exit_type IS NULL
+			  AND ec.end_date <= company_today(ec.company_id) + $1
+
+			UNION ALL
+			SELECT c.user_id, ec.id, ec.company_id, 'probation_ending',
+				'📋 Probation ending – ' || e.name,
+				e.name || ' (' || c.name || '): probation ends on ' || ec.probation_end_date::text || '.'
+			FROM employee_contracts ec
+			JOIN employees e ON e.id = ec.employee_id AND e.company_id = ec.company_id
+			JOIN companies c ON c.id = ec.company_id
+			WHERE ec.status = 'current' AND e.exit_type IS NULL
+			  AND ec.probation_end_date BETWEEN company_today(ec.company_id)
+			                                AND company_today(ec.company_id) + $1
+		) a
+		WHERE a.user_id IS NOT NULL
+		  AND NOT EXISTS (
+		      SELECT 1 FROM notifications n
+		      WHERE n.user_id = a.user_id
+		        AND n.entity_type = 'contract'
+		        AND n.entity_id = a.id
+		        AND n.type = a.type
+		        AND n.created_at >= company_today(a.company_id)::timestamp AT TIME ZONE company_timezone(a.company_id)
+		  )
+	`, compliance.ExpiringSoonDays)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// emiratisationWarningDays is how long before a mid-year or year-end
+// checkpoint companies short of their Emiratisation target are warned.
+const emiratisationWarningDays = 60
+
+// notifyEmiratisation warns the owner of each company that, at today's
+// headcount, would miss its next Emiratisation checkpoint within
+// emiratisationWarningDays, once a week per company and checkpoint.
+func notifyEmiratisation(ctx context.Context, pool *pgxpool.Pool) (int, error) {
+	companies, err := emiratisation.Load(ctx, pool, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range companies {
+		c := &companies[i]
+		next := c.Next()
+		if c.OwnerID == nil || next == nil || next.Result.Status != compliance.QuotaShortfall ||
+			next.Result.DaysUntil > emiratisationWarningDays {
+			continue
+		}
+
+		checkpoint := next.Target.Checkpoint.Format("2006-01-02")
+		title := fmt.Sprintf("⚠️ Emiratisation shortfall by %s – %s", checkpoint, c.CompanyName)
+		message := fmt.Sprintf(
+			"%s: %d of %d UAE nationals required by %s (%d days). Hire %d more to avoid an estimated fine of %.0f AED.",
+			c.CompanyName, c.Nationals, next.Result.Required, checkpoint, next.Result.DaysUntil,
+			next.Result.HiresNeeded, next.Result.ProjectedFine,
+		)
+		tag, err := pool.Exec(ctx, `
+			INSERT INTO notifications (user_id, title, message, type, entity_type, entity_id)
+			SELECT $1, $2, $3, 'emiratisation_shortfall', 'company', $4
+			WHERE NOT EXISTS (
+			    SELECT 1 FROM notifications n
+			    WHERE n.user_id = $1
+			      AND n.entity_type = 'company'
+			      AND n.entity_id = $4
+			      AND n.type = 'emiratisation_shortfall'
+			      AND n.title = $2
+			      AND n.created_at > NOW() - INTERVAL '7 days'
+			)
+		`, *c.OwnerID, title, message, c.CompanyID)
+		if err != nil {
+			log.Printf("[cron] Emiratisation notification error for company %s: %v", c.CompanyID, err)
+			continue
+		}
+		sent += int(tag.RowsAffected())
+	}
+	return sent, nil
 }
@@ -0,0 +1,56 @@
+package cron
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"manpower-backend/internal/database"
+	"manpower-backend/internal/docstatus"
+)
+
+// StartStatusRefresher launches a background goroutine that keeps the
+// persisted document statuses (document_status, employee_compliance) current.
+// It runs once immediately and then hourly; each run only recomputes
+// companies whose local date has rolled over since their last refresh, or
+// that have rows missing after a failed write-time refresh. Before that,
+// approved leave starting or ending on the local date is applied to employee
+// status. Each run then records the day's compliance snapshot for every
+// company.
+func StartStatusRefresher(db database.Service) {
+	go func() {
+		refreshStatuses(db)
+
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			refreshStatuses(db)
+		}
+	}()
+
+	log.Println("[cron] document status refresher started – runs every 1 h")
+}
+
+func refreshStatuses(db database.Service) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	// Employees going on or returning from leave
+	if changed, err := docstatus.SyncLeaveStatuses(ctx, db.GetPool()); err != nil {
+		log.Printf("[cron] leave status sync error: %v", err)
+	} else if len(changed) > 0 {
+		log.Printf("[cron] updated leave status of %d employees", len(changed))
+	}
+
+	start := time.Now()
+	if err := docstatus.RefreshStale(ctx, db.GetPool()); err != nil {
+		log.Printf("[cron] document status refresh error: %v", err)
+		return
+	}
+	log.Printf("[cron] document status refresh complete in %s", time.Since(start).Round(time.Millisecond))
+
+	if err := docstatus.RecordSnapshots(ctx, db.GetPool()); err != nil {
+		log.Printf("[cron] compliance snapshot error: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package cron
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"manpower-backend/internal/database"
+)
+
+// StartTransferScheduler launches a background goroutine that applies
+// scheduled employee transfers once their effective date arrives. It runs
+// once immediately and then hourly, so a transfer takes effect within an
+// hour of midnight in the employee's company timezone. apply is
+// handlers.ApplyDueTransfers, passed in to keep this package free of the
+// HTTP layer.
+func StartTransferScheduler(db database.Service, apply func(context.Context, *pgxpool.Pool) (int, error)) {
+	go func() {
+		applyTransfers(db, apply)
+
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			applyTransfers(db, apply)
+		}
+	}()
+
+	log.Println("[cron] transfer scheduler started – runs every 1 h")
+}
+
+func applyTransfers(db database.Service, apply func(context.Context, *pgxpool.Pool) (int, error)) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	if n, err := apply(ctx, db.GetPool()); err != nil {
+		log.Printf("[cron] scheduled transfer error: %v", err)
+	} else if n > 0 {
+		log.Printf("[cron] applied %d scheduled transfers", n)
+	}
+}
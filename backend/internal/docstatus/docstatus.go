@@ -0,0 +1,530 @@
+// Package docstatus persists the compliance status of every document
+// (document_status) and its per-employee rollup (employee_compliance).
+// Status is computed by the compliance package only — SQL readers such as
+// the employee list, dashboard and notifier read these tables instead of
+// re-deriving the rules.
+package docstatus
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"manpower-backend/internal/compliance"
+)
+
+// Querier is satisfied by both *pgxpool.Pool and pgx.Tx.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+}
+
+// docInputQuery loads each document with the rule values in force for it.
+// The grace period is already in calendar days (grace_days_for, migration 015);
+// settled_through is the last day covered by the fine ledger (migration 018).
+// For exited employees, xi is the exit checklist item covering the document
+// type (migration 024); its fine settings replace the rule's, and only one
+// document per item (carries_fine) carries the item's fine.
+const docInputQuery = `
+	SELECT d.id, d.employee_id, e.company_id, d.document_type,
+		CASE WHEN d.dependent_id IS NULL
+			THEN doc_required_for(e.company_id, d.document_type, e.nationality, e.trade, e.gender, e.status)
+			ELSE dependent_doc_required_for(e.company_id, d.document_type, d.dependent_id) END,
+		d.expiry_date, COALESCE(d.document_number, ''),
+		grace_days_for(e.company_id, d.expiry_date,
+			COALESCE(cr.grace_period_days, gr.grace_period_days, 0),
+			COALESCE(cr.grace_period_unit, gr.grace_period_unit, 'calendar')),
+		COALESCE(xi.fine_per_day, cr.fine_per_day, gr.fine_per_day, 0)::float8,
+		COALESCE(xi.fine_type, cr.fine_type, gr.fine_type, 'daily'),
+		COALESCE(xi.fine_cap, cr.fine_cap, gr.fine_cap, 0)::float8,
+		c.timezone, fs.settled_through, COALESCE(fs.paid, 0)::float8,
+		e.status IN ('resigned', 'terminated'), xi.due_date,
+		COALESCE(xi.done, FALSE), COALESCE(xi.carries_fine, FALSE)
+	FROM documents d
+	JOIN employees e ON e.id = d.employee_id
+	JOIN companies c ON c.id = e.company_id
+	LEFT JOIN compliance_rules cr ON cr.doc_type = d.document_type AND cr.company_id = e.company_id
+	LEFT JOIN compliance_rules gr ON gr.doc_type = d.document_type AND gr.company_id IS NULL
+	LEFT JOIN LATERAL (
+		SELECT MAX(penalty_to) AS settled_through, SUM(amount) AS paid
+		FROM fine_settlements WHERE document_id = d.id
+	) fs ON TRUE
+	LEFT JOIN LATERAL (
+		SELECT x.due_date, x.status <> 'pending' AS done,
+			x.fine_per_day, x.fine_type, x.fine_cap,
+			d.id = (
+				SELECT d2.id FROM documents d2
+				WHERE d2.employee_id = x.employee_id AND d2.document_type = ANY(x.doc_types)
+				ORDER BY array_position(x.doc_types, d2.document_type::text), d2.created_at DESC
+				LIMIT 1
+			) AS carries_fine
+		FROM exit_checklist_items x
+		WHERE x.employee_id = d.employee_id AND d.document_type = ANY(x.doc_types)
+		  AND e.status IN ('resigned', 'terminated')
+		ORDER BY x.due_date
+		LIMIT 1
+	) xi ON TRUE
+	WHERE `
+
+const employeeInputQuery = `
+	SELECT e.id, e.company_id, c.timezone
+	FROM employees e
+	JOIN companies c ON c.id = e.company_id
+	WHERE `
+
+type docRow struct {
+	DocumentID   string
+	EmployeeID   string
+	CompanyID    string
+	DocumentType string
+	IsRequired   bool
+	ExpiryDate   *time.Time
+	DocNumber    string
+	GraceDays    int
+	FinePerDay   float64
+	FineType     string
+	FineCap      float64
+	Timezone     string
+
+	SettledThrough *time.Time
+	FinePaid       float64
+
+	// Exit checklist (migration 024)
+	Exited          bool
+	CancellationDue *time.Time
+	CancelledDone   bool
+	CarriesFine     bool
+
+	Tracking           string
+	Status             string
+	DaysRemaining      *int
+	GraceDaysRemaining *int
+	DaysInPenalty      *int
+	EstimatedFine      float64
+	AsOf               time.Time
+}
+
+type employeeRow struct {
+	EmployeeID        string
+	CompanyID         string
+	Status            string
+	NearestExpiryDays *int
+	DocsComplete      int
+	DocsTotal         int
+	UrgentDocType     *string
+	ExpiredCount      int
+	ExpiringCount     int
+	AsOf              time.Time
+	Score             compliance.Score
+
+	urgentExpiry *time.Time
+}
+
+// ── Refresh Entry Points ─────────────────────────────────────────
+
+// RefreshEmployees recomputes the status of every document belonging to the
+// given employees, plus their rollups.
+func RefreshEmployees(ctx context.Context, q Querier, employeeIDs ...string) error {
+	if len(employeeIDs) == 0 {
+		return nil
+	}
+	return refresh(ctx, q, "e.id::text = ANY($1)", employeeIDs, time.Now())
+}
+
+// RefreshCompany recomputes every document and employee of one company.
+func RefreshCompany(ctx context.Context, q Querier, companyID string) error {
+	return refresh(ctx, q, "e.company_id::text = $1", companyID, time.Now())
+}
+
+// RefreshAll recomputes every company, one at a time.
+func RefreshAll(ctx context.Context, q Querier) error {
+	return refreshCompanies(ctx, q, `SELECT id::text FROM companies`)
+}
+
+// RefreshStale recomputes companies whose rows were computed for an earlier
+// local date, or that have employees/documents with no row yet (e.g. a
+// refresh after a write failed). Cheap to call often: each company is
+// recomputed once per day in its own timezone.
+func RefreshStale(ctx context.Context, q Querier) error {
+	return refreshCompanies(ctx, q, `
+		SELECT c.id::text FROM companies c
+		WHERE EXISTS (
+			SELECT 1 FROM employees e
+			LEFT JOIN employee_compliance ec ON ec.employee_id = e.id
+			WHERE e.company_id = c.id
+			  AND (ec.employee_id IS NULL OR ec.as_of < company_today(c.id))
+		)
+		OR EXISTS (
+			SELECT 1 FROM documents d
+			JOIN employees e ON e.id = d.employee_id
+			LEFT JOIN document_status ds ON ds.document_id = d.id
+			WHERE e.company_id = c.id AND ds.document_id IS NULL
+		)
+	`)
+}
+
+func refreshCompanies(ctx context.Context, q Querier, query string) error {
+	rows, err := q.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := RefreshCompany(ctx, q, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadScoreWeights returns the admin-configured scoring weights
+// (migration 020), or the defaults when none are stored.
+func LoadScoreWeights(ctx context.Context, q Querier) (compliance.ScoreWeights, error) {
+	w := compliance.DefaultScoreWeights
+	err := q.QueryRow(ctx, `
+		SELECT penalty_weight::float8, grace_weight::float8, expiring_weight::float8,
+			incomplete_weight::float8, fine_rate_weight::float8, optional_weight::float8
+		FROM compliance_score_weights LIMIT 1
+	`).Scan(&w.Penalty, &w.Grace, &w.Expiring, &w.Incomplete, &w.FineRate, &w.Optional)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return compliance.DefaultScoreWeights, nil
+	}
+	return w, err
+}
+
+// ── Computation ──────────────────────────────────────────────────
+
+func refresh(ctx context.Context, q Querier, where string, arg interface{}, now time.Time) error {
+	docs, err := loadDocuments(ctx, q, where, arg, now)
+	if err != nil {
+		return err
+	}
+
+	weights, err := LoadScoreWeights(ctx, q)
+	if err != nil {
+		return err
+	}
+
+	employees, err := loadEmployees(ctx, q, where, arg, now)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[string]*employeeRow, len(employees))
+	for i := range employees {
+		byID[employees[i].EmployeeID] = &employees[i]
+	}
+	statuses := map[string][]string{}
+	for i := range docs {
+		d := &docs[i]
+		emp := byID[d.EmployeeID]
+		if emp == nil || d.Tracking == compliance.TrackingNone {
+			continue
+		}
+		// Optional documents count towards the score only
+		emp.Score.Add(weights, d.Status, d.FinePerDay, d.IsRequired)
+		if !d.IsRequired {
+			continue
+		}
+		statuses[d.EmployeeID] = append(statuses[d.EmployeeID], d.Status)
+		addToRollup(emp, d)
+	}
+	for i := range employees {
+		employees[i].Status = compliance.RollupStatus(statuses[employees[i].EmployeeID])
+	}
+
+	if err := saveDocuments(ctx, q, docs); err != nil {
+		return err
+	}
+	return saveEmployees(ctx, q, employees)
+}
+
+func loadDocuments(ctx context.Context, q Querier, where string, arg interface{}, now time.Time) ([]docRow, error) {
+	rows, err := q.Query(ctx, docInputQuery+where, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := []docRow{}
+	for rows.Next() {
+		var d docRow
+		if err := rows.Scan(
+			&d.DocumentID, &d.EmployeeID, &d.CompanyID, &d.DocumentType,
+			&d.IsRequired, &d.ExpiryDate, &d.DocNumber,
+			&d.GraceDays, &d.FinePerDay, &d.FineType, &d.FineCap,
+			&d.Timezone, &d.SettledThrough, &d.FinePaid,
+			&d.Exited, &d.CancellationDue, &d.CancelledDone, &d.CarriesFine,
+		); err != nil {
+			return nil, err
+		}
+		computeDocument(&d, compliance.Today(now, d.Timezone))
+		docs = append(docs, d)
+	}
+	return docs, rows.Err()
+}
+
+func loadEmployees(ctx context.Context, q Querier, where string, arg interface{}, now time.Time) ([]employeeRow, error) {
+	rows, err := q.Query(ctx, employeeInputQuery+where, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	employees := []employeeRow{}
+	for rows.Next() {
+		var e employeeRow
+		var timezone string
+		if err := rows.Scan(&e.EmployeeID, &e.CompanyID, &timezone); err != nil {
+			return nil, err
+		}
+		e.AsOf = localDate(compliance.Today(now, timezone))
+		employees = append(employees, e)
+	}
+	return employees, rows.Err()
+}
+
+// computeDocument fills the derived fields using the compliance engine.
+func computeDocument(d *docRow, now time.Time) {
+	d.AsOf = localDate(now)
+	switch {
+	case d.Exited && d.CancellationDue != nil:
+		computeCancellation(d, now)
+		return
+	case d.Exited:
+		// Nothing to renew or cancel for this type once the employee left
+		d.Tracking = compliance.TrackingNone
+		d.IsRequired = false
+		d.Status = compliance.StatusNone
+		d.DaysRemaining, d.GraceDaysRemaining, d.DaysInPenalty = nil, nil, nil
+		d.FinePerDay, d.EstimatedFine = 0, 0
+		return
+	}
+
+	d.Tracking = compliance.TrackingRenewal
+	d.Status = compliance.ComputeStatus(d.ExpiryDate, d.GraceDays, d.DocNumber, now)
+	d.DaysRemaining = compliance.DaysRemaining(d.ExpiryDate, now)
+	d.GraceDaysRemaining = nil
+	d.DaysInPenalty = nil
+	d.EstimatedFine = 0
+
+	switch d.Status {
+	case compliance.StatusInGrace:
+		d.GraceDaysRemaining = compliance.GraceDaysRemaining(d.ExpiryDate, d.GraceDays, now)
+	case compliance.StatusPenaltyActive:
+		d.DaysInPenalty = compliance.DaysInPenalty(d.ExpiryDate, d.GraceDays, now)
+		d.EstimatedFine = compliance.OutstandingFine(*d.ExpiryDate, d.GraceDays, d.FinePerDay, d.FineType, d.FineCap, now, d.SettledThrough)
+	}
+}
+
+// computeCancellation tracks an exited employee's document against the
+// checklist item that cancels it: the item's due date replaces the expiry
+// and the grace period. The item's fine is carried by one document only.
+func computeCancellation(d *docRow, now time.Time) {
+	due := *d.CancellationDue
+	d.Tracking = compliance.TrackingCancellation
+	d.IsRequired = true
+	d.GraceDays = 0
+	d.Status = compliance.ExitItemStatus(due, d.CancelledDone, now)
+	d.DaysRemaining = compliance.DaysRemaining(&due, now)
+	d.GraceDaysRemaining = nil
+	d.DaysInPenalty = nil
+	d.EstimatedFine = 0
+	if !d.CarriesFine {
+		d.FinePerDay, d.FineCap = 0, 0
+	}
+	if d.Status == compliance.StatusPenaltyActive {
+		d.DaysInPenalty = compliance.DaysInPenalty(&due, 0, now)
+		d.EstimatedFine = compliance.OutstandingFine(due, 0, d.FinePerDay, d.FineType, d.FineCap, now, d.SettledThrough)
+	}
+}
+
+// addToRollup folds one required document into its employee's rollup.
+func addToRollup(e *employeeRow, d *docRow) {
+	e.DocsTotal++
+	if d.ExpiryDate != nil && d.DocNumber != "" {
+		e.DocsComplete++
+	}
+	switch d.Status {
+	case compliance.StatusPenaltyActive:
+		e.ExpiredCount++
+	case compliance.StatusExpiringSoon:
+		e.ExpiringCount++
+	}
+	if d.ExpiryDate == nil {
+		return
+	}
+	if e.NearestExpiryDays == nil || *d.DaysRemaining < *e.NearestExpiryDays {
+		days := *d.DaysRemaining
+		e.NearestExpiryDays = &days
+	}
+	if e.urgentExpiry == nil || d.ExpiryDate.Before(*e.urgentExpiry) {
+		docType := d.DocumentType
+		e.urgentExpiry = d.ExpiryDate
+		e.UrgentDocType = &docType
+	}
+}
+
+// localDate returns the calendar date of t (in t's location) as a UTC
+// midnight, so pgx writes it as that date.
+func localDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// ── Persistence ──────────────────────────────────────────────────
+
+func saveDocuments(ctx context.Context, q Querier, docs []docRow) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	n := len(docs)
+	ids, empIDs, companyIDs := make([]string, n), make([]string, n), make([]string, n)
+	docTypes, statuses, fineTypes := make([]string, n), make([]string, n), make([]string, n)
+	required := make([]bool, n)
+	expiries, asOf := make([]*time.Time, n), make([]time.Time, n)
+	daysRem, graceRem, penalty := make([]*int, n), make([]*int, n), make([]*int, n)
+	graceDays := make([]int, n)
+	finePerDay, fineCap, fines := make([]float64, n), make([]float64, n), make([]float64, n)
+	settled, paid := make([]*time.Time, n), make([]float64, n)
+	tracking, cancelDue := make([]string, n), make([]*time.Time, n)
+	for i, d := range docs {
+		ids[i], empIDs[i], companyIDs[i] = d.DocumentID, d.EmployeeID, d.CompanyID
+		docTypes[i], statuses[i], fineTypes[i] = d.DocumentType, d.Status, d.FineType
+		required[i] = d.IsRequired
+		expiries[i], asOf[i] = d.ExpiryDate, d.AsOf
+		daysRem[i], graceRem[i], penalty[i] = d.DaysRemaining, d.GraceDaysRemaining, d.DaysInPenalty
+		graceDays[i] = d.GraceDays
+		finePerDay[i], fineCap[i], fines[i] = d.FinePerDay, d.FineCap, d.EstimatedFine
+		settled[i], paid[i] = d.SettledThrough, d.FinePaid
+		tracking[i] = d.Tracking
+		if d.Tracking == compliance.TrackingCancellation {
+			cancelDue[i] = d.CancellationDue
+		}
+	}
+
+	_, err := q.Exec(ctx, `
+		INSERT INTO document_status (
+			document_id, employee_id, company_id, document_type, is_required,
+			status, expiry_date, days_remaining, grace_days, grace_days_remaining,
+			days_in_penalty, fine_per_day, fine_type, fine_cap, estimated_fine,
+			as_of, settled_through, fine_paid, tracking, cancellation_due, computed_at
+		)
+		SELECT u.document_id::uuid, u.employee_id::uuid, u.company_id::uuid,
+			u.document_type, u.is_required, u.status, u.expiry_date, u.days_remaining,
+			u.grace_days, u.grace_days_remaining, u.days_in_penalty, u.fine_per_day,
+			u.fine_type, u.fine_cap, u.estimated_fine, u.as_of,
+			u.settled_through, u.fine_paid, u.tracking, u.cancellation_due, NOW()
+		FROM unnest(
+			$1::text[], $2::text[], $3::text[], $4::text[], $5::bool[],
+			$6::text[], $7::date[], $8::int[], $9::int[], $10::int[],
+			$11::int[], $12::numeric[], $13::text[], $14::numeric[], $15::numeric[],
+			$16::date[], $17::date[], $18::numeric[], $19::text[], $20::date[]
+		) AS u(document_id, employee_id, company_id, document_type, is_required,
+			status, expiry_date, days_remaining, grace_days, grace_days_remaining,
+			days_in_penalty, fine_per_day, fine_type, fine_cap, estimated_fine, as_of,
+			settled_through, fine_paid, tracking, cancellation_due)
+		ON CONFLICT (document_id) DO UPDATE SET
+			employee_id          = EXCLUDED.employee_id,
+			company_id           = EXCLUDED.company_id,
+			document_type        = EXCLUDED.document_type,
+			is_required          = EXCLUDED.is_required,
+			status               = EXCLUDED.status,
+			expiry_date          = EXCLUDED.expiry_date,
+			days_remaining       = EXCLUDED.days_remaining,
+			grace_days           = EXCLUDED.grace_days,
+			grace_days_remaining = EXCLUDED.grace_days_remaining,
+			days_in_penalty      = EXCLUDED.days_in_penalty,
+			fine_per_day         = EXCLUDED.fine_per_day,
+			fine_type            = EXCLUDED.fine_type,
+			fine_cap             = EXCLUDED.fine_cap,
+			estimated_fine       = EXCLUDED.estimated_fine,
+			as_of                = EXCLUDED.as_of,
+			settled_through      = EXCLUDED.settled_through,
+			fine_paid            = EXCLUDED.fine_paid,
+			tracking             = EXCLUDED.tracking,
+			cancellation_due     = EXCLUDED.cancellation_due,
+			computed_at          = NOW()
+	`, ids, empIDs, companyIDs, docTypes, required,
+		statuses, expiries, daysRem, graceDays, graceRem,
+		penalty, finePerDay, fineTypes, fineCap, fines,
+		asOf, settled, paid, tracking, cancelDue,
+	)
+	return err
+}
+
+func saveEmployees(ctx context.Context, q Querier, employees []employeeRow) error {
+	if len(employees) == 0 {
+		return nil
+	}
+
+	n := len(employees)
+	ids, companyIDs, statuses := make([]string, n), make([]string, n), make([]string, n)
+	nearest := make([]*int, n)
+	complete, total, expired, expiring := make([]int, n), make([]int, n), make([]int, n), make([]int, n)
+	urgent := make([]*string, n)
+	asOf := make([]time.Time, n)
+	score, scoreLost, scoreMax := make([]float64, n), make([]float64, n), make([]float64, n)
+	for i, e := range employees {
+		ids[i], companyIDs[i], statuses[i] = e.EmployeeID, e.CompanyID, e.Status
+		nearest[i] = e.NearestExpiryDays
+		complete[i], total[i], expired[i], expiring[i] = e.DocsComplete, e.DocsTotal, e.ExpiredCount, e.ExpiringCount
+		urgent[i] = e.UrgentDocType
+		asOf[i] = e.AsOf
+		score[i], scoreLost[i], scoreMax[i] = e.Score.Value(), e.Score.Lost, e.Score.Max
+	}
+
+	_, err := q.Exec(ctx, `
+		INSERT INTO employee_compliance (
+			employee_id, company_id, compliance_status, nearest_expiry_days,
+			docs_complete, docs_total, urgent_doc_type, expired_count,
+			expiring_count, as_of, score, score_lost, score_max, computed_at
+		)
+		SELECT u.employee_id::uuid, u.company_id::uuid, u.compliance_status,
+			u.nearest_expiry_days, u.docs_complete, u.docs_total, u.urgent_doc_type,
+			u.expired_count, u.expiring_count, u.as_of,
+			u.score, u.score_lost, u.score_max, NOW()
+		FROM unnest(
+			$1::text[], $2::text[], $3::text[], $4::int[],
+			$5::int[], $6::int[], $7::text[], $8::int[],
+			$9::int[], $10::date[], $11::numeric[], $12::numeric[], $13::numeric[]
+		) AS u(employee_id, company_id, compliance_status, nearest_expiry_days,
+			docs_complete, docs_total, urgent_doc_type, expired_count,
+			expiring_count, as_of, score, score_lost, score_max)
+		ON CONFLICT (employee_id) DO UPDATE SET
+			company_id          = EXCLUDED.company_id,
+			compliance_status   = EXCLUDED.compliance_status,
+			nearest_expiry_days = EXCLUDED.nearest_expiry_days,
+			docs_complete       = EXCLUDED.docs_complete,
+			docs_total          = EXCLUDED.docs_total,
+			urgent_doc_type     = EXCLUDED.urgent_doc_type,
+			expired_count       = EXCLUDED.expired_count,
+			expiring_count      = EXCLUDED.expiring_count,
+			as_of               = EXCLUDED.as_of,
+			score               = EXCLUDED.score,
+			score_lost          = EXCLUDED.score_lost,
+			score_max           = EXCLUDED.score_max,
+			computed_at         = NOW()
+	`, ids, companyIDs, statuses, nearest,
+		complete, total, urgent, expired,
+		expiring, asOf, score, scoreLost, scoreMax,
+	)
+	return err
+}
@@ -0,0 +1,98 @@
+package docstatus
+
+import "context"
+
+// ── Leave ────────────────────────────────────────────────────────
+// Approved leave (migration 030) switches an active employee to on_leave
+// on its start date and back to active after its end date, both in the
+// company's time zone. A leave that switched the employee is marked
+// on_leave_applied, so a status set by hand is never reverted. Leave that
+// ends is processed before leave that starts, so back-to-back leave keeps
+// the employee on leave.
+
+// leaveEndQuery reverts employees whose applied leave has ended, been
+// cancelled or been moved into the future. $1 = employee IDs (NULL = all).
+const leaveEndQuery = `
+	WITH done AS (
+		SELECT lr.id, lr.employee_id
+		FROM leave_requests lr
+		JOIN employees e ON e.id = lr.employee_id
+		WHERE lr.on_leave_applied
+		  AND (lr.status <> 'approved'
+		       OR lr.end_date < company_today(e.company_id)
+		       OR lr.start_date > company_today(e.company_id))
+		  AND ($1::text[] IS NULL OR lr.employee_id::text = ANY($1))
+	), cleared AS (
+		UPDATE leave_requests SET on_leave_applied = FALSE, updated_at = NOW()
+		WHERE id IN (SELECT id FROM done)
+	)
+	UPDATE employees e SET status = 'active', updated_at = NOW()
+	WHERE e.id IN (SELECT employee_id FROM done)
+	  AND e.status = 'on_leave' AND e.exit_type IS NULL
+	  AND NOT EXISTS (
+		SELECT 1 FROM leave_requests o
+		WHERE o.employee_id = e.id AND o.on_leave_applied
+		  AND o.id NOT IN (SELECT id FROM done)
+	  )
+	RETURNING e.id::text`
+
+// leaveStartQuery puts active employees whose approved leave covers today
+// on leave. $1 = employee IDs (NULL = all).
+const leaveStartQuery = `
+	WITH due AS (
+		SELECT lr.id, lr.employee_id
+		FROM leave_requests lr
+		JOIN employees e ON e.id = lr.employee_id
+		WHERE lr.status = 'approved' AND NOT lr.on_leave_applied
+		  AND lr.start_date <= company_today(e.company_id)
+		  AND lr.end_date >= company_today(e.company_id)
+		  AND e.status = 'active' AND e.exit_type IS NULL
+		  AND ($1::text[] IS NULL OR lr.employee_id::text = ANY($1))
+	), applied AS (
+		UPDATE leave_requests SET on_leave_applied = TRUE, updated_at = NOW()
+		WHERE id IN (SELECT id FROM due)
+	)
+	UPDATE employees e SET status = 'on_leave', updated_at = NOW()
+	WHERE e.id IN (SELECT employee_id FROM due)
+	RETURNING e.id::text`
+
+// SyncLeaveStatuses applies approved leave to the given employees' status
+// (none = every employee) and refreshes the document status of those that
+// changed. Returns the IDs of the employees whose status changed.
+func SyncLeaveStatuses(ctx context.Context, q Querier, employeeIDs ...string) ([]string, error) {
+	var filter []string
+	if len(employeeIDs) > 0 {
+		filter = employeeIDs
+	}
+
+	changed := []string{}
+	seen := map[string]bool{}
+	for _, query := range []string{leaveEndQuery, leaveStartQuery} {
+		rows, err := q.Query(ctx, query, filter)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if !seen[id] {
+				seen[id] = true
+				changed = append(changed, id)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(changed) > 0 {
+		if err := RefreshEmployees(ctx, q, changed...); err != nil {
+			return changed, err
+		}
+	}
+	return changed, nil
+}
@@ -0,0 +1,66 @@
+package docstatus
+
+import "context"
+
+// ── Daily Snapshots ──────────────────────────────────────────────
+
+// RecordSnapshots copies every company's current counts into
+// compliance_snapshots (migration 019) for its local date. Run it after
+// RefreshStale so the counts reflect today's statuses; repeated runs on the
+// same day overwrite that day's row.
+func RecordSnapshots(ctx context.Context, q Querier) error {
+	_, err := q.Exec(ctx, `
+		INSERT INTO compliance_snapshots (
+			company_id, snapshot_date,
+			docs_valid, docs_expiring_soon, docs_in_grace, docs_penalty_active, docs_incomplete,
+			employees_total, employees_compliant, employees_incomplete, employees_penalty,
+			fine_exposure, score_lost, score_max, recorded_at
+		)
+		SELECT c.id, company_today(c.id),
+			COALESCE(d.valid, 0), COALESCE(d.expiring_soon, 0), COALESCE(d.in_grace, 0),
+			COALESCE(d.penalty_active, 0), COALESCE(d.incomplete, 0),
+			COALESCE(ec.total, 0), COALESCE(ec.compliant, 0), COALESCE(ec.incomplete, 0),
+			COALESCE(ec.penalty, 0),
+			COALESCE(d.exposure, 0), COALESCE(ec.score_lost, 0), COALESCE(ec.score_max, 0), NOW()
+		FROM companies c
+		LEFT JOIN LATERAL (
+			SELECT
+				COUNT(*) FILTER (WHERE ds.status = 'valid')          AS valid,
+				COUNT(*) FILTER (WHERE ds.status = 'expiring_soon')  AS expiring_soon,
+				COUNT(*) FILTER (WHERE ds.status = 'in_grace')       AS in_grace,
+				COUNT(*) FILTER (WHERE ds.status = 'penalty_active') AS penalty_active,
+				COUNT(*) FILTER (WHERE ds.status = 'incomplete')     AS incomplete,
+				SUM(ds.estimated_fine)                               AS exposure
+			FROM document_status ds
+			JOIN employees e ON e.id = ds.employee_id
+			WHERE ds.company_id = c.id AND ds.is_required AND e.exit_type IS NULL
+		) d ON TRUE
+		LEFT JOIN LATERAL (
+			SELECT
+				COUNT(*)                                                          AS total,
+				COUNT(*) FILTER (WHERE ec.compliance_status IN ('valid', 'none')) AS compliant,
+				COUNT(*) FILTER (WHERE ec.compliance_status = 'incomplete')       AS incomplete,
+				COUNT(*) FILTER (WHERE ec.compliance_status = 'penalty_active')   AS penalty,
+				SUM(ec.score_lost)                                                AS score_lost,
+				SUM(ec.score_max)                                                 AS score_max
+			FROM employee_compliance ec
+			JOIN employees e ON e.id = ec.employee_id
+			WHERE ec.company_id = c.id AND e.exit_type IS NULL
+		) ec ON TRUE
+		ON CONFLICT (company_id, snapshot_date) DO UPDATE SET
+			docs_valid           = EXCLUDED.docs_valid,
+			docs_expiring_soon   = EXCLUDED.docs_expiring_soon,
+			docs_in_grace        = EXCLUDED.docs_in_grace,
+			docs_penalty_active  = EXCLUDED.docs_penalty_active,
+			docs_incomplete      = EXCLUDED.docs_incomplete,
+			employees_total      = EXCLUDED.employees_total,
+			employees_compliant  = EXCLUDED.employees_compliant,
+			employees_incomplete = EXCLUDED.employees_incomplete,
+			employees_penalty    = EXCLUDED.employees_penalty,
+			fine_exposure        = EXCLUDED.fine_exposure,
+			score_lost           = EXCLUDED.score_lost,
+			score_max            = EXCLUDED.score_max,
+			recorded_at          = NOW()
+	`)
+	return err
+}
@@ -0,0 +1,169 @@
+// Package emiratisation projects each company's UAE-national headcount onto
+// its Emiratisation targets (emiratisation_targets, migration 033). It is
+// shared by the dashboard and the notifier; the quota rules themselves live
+// in the compliance package.
+package emiratisation
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"manpower-backend/internal/compliance"
+)
+
+// Querier is satisfied by both *pgxpool.Pool and pgx.Tx.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+// Checkpoint is one upcoming target and the company's projected position.
+type Checkpoint struct {
+	TargetID        string
+	Description     string
+	CompanySpecific bool
+	Target          compliance.QuotaTarget
+	Result          compliance.QuotaResult
+}
+
+// Company is one company's headcount and its upcoming checkpoints, nearest
+// first.
+type Company struct {
+	CompanyID   string
+	CompanyName string
+	OwnerID     *string
+	Authority   string
+	Subject     bool // MOHRE company; free-zone companies are exempt
+	Today       time.Time
+	Workforce   int // active and on-leave employees
+	Nationals   int // of which UAE nationals
+	Checkpoints []Checkpoint
+}
+
+// Next returns the nearest upcoming checkpoint, or nil when none is configured.
+func (c *Company) Next() *Checkpoint {
+	if len(c.Checkpoints) == 0 {
+		return nil
+	}
+	return &c.Checkpoints[0]
+}
+
+type targetRow struct {
+	id          string
+	companyID   *string
+	description string
+	target      compliance.QuotaTarget
+}
+
+// Load returns the given companies (nil = every company) by name with their
+// checkpoints from today (company local date) onwards. A company target
+// replaces the global target for the same checkpoint date.
+func Load(ctx context.Context, q Querier, companyIDs []string) ([]Company, error) {
+	rows, err := q.Query(ctx, `
+		SELECT c.id::text, c.name, c.user_id::text, COALESCE(c.regulatory_authority, 'MOHRE'),
+			company_today(c.id),
+			COUNT(e.id),
+			COUNT(e.id) FILTER (WHERE is_uae_national(e.nationality))
+		FROM companies c
+		LEFT JOIN employees e ON e.company_id = c.id
+			AND e.exit_type IS NULL AND e.status IN ('active', 'on_leave')
+		WHERE $1::text[] IS NULL OR c.id::text = ANY($1)
+		GROUP BY c.id
+		ORDER BY c.name
+	`, companyIDs)
+	if err != nil {
+		return nil, err
+	}
+	companies := []Company{}
+	for rows.Next() {
+		var c Company
+		if err := rows.Scan(&c.CompanyID, &c.CompanyName, &c.OwnerID, &c.Authority,
+			&c.Today, &c.Workforce, &c.Nationals); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		c.Subject = strings.EqualFold(c.Authority, "MOHRE")
+		companies = append(companies, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Targets from the earliest company date; each company filters below
+	var from *time.Time
+	for i := range companies {
+		if from == nil || companies[i].Today.Before(*from) {
+			from = &companies[i].Today
+		}
+	}
+	if from == nil {
+		return companies, nil
+	}
+	targets, err := loadTargets(ctx, q, *from)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range companies {
+		c := &companies[i]
+		byDate := map[time.Time]targetRow{}
+		dates := []time.Time{}
+		for _, t := range targets {
+			if t.target.Checkpoint.Before(c.Today) || (t.companyID != nil && *t.companyID != c.CompanyID) {
+				continue
+			}
+			cur, seen := byDate[t.target.Checkpoint]
+			if !seen {
+				dates = append(dates, t.target.Checkpoint)
+			}
+			if !seen || (t.companyID != nil && cur.companyID == nil) {
+				byDate[t.target.Checkpoint] = t
+			}
+		}
+		// targets are ordered by date, so dates are too
+		for _, d := range dates {
+			t := byDate[d]
+			cp := Checkpoint{
+				TargetID:        t.id,
+				Description:     t.description,
+				CompanySpecific: t.companyID != nil,
+				Target:          t.target,
+				Result:          compliance.EvaluateQuota(t.target, c.Workforce, c.Nationals, c.Today),
+			}
+			if !c.Subject {
+				cp.Result = compliance.QuotaResult{Status: compliance.QuotaExempt, DaysUntil: cp.Result.DaysUntil}
+			}
+			c.Checkpoints = append(c.Checkpoints, cp)
+		}
+	}
+	return companies, nil
+}
+
+func loadTargets(ctx context.Context, q Querier, from time.Time) ([]targetRow, error) {
+	rows, err := q.Query(ctx, `
+		SELECT id::text, company_id::text, description, checkpoint_date,
+			target_percent::float8, min_headcount, min_workforce, fine_per_missing::float8
+		FROM emiratisation_targets
+		WHERE checkpoint_date >= $1
+		ORDER BY checkpoint_date
+	`, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	targets := []targetRow{}
+	for rows.Next() {
+		var t targetRow
+		if err := rows.Scan(&t.id, &t.companyID, &t.description, &t.target.Checkpoint,
+			&t.target.TargetPercent, &t.target.MinHeadcount, &t.target.MinWorkforce,
+			&t.target.FinePerMissing); err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
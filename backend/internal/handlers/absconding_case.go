@@ -0,0 +1,574 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"manpower-backend/internal/compliance"
+	"manpower-backend/internal/ctxkeys"
+	"manpower-backend/internal/database"
+	"manpower-backend/internal/models"
+	"manpower-backend/internal/storage"
+)
+
+// AbscondingCaseHandler handles absconding cases and their supporting
+// documents, which are stored through the storage.Store interface.
+type AbscondingCaseHandler struct {
+	db    database.Service
+	store storage.Store
+}
+
+// NewAbscondingCaseHandler creates a new AbscondingCaseHandler.
+func NewAbscondingCaseHandler(db database.Service, store storage.Store) *AbscondingCaseHandler {
+	return &AbscondingCaseHandler{db: db, store: store}
+}
+
+// ── Absconding Cases ─────────────────────────────────────────────
+// EmployeeHandler.Exit opens a case for exit_type "absconded" (migration
+// 025). The case is filed with MOHRE or GDRFA by the filing deadline, or
+// withdrawn if the worker returns, which reinstates the employee. While a
+// case is open the employee's pending salary records are on hold.
+
+const abscondingCaseCols = `ac.id, ac.employee_id, ac.company_id, ac.status,
+	ac.reported_on::text, ac.last_working_day::text, ac.authority, ac.filing_deadline::text,
+	(ac.status = 'reported' AND ac.filing_deadline < company_today(ac.company_id)),
+	ac.filed_on::text, ac.filing_reference, ac.withdrawn_on::text, ac.withdrawal_reason, ac.notes,
+	(SELECT COUNT(*) FROM salary_records s WHERE s.held_by_case_id = ac.id AND s.status = 'on_hold'),
+	ac.created_by, ac.created_at::text, ac.updated_at::text, e.name, co.name`
+
+const abscondingCaseFrom = `
+	FROM absconding_cases ac
+	JOIN employees e ON e.id = ac.employee_id
+	JOIN companies co ON co.id = ac.company_id`
+
+// scanAbscondingCase scans abscondingCaseCols.
+func scanAbscondingCase(row pgx.Row, c *models.AbscondingCase) error {
+	if err := row.Scan(
+		&c.ID, &c.EmployeeID, &c.CompanyID, &c.Status,
+		&c.ReportedOn, &c.LastWorkingDay, &c.Authority, &c.FilingDeadline,
+		&c.FilingOverdue,
+		&c.FiledOn, &c.FilingReference, &c.WithdrawnOn, &c.WithdrawalReason, &c.Notes,
+		&c.SalaryOnHold,
+		&c.CreatedBy, &c.CreatedAt, &c.UpdatedAt, &c.EmployeeName, &c.CompanyName,
+	); err != nil {
+		return err
+	}
+	if lwd, err := time.Parse("2006-01-02", c.LastWorkingDay); err == nil {
+		c.EarliestFiling = compliance.AbscondingEarliestFiling(lwd).Format("2006-01-02")
+	}
+	return nil
+}
+
+func loadAbscondingCase(ctx context.Context, q querier, id string) (models.AbscondingCase, error) {
+	var c models.AbscondingCase
+	err := scanAbscondingCase(q.QueryRow(ctx, `SELECT `+abscondingCaseCols+abscondingCaseFrom+` WHERE ac.id = $1`, id), &c)
+	return c, err
+}
+
+// openAbscondingCase opens a case for an employee who absconded after
+// lastWorkingDay, or moves the dates of the open case if it is not yet
+// filed, then puts the employee's pending salary on hold. Run in the exit's
+// transaction. Returns the case ID.
+func openAbscondingCase(ctx context.Context, q querier, employeeID, lastWorkingDay, userID string) (string, error) {
+	lwd, err := time.Parse("2006-01-02", lastWorkingDay)
+	if err != nil {
+		return "", err
+	}
+	var caseID string
+	err = q.QueryRow(ctx, `
+		INSERT INTO absconding_cases (employee_id, company_id, reported_on, last_working_day,
+		    filing_deadline, created_by)
+		SELECT e.id, e.company_id, company_today(e.company_id), $2, $3, $4
+		FROM employees e WHERE e.id = $1
+		ON CONFLICT (employee_id) WHERE status IN ('reported', 'filed') DO UPDATE SET
+		    last_working_day = CASE WHEN absconding_cases.status = 'reported'
+		                            THEN EXCLUDED.last_working_day ELSE absconding_cases.last_working_day END,
+		    filing_deadline  = CASE WHEN absconding_cases.status = 'reported'
+		                            THEN EXCLUDED.filing_deadline ELSE absconding_cases.filing_deadline END,
+		    updated_at       = NOW()
+		RETURNING id
+	`, employeeID, lwd, compliance.AbscondingFilingDeadline(lwd), nilIfEmpty(userID)).Scan(&caseID)
+	if err != nil {
+		return "", err
+	}
+	if _, err := holdAbscondingSalaries(ctx, q, employeeID, nil); err != nil {
+		return "", err
+	}
+	return caseID, nil
+}
+
+// holdAbscondingSalaries puts the pending salary records of employees with
+// an open absconding case on hold ("" = every employee), limited to the
+// given companies' records (nil = every company). Returns the number of
+// records held.
+func holdAbscondingSalaries(ctx context.Context, q querier, employeeID string, companyIDs []string) (int64, error) {
+	tag, err := q.Exec(ctx, `
+		UPDATE salary_records s
+		SET status = 'on_hold', held_by_case_id = ac.id, updated_at = NOW()
+		FROM absconding_cases ac
+		WHERE ac.employee_id = s.employee_id
+		  AND ac.status IN ('reported', 'filed')
+		  AND s.status = 'pending'
+		  AND ($1::text = '' OR s.employee_id::text = $1)
+		  AND ($2::text[] IS NULL OR s.company_id::text = ANY($2))
+	`, employeeID, companyIDs)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// List handles GET /api/absconding-cases
+// Filters: status, company_id, employee_id, overdue=true (reported past the
+// filing deadline). Pagination: page, limit (default 20, max 100).
+func (h *AbscondingCaseHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	where := "WHERE 1=1"
+	args := []interface{}{}
+	argIdx := 1
+	where, args, argIdx = appendCompanyScope(ctx, where, args, argIdx, "ac.company_id")
+
+	filters := []struct{ param, clause string }{
+		{"status", "ac.status = $%d"},
+		{"company_id", "ac.company_id::text = $%d"},
+		{"employee_id", "ac.employee_id::text = $%d"},
+	}
+	for _, f := range filters {
+		if v := q.Get(f.param); v != "" {
+			where += " AND " + fmt.Sprintf(f.clause, argIdx)
+			args = append(args, v)
+			argIdx++
+		}
+	}
+	if q.Get("overdue") == "true" {
+		where += " AND ac.status = 'reported' AND ac.filing_deadline < company_today(ac.company_id)"
+	}
+
+	var total int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM absconding_cases ac `+where, args...).Scan(&total); err != nil {
+		log.Printf("Error counting absconding cases: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch absconding cases")
+		return
+	}
+
+	args = append(args, limit, (page-1)*limit)
+	rows, err := pool.Query(ctx, fmt.Sprintf(`
+		SELECT %s %s
+		%s
+		ORDER BY ac.status = 'reported' DESC, ac.filing_deadline ASC, ac.created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, abscondingCaseCols, abscondingCaseFrom, where, argIdx, argIdx+1), args...)
+	if err != nil {
+		log.Printf("Error fetching absconding cases: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch absconding cases")
+		return
+	}
+	defer rows.Close()
+
+	cases := []models.AbscondingCase{}
+	for rows.Next() {
+		var c models.AbscondingCase
+		if err := scanAbscondingCase(rows, &c); err != nil {
+			log.Printf("Error scanning absconding case: %v", err)
+			continue
+		}
+		cases = append(cases, c)
+	}
+
+	JSON(w, http.StatusOK, PaginatedResponse{
+		Data: cases,
+		Pagination: PaginationMeta{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: int(math.Ceil(float64(total) / float64(limit))),
+		},
+	})
+}
+
+// Get handles GET /api/absconding-cases/{id}
+// Returns the case with its supporting documents, newest first.
+func (h *AbscondingCaseHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !checkAbscondingCaseAccess(r.Context(), h.db.GetPool(), id) {
+		JSONError(w, http.StatusForbidden, "Access denied to this absconding case")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	c, err := loadAbscondingCase(ctx, pool, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Absconding case not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching absconding case %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch absconding case")
+		return
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT id, case_id, file_url, file_name, file_size, file_type, description,
+			uploaded_by, created_at::text
+		FROM absconding_case_files WHERE case_id = $1
+		ORDER BY created_at DESC
+	`, id)
+	if err != nil {
+		log.Printf("Error fetching absconding case files: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch absconding case")
+		return
+	}
+	defer rows.Close()
+
+	files := []models.AbscondingCaseFile{}
+	for rows.Next() {
+		var f models.AbscondingCaseFile
+		if err := rows.Scan(&f.ID, &f.CaseID, &f.FileURL, &f.FileName, &f.FileSize, &f.FileType,
+			&f.Description, &f.UploadedBy, &f.CreatedAt); err != nil {
+			log.Printf("Error scanning absconding case file: %v", err)
+			continue
+		}
+		files = append(files, f)
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"data":  c,
+		"files": files,
+	})
+}
+
+// Update handles PATCH /api/absconding-cases/{id}
+// Records the filing (filedOn moves a reported case to filed; it cannot be
+// before the minimum absence period) or edits the authority, reference and
+// notes. Withdrawn cases cannot be changed.
+func (h *AbscondingCaseHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !checkAbscondingCaseAccess(r.Context(), h.db.GetPool(), id) {
+		JSONError(w, http.StatusForbidden, "Access denied to this absconding case")
+		return
+	}
+
+	var req models.UpdateAbscondingCaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	if errs := req.Validate(); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	current, err := loadAbscondingCase(ctx, pool, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Absconding case not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching absconding case %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to update absconding case")
+		return
+	}
+	if current.Status == "withdrawn" {
+		JSONError(w, http.StatusConflict, "This absconding case has been withdrawn")
+		return
+	}
+	if req.FiledOn != nil && *req.FiledOn < current.EarliestFiling {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error": "Validation failed",
+			"details": map[string]string{
+				"filedOn": fmt.Sprintf("The report can be filed after %d days of absence (from %s)",
+					compliance.AbscondingMinAbsenceDays, current.EarliestFiling),
+			},
+		})
+		return
+	}
+
+	_, err = pool.Exec(ctx, `
+		UPDATE absconding_cases SET
+			authority        = COALESCE($2::text, authority),
+			filed_on         = COALESCE($3::date, filed_on),
+			status           = CASE WHEN $3::date IS NOT NULL THEN 'filed' ELSE status END,
+			filing_reference = COALESCE($4::text, filing_reference),
+			notes            = COALESCE($5::text, notes),
+			updated_at       = NOW()
+		WHERE id = $1
+	`, id, req.Authority, req.FiledOn, req.FilingReference, req.Notes)
+	if err != nil {
+		log.Printf("Error updating absconding case %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to update absconding case")
+		return
+	}
+
+	c, err := loadAbscondingCase(ctx, pool, id)
+	if err != nil {
+		log.Printf("Error fetching absconding case %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch absconding case")
+		return
+	}
+
+	action := "updated"
+	if current.Status == "reported" && c.Status == "filed" {
+		action = "filed"
+	}
+	go logActivity(pool, userID, action, "absconding_case", id, map[string]interface{}{
+		"employeeId": c.EmployeeID, "authority": c.Authority, "filedOn": c.FiledOn,
+		"filingReference": c.FilingReference,
+	})
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"data":    c,
+		"message": "Absconding case updated",
+	})
+}
+
+// Withdraw handles POST /api/absconding-cases/{id}/withdraw
+// The worker returned: the case is withdrawn, the employee reinstated as
+// active, pending exit checklist items dropped and the salary records held
+// by the case released back to pending.
+func (h *AbscondingCaseHandler) Withdraw(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !checkAbscondingCaseAccess(r.Context(), h.db.GetPool(), id) {
+		JSONError(w, http.StatusForbidden, "Access denied to this absconding case")
+		return
+	}
+
+	var req models.WithdrawAbscondingCaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	if errs := req.Validate(); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var employeeID string
+	err = tx.QueryRow(ctx, `
+		UPDATE absconding_cases SET
+			status = 'withdrawn', withdrawn_on = $2, withdrawal_reason = $3, updated_at = NOW()
+		WHERE id = $1 AND status IN ('reported', 'filed')
+		RETURNING employee_id::text
+	`, id, req.ReturnedOn, req.Reason).Scan(&employeeID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusConflict, "Only open absconding cases can be withdrawn")
+		return
+	}
+	if err != nil {
+		log.Printf("Error withdrawing absconding case %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to withdraw absconding case")
+		return
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE employees SET
+			status = 'active', exit_type = NULL, exit_date = NULL, exit_notes = NULL,
+			updated_at = NOW()
+		WHERE id = $1 AND exit_type = 'absconded'
+	`, employeeID)
+	if err == nil {
+		_, err = tx.Exec(ctx, `DELETE FROM exit_checklist_items WHERE employee_id = $1 AND status = 'pending'`, employeeID)
+	}
+	var released int64
+	if err == nil {
+		var tag pgconn.CommandTag
+		tag, err = tx.Exec(ctx, `
+			UPDATE salary_records SET status = 'pending', held_by_case_id = NULL, updated_at = NOW()
+			WHERE held_by_case_id = $1 AND status = 'on_hold'
+		`, id)
+		released = tag.RowsAffected()
+	}
+	if err != nil {
+		log.Printf("Error reinstating employee %s: %v", employeeID, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to withdraw absconding case")
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to withdraw absconding case")
+		return
+	}
+	refreshEmployeeStatus(ctx, pool, employeeID)
+
+	c, err := loadAbscondingCase(ctx, pool, id)
+	if err != nil {
+		log.Printf("Error fetching absconding case %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch absconding case")
+		return
+	}
+
+	go logActivity(pool, userID, "withdrawn", "absconding_case", id, map[string]interface{}{
+		"employeeId": employeeID, "returnedOn": req.ReturnedOn, "salaryReleased": released,
+	})
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"data":    c,
+		"message": "Absconding case withdrawn and employee reinstated",
+	})
+}
+
+// UploadFile handles POST /api/absconding-cases/{id}/files (multipart:
+// file, description). Accepts the same file types as document uploads.
+func (h *AbscondingCaseHandler) UploadFile(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !checkAbscondingCaseAccess(r.Context(), h.db.GetPool(), id) {
+		JSONError(w, http.StatusForbidden, "Access denied to this absconding case")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		JSONError(w, http.StatusBadRequest, "File too large. Maximum size is 10MB.")
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		JSONError(w, http.StatusBadRequest, "No file provided. Use form field 'file'.")
+		return
+	}
+	defer file.Close()
+
+	contentType, ok := sniffUpload(w, file)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	var companyID string
+	if err := pool.QueryRow(ctx, `SELECT company_id::text FROM absconding_cases WHERE id = $1`, id).Scan(&companyID); err != nil {
+		JSONError(w, http.StatusNotFound, "Absconding case not found")
+		return
+	}
+
+	path := fmt.Sprintf("absconding/%s/%s/%d_%s", companyID, id, time.Now().Unix(), sanitizeFilename(header.Filename))
+	info, err := h.store.Save(ctx, path, file, contentType)
+	if err != nil {
+		log.Printf("Error saving absconding case file: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to save file")
+		return
+	}
+
+	var f models.AbscondingCaseFile
+	err = pool.QueryRow(ctx, `
+		INSERT INTO absconding_case_files (case_id, file_path, file_url, file_name, file_size, file_type,
+		    description, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, case_id, file_url, file_name, file_size, file_type, description,
+			uploaded_by, created_at::text
+	`, id, path, info.URL, info.FileName, info.FileSize, info.FileType,
+		r.FormValue("description"), nilIfEmpty(userID),
+	).Scan(&f.ID, &f.CaseID, &f.FileURL, &f.FileName, &f.FileSize, &f.FileType,
+		&f.Description, &f.UploadedBy, &f.CreatedAt)
+	if err != nil {
+		log.Printf("Error recording absconding case file: %v", err)
+		_ = h.store.Delete(context.Background(), path)
+		JSONError(w, http.StatusInternalServerError, "Failed to save file")
+		return
+	}
+
+	go logActivity(pool, userID, "uploaded_file", "absconding_case", id, map[string]interface{}{
+		"fileId": f.ID, "fileName": f.FileName,
+	})
+
+	JSON(w, http.StatusCreated, map[string]interface{}{
+		"data":    f,
+		"message": "File uploaded",
+	})
+}
+
+// DeleteFile handles DELETE /api/absconding-cases/{id}/files/{fileId}
+func (h *AbscondingCaseHandler) DeleteFile(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	fileID := chi.URLParam(r, "fileId")
+	if !checkAbscondingCaseAccess(r.Context(), h.db.GetPool(), id) {
+		JSONError(w, http.StatusForbidden, "Access denied to this absconding case")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	var path, name string
+	err := pool.QueryRow(ctx, `
+		DELETE FROM absconding_case_files WHERE id::text = $1 AND case_id::text = $2
+		RETURNING file_path, file_name
+	`, fileID, id).Scan(&path, &name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "File not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error deleting absconding case file %s: %v", fileID, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to delete file")
+		return
+	}
+	if err := h.store.Delete(ctx, path); err != nil {
+		log.Printf("Error deleting absconding case file %s: %v", path, err)
+	}
+
+	go logActivity(pool, userID, "deleted_file", "absconding_case", id, map[string]interface{}{
+		"fileId": fileID, "fileName": name,
+	})
+
+	JSON(w, http.StatusOK, map[string]interface{}{"message": "File deleted"})
+}
@@ -3,12 +3,18 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 
+	"manpower-backend/internal/compliance"
 	"manpower-backend/internal/ctxkeys"
 	"manpower-backend/internal/database"
 	"manpower-backend/internal/models"
@@ -27,6 +33,7 @@ func NewAdminHandler(db database.Service) *AdminHandler {
 
 // ListDocumentTypes returns all active document types, ordered by sort_order.
 // Accessible to all authenticated users (needed for document forms).
+// applies_to=employee|dependent limits the list to one set.
 func (h *AdminHandler) ListDocumentTypes(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
@@ -36,16 +43,17 @@ func (h *AdminHandler) ListDocumentTypes(w http.ResponseWriter, r *http.Request)
 	rows, err := pool.Query(ctx, `
 		SELECT id, doc_type, display_name, is_mandatory, has_expiry,
 		       number_label, number_placeholder, expiry_label, sort_order,
-		       metadata_fields, is_system, is_active,
+		       metadata_fields, is_system, is_active, applies_to,
 		       show_document_number, require_document_number,
 		       show_issue_date, require_issue_date,
 		       show_expiry_date, require_expiry_date,
 		       show_file, require_file,
+		       validity_period, validity_unit,
 		       created_at::text, updated_at::text
 		FROM document_types
-		WHERE is_active = TRUE
+		WHERE is_active = TRUE AND ($1 = '' OR applies_to = $1)
 		ORDER BY sort_order, display_name
-	`)
+	`, r.URL.Query().Get("applies_to"))
 	if err != nil {
 		log.Printf("Failed to list document types: %v", err)
 		JSONError(w, http.StatusInternalServerError, "Failed to fetch document types")
@@ -59,11 +67,12 @@ func (h *AdminHandler) ListDocumentTypes(w http.ResponseWriter, r *http.Request)
 		if err := rows.Scan(
 			&dt.ID, &dt.DocType, &dt.DisplayName, &dt.IsMandatory, &dt.HasExpiry,
 			&dt.NumberLabel, &dt.NumberPlaceholder, &dt.ExpiryLabel, &dt.SortOrder,
-			&dt.MetadataFields, &dt.IsSystem, &dt.IsActive,
+			&dt.MetadataFields, &dt.IsSystem, &dt.IsActive, &dt.AppliesTo,
 			&dt.ShowDocumentNumber, &dt.RequireDocumentNumber,
 			&dt.ShowIssueDate, &dt.RequireIssueDate,
 			&dt.ShowExpiryDate, &dt.RequireExpiryDate,
 			&dt.ShowFile, &dt.RequireFile,
+			&dt.ValidityPeriod, &dt.ValidityUnit,
 			&dt.CreatedAt, &dt.UpdatedAt,
 		); err != nil {
 			log.Printf("Failed to scan document type: %v", err)
@@ -116,23 +125,26 @@ func (h *AdminHandler) CreateDocumentType(w http.ResponseWriter, r *http.Request
 	err := pool.QueryRow(ctx, `
 		INSERT INTO document_types (doc_type, display_name, is_mandatory, has_expiry,
 		    number_label, number_placeholder, expiry_label, sort_order, metadata_fields,
-		    is_system, is_active,
+		    is_system, is_active, applies_to,
 		    show_document_number, require_document_number,
 		    show_issue_date, require_issue_date,
 		    show_expiry_date, require_expiry_date,
-		    show_file, require_file)
-		VALUES ($1, $2, FALSE, $3, $4, $5, $6, $7, $8, FALSE, TRUE,
+		    show_file, require_file,
+		    validity_period, validity_unit)
+		VALUES ($1, $2, FALSE, $3, $4, $5, $6, $7, $8, FALSE, TRUE, $19,
 		    COALESCE($9, TRUE), COALESCE($10, FALSE),
 		    COALESCE($11, TRUE), COALESCE($12, FALSE),
 		    COALESCE($13, TRUE), COALESCE($14, FALSE),
-		    COALESCE($15, TRUE), COALESCE($16, FALSE))
+		    COALESCE($15, TRUE), COALESCE($16, FALSE),
+		    $17, $18)
 		RETURNING id, doc_type, display_name, is_mandatory, has_expiry,
 		          number_label, number_placeholder, expiry_label, sort_order,
-		          metadata_fields, is_system, is_active,
+		          metadata_fields, is_system, is_active, applies_to,
 		          show_document_number, require_document_number,
 		          show_issue_date, require_issue_date,
 		          show_expiry_date, require_expiry_date,
 		          show_file, require_file,
+		          validity_period, validity_unit,
 		          created_at::text, updated_at::text
 	`, req.DocType, req.DisplayName, req.HasExpiry,
 		req.NumberLabel, req.NumberPlaceholder, req.ExpiryLabel,
@@ -141,14 +153,16 @@ func (h *AdminHandler) CreateDocumentType(w http.ResponseWriter, r *http.Request
 		req.ShowIssueDate, req.RequireIssueDate,
 		req.ShowExpiryDate, req.RequireExpiryDate,
 		req.ShowFile, req.RequireFile,
+		req.ValidityPeriod, req.ValidityUnit, req.AppliesTo,
 	).Scan(
 		&dt.ID, &dt.DocType, &dt.DisplayName, &dt.IsMandatory, &dt.HasExpiry,
 		&dt.NumberLabel, &dt.NumberPlaceholder, &dt.ExpiryLabel, &dt.SortOrder,
-		&dt.MetadataFields, &dt.IsSystem, &dt.IsActive,
+		&dt.MetadataFields, &dt.IsSystem, &dt.IsActive, &dt.AppliesTo,
 		&dt.ShowDocumentNumber, &dt.RequireDocumentNumber,
 		&dt.ShowIssueDate, &dt.RequireIssueDate,
 		&dt.ShowExpiryDate, &dt.RequireExpiryDate,
 		&dt.ShowFile, &dt.RequireFile,
+		&dt.ValidityPeriod, &dt.ValidityUnit,
 		&dt.CreatedAt, &dt.UpdatedAt,
 	)
 	if err != nil {
@@ -165,6 +179,7 @@ func (h *AdminHandler) CreateDocumentType(w http.ResponseWriter, r *http.Request
 		"docType":     dt.DocType,
 		"displayName": dt.DisplayName,
 	})
+	refreshStatusAsync(pool, "")
 
 	JSON(w, http.StatusCreated, map[string]interface{}{
 		"data":    dt,
@@ -183,6 +198,14 @@ func (h *AdminHandler) UpdateDocumentType(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if errs := req.Validate(); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
@@ -219,15 +242,18 @@ func (h *AdminHandler) UpdateDocumentType(w http.ResponseWriter, r *http.Request
 			require_expiry_date     = COALESCE($13, require_expiry_date),
 			show_file               = COALESCE($14, show_file),
 			require_file            = COALESCE($15, require_file),
+			validity_period         = COALESCE($16, validity_period),
+			validity_unit           = COALESCE($17, validity_unit),
 			updated_at         = NOW()
 		WHERE id = $7
 		RETURNING id, doc_type, display_name, is_mandatory, has_expiry,
 		          number_label, number_placeholder, expiry_label, sort_order,
-		          metadata_fields, is_system, is_active,
+		          metadata_fields, is_system, is_active, applies_to,
 		          show_document_number, require_document_number,
 		          show_issue_date, require_issue_date,
 		          show_expiry_date, require_expiry_date,
 		          show_file, require_file,
+		          validity_period, validity_unit,
 		          created_at::text, updated_at::text
 	`, req.DisplayName, req.NumberLabel, req.NumberPlaceholder,
 		req.ExpiryLabel, req.SortOrder, req.MetadataFields, id,
@@ -235,14 +261,16 @@ func (h *AdminHandler) UpdateDocumentType(w http.ResponseWriter, r *http.Request
 		req.ShowIssueDate, req.RequireIssueDate,
 		req.ShowExpiryDate, req.RequireExpiryDate,
 		req.ShowFile, req.RequireFile,
+		req.ValidityPeriod, req.ValidityUnit,
 	).Scan(
 		&dt.ID, &dt.DocType, &dt.DisplayName, &dt.IsMandatory, &dt.HasExpiry,
 		&dt.NumberLabel, &dt.NumberPlaceholder, &dt.ExpiryLabel, &dt.SortOrder,
-		&dt.MetadataFields, &dt.IsSystem, &dt.IsActive,
+		&dt.MetadataFields, &dt.IsSystem, &dt.IsActive, &dt.AppliesTo,
 		&dt.ShowDocumentNumber, &dt.RequireDocumentNumber,
 		&dt.ShowIssueDate, &dt.RequireIssueDate,
 		&dt.ShowExpiryDate, &dt.RequireExpiryDate,
 		&dt.ShowFile, &dt.RequireFile,
+		&dt.ValidityPeriod, &dt.ValidityUnit,
 		&dt.CreatedAt, &dt.UpdatedAt,
 	)
 	if err != nil {
@@ -255,6 +283,7 @@ func (h *AdminHandler) UpdateDocumentType(w http.ResponseWriter, r *http.Request
 		"docType":     dt.DocType,
 		"displayName": dt.DisplayName,
 	})
+	refreshStatusAsync(pool, "")
 
 	JSON(w, http.StatusOK, map[string]interface{}{
 		"data":    dt,
@@ -296,6 +325,7 @@ func (h *AdminHandler) DeleteDocumentType(w http.ResponseWriter, r *http.Request
 	go logActivity(pool, userID, "deleted", "document_type", id, map[string]interface{}{
 		"docType": docType,
 	})
+	refreshStatusAsync(pool, "")
 
 	JSON(w, http.StatusOK, map[string]interface{}{"message": "Document type deleted successfully"})
 }
@@ -316,11 +346,15 @@ func (h *AdminHandler) ListComplianceRules(w http.ResponseWriter, r *http.Reques
 	query := `
 		SELECT dt.doc_type, dt.display_name, dt.is_mandatory AS global_mandatory,
 		       COALESCE(cr.grace_period_days, gr.grace_period_days, 0) AS grace_period_days,
+		       COALESCE(cr.grace_period_unit, gr.grace_period_unit, 'calendar') AS grace_period_unit,
 		       COALESCE(cr.fine_per_day, gr.fine_per_day, 0) AS fine_per_day,
 		       COALESCE(cr.fine_type, gr.fine_type, 'daily') AS fine_type,
 		       COALESCE(cr.fine_cap, gr.fine_cap, 0) AS fine_cap,
 		       cr.is_mandatory AS company_mandatory,
-		       cr.id AS rule_id
+		       cr.id AS rule_id,
+		       dt.validity_period, dt.validity_unit,
+		       COALESCE(cr.validity_period, gr.validity_period),
+		       COALESCE(cr.validity_unit, gr.validity_unit)
 		FROM document_types dt
 		LEFT JOIN compliance_rules cr ON cr.doc_type = dt.doc_type AND cr.company_id = $1
 		LEFT JOIN compliance_rules gr ON gr.doc_type = dt.doc_type AND gr.company_id IS NULL
@@ -336,11 +370,18 @@ func (h *AdminHandler) ListComplianceRules(w http.ResponseWriter, r *http.Reques
 		DisplayName      string  `json:"displayName"`
 		GlobalMandatory  bool    `json:"globalMandatory"`
 		GracePeriodDays  int     `json:"gracePeriodDays"`
+		GracePeriodUnit  string  `json:"gracePeriodUnit"`
 		FinePerDay       float64 `json:"finePerDay"`
 		FineType         string  `json:"fineType"`
 		FineCap          float64 `json:"fineCap"`
 		CompanyMandatory *bool   `json:"companyMandatory"`
 		RuleID           *string `json:"ruleId"`
+
+		// Standard validity of the document type and the rule override, if any
+		TypeValidityPeriod int     `json:"typeValidityPeriod"`
+		TypeValidityUnit   string  `json:"typeValidityUnit"`
+		ValidityPeriod     *int    `json:"validityPeriod"`
+		ValidityUnit       *string `json:"validityUnit"`
 	}
 
 	var companyIDPtr *string
@@ -364,8 +405,9 @@ func (h *AdminHandler) ListComplianceRules(w http.ResponseWriter, r *http.Reques
 		var rr ruleRow
 		if err := pgRows.Scan(
 			&rr.DocType, &rr.DisplayName, &rr.GlobalMandatory,
-			&rr.GracePeriodDays, &rr.FinePerDay, &rr.FineType, &rr.FineCap,
+			&rr.GracePeriodDays, &rr.GracePeriodUnit, &rr.FinePerDay, &rr.FineType, &rr.FineCap,
 			&rr.CompanyMandatory, &rr.RuleID,
+			&rr.TypeValidityPeriod, &rr.TypeValidityUnit, &rr.ValidityPeriod, &rr.ValidityUnit,
 		); err != nil {
 			log.Printf("Failed to scan compliance rule: %v", err)
 			continue
@@ -396,7 +438,8 @@ func (h *AdminHandler) UpsertComplianceRules(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	// Reconciling slots may touch every employee
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
 	pool := h.db.GetPool()
@@ -410,20 +453,51 @@ func (h *AdminHandler) UpsertComplianceRules(w http.ResponseWriter, r *http.Requ
 	}
 	defer tx.Rollback(ctx)
 
+	// Mandatory slots follow is_mandatory: snapshot them before the first
+	// rule that changes it (earlier rules left them as they were)
+	var slots *slotScope
 	for _, rule := range req.Rules {
+		if slots == nil {
+			var changed bool
+			if err := tx.QueryRow(ctx, `
+				SELECT NOT EXISTS(
+					SELECT 1 FROM compliance_rules
+					WHERE company_id IS NOT DISTINCT FROM $1::uuid AND doc_type = $2
+					  AND is_mandatory IS NOT DISTINCT FROM $3::boolean
+				)
+			`, req.CompanyID, rule.DocType, rule.IsMandatory).Scan(&changed); err != nil {
+				log.Printf("Failed to check rule for %s: %v", rule.DocType, err)
+				JSONError(w, http.StatusInternalServerError, "Failed to save rule for "+rule.DocType)
+				return
+			}
+			if changed {
+				if slots, err = snapshotSlots(ctx, tx, req.CompanyID); err != nil {
+					log.Printf("Failed to load document slots: %v", err)
+					JSONError(w, http.StatusInternalServerError, "Failed to save rules")
+					return
+				}
+			}
+		}
+
 		_, err := tx.Exec(ctx, `
-			INSERT INTO compliance_rules (company_id, doc_type, grace_period_days, fine_per_day, fine_type, fine_cap, is_mandatory)
-			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			INSERT INTO compliance_rules (company_id, doc_type, grace_period_days, fine_per_day, fine_type, fine_cap, is_mandatory, grace_period_unit,
+			                              validity_period, validity_unit)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8,
+			        NULLIF($9::int, 0), CASE WHEN COALESCE($9::int, 0) > 0 THEN $10::text END)
 			ON CONFLICT (company_id, doc_type)
 			DO UPDATE SET
 				grace_period_days = EXCLUDED.grace_period_days,
+				grace_period_unit = EXCLUDED.grace_period_unit,
 				fine_per_day      = EXCLUDED.fine_per_day,
 				fine_type         = EXCLUDED.fine_type,
 				fine_cap          = EXCLUDED.fine_cap,
 				is_mandatory      = EXCLUDED.is_mandatory,
+				validity_period   = CASE WHEN $9::int IS NULL THEN compliance_rules.validity_period ELSE EXCLUDED.validity_period END,
+				validity_unit     = CASE WHEN $9::int IS NULL THEN compliance_rules.validity_unit ELSE EXCLUDED.validity_unit END,
 				updated_at        = NOW()
 		`, req.CompanyID, rule.DocType, rule.GracePeriodDays,
-			rule.FinePerDay, rule.FineType, rule.FineCap, rule.IsMandatory)
+			rule.FinePerDay, rule.FineType, rule.FineCap, rule.IsMandatory, rule.GraceUnit(),
+			rule.ValidityPeriod, rule.ValidityUnit)
 		if err != nil {
 			log.Printf("Failed to upsert rule for %s: %v", rule.DocType, err)
 			JSONError(w, http.StatusInternalServerError, "Failed to save rule for "+rule.DocType)
@@ -431,6 +505,14 @@ func (h *AdminHandler) UpsertComplianceRules(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
+	if slots != nil {
+		if err := slots.reconcile(ctx, tx); err != nil {
+			log.Printf("Failed to reconcile document slots: %v", err)
+			JSONError(w, http.StatusInternalServerError, "Failed to save rules")
+			return
+		}
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		log.Printf("Failed to commit rules: %v", err)
 		JSONError(w, http.StatusInternalServerError, "Failed to save rules")
@@ -445,6 +527,7 @@ func (h *AdminHandler) UpsertComplianceRules(w http.ResponseWriter, r *http.Requ
 		"ruleCount": len(req.Rules),
 		"companyId": req.CompanyID,
 	})
+	refreshStatusAsync(pool, nilStringDefault(req.CompanyID, ""))
 
 	JSON(w, http.StatusOK, map[string]interface{}{
 		"message": "Compliance rules saved successfully",
@@ -452,27 +535,230 @@ func (h *AdminHandler) UpsertComplianceRules(w http.ResponseWriter, r *http.Requ
 }
 
 // ── Document Dependencies ────────────────────────────────────
+// Rows are global, per regulatory authority or per company. The rules in
+// force for a company come from effective_dependencies() (migration 013):
+// company rows replace authority rows, which replace global rows, per blocked doc type.
 
 type dependencyRow struct {
-	ID              string `json:"id"`
-	BlockingDocType string `json:"blockingDocType"`
-	BlockedDocType  string `json:"blockedDocType"`
-	Description     string `json:"description"`
-	CreatedAt       string `json:"createdAt"`
+	ID                  string  `json:"id"`
+	BlockingDocType     string  `json:"blockingDocType"`
+	BlockedDocType      string  `json:"blockedDocType"`
+	Description         string  `json:"description"`
+	MinValidityDays     int     `json:"minValidityDays"`
+	CompanyID           *string `json:"companyId"`
+	RegulatoryAuthority *string `json:"regulatoryAuthority"`
+	Scope               string  `json:"scope"` // "company" | "authority" | "global"
+	CreatedAt           string  `json:"createdAt"`
+}
+
+const dependencyCols = `id, blocking_doc_type, blocked_doc_type, description, min_validity_days,
+		company_id, regulatory_authority,
+		CASE WHEN company_id IS NOT NULL THEN 'company'
+		     WHEN regulatory_authority IS NOT NULL THEN 'authority'
+		     ELSE 'global' END,
+		created_at::text`
+
+func scanDependency(row pgx.Row, d *dependencyRow) error {
+	return row.Scan(&d.ID, &d.BlockingDocType, &d.BlockedDocType, &d.Description, &d.MinValidityDays,
+		&d.CompanyID, &d.RegulatoryAuthority, &d.Scope, &d.CreatedAt)
+}
+
+// dependencyRequest is the body for creating/updating a dependency rule.
+// At most one of CompanyID / RegulatoryAuthority may be set; neither = global.
+type dependencyRequest struct {
+	BlockingDocType     string  `json:"blockingDocType"`
+	BlockedDocType      string  `json:"blockedDocType"`
+	Description         string  `json:"description"`
+	MinValidityDays     *int    `json:"minValidityDays"`
+	CompanyID           *string `json:"companyId"`
+	RegulatoryAuthority *string `json:"regulatoryAuthority"`
+}
+
+// normalize trims the scope fields and upper-cases the authority code.
+func (req *dependencyRequest) normalize() {
+	if req.CompanyID != nil && strings.TrimSpace(*req.CompanyID) == "" {
+		req.CompanyID = nil
+	}
+	if req.RegulatoryAuthority != nil {
+		code := strings.ToUpper(strings.TrimSpace(*req.RegulatoryAuthority))
+		if code == "" {
+			req.RegulatoryAuthority = nil
+		} else {
+			req.RegulatoryAuthority = &code
+		}
+	}
+}
+
+// validateDependency checks a create/update dependency request.
+// Writes the error response and returns false on failure.
+func validateDependency(ctx context.Context, w http.ResponseWriter, q querier, req *dependencyRequest) bool {
+	if req.BlockingDocType == "" || req.BlockedDocType == "" || req.Description == "" {
+		JSONError(w, http.StatusUnprocessableEntity, "All fields are required")
+		return false
+	}
+	if req.BlockingDocType == req.BlockedDocType {
+		JSONError(w, http.StatusUnprocessableEntity, "A document type cannot block itself")
+		return false
+	}
+
+	errs := map[string]string{}
+	if req.MinValidityDays != nil && (*req.MinValidityDays < 0 || *req.MinValidityDays > 3650) {
+		errs["minValidityDays"] = "Must be between 0 and 3650"
+	}
+	if req.CompanyID != nil && req.RegulatoryAuthority != nil {
+		errs["companyId"] = "A dependency can be scoped to a company or an authority, not both"
+	} else if req.CompanyID != nil {
+		var exists bool
+		_ = q.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM companies WHERE id::text = $1)`, *req.CompanyID).Scan(&exists)
+		if !exists {
+			errs["companyId"] = "Company not found"
+		}
+	}
+	if len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return false
+	}
+	return true
+}
+
+// findDependencyCycle checks the effective dependency graph of every scope
+// (global, each authority, each company with its own rows) and returns the
+// first cycle found together with a label for the scope it occurs in.
+// Run inside the transaction that changed document_dependencies.
+func findDependencyCycle(ctx context.Context, q querier) ([]string, string, error) {
+	type scope struct {
+		companyID *string
+		authority *string
+		label     string
+	}
+	scopes := []scope{{label: "global"}}
+
+	rows, err := q.Query(ctx, `
+		SELECT DISTINCT upper(regulatory_authority) FROM document_dependencies WHERE regulatory_authority IS NOT NULL
+		UNION
+		SELECT DISTINCT upper(regulatory_authority) FROM companies WHERE regulatory_authority IS NOT NULL
+	`)
+	if err != nil {
+		return nil, "", err
+	}
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			rows.Close()
+			return nil, "", err
+		}
+		scopes = append(scopes, scope{authority: &code, label: "authority " + code})
+	}
+	rows.Close()
+
+	rows, err = q.Query(ctx, `
+		SELECT DISTINCT c.id::text, c.name
+		FROM document_dependencies d JOIN companies c ON c.id = d.company_id
+	`)
+	if err != nil {
+		return nil, "", err
+	}
+	for rows.Next() {
+		var id, name string
+		if err := rows.Scan(&id, &name); err != nil {
+			rows.Close()
+			return nil, "", err
+		}
+		scopes = append(scopes, scope{companyID: &id, label: "company " + name})
+	}
+	rows.Close()
+
+	for _, s := range scopes {
+		edgeRows, err := q.Query(ctx, `
+			SELECT blocking_doc_type, blocked_doc_type FROM effective_dependencies($1::uuid, $2)
+		`, s.companyID, s.authority)
+		if err != nil {
+			return nil, "", err
+		}
+		edges := []compliance.DependencyEdge{}
+		for edgeRows.Next() {
+			var e compliance.DependencyEdge
+			if err := edgeRows.Scan(&e.Blocking, &e.Blocked); err != nil {
+				edgeRows.Close()
+				return nil, "", err
+			}
+			edges = append(edges, e)
+		}
+		edgeRows.Close()
+
+		if cycle := compliance.FindDependencyCycle(edges); cycle != nil {
+			return cycle, s.label, nil
+		}
+	}
+	return nil, "", nil
+}
+
+// saveDependency runs a dependency insert/update in a transaction and rolls it
+// back if it would introduce a cycle in any scope. Writes the error response
+// and returns false on failure.
+func saveDependency(ctx context.Context, w http.ResponseWriter, pool *pgxpool.Pool, query string, args []interface{}, dep *dependencyRow) bool {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to start transaction")
+		return false
+	}
+	defer tx.Rollback(ctx)
+
+	if err := scanDependency(tx.QueryRow(ctx, query, args...), dep); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			JSONError(w, http.StatusNotFound, "Dependency rule not found")
+			return false
+		}
+		log.Printf("Error saving dependency: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to save dependency")
+		return false
+	}
+
+	cycle, scopeLabel, err := findDependencyCycle(ctx, tx)
+	if err != nil {
+		log.Printf("Error checking dependency cycle: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to validate dependency")
+		return false
+	}
+	if cycle != nil {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error": fmt.Sprintf("Dependency would create a cycle (%s): %s", scopeLabel, strings.Join(cycle, " → ")),
+			"cycle": cycle,
+		})
+		return false
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to save dependency")
+		return false
+	}
+	return true
 }
 
-// ListDependencies returns all dependency rules.
+// ListDependencies returns dependency rules.
+// With ?company_id=X (or ?regulatory_authority=Y) returns the rules in force
+// for that scope after fallback; otherwise returns every row.
 func (h *AdminHandler) ListDependencies(w http.ResponseWriter, r *http.Request) {
+	companyID := r.URL.Query().Get("company_id")
+	authority := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("regulatory_authority")))
+
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
 	pool := h.db.GetPool()
 
-	rows, err := pool.Query(ctx, `
-		SELECT id, blocking_doc_type, blocked_doc_type, description, created_at::text
-		FROM document_dependencies
-		ORDER BY blocking_doc_type, blocked_doc_type
-	`)
+	query := `SELECT ` + dependencyCols + ` FROM document_dependencies`
+	args := []interface{}{}
+	if companyID != "" || authority != "" {
+		query += ` WHERE id IN (SELECT id FROM effective_dependencies($1::uuid, $2))`
+		args = append(args, nilIfEmpty(companyID), nilIfEmpty(authority))
+	}
+	query += ` ORDER BY blocking_doc_type, blocked_doc_type`
+
+	rows, err := pool.Query(ctx, query, args...)
 	if err != nil {
 		log.Printf("Error listing dependencies: %v", err)
 		JSONError(w, http.StatusInternalServerError, "Failed to fetch dependencies")
@@ -483,7 +769,7 @@ func (h *AdminHandler) ListDependencies(w http.ResponseWriter, r *http.Request)
 	deps := []dependencyRow{}
 	for rows.Next() {
 		var d dependencyRow
-		if err := rows.Scan(&d.ID, &d.BlockingDocType, &d.BlockedDocType, &d.Description, &d.CreatedAt); err != nil {
+		if err := scanDependency(rows, &d); err != nil {
 			continue
 		}
 		deps = append(deps, d)
@@ -494,46 +780,38 @@ func (h *AdminHandler) ListDependencies(w http.ResponseWriter, r *http.Request)
 
 // CreateDependency adds a new dependency rule.
 func (h *AdminHandler) CreateDependency(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		BlockingDocType string `json:"blockingDocType"`
-		BlockedDocType  string `json:"blockedDocType"`
-		Description     string `json:"description"`
-	}
+	var req dependencyRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
 		return
 	}
-	if req.BlockingDocType == "" || req.BlockedDocType == "" || req.Description == "" {
-		JSONError(w, http.StatusUnprocessableEntity, "All fields are required")
-		return
-	}
-	if req.BlockingDocType == req.BlockedDocType {
-		JSONError(w, http.StatusUnprocessableEntity, "A document type cannot block itself")
-		return
-	}
+	req.normalize()
 
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
 	pool := h.db.GetPool()
 
+	if !validateDependency(ctx, w, pool, &req) {
+		return
+	}
+
 	var dep dependencyRow
-	err := pool.QueryRow(ctx, `
-		INSERT INTO document_dependencies (blocking_doc_type, blocked_doc_type, description)
-		VALUES ($1, $2, $3)
-		RETURNING id, blocking_doc_type, blocked_doc_type, description, created_at::text
-	`, req.BlockingDocType, req.BlockedDocType, req.Description).Scan(
-		&dep.ID, &dep.BlockingDocType, &dep.BlockedDocType, &dep.Description, &dep.CreatedAt,
-	)
-	if err != nil {
-		log.Printf("Error creating dependency: %v", err)
-		JSONError(w, http.StatusInternalServerError, "Failed to create dependency")
+	if !saveDependency(ctx, w, pool, `
+		INSERT INTO document_dependencies
+			(blocking_doc_type, blocked_doc_type, description, min_validity_days, company_id, regulatory_authority)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+dependencyCols,
+		[]interface{}{req.BlockingDocType, req.BlockedDocType, req.Description,
+			nilIntDefault(req.MinValidityDays, 0), req.CompanyID, req.RegulatoryAuthority},
+		&dep) {
 		return
 	}
 
 	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
 	go logActivity(pool, userID, "created", "dependency", dep.ID, map[string]interface{}{
-		"blocking": req.BlockingDocType, "blocked": req.BlockedDocType,
+		"blocking": req.BlockingDocType, "blocked": req.BlockedDocType, "minValidityDays": dep.MinValidityDays,
+		"scope": dep.Scope, "companyId": dep.CompanyID, "regulatoryAuthority": dep.RegulatoryAuthority,
 	})
 
 	JSON(w, http.StatusCreated, map[string]interface{}{
@@ -542,43 +820,47 @@ func (h *AdminHandler) CreateDependency(w http.ResponseWriter, r *http.Request)
 	})
 }
 
-// UpdateDependency modifies an existing dependency rule.
+// UpdateDependency modifies an existing dependency rule, including its scope.
 func (h *AdminHandler) UpdateDependency(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	var req struct {
-		BlockingDocType string `json:"blockingDocType"`
-		BlockedDocType  string `json:"blockedDocType"`
-		Description     string `json:"description"`
-	}
+	var req dependencyRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
 		return
 	}
-	if req.BlockingDocType == "" || req.BlockedDocType == "" || req.Description == "" {
-		JSONError(w, http.StatusUnprocessableEntity, "All fields are required")
-		return
-	}
+	req.normalize()
 
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
 	pool := h.db.GetPool()
 
+	if !validateDependency(ctx, w, pool, &req) {
+		return
+	}
+
+	// minValidityDays is optional on update — omitted keeps the current threshold
 	var dep dependencyRow
-	err := pool.QueryRow(ctx, `
+	if !saveDependency(ctx, w, pool, `
 		UPDATE document_dependencies
-		SET blocking_doc_type = $1, blocked_doc_type = $2, description = $3
-		WHERE id = $4
-		RETURNING id, blocking_doc_type, blocked_doc_type, description, created_at::text
-	`, req.BlockingDocType, req.BlockedDocType, req.Description, id).Scan(
-		&dep.ID, &dep.BlockingDocType, &dep.BlockedDocType, &dep.Description, &dep.CreatedAt,
-	)
-	if err != nil {
-		JSONError(w, http.StatusNotFound, "Dependency rule not found")
+		SET blocking_doc_type = $1, blocked_doc_type = $2, description = $3,
+		    min_validity_days = COALESCE($4, min_validity_days),
+		    company_id = $5, regulatory_authority = $6
+		WHERE id = $7
+		RETURNING `+dependencyCols,
+		[]interface{}{req.BlockingDocType, req.BlockedDocType, req.Description,
+			req.MinValidityDays, req.CompanyID, req.RegulatoryAuthority, id},
+		&dep) {
 		return
 	}
 
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+	go logActivity(pool, userID, "updated", "dependency", dep.ID, map[string]interface{}{
+		"blocking": dep.BlockingDocType, "blocked": dep.BlockedDocType, "minValidityDays": dep.MinValidityDays,
+		"scope": dep.Scope, "companyId": dep.CompanyID, "regulatoryAuthority": dep.RegulatoryAuthority,
+	})
+
 	JSON(w, http.StatusOK, map[string]interface{}{
 		"data":    dep,
 		"message": "Dependency rule updated",
@@ -586,6 +868,8 @@ func (h *AdminHandler) UpdateDependency(w http.ResponseWriter, r *http.Request)
 }
 
 // DeleteDependency removes a dependency rule.
+// Deleting a scoped row can re-expose the fallback chain, so the
+// remaining graph is cycle-checked before committing.
 func (h *AdminHandler) DeleteDependency(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
@@ -594,7 +878,14 @@ func (h *AdminHandler) DeleteDependency(w http.ResponseWriter, r *http.Request)
 
 	pool := h.db.GetPool()
 
-	tag, err := pool.Exec(ctx, "DELETE FROM document_dependencies WHERE id = $1", id)
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, "DELETE FROM document_dependencies WHERE id = $1", id)
 	if err != nil {
 		log.Printf("Error deleting dependency: %v", err)
 		JSONError(w, http.StatusInternalServerError, "Failed to delete dependency")
@@ -605,7 +896,283 @@ func (h *AdminHandler) DeleteDependency(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	cycle, scopeLabel, err := findDependencyCycle(ctx, tx)
+	if err != nil {
+		log.Printf("Error checking dependency cycle: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to delete dependency")
+		return
+	}
+	if cycle != nil {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error": fmt.Sprintf("Deleting this rule would expose a cycle (%s): %s", scopeLabel, strings.Join(cycle, " → ")),
+			"cycle": cycle,
+		})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to delete dependency")
+		return
+	}
+
 	JSON(w, http.StatusOK, map[string]interface{}{
 		"message": "Dependency rule deleted",
 	})
 }
+
+// ── Requirement Conditions ───────────────────────────────────
+
+const requirementConditionCols = `id, company_id, doc_type, effect,
+	nationalities, trades, genders, statuses, description,
+	created_at::text, updated_at::text`
+
+func scanRequirementCondition(scanner interface {
+	Scan(dest ...interface{}) error
+}, c *models.RequirementCondition) error {
+	return scanner.Scan(
+		&c.ID, &c.CompanyID, &c.DocType, &c.Effect,
+		&c.Nationalities, &c.Trades, &c.Genders, &c.Statuses, &c.Description,
+		&c.CreatedAt, &c.UpdatedAt,
+	)
+}
+
+// ListRequirementConditions returns conditional mandatory rules.
+// With ?company_id=X, returns that company's rows plus global rows;
+// otherwise returns every condition.
+func (h *AdminHandler) ListRequirementConditions(w http.ResponseWriter, r *http.Request) {
+	companyID := r.URL.Query().Get("company_id")
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	query := `SELECT ` + requirementConditionCols + ` FROM document_requirement_conditions`
+	args := []interface{}{}
+	if companyID != "" {
+		query += ` WHERE company_id IS NULL OR company_id = $1`
+		args = append(args, companyID)
+	}
+	query += ` ORDER BY doc_type, company_id NULLS FIRST, created_at`
+
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to list requirement conditions: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch requirement conditions")
+		return
+	}
+	defer rows.Close()
+
+	conditions := []models.RequirementCondition{}
+	for rows.Next() {
+		var c models.RequirementCondition
+		if err := scanRequirementCondition(rows, &c); err != nil {
+			log.Printf("Failed to scan requirement condition: %v", err)
+			continue
+		}
+		conditions = append(conditions, c)
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{"data": conditions})
+}
+
+// CreateRequirementCondition adds a conditional mandatory rule (admin-only).
+// Slots of the employees it affects are reconciled in the same transaction.
+func (h *AdminHandler) CreateRequirementCondition(w http.ResponseWriter, r *http.Request) {
+	var req models.RequirementConditionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	if errs := req.Validate(); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	// Reconciling slots may touch every employee
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	slots, err := snapshotSlots(ctx, tx, req.CompanyID)
+	if err != nil {
+		log.Printf("Failed to load document slots: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to create requirement condition")
+		return
+	}
+
+	var c models.RequirementCondition
+	err = scanRequirementCondition(tx.QueryRow(ctx, `
+		INSERT INTO document_requirement_conditions
+			(company_id, doc_type, effect, nationalities, trades, genders, statuses, description)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING `+requirementConditionCols,
+		req.CompanyID, req.DocType, req.Effect,
+		req.Nationalities, req.Trades, req.Genders, req.Statuses, req.Description,
+	), &c)
+	if err == nil {
+		err = slots.reconcile(ctx, tx)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		log.Printf("Failed to create requirement condition: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to create requirement condition")
+		return
+	}
+
+	go logActivity(pool, userID, "created", "requirement_condition", c.ID, map[string]interface{}{
+		"docType": c.DocType, "effect": c.Effect, "companyId": c.CompanyID,
+	})
+	refreshStatusAsync(pool, "")
+
+	JSON(w, http.StatusCreated, map[string]interface{}{
+		"data":    c,
+		"message": "Requirement condition created",
+	})
+}
+
+// UpdateRequirementCondition replaces an existing conditional rule (admin-only).
+func (h *AdminHandler) UpdateRequirementCondition(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var req models.RequirementConditionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	if errs := req.Validate(); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	// Reconciling slots may touch every employee
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// The condition may move between scopes: reconcile both
+	var fromCompanyID *string
+	if err := tx.QueryRow(ctx, `
+		SELECT company_id::text FROM document_requirement_conditions WHERE id = $1 FOR UPDATE
+	`, id).Scan(&fromCompanyID); err != nil {
+		JSONError(w, http.StatusNotFound, "Requirement condition not found")
+		return
+	}
+	slots, err := snapshotSlots(ctx, tx, fromCompanyID, req.CompanyID)
+	if err != nil {
+		log.Printf("Failed to load document slots: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to update requirement condition")
+		return
+	}
+
+	var c models.RequirementCondition
+	err = scanRequirementCondition(tx.QueryRow(ctx, `
+		UPDATE document_requirement_conditions SET
+			company_id = $1, doc_type = $2, effect = $3,
+			nationalities = $4, trades = $5, genders = $6, statuses = $7,
+			description = $8, updated_at = NOW()
+		WHERE id = $9
+		RETURNING `+requirementConditionCols,
+		req.CompanyID, req.DocType, req.Effect,
+		req.Nationalities, req.Trades, req.Genders, req.Statuses, req.Description,
+		id,
+	), &c)
+	if err == nil {
+		err = slots.reconcile(ctx, tx)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		log.Printf("Failed to update requirement condition %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to update requirement condition")
+		return
+	}
+
+	go logActivity(pool, userID, "updated", "requirement_condition", c.ID, map[string]interface{}{
+		"docType": c.DocType, "effect": c.Effect, "companyId": c.CompanyID,
+	})
+	refreshStatusAsync(pool, "")
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"data":    c,
+		"message": "Requirement condition updated",
+	})
+}
+
+// DeleteRequirementCondition removes a conditional rule (admin-only).
+func (h *AdminHandler) DeleteRequirementCondition(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	// Reconciling slots may touch every employee
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var companyID *string
+	if err := tx.QueryRow(ctx, `
+		SELECT company_id::text FROM document_requirement_conditions WHERE id = $1 FOR UPDATE
+	`, id).Scan(&companyID); err != nil {
+		JSONError(w, http.StatusNotFound, "Requirement condition not found")
+		return
+	}
+	slots, err := snapshotSlots(ctx, tx, companyID)
+	if err == nil {
+		_, err = tx.Exec(ctx, "DELETE FROM document_requirement_conditions WHERE id = $1", id)
+	}
+	if err == nil {
+		err = slots.reconcile(ctx, tx)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		log.Printf("Error deleting requirement condition: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to delete requirement condition")
+		return
+	}
+
+	go logActivity(pool, userID, "deleted", "requirement_condition", id, nil)
+	refreshStatusAsync(pool, "")
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Requirement condition deleted",
+	})
+}
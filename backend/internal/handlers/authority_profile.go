@@ -0,0 +1,633 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"manpower-backend/internal/ctxkeys"
+	"manpower-backend/internal/models"
+)
+
+// ── Authority Profiles ───────────────────────────────────────
+// A profile is applied to a company by upserting its rules as company-level
+// compliance_rules. Its dependencies live as authority-scoped
+// document_dependencies rows, replaced whenever the profile is saved, so every
+// company with that authority picks them up through effective_dependencies().
+
+const authorityProfileCols = `id, code, name, description, version, rules, dependencies,
+		created_at::text, updated_at::text`
+
+func scanAuthorityProfile(row pgx.Row, p *models.AuthorityProfile) error {
+	var rules, deps []byte
+	if err := row.Scan(&p.ID, &p.Code, &p.Name, &p.Description, &p.Version,
+		&rules, &deps, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return err
+	}
+	p.Rules = []models.ComplianceRuleInput{}
+	p.Dependencies = []models.AuthorityProfileDependency{}
+	_ = json.Unmarshal(rules, &p.Rules)
+	_ = json.Unmarshal(deps, &p.Dependencies)
+	return nil
+}
+
+// loadAuthorityProfile fetches a profile by code (case-insensitive).
+// Returns pgx.ErrNoRows when there is no profile for the code.
+func loadAuthorityProfile(ctx context.Context, q querier, code string) (*models.AuthorityProfile, error) {
+	var p models.AuthorityProfile
+	err := scanAuthorityProfile(q.QueryRow(ctx,
+		`SELECT `+authorityProfileCols+` FROM authority_profiles WHERE code = $1`,
+		models.NormalizeAuthorityCode(code)), &p)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// validateProfileDocTypes checks that every doc type referenced by the profile
+// exists in document_types.
+func validateProfileDocTypes(ctx context.Context, q querier, req *models.AuthorityProfileRequest) (map[string]string, error) {
+	errs := map[string]string{}
+	rows, err := q.Query(ctx, `SELECT doc_type FROM document_types`)
+	if err != nil {
+		return nil, err
+	}
+	known := map[string]bool{}
+	for rows.Next() {
+		var dt string
+		if err := rows.Scan(&dt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		known[dt] = true
+	}
+	rows.Close()
+
+	for _, rule := range req.Rules {
+		if !known[rule.DocType] {
+			errs["rules"] = "Unknown document type: " + rule.DocType
+			break
+		}
+	}
+	for _, d := range req.Dependencies {
+		if !known[d.BlockingDocType] || !known[d.BlockedDocType] {
+			errs["dependencies"] = fmt.Sprintf("Unknown document type in %s → %s", d.BlockingDocType, d.BlockedDocType)
+			break
+		}
+	}
+	return errs, nil
+}
+
+// syncProfileDependencies replaces the authority-scoped dependency rows with
+// the profile's dependencies. Run inside the transaction saving the profile;
+// the caller must cycle-check afterwards.
+func syncProfileDependencies(ctx context.Context, q querier, code string, deps []models.AuthorityProfileDependency) error {
+	if _, err := q.Exec(ctx, `
+		DELETE FROM document_dependencies
+		WHERE company_id IS NULL AND upper(regulatory_authority) = $1
+	`, code); err != nil {
+		return err
+	}
+	for _, d := range deps {
+		if _, err := q.Exec(ctx, `
+			INSERT INTO document_dependencies
+				(blocking_doc_type, blocked_doc_type, description, min_validity_days, regulatory_authority)
+			VALUES ($1, $2, $3, $4, $5)
+		`, d.BlockingDocType, d.BlockedDocType, strings.TrimSpace(d.Description), d.MinValidityDays, code); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// profileApplication summarises applying a profile to a company.
+type profileApplication struct {
+	Code             string `json:"code"`
+	Version          int    `json:"version"`
+	RulesApplied     int    `json:"rulesApplied"`
+	EmployeesChanged int    `json:"employeesChanged"` // employees whose mandatory slots changed
+}
+
+// applyAuthorityProfile writes the profile for `code` as company-level
+// compliance_rules, records the applied version and reconciles employee slots.
+// Returns nil, nil when no profile exists for the code (free-text authority).
+func applyAuthorityProfile(ctx context.Context, q querier, companyID, code string) (*profileApplication, error) {
+	profile, err := loadAuthorityProfile(ctx, q, code)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := requiredSlots(ctx, q, "e.company_id::text = $1", companyID)
+	if err != nil {
+		return nil, fmt.Errorf("load slots: %w", err)
+	}
+
+	for _, rule := range profile.Rules {
+		if _, err := q.Exec(ctx, `
+			INSERT INTO compliance_rules (company_id, doc_type, grace_period_days, fine_per_day, fine_type, fine_cap, is_mandatory, grace_period_unit)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (company_id, doc_type)
+			DO UPDATE SET
+				grace_period_days = EXCLUDED.grace_period_days,
+				grace_period_unit = EXCLUDED.grace_period_unit,
+				fine_per_day      = EXCLUDED.fine_per_day,
+				fine_type         = EXCLUDED.fine_type,
+				fine_cap          = EXCLUDED.fine_cap,
+				is_mandatory      = EXCLUDED.is_mandatory,
+				updated_at        = NOW()
+		`, companyID, rule.DocType, rule.GracePeriodDays, rule.FinePerDay, rule.FineType, rule.FineCap, rule.IsMandatory, rule.GraceUnit()); err != nil {
+			return nil, fmt.Errorf("upsert rule %s: %w", rule.DocType, err)
+		}
+	}
+
+	if _, err := q.Exec(ctx, `UPDATE companies SET authority_profile_version = $1 WHERE id = $2`,
+		profile.Version, companyID); err != nil {
+		return nil, err
+	}
+
+	changed, err := reconcileCompanySlots(ctx, q, companyID, previous)
+	if err != nil {
+		return nil, fmt.Errorf("reconcile slots: %w", err)
+	}
+
+	return &profileApplication{
+		Code:             profile.Code,
+		Version:          profile.Version,
+		RulesApplied:     len(profile.Rules),
+		EmployeesChanged: changed,
+	}, nil
+}
+
+// previewAuthorityProfile diffs a profile against a company's current rules
+// and effective dependency chain without changing anything.
+func previewAuthorityProfile(ctx context.Context, q querier, companyID string, profile *models.AuthorityProfile) (*models.ProfilePreview, error) {
+	preview := &models.ProfilePreview{
+		CompanyID:           companyID,
+		Code:                profile.Code,
+		Version:             profile.Version,
+		Rules:               []models.ProfileRuleDiff{},
+		DependenciesAdded:   []models.AuthorityProfileDependency{},
+		DependenciesRemoved: []models.AuthorityProfileDependency{},
+	}
+	if err := q.QueryRow(ctx, `SELECT authority_profile_version FROM companies WHERE id = $1`,
+		companyID).Scan(&preview.AppliedVersion); err != nil {
+		return nil, err
+	}
+
+	for _, proposed := range profile.Rules {
+		diff := models.ProfileRuleDiff{DocType: proposed.DocType, Proposed: proposed}
+
+		var cur models.ComplianceRuleInput
+		var curFound bool
+		err := q.QueryRow(ctx, `
+			SELECT doc_type, grace_period_days, grace_period_unit, fine_per_day, fine_type, fine_cap, is_mandatory
+			FROM compliance_rules WHERE company_id = $1 AND doc_type = $2
+		`, companyID, proposed.DocType).Scan(&cur.DocType, &cur.GracePeriodDays, &cur.GracePeriodUnit,
+			&cur.FinePerDay, &cur.FineType, &cur.FineCap, &cur.IsMandatory)
+		switch {
+		case err == nil:
+			curFound = true
+			diff.Current = &cur
+		case !errors.Is(err, pgx.ErrNoRows):
+			return nil, err
+		}
+
+		// Effective = company row, else global row, with mandatory-ness from the document type
+		var eff models.ComplianceRuleInput
+		eff.DocType = proposed.DocType
+		var mandatory bool
+		if err := q.QueryRow(ctx, `
+			SELECT COALESCE(cr.grace_period_days, gr.grace_period_days, 0),
+			       COALESCE(cr.grace_period_unit, gr.grace_period_unit, 'calendar'),
+			       COALESCE(cr.fine_per_day, gr.fine_per_day, 0),
+			       COALESCE(cr.fine_type, gr.fine_type, 'daily'),
+			       COALESCE(cr.fine_cap, gr.fine_cap, 0),
+			       COALESCE(cr.is_mandatory, dt.is_mandatory, FALSE)
+			FROM (SELECT $2::text AS doc_type) x
+			LEFT JOIN document_types dt ON dt.doc_type = x.doc_type
+			LEFT JOIN compliance_rules cr ON cr.doc_type = x.doc_type AND cr.company_id = $1
+			LEFT JOIN compliance_rules gr ON gr.doc_type = x.doc_type AND gr.company_id IS NULL
+		`, companyID, proposed.DocType).Scan(&eff.GracePeriodDays, &eff.GracePeriodUnit, &eff.FinePerDay, &eff.FineType,
+			&eff.FineCap, &mandatory); err != nil {
+			return nil, err
+		}
+		eff.IsMandatory = &mandatory
+		diff.Effective = eff
+
+		switch {
+		case !curFound:
+			diff.Change = "add"
+		case sameRule(cur, proposed):
+			diff.Change = "unchanged"
+		default:
+			diff.Change = "update"
+		}
+		preview.Rules = append(preview.Rules, diff)
+	}
+
+	before, err := effectiveDependencySet(ctx, q, companyID, nil)
+	if err != nil {
+		return nil, err
+	}
+	after, err := effectiveDependencySet(ctx, q, companyID, &profile.Code)
+	if err != nil {
+		return nil, err
+	}
+	for key, d := range after {
+		if _, ok := before[key]; !ok {
+			preview.DependenciesAdded = append(preview.DependenciesAdded, d)
+		}
+	}
+	for key, d := range before {
+		if _, ok := after[key]; !ok {
+			preview.DependenciesRemoved = append(preview.DependenciesRemoved, d)
+		}
+	}
+	return preview, nil
+}
+
+// effectiveDependencySet returns the dependencies in force for the company,
+// optionally as if its authority were `authority`, keyed by edge + threshold.
+func effectiveDependencySet(ctx context.Context, q querier, companyID string, authority *string) (map[string]models.AuthorityProfileDependency, error) {
+	rows, err := q.Query(ctx, `
+		SELECT blocking_doc_type, blocked_doc_type, description, min_validity_days
+		FROM effective_dependencies($1::uuid, $2)
+	`, companyID, authority)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	set := map[string]models.AuthorityProfileDependency{}
+	for rows.Next() {
+		var d models.AuthorityProfileDependency
+		if err := rows.Scan(&d.BlockingDocType, &d.BlockedDocType, &d.Description, &d.MinValidityDays); err != nil {
+			return nil, err
+		}
+		set[fmt.Sprintf("%s→%s:%d", d.BlockingDocType, d.BlockedDocType, d.MinValidityDays)] = d
+	}
+	return set, rows.Err()
+}
+
+func sameRule(a, b models.ComplianceRuleInput) bool {
+	mandatory := func(p *bool) string {
+		if p == nil {
+			return "inherit"
+		}
+		return fmt.Sprint(*p)
+	}
+	return a.GracePeriodDays == b.GracePeriodDays && a.GraceUnit() == b.GraceUnit() && a.FinePerDay == b.FinePerDay &&
+		a.FineType == b.FineType && a.FineCap == b.FineCap &&
+		mandatory(a.IsMandatory) == mandatory(b.IsMandatory)
+}
+
+// ListAuthorityProfiles returns all authority profiles.
+func (h *AdminHandler) ListAuthorityProfiles(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	rows, err := pool.Query(ctx, `SELECT `+authorityProfileCols+` FROM authority_profiles ORDER BY code`)
+	if err != nil {
+		log.Printf("Failed to list authority profiles: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch authority profiles")
+		return
+	}
+	defer rows.Close()
+
+	profiles := []models.AuthorityProfile{}
+	for rows.Next() {
+		var p models.AuthorityProfile
+		if err := scanAuthorityProfile(rows, &p); err != nil {
+			log.Printf("Failed to scan authority profile: %v", err)
+			continue
+		}
+		profiles = append(profiles, p)
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{"data": profiles})
+}
+
+// GetAuthorityProfile returns one profile by code.
+func (h *AdminHandler) GetAuthorityProfile(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	profile, err := loadAuthorityProfile(ctx, h.db.GetPool(), chi.URLParam(r, "code"))
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("Failed to fetch authority profile: %v", err)
+		}
+		JSONError(w, http.StatusNotFound, "Authority profile not found")
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{"data": profile})
+}
+
+// CreateAuthorityProfile adds a new profile at version 1.
+func (h *AdminHandler) CreateAuthorityProfile(w http.ResponseWriter, r *http.Request) {
+	var req models.AuthorityProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	h.saveAuthorityProfile(w, r, &req, "")
+}
+
+// UpdateAuthorityProfile replaces a profile's contents and bumps its version.
+// Companies keep their applied version until the profile is re-applied.
+func (h *AdminHandler) UpdateAuthorityProfile(w http.ResponseWriter, r *http.Request) {
+	var req models.AuthorityProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	h.saveAuthorityProfile(w, r, &req, models.NormalizeAuthorityCode(chi.URLParam(r, "code")))
+}
+
+// saveAuthorityProfile creates (existingCode == "") or updates a profile,
+// snapshots the new version and syncs its dependencies, all in one transaction.
+func (h *AdminHandler) saveAuthorityProfile(w http.ResponseWriter, r *http.Request, req *models.AuthorityProfileRequest, existingCode string) {
+	isCreate := existingCode == ""
+	if errs := req.Validate(isCreate); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+	code := existingCode
+	if isCreate {
+		code = req.Code
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	if errs, err := validateProfileDocTypes(ctx, pool, req); err != nil {
+		log.Printf("Failed to validate authority profile doc types: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to save authority profile")
+		return
+	} else if len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	rulesJSON, _ := json.Marshal(req.Rules)
+	if req.Dependencies == nil {
+		req.Dependencies = []models.AuthorityProfileDependency{}
+	}
+	depsJSON, _ := json.Marshal(req.Dependencies)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var profile models.AuthorityProfile
+	if isCreate {
+		err = scanAuthorityProfile(tx.QueryRow(ctx, `
+			INSERT INTO authority_profiles (code, name, description, rules, dependencies)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING `+authorityProfileCols,
+			code, req.Name, req.Description, string(rulesJSON), string(depsJSON)), &profile)
+		if err != nil {
+			if isDuplicateKeyError(err) {
+				JSONError(w, http.StatusConflict, "A profile with this code already exists")
+				return
+			}
+			log.Printf("Failed to create authority profile: %v", err)
+			JSONError(w, http.StatusInternalServerError, "Failed to save authority profile")
+			return
+		}
+	} else {
+		err = scanAuthorityProfile(tx.QueryRow(ctx, `
+			UPDATE authority_profiles
+			SET name = $1, description = $2, rules = $3, dependencies = $4,
+			    version = version + 1, updated_at = NOW()
+			WHERE code = $5
+			RETURNING `+authorityProfileCols,
+			req.Name, req.Description, string(rulesJSON), string(depsJSON), code), &profile)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				JSONError(w, http.StatusNotFound, "Authority profile not found")
+				return
+			}
+			log.Printf("Failed to update authority profile: %v", err)
+			JSONError(w, http.StatusInternalServerError, "Failed to save authority profile")
+			return
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO authority_profile_versions (profile_id, version, name, description, rules, dependencies, changed_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, profile.ID, profile.Version, profile.Name, profile.Description,
+		string(rulesJSON), string(depsJSON), nilIfEmptyStr(userID)); err != nil {
+		log.Printf("Failed to snapshot authority profile version: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to save authority profile")
+		return
+	}
+
+	if err := syncProfileDependencies(ctx, tx, code, req.Dependencies); err != nil {
+		log.Printf("Failed to sync authority profile dependencies: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to save authority profile")
+		return
+	}
+	cycle, scopeLabel, err := findDependencyCycle(ctx, tx)
+	if err != nil {
+		log.Printf("Error checking dependency cycle: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to save authority profile")
+		return
+	}
+	if cycle != nil {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error": fmt.Sprintf("Profile dependencies would create a cycle (%s): %s", scopeLabel, strings.Join(cycle, " → ")),
+			"cycle": cycle,
+		})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to save authority profile")
+		return
+	}
+
+	action, status, message := "updated", http.StatusOK, "Authority profile updated"
+	if isCreate {
+		action, status, message = "created", http.StatusCreated, "Authority profile created"
+	}
+	go logActivity(pool, userID, action, "authority_profile", profile.ID, map[string]interface{}{
+		"code": profile.Code, "version": profile.Version,
+	})
+
+	JSON(w, status, map[string]interface{}{
+		"data":    profile,
+		"message": message,
+	})
+}
+
+// ListAuthorityProfileVersions returns the version history of a profile, newest first.
+func (h *AdminHandler) ListAuthorityProfileVersions(w http.ResponseWriter, r *http.Request) {
+	code := models.NormalizeAuthorityCode(chi.URLParam(r, "code"))
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	rows, err := pool.Query(ctx, `
+		SELECT v.version, v.name, v.description, v.rules, v.dependencies,
+		       v.changed_by::text, v.created_at::text
+		FROM authority_profile_versions v
+		JOIN authority_profiles p ON p.id = v.profile_id
+		WHERE p.code = $1
+		ORDER BY v.version DESC
+	`, code)
+	if err != nil {
+		log.Printf("Failed to list authority profile versions: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch profile versions")
+		return
+	}
+	defer rows.Close()
+
+	versions := []models.AuthorityProfileVersion{}
+	for rows.Next() {
+		var v models.AuthorityProfileVersion
+		var rules, deps []byte
+		if err := rows.Scan(&v.Version, &v.Name, &v.Description, &rules, &deps, &v.ChangedBy, &v.CreatedAt); err != nil {
+			log.Printf("Failed to scan authority profile version: %v", err)
+			continue
+		}
+		v.Rules = []models.ComplianceRuleInput{}
+		v.Dependencies = []models.AuthorityProfileDependency{}
+		_ = json.Unmarshal(rules, &v.Rules)
+		_ = json.Unmarshal(deps, &v.Dependencies)
+		versions = append(versions, v)
+	}
+
+	if len(versions) == 0 {
+		JSONError(w, http.StatusNotFound, "Authority profile not found")
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{"data": versions})
+}
+
+// PreviewAuthorityProfile handles GET /api/admin/authority-profiles/{code}/preview?company_id=X
+// and shows what applying the profile would change for the company.
+func (h *AdminHandler) PreviewAuthorityProfile(w http.ResponseWriter, r *http.Request) {
+	companyID := r.URL.Query().Get("company_id")
+	if companyID == "" {
+		JSONError(w, http.StatusBadRequest, "company_id is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	profile, err := loadAuthorityProfile(ctx, pool, chi.URLParam(r, "code"))
+	if err != nil {
+		JSONError(w, http.StatusNotFound, "Authority profile not found")
+		return
+	}
+
+	preview, err := previewAuthorityProfile(ctx, pool, companyID, profile)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			JSONError(w, http.StatusNotFound, "Company not found")
+			return
+		}
+		log.Printf("Failed to preview authority profile: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to preview authority profile")
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{"data": preview})
+}
+
+// ApplyAuthorityProfile handles POST /api/admin/authority-profiles/{code}/apply
+// with {"companyId": "..."}. Sets the company's authority to the profile code
+// and writes the profile rules as company-level overrides.
+func (h *AdminHandler) ApplyAuthorityProfile(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		CompanyID string `json:"companyId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	if req.CompanyID == "" {
+		JSONError(w, http.StatusUnprocessableEntity, "companyId is required")
+		return
+	}
+	code := models.NormalizeAuthorityCode(chi.URLParam(r, "code"))
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `UPDATE companies SET regulatory_authority = $1, updated_at = NOW() WHERE id = $2`, code, req.CompanyID)
+	if err != nil || tag.RowsAffected() == 0 {
+		JSONError(w, http.StatusNotFound, "Company not found")
+		return
+	}
+
+	applied, err := applyAuthorityProfile(ctx, tx, req.CompanyID, code)
+	if err != nil {
+		log.Printf("Failed to apply authority profile %s to company %s: %v", code, req.CompanyID, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to apply authority profile")
+		return
+	}
+	if applied == nil {
+		JSONError(w, http.StatusNotFound, "Authority profile not found")
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to apply authority profile")
+		return
+	}
+
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+	go logActivity(pool, userID, "applied", "authority_profile", req.CompanyID, map[string]interface{}{
+		"code": applied.Code, "version": applied.Version, "employeesChanged": applied.EmployeesChanged,
+	})
+	refreshStatusAsync(pool, req.CompanyID)
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"data":    applied,
+		"message": "Authority profile applied",
+	})
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"manpower-backend/internal/compliance"
 	"manpower-backend/internal/ctxkeys"
 	"manpower-backend/internal/database"
 	"manpower-backend/internal/models"
@@ -44,6 +45,7 @@ func (h *CompanyHandler) List(w http.ResponseWriter, r *http.Request) {
 		SELECT c.id, c.name, COALESCE(c.currency, 'AED'),
 			c.trade_license_number, c.establishment_card_number,
 			c.mohre_category, c.regulatory_authority,
+			c.timezone, c.weekend_days,
 			c.created_at::text, c.updated_at::text,
 			COUNT(e.id) AS employee_count
 		FROM companies c
@@ -52,6 +54,7 @@ func (h *CompanyHandler) List(w http.ResponseWriter, r *http.Request) {
 		GROUP BY c.id, c.name, c.currency,
 			c.trade_license_number, c.establishment_card_number,
 			c.mohre_category, c.regulatory_authority,
+			c.timezone, c.weekend_days,
 			c.created_at, c.updated_at
 		ORDER BY c.name ASC
 	`, where), args...)
@@ -74,6 +77,7 @@ func (h *CompanyHandler) List(w http.ResponseWriter, r *http.Request) {
 			&c.ID, &c.Name, &c.Currency,
 			&c.TradeLicenseNumber, &c.EstablishmentCardNumber,
 			&c.MohreCategory, &c.RegulatoryAuthority,
+			&c.Timezone, &c.WeekendDays,
 			&c.CreatedAt, &c.UpdatedAt,
 			&c.EmployeeCount,
 		); err != nil {
@@ -113,12 +117,14 @@ func (h *CompanyHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		SELECT id, name, COALESCE(currency, 'AED'),
 			trade_license_number, establishment_card_number,
 			mohre_category, regulatory_authority,
+			timezone, weekend_days,
 			created_at::text, updated_at::text
 		FROM companies WHERE id = $1
 	`, id).Scan(
 		&company.ID, &company.Name, &company.Currency,
 		&company.TradeLicenseNumber, &company.EstablishmentCardNumber,
 		&company.MohreCategory, &company.RegulatoryAuthority,
+		&company.Timezone, &company.WeekendDays,
 		&company.CreatedAt, &company.UpdatedAt,
 	)
 	if err != nil {
@@ -176,9 +182,43 @@ type createCompanyRequest struct {
 	EstablishmentCardNumber *string `json:"establishmentCardNumber,omitempty"`
 	MohreCategory           *string `json:"mohreCategory,omitempty"`
 	RegulatoryAuthority     *string `json:"regulatoryAuthority,omitempty"`
+	Timezone                string  `json:"timezone,omitempty"`    // empty = Asia/Dubai on create, unchanged on update
+	WeekendDays             []int   `json:"weekendDays,omitempty"` // empty = Sat/Sun on create, unchanged on update
 }
 
-// Create adds a new company.
+// normalizeAuthority upper-cases the authority code so it matches
+// authority_profiles.code; blank means none.
+func (req *createCompanyRequest) normalizeAuthority() {
+	if req.RegulatoryAuthority == nil {
+		return
+	}
+	code := models.NormalizeAuthorityCode(*req.RegulatoryAuthority)
+	if code == "" {
+		req.RegulatoryAuthority = nil
+		return
+	}
+	req.RegulatoryAuthority = &code
+}
+
+// validateCalendar checks the timezone and weekend days; returns "" when valid.
+func (req *createCompanyRequest) validateCalendar() string {
+	if req.Timezone != "" && !compliance.ValidTimezone(req.Timezone) {
+		return "Unknown timezone: " + req.Timezone
+	}
+	if len(req.WeekendDays) > 6 {
+		return "At least one working day is required"
+	}
+	seen := map[int]bool{}
+	for _, d := range req.WeekendDays {
+		if d < 1 || d > 7 || seen[d] {
+			return "weekendDays must be distinct ISO weekdays (1 = Monday … 7 = Sunday)"
+		}
+		seen[d] = true
+	}
+	return ""
+}
+
+// Create adds a new company and applies its authority profile, if any.
 func (h *CompanyHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req createCompanyRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -193,6 +233,11 @@ func (h *CompanyHandler) Create(w http.ResponseWriter, r *http.Request) {
 	if req.Currency == "" {
 		req.Currency = "AED"
 	}
+	req.normalizeAuthority()
+	if msg := req.validateCalendar(); msg != "" {
+		JSONError(w, http.StatusUnprocessableEntity, msg)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
@@ -202,25 +247,37 @@ func (h *CompanyHandler) Create(w http.ResponseWriter, r *http.Request) {
 	// Link company to the logged-in user (if available)
 	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
 
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback(ctx)
+
 	var company models.Company
-	err := pool.QueryRow(ctx, `
+	err = tx.QueryRow(ctx, `
 		INSERT INTO companies (
 			name, currency, user_id,
 			trade_license_number, establishment_card_number,
-			mohre_category, regulatory_authority
+			mohre_category, regulatory_authority,
+			timezone, weekend_days
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		VALUES ($1, $2, $3, $4, $5, $6, $7,
+			COALESCE($8, 'Asia/Dubai'), COALESCE($9::smallint[], '{6,7}'))
 		RETURNING id, name, currency,
 			trade_license_number, establishment_card_number,
 			mohre_category, regulatory_authority,
+			timezone, weekend_days,
 			created_at::text, updated_at::text
 	`, req.Name, req.Currency, nilIfEmptyStr(userID),
 		req.TradeLicenseNumber, req.EstablishmentCardNumber,
 		req.MohreCategory, req.RegulatoryAuthority,
+		nilIfEmptyStr(req.Timezone), req.WeekendDays,
 	).Scan(
 		&company.ID, &company.Name, &company.Currency,
 		&company.TradeLicenseNumber, &company.EstablishmentCardNumber,
 		&company.MohreCategory, &company.RegulatoryAuthority,
+		&company.Timezone, &company.WeekendDays,
 		&company.CreatedAt, &company.UpdatedAt,
 	)
 
@@ -234,15 +291,33 @@ func (h *CompanyHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Seed company-level compliance rules from the authority profile, if one exists
+	var profile *profileApplication
+	if company.RegulatoryAuthority != nil && *company.RegulatoryAuthority != "" {
+		profile, err = applyAuthorityProfile(ctx, tx, company.ID, *company.RegulatoryAuthority)
+		if err != nil {
+			log.Printf("Error applying authority profile to company %s: %v", company.ID, err)
+			JSONError(w, http.StatusInternalServerError, "Failed to create company")
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to create company")
+		return
+	}
+
 	JSON(w, http.StatusCreated, map[string]interface{}{
 		"data":    company,
+		"profile": profile,
 		"message": "Company created successfully",
 	})
 }
 
 // ── Update ─────────────────────────────────────────────────────
 
-// Update modifies a company's details.
+// Update modifies a company's details. Changing the regulatory authority
+// re-applies the matching authority profile.
 func (h *CompanyHandler) Update(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
@@ -259,31 +334,53 @@ func (h *CompanyHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if req.Currency == "" {
 		req.Currency = "AED"
 	}
+	req.normalizeAuthority()
+	if msg := req.validateCalendar(); msg != "" {
+		JSONError(w, http.StatusUnprocessableEntity, msg)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
 	pool := h.db.GetPool()
 
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var previousAuthority *string
+	if err := tx.QueryRow(ctx, `SELECT regulatory_authority FROM companies WHERE id = $1 FOR UPDATE`, id).Scan(&previousAuthority); err != nil {
+		JSONError(w, http.StatusNotFound, "Company not found")
+		return
+	}
+
 	var company models.Company
-	err := pool.QueryRow(ctx, `
+	err = tx.QueryRow(ctx, `
 		UPDATE companies SET
 			name = $1, currency = $2, updated_at = NOW(),
 			trade_license_number = $3, establishment_card_number = $4,
-			mohre_category = $5, regulatory_authority = $6
+			mohre_category = $5, regulatory_authority = $6,
+			timezone = COALESCE($8, timezone),
+			weekend_days = COALESCE($9::smallint[], weekend_days)
 		WHERE id = $7
 		RETURNING id, name, currency,
 			trade_license_number, establishment_card_number,
 			mohre_category, regulatory_authority,
+			timezone, weekend_days,
 			created_at::text, updated_at::text
 	`, req.Name, req.Currency,
 		req.TradeLicenseNumber, req.EstablishmentCardNumber,
 		req.MohreCategory, req.RegulatoryAuthority,
-		id,
+		id, nilIfEmptyStr(req.Timezone), req.WeekendDays,
 	).Scan(
 		&company.ID, &company.Name, &company.Currency,
 		&company.TradeLicenseNumber, &company.EstablishmentCardNumber,
 		&company.MohreCategory, &company.RegulatoryAuthority,
+		&company.Timezone, &company.WeekendDays,
 		&company.CreatedAt, &company.UpdatedAt,
 	)
 
@@ -296,8 +393,28 @@ func (h *CompanyHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Authority changed — apply the new authority's profile as company-level rules
+	var profile *profileApplication
+	if company.RegulatoryAuthority != nil && *company.RegulatoryAuthority != "" &&
+		(previousAuthority == nil || *previousAuthority != *company.RegulatoryAuthority) {
+		profile, err = applyAuthorityProfile(ctx, tx, company.ID, *company.RegulatoryAuthority)
+		if err != nil {
+			log.Printf("Error applying authority profile to company %s: %v", company.ID, err)
+			JSONError(w, http.StatusInternalServerError, "Failed to update company")
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to update company")
+		return
+	}
+	// Timezone, weekend days and applied rules all feed status computation
+	refreshStatusAsync(h.db.GetPool(), company.ID)
+
 	JSON(w, http.StatusOK, map[string]interface{}{
 		"data":    company,
+		"profile": profile,
 		"message": "Company updated successfully",
 	})
 }
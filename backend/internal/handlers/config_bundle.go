@@ -0,0 +1,1706 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gopkg.in/yaml.v3"
+
+	"manpower-backend/internal/compliance"
+	"manpower-backend/internal/ctxkeys"
+	"manpower-backend/internal/models"
+)
+
+// ── Admin Configuration Bundle ───────────────────────────────
+// Export serialises every admin setting, global and per-company: document
+// types, compliance rules, dependencies, requirement conditions, authority
+// profiles, document links, public holidays, company calendars, renewal
+// costs, score weights, exit checklist rules, custom fields, leave types and
+// Emiratisation targets. Import diffs a bundle against the current settings
+// and applies the diff in one transaction; importing the same bundle twice
+// is a no-op. Document types are only ever deactivated (never hard-deleted),
+// and never when documents still use them; custom fields and leave types
+// are kept while employee data still uses them.
+
+const maxConfigBundleSize = 5 << 20 // 5 MB
+
+// mandatorySections are the sections that decide which document slots
+// employees need; changing them reconciles every company's slots.
+var mandatorySections = map[string]bool{
+	"documentTypes": true, "complianceRules": true, "requirementConditions": true,
+}
+
+// defaultWeekendDays is the column default of companies.weekend_days
+// (Saturday/Sunday, migration 015).
+var defaultWeekendDays = []int{6, 7}
+
+// ── Load ──────────────────────────────────────────────────────
+
+// loadConfigBundle reads the current admin settings into a bundle.
+func loadConfigBundle(ctx context.Context, q querier) (*models.ConfigBundle, error) {
+	b := &models.ConfigBundle{
+		Format:                models.ConfigBundleFormat,
+		SchemaVersion:         models.ConfigBundleSchemaVersion,
+		ExportedAt:            time.Now().UTC().Format(time.RFC3339),
+		DocumentTypes:         []models.BundleDocumentType{},
+		ComplianceRules:       []models.BundleComplianceRule{},
+		Dependencies:          []models.BundleDependency{},
+		RequirementConditions: []models.BundleRequirementCondition{},
+		AuthorityProfiles:     []models.BundleAuthorityProfile{},
+		DocumentLinks:         []models.BundleDocumentLink{},
+		PublicHolidays:        []models.BundlePublicHoliday{},
+		CompanyCalendars:      []models.BundleCompanyCalendar{},
+		RenewalCosts:          []models.BundleRenewalCost{},
+		ExitChecklistRules:    []models.BundleExitChecklistRule{},
+		CustomFields:          []models.BundleCustomField{},
+		LeaveTypes:            []models.LeaveType{},
+		EmiratisationTargets:  []models.BundleEmiratisationTarget{},
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT doc_type, display_name, is_mandatory, has_expiry,
+		       number_label, number_placeholder, expiry_label, sort_order,
+		       metadata_fields, is_system, is_active,
+		       show_document_number, require_document_number,
+		       show_issue_date, require_issue_date,
+		       show_expiry_date, require_expiry_date,
+		       show_file, require_file, validity_period, validity_unit, applies_to
+		FROM document_types
+		ORDER BY sort_order, doc_type
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var dt models.BundleDocumentType
+		var metadata []byte
+		if err := rows.Scan(&dt.DocType, &dt.DisplayName, &dt.IsMandatory, &dt.HasExpiry,
+			&dt.NumberLabel, &dt.NumberPlaceholder, &dt.ExpiryLabel, &dt.SortOrder,
+			&metadata, &dt.IsSystem, &dt.IsActive,
+			&dt.ShowDocumentNumber, &dt.RequireDocumentNumber,
+			&dt.ShowIssueDate, &dt.RequireIssueDate,
+			&dt.ShowExpiryDate, &dt.RequireExpiryDate,
+			&dt.ShowFile, &dt.RequireFile, &dt.ValidityPeriod, &dt.ValidityUnit, &dt.AppliesTo); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		dt.MetadataFields = []interface{}{}
+		_ = json.Unmarshal(metadata, &dt.MetadataFields)
+		b.DocumentTypes = append(b.DocumentTypes, dt)
+	}
+	rows.Close()
+
+	rows, err = q.Query(ctx, `
+		SELECT cr.id, COALESCE(c.name, ''), cr.doc_type, cr.grace_period_days, cr.grace_period_unit,
+		       cr.fine_per_day, cr.fine_type, cr.fine_cap, cr.is_mandatory,
+		       cr.validity_period, cr.validity_unit
+		FROM compliance_rules cr
+		LEFT JOIN companies c ON c.id = cr.company_id
+		ORDER BY c.name NULLS FIRST, cr.doc_type
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var cr models.BundleComplianceRule
+		if err := rows.Scan(&cr.ID, &cr.Company, &cr.DocType, &cr.GracePeriodDays, &cr.GracePeriodUnit,
+			&cr.FinePerDay, &cr.FineType, &cr.FineCap, &cr.IsMandatory,
+			&cr.ValidityPeriod, &cr.ValidityUnit); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		b.ComplianceRules = append(b.ComplianceRules, cr)
+	}
+	rows.Close()
+
+	rows, err = q.Query(ctx, `
+		SELECT d.id, COALESCE(c.name, ''), COALESCE(upper(d.regulatory_authority), ''),
+		       d.blocking_doc_type, d.blocked_doc_type, d.description, d.min_validity_days
+		FROM document_dependencies d
+		LEFT JOIN companies c ON c.id = d.company_id
+		ORDER BY c.name NULLS FIRST, d.regulatory_authority NULLS FIRST, d.blocking_doc_type, d.blocked_doc_type
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var d models.BundleDependency
+		if err := rows.Scan(&d.ID, &d.Company, &d.RegulatoryAuthority,
+			&d.BlockingDocType, &d.BlockedDocType, &d.Description, &d.MinValidityDays); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		b.Dependencies = append(b.Dependencies, d)
+	}
+	rows.Close()
+
+	rows, err = q.Query(ctx, `
+		SELECT rc.id, COALESCE(c.name, ''), rc.doc_type, rc.effect,
+		       rc.nationalities, rc.trades, rc.genders, rc.statuses, rc.description
+		FROM document_requirement_conditions rc
+		LEFT JOIN companies c ON c.id = rc.company_id
+		ORDER BY c.name NULLS FIRST, rc.doc_type, rc.created_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var rc models.BundleRequirementCondition
+		if err := rows.Scan(&rc.ID, &rc.Company, &rc.DocType, &rc.Effect,
+			&rc.Nationalities, &rc.Trades, &rc.Genders, &rc.Statuses, &rc.Description); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		b.RequirementConditions = append(b.RequirementConditions, rc)
+	}
+	rows.Close()
+
+	rows, err = q.Query(ctx, `SELECT `+authorityProfileCols+` FROM authority_profiles ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var p models.AuthorityProfile
+		if err := scanAuthorityProfile(rows, &p); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		b.AuthorityProfiles = append(b.AuthorityProfiles, models.BundleAuthorityProfile{
+			Code: p.Code, Name: p.Name, Description: p.Description,
+			Rules: p.Rules, Dependencies: p.Dependencies,
+		})
+	}
+	rows.Close()
+
+	rows, err = q.Query(ctx, `
+		SELECT l.id, COALESCE(c.name, ''), l.source_doc_type, l.target_doc_type, l.mode,
+		       l.offset_days, l.validity_months, l.validity_days, l.action, l.is_active, l.description
+		FROM document_links l
+		LEFT JOIN companies c ON c.id = l.company_id
+		ORDER BY c.name NULLS FIRST, l.source_doc_type, l.target_doc_type
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var l models.BundleDocumentLink
+		if err := rows.Scan(&l.ID, &l.Company, &l.SourceDocType, &l.TargetDocType, &l.Mode,
+			&l.OffsetDays, &l.ValidityMonths, &l.ValidityDays, &l.Action, &l.IsActive, &l.Description); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		b.DocumentLinks = append(b.DocumentLinks, l)
+	}
+	rows.Close()
+
+	rows, err = q.Query(ctx, `
+		SELECT h.id, COALESCE(c.name, ''), h.holiday_date::text, h.name
+		FROM public_holidays h
+		LEFT JOIN companies c ON c.id = h.company_id
+		ORDER BY c.name NULLS FIRST, h.holiday_date
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var ph models.BundlePublicHoliday
+		if err := rows.Scan(&ph.ID, &ph.Company, &ph.Date, &ph.Name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		b.PublicHolidays = append(b.PublicHolidays, ph)
+	}
+	rows.Close()
+
+	rows, err = q.Query(ctx, `
+		SELECT name, timezone, weekend_days FROM companies
+		WHERE timezone <> $1 OR weekend_days <> $2::smallint[]
+		ORDER BY name
+	`, compliance.DefaultTimezone, defaultWeekendDays)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var cal models.BundleCompanyCalendar
+		if err := rows.Scan(&cal.Company, &cal.Timezone, &cal.WeekendDays); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		b.CompanyCalendars = append(b.CompanyCalendars, cal)
+	}
+	rows.Close()
+
+	rows, err = q.Query(ctx, `
+		SELECT rc.id, COALESCE(c.name, ''), rc.doc_type, rc.component, rc.amount::float8,
+		       rc.effective_from::text, rc.effective_to::text, rc.description
+		FROM renewal_costs rc
+		LEFT JOIN companies c ON c.id = rc.company_id
+		ORDER BY c.name NULLS FIRST, rc.doc_type, rc.component, rc.effective_from
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var rc models.BundleRenewalCost
+		if err := rows.Scan(&rc.ID, &rc.Company, &rc.DocType, &rc.Component, &rc.Amount,
+			&rc.EffectiveFrom, &rc.EffectiveTo, &rc.Description); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		b.RenewalCosts = append(b.RenewalCosts, rc)
+	}
+	rows.Close()
+
+	rows, err = q.Query(ctx, `
+		SELECT r.id, COALESCE(c.name, ''), r.item, r.due_days, r.fine_per_day::float8,
+		       r.fine_type, r.fine_cap::float8, r.doc_types, r.description
+		FROM exit_checklist_rules r
+		LEFT JOIN companies c ON c.id = r.company_id
+		ORDER BY c.name NULLS FIRST, r.item
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var er models.BundleExitChecklistRule
+		if err := rows.Scan(&er.ID, &er.Company, &er.Item, &er.DueDays, &er.FinePerDay,
+			&er.FineType, &er.FineCap, &er.DocTypes, &er.Description); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if er.DocTypes == nil {
+			er.DocTypes = []string{}
+		}
+		b.ExitChecklistRules = append(b.ExitChecklistRules, er)
+	}
+	rows.Close()
+
+	rows, err = q.Query(ctx, `
+		SELECT f.id, c.name, f.field_key, f.label, f.field_type, f.required, f.options, f.pattern,
+		       f.min_value::float8, f.max_value::float8, f.help_text, f.sort_order, f.is_active
+		FROM custom_field_definitions f
+		JOIN companies c ON c.id = f.company_id
+		ORDER BY c.name, f.sort_order, f.field_key
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var cf models.BundleCustomField
+		if err := rows.Scan(&cf.ID, &cf.Company, &cf.Key, &cf.Label, &cf.Type, &cf.Required, &cf.Options, &cf.Pattern,
+			&cf.MinValue, &cf.MaxValue, &cf.HelpText, &cf.SortOrder, &cf.IsActive); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if cf.Options == nil {
+			cf.Options = []models.CustomFieldOption{}
+		}
+		b.CustomFields = append(b.CustomFields, cf)
+	}
+	rows.Close()
+
+	rows, err = q.Query(ctx, `
+		SELECT code, name, is_paid, accrues, annual_days::float8, once_per_employment,
+		       max_carry_over::float8, sort_order
+		FROM leave_types
+		ORDER BY sort_order, code
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var lt models.LeaveType
+		if err := rows.Scan(&lt.Code, &lt.Name, &lt.IsPaid, &lt.Accrues, &lt.AnnualDays, &lt.OncePerEmployment,
+			&lt.MaxCarryOver, &lt.SortOrder); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		b.LeaveTypes = append(b.LeaveTypes, lt)
+	}
+	rows.Close()
+
+	rows, err = q.Query(ctx, `
+		SELECT t.id, COALESCE(c.name, ''), t.checkpoint_date::text, t.target_percent::float8,
+		       t.min_headcount, t.min_workforce, t.fine_per_missing::float8, t.description
+		FROM emiratisation_targets t
+		LEFT JOIN companies c ON c.id = t.company_id
+		ORDER BY c.name NULLS FIRST, t.checkpoint_date
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var et models.BundleEmiratisationTarget
+		if err := rows.Scan(&et.ID, &et.Company, &et.CheckpointDate, &et.TargetPercent,
+			&et.MinHeadcount, &et.MinWorkforce, &et.FinePerMissing, &et.Description); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		b.EmiratisationTargets = append(b.EmiratisationTargets, et)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	d := compliance.DefaultScoreWeights
+	sw := models.ScoreWeights{
+		Penalty: d.Penalty, Grace: d.Grace, Expiring: d.Expiring,
+		Incomplete: d.Incomplete, FineRate: d.FineRate, Optional: d.Optional,
+	}
+	err = q.QueryRow(ctx, `
+		SELECT penalty_weight::float8, grace_weight::float8, expiring_weight::float8,
+			incomplete_weight::float8, fine_rate_weight::float8, optional_weight::float8
+		FROM compliance_score_weights LIMIT 1
+	`).Scan(&sw.Penalty, &sw.Grace, &sw.Expiring, &sw.Incomplete, &sw.FineRate, &sw.Optional)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	b.ScoreWeights = &sw
+
+	b.Checksum = configBundleChecksum(b)
+	return b, nil
+}
+
+// configBundleChecksum hashes the settings sections only, so the same
+// configuration exported at different times has the same checksum.
+func configBundleChecksum(b *models.ConfigBundle) string {
+	sections, _ := json.Marshal([]interface{}{
+		b.DocumentTypes, b.ComplianceRules, b.Dependencies,
+		b.RequirementConditions, b.AuthorityProfiles, b.DocumentLinks,
+		b.PublicHolidays, b.CompanyCalendars, b.RenewalCosts, b.ScoreWeights,
+		b.ExitChecklistRules, b.CustomFields, b.LeaveTypes, b.EmiratisationTargets,
+	})
+	sum := sha256.Sum256(sections)
+	return hex.EncodeToString(sum[:])
+}
+
+// ── Encode / Decode ───────────────────────────────────────────
+
+// configBundleYAML renders the bundle as block-style YAML using the JSON
+// field names (JSON is parsed as YAML so key order is preserved).
+func configBundleYAML(b *models.ConfigBundle) ([]byte, error) {
+	raw, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(raw, &node); err != nil {
+		return nil, err
+	}
+	var clearStyle func(n *yaml.Node)
+	clearStyle = func(n *yaml.Node) {
+		n.Style = 0
+		for _, c := range n.Content {
+			clearStyle(c)
+		}
+	}
+	clearStyle(&node)
+	return yaml.Marshal(&node)
+}
+
+// parseConfigBundle decodes a JSON or YAML bundle. format may be "json",
+// "yaml" or "" (detect: JSON if the body starts with '{').
+func parseConfigBundle(body []byte, format string) (*models.ConfigBundle, error) {
+	trimmed := bytes.TrimSpace(body)
+	if format == "" {
+		format = "yaml"
+		if len(trimmed) > 0 && trimmed[0] == '{' {
+			format = "json"
+		}
+	}
+
+	raw := trimmed
+	if format == "yaml" {
+		var generic interface{}
+		if err := yaml.Unmarshal(trimmed, &generic); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+		var err error
+		if raw, err = json.Marshal(generic); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+	}
+
+	var b models.ConfigBundle
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&b); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	return &b, nil
+}
+
+// ── Validate ──────────────────────────────────────────────────
+
+// validateConfigBundle checks the bundle is self-consistent and that every
+// company it references exists. Returns the company name → ID map for apply.
+// Match values of requirement conditions are normalised in place.
+func validateConfigBundle(ctx context.Context, q querier, b *models.ConfigBundle) (map[string]string, map[string]string, error) {
+	errs := map[string]string{}
+
+	if b.Format != models.ConfigBundleFormat {
+		errs["format"] = "Not an admin configuration bundle (format must be " + models.ConfigBundleFormat + ")"
+	}
+	if b.SchemaVersion < 1 || b.SchemaVersion > models.ConfigBundleSchemaVersion {
+		errs["schemaVersion"] = fmt.Sprintf("Unsupported schema version %d (max %d)", b.SchemaVersion, models.ConfigBundleSchemaVersion)
+	}
+
+	docTypes := map[string]bool{}
+	for i := range b.DocumentTypes {
+		dt := &b.DocumentTypes[i]
+		if len(dt.DocType) < 2 || len(dt.DisplayName) < 2 {
+			errs["documentTypes"] = "Every document type needs a docType and displayName (min 2 characters)"
+			break
+		}
+		if dt.ValidityUnit == "" {
+			dt.ValidityUnit = "months"
+		}
+		if dt.ValidityPeriod < 0 || !models.ValidValidityUnit(dt.ValidityUnit) {
+			errs["documentTypes"] = "Document type " + dt.DocType + " has invalid validity"
+			break
+		}
+		if dt.AppliesTo == "" {
+			dt.AppliesTo = "employee"
+		}
+		if dt.AppliesTo != "employee" && dt.AppliesTo != "dependent" {
+			errs["documentTypes"] = "Document type " + dt.DocType + " must apply to employee or dependent"
+			break
+		}
+		if docTypes[dt.DocType] {
+			errs["documentTypes"] = "Duplicate document type " + dt.DocType
+			break
+		}
+		docTypes[dt.DocType] = true
+	}
+	if len(b.DocumentTypes) == 0 {
+		errs["documentTypes"] = "Bundle contains no document types"
+	}
+
+	companyNames := map[string]bool{}
+	unknownDocType := func(section string, types ...string) bool {
+		for _, t := range types {
+			if !docTypes[t] {
+				errs[section] = "Unknown document type " + t
+				return true
+			}
+		}
+		return false
+	}
+
+	seen := map[string]bool{}
+	for i := range b.ComplianceRules {
+		cr := &b.ComplianceRules[i]
+		if unknownDocType("complianceRules", cr.DocType) {
+			break
+		}
+		if cr.GracePeriodUnit == "" {
+			cr.GracePeriodUnit = "calendar"
+		}
+		if !models.ValidGraceUnit(cr.GracePeriodUnit) {
+			errs["complianceRules"] = "Rule " + cr.DocType + " has invalid gracePeriodUnit"
+			break
+		}
+		if cr.FineType != "daily" && cr.FineType != "monthly" && cr.FineType != "one_time" {
+			errs["complianceRules"] = "Rule " + cr.DocType + " has invalid fineType"
+			break
+		}
+		if cr.GracePeriodDays < 0 || cr.FinePerDay < 0 || cr.FineCap < 0 {
+			errs["complianceRules"] = "Rule " + cr.DocType + " has negative values"
+			break
+		}
+		if cr.ValidityPeriod != nil && *cr.ValidityPeriod <= 0 {
+			cr.ValidityPeriod, cr.ValidityUnit = nil, nil
+		}
+		if cr.ValidityPeriod != nil && cr.ValidityUnit == nil {
+			unit := "months"
+			cr.ValidityUnit = &unit
+		}
+		if cr.ValidityPeriod == nil {
+			cr.ValidityUnit = nil
+		} else if !models.ValidValidityUnit(*cr.ValidityUnit) {
+			errs["complianceRules"] = "Rule " + cr.DocType + " has invalid validityUnit"
+			break
+		}
+		key := ruleBundleKey(*cr)
+		if seen[key] {
+			errs["complianceRules"] = "Duplicate rule " + key
+			break
+		}
+		seen[key] = true
+		if cr.Company != "" {
+			companyNames[cr.Company] = true
+		}
+	}
+
+	seen = map[string]bool{}
+	for i := range b.Dependencies {
+		d := &b.Dependencies[i]
+		d.RegulatoryAuthority = models.NormalizeAuthorityCode(d.RegulatoryAuthority)
+		if unknownDocType("dependencies", d.BlockingDocType, d.BlockedDocType) {
+			break
+		}
+		if d.BlockingDocType == d.BlockedDocType || strings.TrimSpace(d.Description) == "" {
+			errs["dependencies"] = "Dependency " + dependencyBundleKey(*d) + " is invalid"
+			break
+		}
+		if d.Company != "" && d.RegulatoryAuthority != "" {
+			errs["dependencies"] = "Dependency " + dependencyBundleKey(*d) + " has both a company and an authority"
+			break
+		}
+		if d.MinValidityDays < 0 || d.MinValidityDays > 3650 {
+			errs["dependencies"] = "minValidityDays must be between 0 and 3650"
+			break
+		}
+		key := dependencyBundleKey(*d)
+		if seen[key] {
+			errs["dependencies"] = "Duplicate dependency " + key
+			break
+		}
+		seen[key] = true
+		if d.Company != "" {
+			companyNames[d.Company] = true
+		}
+	}
+
+	seen = map[string]bool{}
+	for i := range b.RequirementConditions {
+		rc := &b.RequirementConditions[i]
+		req := models.RequirementConditionRequest{
+			DocType: rc.DocType, Effect: rc.Effect,
+			Nationalities: rc.Nationalities, Trades: rc.Trades,
+			Genders: rc.Genders, Statuses: rc.Statuses,
+		}
+		if e := req.Validate(); len(e) > 0 {
+			errs["requirementConditions"] = "Invalid condition for " + rc.DocType
+			break
+		}
+		rc.Nationalities, rc.Trades, rc.Genders, rc.Statuses = req.Nationalities, req.Trades, req.Genders, req.Statuses
+		if unknownDocType("requirementConditions", rc.DocType) {
+			break
+		}
+		key := conditionBundleKey(*rc)
+		if seen[key] {
+			errs["requirementConditions"] = "Duplicate condition " + key
+			break
+		}
+		seen[key] = true
+		if rc.Company != "" {
+			companyNames[rc.Company] = true
+		}
+	}
+
+	seen = map[string]bool{}
+	for i := range b.AuthorityProfiles {
+		p := &b.AuthorityProfiles[i]
+		req := models.AuthorityProfileRequest{
+			Code: p.Code, Name: p.Name, Description: p.Description,
+			Rules: p.Rules, Dependencies: p.Dependencies,
+		}
+		if e := req.Validate(true); len(e) > 0 {
+			errs["authorityProfiles"] = "Invalid profile " + p.Code
+			break
+		}
+		p.Code, p.Name = req.Code, req.Name
+		if seen[p.Code] {
+			errs["authorityProfiles"] = "Duplicate profile " + p.Code
+			break
+		}
+		seen[p.Code] = true
+		for _, rule := range p.Rules {
+			if unknownDocType("authorityProfiles", rule.DocType) {
+				break
+			}
+		}
+		if p.Dependencies == nil {
+			p.Dependencies = []models.AuthorityProfileDependency{}
+		}
+	}
+
+	seen = map[string]bool{}
+	for i := range b.DocumentLinks {
+		l := &b.DocumentLinks[i]
+		isActive := l.IsActive
+		req := models.DocumentLinkRequest{
+			SourceDocType: l.SourceDocType, TargetDocType: l.TargetDocType, Mode: l.Mode,
+			OffsetDays: l.OffsetDays, ValidityMonths: l.ValidityMonths, ValidityDays: l.ValidityDays,
+			Action: l.Action, IsActive: &isActive, Description: l.Description,
+		}
+		if e := req.Validate(); len(e) > 0 {
+			errs["documentLinks"] = "Invalid link " + documentLinkBundleKey(*l)
+			break
+		}
+		l.SourceDocType, l.TargetDocType, l.Mode = req.SourceDocType, req.TargetDocType, req.Mode
+		l.Action, l.Description = req.Action, req.Description
+		if unknownDocType("documentLinks", l.SourceDocType, l.TargetDocType) {
+			break
+		}
+		key := documentLinkBundleKey(*l)
+		if seen[key] {
+			errs["documentLinks"] = "Duplicate link " + key
+			break
+		}
+		seen[key] = true
+		if l.Company != "" {
+			companyNames[l.Company] = true
+		}
+	}
+
+	seen = map[string]bool{}
+	for i := range b.PublicHolidays {
+		ph := &b.PublicHolidays[i]
+		req := models.PublicHolidayRequest{Date: ph.Date, Name: ph.Name}
+		if e := req.Validate(); len(e) > 0 {
+			errs["publicHolidays"] = "Invalid holiday " + holidayBundleKey(*ph)
+			break
+		}
+		ph.Name = req.Name
+		key := holidayBundleKey(*ph)
+		if seen[key] {
+			errs["publicHolidays"] = "Duplicate holiday " + key
+			break
+		}
+		seen[key] = true
+		if ph.Company != "" {
+			companyNames[ph.Company] = true
+		}
+	}
+
+	// Default calendars are dropped: export leaves them out too
+	seen = map[string]bool{}
+	calendars := []models.BundleCompanyCalendar{}
+	for _, cal := range b.CompanyCalendars {
+		if cal.Timezone == "" {
+			cal.Timezone = compliance.DefaultTimezone
+		}
+		if cal.WeekendDays == nil {
+			cal.WeekendDays = []int{}
+		}
+		sort.Ints(cal.WeekendDays)
+		if msg := (&createCompanyRequest{Timezone: cal.Timezone, WeekendDays: cal.WeekendDays}).validateCalendar(); msg != "" {
+			errs["companyCalendars"] = cal.Company + ": " + msg
+			break
+		}
+		if cal.Company == "" || seen[cal.Company] {
+			errs["companyCalendars"] = "Every calendar needs a distinct company"
+			break
+		}
+		seen[cal.Company] = true
+		companyNames[cal.Company] = true
+		if cal.Timezone != compliance.DefaultTimezone || fmt.Sprint(cal.WeekendDays) != fmt.Sprint(defaultWeekendDays) {
+			calendars = append(calendars, cal)
+		}
+	}
+	b.CompanyCalendars = calendars
+
+	// Cost lines of one scope, document type and component may not overlap
+	seen = map[string]bool{}
+	periods := map[string][][2]string{}
+	for i := range b.RenewalCosts {
+		rc := &b.RenewalCosts[i]
+		req := models.RenewalCostRequest{
+			DocType: rc.DocType, Component: rc.Component, Amount: rc.Amount,
+			EffectiveFrom: rc.EffectiveFrom, EffectiveTo: rc.EffectiveTo, Description: rc.Description,
+		}
+		if e := req.Validate(); len(e) > 0 {
+			errs["renewalCosts"] = "Invalid cost " + renewalCostBundleKey(*rc)
+			break
+		}
+		rc.DocType, rc.Component, rc.EffectiveTo, rc.Description = req.DocType, req.Component, req.EffectiveTo, req.Description
+		if unknownDocType("renewalCosts", rc.DocType) {
+			break
+		}
+		key := renewalCostBundleKey(*rc)
+		if seen[key] {
+			errs["renewalCosts"] = "Duplicate cost " + key
+			break
+		}
+		seen[key] = true
+		to := "9999-12-31"
+		if rc.EffectiveTo != nil {
+			to = *rc.EffectiveTo
+		}
+		group := bundleScopeLabel(rc.Company, "") + "/" + rc.DocType + "/" + rc.Component
+		for _, p := range periods[group] {
+			if rc.EffectiveFrom <= p[1] && p[0] <= to {
+				errs["renewalCosts"] = "Cost " + key + " overlaps another period"
+			}
+		}
+		if errs["renewalCosts"] != "" {
+			break
+		}
+		periods[group] = append(periods[group], [2]string{rc.EffectiveFrom, to})
+		if rc.Company != "" {
+			companyNames[rc.Company] = true
+		}
+	}
+
+	if b.ScoreWeights != nil {
+		b.ScoreWeights.UpdatedAt = ""
+		if e := b.ScoreWeights.Validate(); len(e) > 0 {
+			errs["scoreWeights"] = "Invalid score weights"
+		}
+	}
+
+	seen = map[string]bool{}
+	for i := range b.ExitChecklistRules {
+		er := &b.ExitChecklistRules[i]
+		req := models.ExitChecklistRuleRequest{
+			Item: er.Item, DueDays: er.DueDays, FinePerDay: er.FinePerDay, FineType: er.FineType,
+			FineCap: er.FineCap, DocTypes: er.DocTypes, Description: er.Description,
+		}
+		if e := req.Validate(); len(e) > 0 {
+			errs["exitChecklistRules"] = "Invalid rule " + exitRuleBundleKey(*er)
+			break
+		}
+		er.Item, er.FineType, er.DocTypes, er.Description = req.Item, req.FineType, req.DocTypes, req.Description
+		if unknownDocType("exitChecklistRules", er.DocTypes...) {
+			break
+		}
+		key := exitRuleBundleKey(*er)
+		if seen[key] {
+			errs["exitChecklistRules"] = "Duplicate rule " + key
+			break
+		}
+		seen[key] = true
+		if er.Company != "" {
+			companyNames[er.Company] = true
+		}
+	}
+
+	seen = map[string]bool{}
+	for i := range b.CustomFields {
+		cf := &b.CustomFields[i]
+		sortOrder, isActive := cf.SortOrder, cf.IsActive
+		req := models.CustomFieldDefinitionRequest{
+			CompanyID: cf.Company, Key: cf.Key, Label: cf.Label, Type: cf.Type, Required: cf.Required,
+			Options: cf.Options, Pattern: cf.Pattern, MinValue: cf.MinValue, MaxValue: cf.MaxValue,
+			HelpText: cf.HelpText, SortOrder: &sortOrder, IsActive: &isActive,
+		}
+		if e := req.Validate(); len(e) > 0 {
+			errs["customFields"] = "Invalid custom field " + customFieldBundleKey(*cf)
+			break
+		}
+		cf.Key, cf.Label, cf.Type, cf.Options, cf.HelpText = req.Key, req.Label, req.Type, req.Options, req.HelpText
+		key := customFieldBundleKey(*cf)
+		if seen[key] {
+			errs["customFields"] = "Duplicate custom field " + key
+			break
+		}
+		seen[key] = true
+		companyNames[cf.Company] = true
+	}
+
+	seen = map[string]bool{}
+	for i := range b.LeaveTypes {
+		lt := &b.LeaveTypes[i]
+		lt.Code = strings.ToLower(strings.TrimSpace(lt.Code))
+		lt.Name = strings.TrimSpace(lt.Name)
+		if lt.Code == "" || len(lt.Code) > 20 || lt.Name == "" || len(lt.Name) > 100 {
+			errs["leaveTypes"] = "Every leave type needs a code (max 20 characters) and a name (max 100)"
+			break
+		}
+		if (lt.AnnualDays != nil && (*lt.AnnualDays < 0 || *lt.AnnualDays > 365)) || lt.MaxCarryOver < 0 || lt.MaxCarryOver > 365 {
+			errs["leaveTypes"] = "Leave type " + lt.Code + " has invalid day counts"
+			break
+		}
+		if seen[lt.Code] {
+			errs["leaveTypes"] = "Duplicate leave type " + lt.Code
+			break
+		}
+		seen[lt.Code] = true
+	}
+
+	seen = map[string]bool{}
+	for i := range b.EmiratisationTargets {
+		et := &b.EmiratisationTargets[i]
+		minWorkforce := et.MinWorkforce
+		req := models.EmiratisationTargetRequest{
+			CheckpointDate: et.CheckpointDate, TargetPercent: et.TargetPercent, MinHeadcount: et.MinHeadcount,
+			MinWorkforce: &minWorkforce, FinePerMissing: et.FinePerMissing, Description: et.Description,
+		}
+		if e := req.Validate(); len(e) > 0 {
+			errs["emiratisationTargets"] = "Invalid target " + emiratisationTargetBundleKey(*et)
+			break
+		}
+		et.Description = req.Description
+		key := emiratisationTargetBundleKey(*et)
+		if seen[key] {
+			errs["emiratisationTargets"] = "Duplicate target " + key
+			break
+		}
+		seen[key] = true
+		if et.Company != "" {
+			companyNames[et.Company] = true
+		}
+	}
+
+	companyIDs := map[string]string{}
+	if len(companyNames) > 0 {
+		names := make([]string, 0, len(companyNames))
+		for n := range companyNames {
+			names = append(names, n)
+		}
+		rows, err := q.Query(ctx, `SELECT name, id::text FROM companies WHERE name = ANY($1)`, names)
+		if err != nil {
+			return nil, nil, err
+		}
+		for rows.Next() {
+			var name, id string
+			if err := rows.Scan(&name, &id); err != nil {
+				rows.Close()
+				return nil, nil, err
+			}
+			companyIDs[name] = id
+		}
+		rows.Close()
+		sort.Strings(names)
+		for _, n := range names {
+			if _, ok := companyIDs[n]; !ok {
+				errs["companies"] = "Unknown company: " + n
+				break
+			}
+		}
+	}
+
+	return companyIDs, errs, nil
+}
+
+// ── Diff ──────────────────────────────────────────────────────
+
+func ruleBundleKey(r models.BundleComplianceRule) string {
+	return bundleScopeLabel(r.Company, "") + "/" + r.DocType
+}
+
+func dependencyBundleKey(d models.BundleDependency) string {
+	return bundleScopeLabel(d.Company, d.RegulatoryAuthority) + "/" + d.BlockingDocType + "→" + d.BlockedDocType
+}
+
+func conditionBundleKey(c models.BundleRequirementCondition) string {
+	list := func(v []string) string {
+		s := append([]string{}, v...)
+		sort.Strings(s)
+		return strings.Join(s, ",")
+	}
+	return fmt.Sprintf("%s/%s/%s[n=%s;t=%s;g=%s;s=%s]", bundleScopeLabel(c.Company, ""), c.DocType, c.Effect,
+		list(c.Nationalities), list(c.Trades), list(c.Genders), list(c.Statuses))
+}
+
+func documentLinkBundleKey(l models.BundleDocumentLink) string {
+	return bundleScopeLabel(l.Company, "") + "/" + l.SourceDocType + "→" + l.TargetDocType
+}
+
+func holidayBundleKey(h models.BundlePublicHoliday) string {
+	return bundleScopeLabel(h.Company, "") + "/" + h.Date
+}
+
+func calendarBundleKey(c models.BundleCompanyCalendar) string {
+	return c.Company
+}
+
+func renewalCostBundleKey(c models.BundleRenewalCost) string {
+	return bundleScopeLabel(c.Company, "") + "/" + c.DocType + "/" + c.Component + "@" + c.EffectiveFrom
+}
+
+func exitRuleBundleKey(r models.BundleExitChecklistRule) string {
+	return bundleScopeLabel(r.Company, "") + "/" + r.Item
+}
+
+func customFieldBundleKey(f models.BundleCustomField) string {
+	return bundleScopeLabel(f.Company, "") + "/" + f.Key
+}
+
+func leaveTypeBundleKey(t models.LeaveType) string {
+	return t.Code
+}
+
+func emiratisationTargetBundleKey(t models.BundleEmiratisationTarget) string {
+	return bundleScopeLabel(t.Company, "") + "/" + t.CheckpointDate
+}
+
+func bundleScopeLabel(company, authority string) string {
+	switch {
+	case company != "":
+		return "company:" + company
+	case authority != "":
+		return "authority:" + authority
+	default:
+		return "global"
+	}
+}
+
+// sameBundleEntry compares two entries by their serialised form (IDs are not serialised).
+func sameBundleEntry(a, b interface{}) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return bytes.Equal(ja, jb)
+}
+
+// diffBundleSection lists the creates, updates and deletes turning the
+// current entries of a section into the incoming ones, matched by key.
+// Update changes carry the current entry (with its ID) in Before.
+func diffBundleSection[T any](section string, current, incoming []T, key func(T) string) []models.ConfigChange {
+	changes := []models.ConfigChange{}
+	cur := map[string]T{}
+	for _, e := range current {
+		cur[key(e)] = e
+	}
+	in := map[string]bool{}
+	for _, e := range incoming {
+		k := key(e)
+		in[k] = true
+		if c, ok := cur[k]; !ok {
+			changes = append(changes, models.ConfigChange{Section: section, Key: k, Action: "create", After: e})
+		} else if !sameBundleEntry(c, e) {
+			changes = append(changes, models.ConfigChange{Section: section, Key: k, Action: "update", Before: c, After: e})
+		}
+	}
+	for _, c := range current {
+		if k := key(c); !in[k] {
+			changes = append(changes, models.ConfigChange{Section: section, Key: k, Action: "delete", Before: c})
+		}
+	}
+	return changes
+}
+
+// configUsage lists the settings still referenced by data, which import
+// retains instead of deleting.
+type configUsage struct {
+	docTypes     map[string]bool // doc types referenced by documents
+	authorities  map[string]bool // authority codes set on companies
+	customFields map[string]bool // "<company name>/<key>" with employee values
+	leaveTypes   map[string]bool // leave type codes referenced by leave requests
+}
+
+// diffConfigBundle lists the changes needed to turn current into incoming.
+func diffConfigBundle(current, incoming *models.ConfigBundle, usage configUsage) []models.ConfigChange {
+	changes := []models.ConfigChange{}
+
+	// Document types — removal means deactivation, and is skipped for system or in-use types
+	curTypes := map[string]models.BundleDocumentType{}
+	for _, dt := range current.DocumentTypes {
+		curTypes[dt.DocType] = dt
+	}
+	inTypes := map[string]bool{}
+	for _, dt := range incoming.DocumentTypes {
+		inTypes[dt.DocType] = true
+		cur, ok := curTypes[dt.DocType]
+		if !ok {
+			changes = append(changes, models.ConfigChange{Section: "documentTypes", Key: dt.DocType, Action: "create", After: dt})
+			continue
+		}
+		dt.IsSystem = cur.IsSystem
+		if incoming.SchemaVersion < 2 { // bundle predates standard validity
+			dt.ValidityPeriod, dt.ValidityUnit = cur.ValidityPeriod, cur.ValidityUnit
+		}
+		if incoming.SchemaVersion < 3 { // bundle predates dependent document types
+			dt.AppliesTo = cur.AppliesTo
+		}
+		if cur.IsSystem {
+			dt.MetadataFields = cur.MetadataFields // system metadata fields are read-only
+		}
+		if !sameBundleEntry(cur, dt) {
+			changes = append(changes, models.ConfigChange{Section: "documentTypes", Key: dt.DocType, Action: "update", Before: cur, After: dt})
+		}
+	}
+	for _, cur := range current.DocumentTypes {
+		if inTypes[cur.DocType] || !cur.IsActive {
+			continue
+		}
+		switch {
+		case cur.IsSystem:
+			changes = append(changes, models.ConfigChange{Section: "documentTypes", Key: cur.DocType, Action: "retain", Reason: "system document type", Before: cur})
+		case usage.docTypes[cur.DocType]:
+			changes = append(changes, models.ConfigChange{Section: "documentTypes", Key: cur.DocType, Action: "retain", Reason: "in use by existing documents", Before: cur})
+		default:
+			changes = append(changes, models.ConfigChange{Section: "documentTypes", Key: cur.DocType, Action: "delete", Before: cur})
+		}
+	}
+
+	// Compliance rules
+	curRules := map[string]models.BundleComplianceRule{}
+	for _, cr := range current.ComplianceRules {
+		curRules[ruleBundleKey(cr)] = cr
+	}
+	inRules := map[string]bool{}
+	for _, cr := range incoming.ComplianceRules {
+		key := ruleBundleKey(cr)
+		inRules[key] = true
+		cur, ok := curRules[key]
+		if ok && incoming.SchemaVersion < 2 {
+			cr.ValidityPeriod, cr.ValidityUnit = cur.ValidityPeriod, cur.ValidityUnit
+		}
+		if !ok {
+			changes = append(changes, models.ConfigChange{Section: "complianceRules", Key: key, Action: "create", After: cr})
+		} else if !sameBundleEntry(cur, cr) {
+			cr.ID = cur.ID
+			changes = append(changes, models.ConfigChange{Section: "complianceRules", Key: key, Action: "update", Before: cur, After: cr})
+		}
+	}
+	for _, cur := range current.ComplianceRules {
+		if key := ruleBundleKey(cur); !inRules[key] {
+			changes = append(changes, models.ConfigChange{Section: "complianceRules", Key: key, Action: "delete", Before: cur})
+		}
+	}
+
+	// Dependencies
+	curDeps := map[string]models.BundleDependency{}
+	for _, d := range current.Dependencies {
+		curDeps[dependencyBundleKey(d)] = d
+	}
+	inDeps := map[string]bool{}
+	for _, d := range incoming.Dependencies {
+		key := dependencyBundleKey(d)
+		inDeps[key] = true
+		if cur, ok := curDeps[key]; !ok {
+			changes = append(changes, models.ConfigChange{Section: "dependencies", Key: key, Action: "create", After: d})
+		} else if !sameBundleEntry(cur, d) {
+			d.ID = cur.ID
+			changes = append(changes, models.ConfigChange{Section: "dependencies", Key: key, Action: "update", Before: cur, After: d})
+		}
+	}
+	for _, cur := range current.Dependencies {
+		if key := dependencyBundleKey(cur); !inDeps[key] {
+			changes = append(changes, models.ConfigChange{Section: "dependencies", Key: key, Action: "delete", Before: cur})
+		}
+	}
+
+	// Requirement conditions — the match lists are part of the key, so only the description can change
+	curConds := map[string]models.BundleRequirementCondition{}
+	for _, c := range current.RequirementConditions {
+		curConds[conditionBundleKey(c)] = c
+	}
+	inConds := map[string]bool{}
+	for _, c := range incoming.RequirementConditions {
+		key := conditionBundleKey(c)
+		inConds[key] = true
+		if cur, ok := curConds[key]; !ok {
+			changes = append(changes, models.ConfigChange{Section: "requirementConditions", Key: key, Action: "create", After: c})
+		} else if cur.Description != c.Description {
+			c.ID = cur.ID
+			changes = append(changes, models.ConfigChange{Section: "requirementConditions", Key: key, Action: "update", Before: cur, After: c})
+		}
+	}
+	for _, cur := range current.RequirementConditions {
+		if key := conditionBundleKey(cur); !inConds[key] {
+			changes = append(changes, models.ConfigChange{Section: "requirementConditions", Key: key, Action: "delete", Before: cur})
+		}
+	}
+
+	// Authority profiles — profiles referenced by companies are kept
+	curProfiles := map[string]models.BundleAuthorityProfile{}
+	for _, p := range current.AuthorityProfiles {
+		curProfiles[p.Code] = p
+	}
+	inProfiles := map[string]bool{}
+	for _, p := range incoming.AuthorityProfiles {
+		inProfiles[p.Code] = true
+		if cur, ok := curProfiles[p.Code]; !ok {
+			changes = append(changes, models.ConfigChange{Section: "authorityProfiles", Key: p.Code, Action: "create", After: p})
+		} else if !sameBundleEntry(cur, p) {
+			changes = append(changes, models.ConfigChange{Section: "authorityProfiles", Key: p.Code, Action: "update", Before: cur, After: p})
+		}
+	}
+	for _, cur := range current.AuthorityProfiles {
+		if inProfiles[cur.Code] {
+			continue
+		}
+		if usage.authorities[cur.Code] {
+			changes = append(changes, models.ConfigChange{Section: "authorityProfiles", Key: cur.Code, Action: "retain", Reason: "used by companies", Before: cur})
+		} else {
+			changes = append(changes, models.ConfigChange{Section: "authorityProfiles", Key: cur.Code, Action: "delete", Before: cur})
+		}
+	}
+
+	// Sections below are left untouched by bundles that predate them
+	if incoming.SchemaVersion >= 4 {
+		changes = append(changes, diffBundleSection("documentLinks", current.DocumentLinks, incoming.DocumentLinks, documentLinkBundleKey)...)
+	}
+
+	if incoming.SchemaVersion >= 5 {
+		changes = append(changes, diffBundleSection("publicHolidays", current.PublicHolidays, incoming.PublicHolidays, holidayBundleKey)...)
+		changes = append(changes, diffBundleSection("companyCalendars", current.CompanyCalendars, incoming.CompanyCalendars, calendarBundleKey)...)
+	}
+
+	if incoming.SchemaVersion >= 6 {
+		changes = append(changes, diffBundleSection("renewalCosts", current.RenewalCosts, incoming.RenewalCosts, renewalCostBundleKey)...)
+	}
+
+	if incoming.SchemaVersion >= 7 && incoming.ScoreWeights != nil && !sameBundleEntry(current.ScoreWeights, incoming.ScoreWeights) {
+		changes = append(changes, models.ConfigChange{Section: "scoreWeights", Key: "global", Action: "update",
+			Before: *current.ScoreWeights, After: *incoming.ScoreWeights})
+	}
+
+	if incoming.SchemaVersion >= 8 {
+		// Global exit rules can be replaced but not deleted (as in DeleteExitChecklistRule)
+		for _, c := range diffBundleSection("exitChecklistRules", current.ExitChecklistRules, incoming.ExitChecklistRules, exitRuleBundleKey) {
+			if c.Action == "delete" && c.Before.(models.BundleExitChecklistRule).Company == "" {
+				c.Action, c.Reason = "retain", "global exit checklist rule"
+			}
+			changes = append(changes, c)
+		}
+	}
+
+	if incoming.SchemaVersion >= 9 {
+		// Custom fields with employee values are kept; deactivate them instead
+		for _, c := range diffBundleSection("customFields", current.CustomFields, incoming.CustomFields, customFieldBundleKey) {
+			if f, ok := c.Before.(models.BundleCustomField); ok && c.Action == "delete" && usage.customFields[f.Company+"/"+f.Key] {
+				c.Action, c.Reason = "retain", "employees have values for this field"
+			}
+			changes = append(changes, c)
+		}
+	}
+
+	if incoming.SchemaVersion >= 10 {
+		// Leave types with leave requests are kept
+		for _, c := range diffBundleSection("leaveTypes", current.LeaveTypes, incoming.LeaveTypes, leaveTypeBundleKey) {
+			if c.Action == "delete" && usage.leaveTypes[c.Key] {
+				c.Action, c.Reason = "retain", "in use by leave requests"
+			}
+			changes = append(changes, c)
+		}
+	}
+
+	if incoming.SchemaVersion >= 11 {
+		changes = append(changes, diffBundleSection("emiratisationTargets", current.EmiratisationTargets, incoming.EmiratisationTargets, emiratisationTargetBundleKey)...)
+	}
+
+	return changes
+}
+
+// ── Apply ─────────────────────────────────────────────────────
+
+// applyConfigChanges executes a diff inside the caller's transaction.
+func applyConfigChanges(ctx context.Context, q querier, changes []models.ConfigChange, companyIDs map[string]string, userID string) error {
+	companyID := func(name string) interface{} {
+		if name == "" {
+			return nil
+		}
+		return companyIDs[name]
+	}
+	nilIfBlank := func(s string) interface{} {
+		if s == "" {
+			return nil
+		}
+		return s
+	}
+
+	for _, c := range changes {
+		var err error
+		switch c.Section + ":" + c.Action {
+		case "documentTypes:create", "documentTypes:update":
+			dt := c.After.(models.BundleDocumentType)
+			metadata, _ := json.Marshal(dt.MetadataFields)
+			if string(metadata) == "null" {
+				metadata = []byte("[]")
+			}
+			_, err = q.Exec(ctx, `
+				INSERT INTO document_types (doc_type, display_name, is_mandatory, has_expiry,
+				    number_label, number_placeholder, expiry_label, sort_order, metadata_fields,
+				    is_system, is_active,
+				    show_document_number, require_document_number,
+				    show_issue_date, require_issue_date,
+				    show_expiry_date, require_expiry_date,
+				    show_file, require_file, validity_period, validity_unit, applies_to)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, FALSE, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+				ON CONFLICT (doc_type) DO UPDATE SET
+				    display_name = EXCLUDED.display_name,
+				    is_mandatory = EXCLUDED.is_mandatory,
+				    has_expiry = EXCLUDED.has_expiry,
+				    number_label = EXCLUDED.number_label,
+				    number_placeholder = EXCLUDED.number_placeholder,
+				    expiry_label = EXCLUDED.expiry_label,
+				    sort_order = EXCLUDED.sort_order,
+				    metadata_fields = CASE WHEN document_types.is_system THEN document_types.metadata_fields
+				                           ELSE EXCLUDED.metadata_fields END,
+				    is_active = EXCLUDED.is_active,
+				    show_document_number = EXCLUDED.show_document_number,
+				    require_document_number = EXCLUDED.require_document_number,
+				    show_issue_date = EXCLUDED.show_issue_date,
+				    require_issue_date = EXCLUDED.require_issue_date,
+				    show_expiry_date = EXCLUDED.show_expiry_date,
+				    require_expiry_date = EXCLUDED.require_expiry_date,
+				    show_file = EXCLUDED.show_file,
+				    require_file = EXCLUDED.require_file,
+				    validity_period = EXCLUDED.validity_period,
+				    validity_unit = EXCLUDED.validity_unit,
+				    applies_to = EXCLUDED.applies_to,
+				    updated_at = NOW()
+			`, dt.DocType, dt.DisplayName, dt.IsMandatory, dt.HasExpiry,
+				dt.NumberLabel, dt.NumberPlaceholder, dt.ExpiryLabel, dt.SortOrder, string(metadata),
+				dt.IsActive, dt.ShowDocumentNumber, dt.RequireDocumentNumber,
+				dt.ShowIssueDate, dt.RequireIssueDate, dt.ShowExpiryDate, dt.RequireExpiryDate,
+				dt.ShowFile, dt.RequireFile, dt.ValidityPeriod, dt.ValidityUnit, dt.AppliesTo)
+		case "documentTypes:delete":
+			// Soft delete, as DeleteDocumentType does; re-checked against documents inside the transaction
+			dt := c.Before.(models.BundleDocumentType)
+			_, err = q.Exec(ctx, `
+				UPDATE document_types SET is_active = FALSE, updated_at = NOW()
+				WHERE doc_type = $1 AND is_system = FALSE
+				  AND NOT EXISTS (SELECT 1 FROM documents WHERE document_type = $1)
+			`, dt.DocType)
+
+		case "complianceRules:create":
+			cr := c.After.(models.BundleComplianceRule)
+			_, err = q.Exec(ctx, `
+				INSERT INTO compliance_rules (company_id, doc_type, grace_period_days, grace_period_unit, fine_per_day, fine_type, fine_cap, is_mandatory,
+				                              validity_period, validity_unit)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			`, companyID(cr.Company), cr.DocType, cr.GracePeriodDays, cr.GracePeriodUnit,
+				cr.FinePerDay, cr.FineType, cr.FineCap, cr.IsMandatory, cr.ValidityPeriod, cr.ValidityUnit)
+		case "complianceRules:update":
+			cr := c.After.(models.BundleComplianceRule)
+			_, err = q.Exec(ctx, `
+				UPDATE compliance_rules SET grace_period_days = $1, grace_period_unit = $2, fine_per_day = $3,
+				       fine_type = $4, fine_cap = $5, is_mandatory = $6,
+				       validity_period = $8, validity_unit = $9, updated_at = NOW()
+				WHERE id = $7
+			`, cr.GracePeriodDays, cr.GracePeriodUnit, cr.FinePerDay, cr.FineType, cr.FineCap, cr.IsMandatory, cr.ID,
+				cr.ValidityPeriod, cr.ValidityUnit)
+		case "complianceRules:delete":
+			_, err = q.Exec(ctx, `DELETE FROM compliance_rules WHERE id = $1`, c.Before.(models.BundleComplianceRule).ID)
+
+		case "dependencies:create":
+			d := c.After.(models.BundleDependency)
+			_, err = q.Exec(ctx, `
+				INSERT INTO document_dependencies
+					(blocking_doc_type, blocked_doc_type, description, min_validity_days, company_id, regulatory_authority)
+				VALUES ($1, $2, $3, $4, $5, $6)
+			`, d.BlockingDocType, d.BlockedDocType, d.Description, d.MinValidityDays,
+				companyID(d.Company), nilIfBlank(d.RegulatoryAuthority))
+		case "dependencies:update":
+			d := c.After.(models.BundleDependency)
+			_, err = q.Exec(ctx, `
+				UPDATE document_dependencies SET description = $1, min_validity_days = $2 WHERE id = $3
+			`, d.Description, d.MinValidityDays, d.ID)
+		case "dependencies:delete":
+			_, err = q.Exec(ctx, `DELETE FROM document_dependencies WHERE id = $1`, c.Before.(models.BundleDependency).ID)
+
+		case "requirementConditions:create":
+			rc := c.After.(models.BundleRequirementCondition)
+			_, err = q.Exec(ctx, `
+				INSERT INTO document_requirement_conditions
+					(company_id, doc_type, effect, nationalities, trades, genders, statuses, description)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			`, companyID(rc.Company), rc.DocType, rc.Effect,
+				rc.Nationalities, rc.Trades, rc.Genders, rc.Statuses, rc.Description)
+		case "requirementConditions:update":
+			rc := c.After.(models.BundleRequirementCondition)
+			_, err = q.Exec(ctx, `
+				UPDATE document_requirement_conditions SET description = $1, updated_at = NOW() WHERE id = $2
+			`, rc.Description, rc.ID)
+		case "requirementConditions:delete":
+			_, err = q.Exec(ctx, `DELETE FROM document_requirement_conditions WHERE id = $1`,
+				c.Before.(models.BundleRequirementCondition).ID)
+
+		case "authorityProfiles:create", "authorityProfiles:update":
+			// Profile dependencies arrive as authority-scoped rows in the dependencies section
+			p := c.After.(models.BundleAuthorityProfile)
+			rules, _ := json.Marshal(p.Rules)
+			deps, _ := json.Marshal(p.Dependencies)
+			_, err = q.Exec(ctx, `
+				WITH saved AS (
+					INSERT INTO authority_profiles (code, name, description, rules, dependencies)
+					VALUES ($1, $2, $3, $4, $5)
+					ON CONFLICT (code) DO UPDATE SET
+						name = EXCLUDED.name, description = EXCLUDED.description,
+						rules = EXCLUDED.rules, dependencies = EXCLUDED.dependencies,
+						version = authority_profiles.version + 1, updated_at = NOW()
+					RETURNING id, version, name, description, rules, dependencies
+				)
+				INSERT INTO authority_profile_versions (profile_id, version, name, description, rules, dependencies, changed_by)
+				SELECT id, version, name, description, rules, dependencies, $6 FROM saved
+			`, p.Code, p.Name, p.Description, string(rules), string(deps), nilIfBlank(userID))
+		case "authorityProfiles:delete":
+			_, err = q.Exec(ctx, `DELETE FROM authority_profiles WHERE code = $1`, c.Before.(models.BundleAuthorityProfile).Code)
+
+		case "documentLinks:create":
+			l := c.After.(models.BundleDocumentLink)
+			_, err = q.Exec(ctx, `
+				INSERT INTO document_links (company_id, source_doc_type, target_doc_type, mode,
+				    offset_days, validity_months, validity_days, action, is_active, description)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			`, companyID(l.Company), l.SourceDocType, l.TargetDocType, l.Mode,
+				l.OffsetDays, l.ValidityMonths, l.ValidityDays, l.Action, l.IsActive, l.Description)
+		case "documentLinks:update":
+			l := c.After.(models.BundleDocumentLink)
+			_, err = q.Exec(ctx, `
+				UPDATE document_links SET mode = $1, offset_days = $2, validity_months = $3, validity_days = $4,
+				       action = $5, is_active = $6, description = $7, updated_at = NOW()
+				WHERE id = $8
+			`, l.Mode, l.OffsetDays, l.ValidityMonths, l.ValidityDays, l.Action, l.IsActive, l.Description,
+				c.Before.(models.BundleDocumentLink).ID)
+		case "documentLinks:delete":
+			_, err = q.Exec(ctx, `DELETE FROM document_links WHERE id = $1`, c.Before.(models.BundleDocumentLink).ID)
+
+		case "publicHolidays:create":
+			ph := c.After.(models.BundlePublicHoliday)
+			_, err = q.Exec(ctx, `INSERT INTO public_holidays (company_id, holiday_date, name) VALUES ($1, $2, $3)`,
+				companyID(ph.Company), ph.Date, ph.Name)
+		case "publicHolidays:update":
+			_, err = q.Exec(ctx, `UPDATE public_holidays SET name = $1 WHERE id = $2`,
+				c.After.(models.BundlePublicHoliday).Name, c.Before.(models.BundlePublicHoliday).ID)
+		case "publicHolidays:delete":
+			_, err = q.Exec(ctx, `DELETE FROM public_holidays WHERE id = $1`, c.Before.(models.BundlePublicHoliday).ID)
+
+		case "companyCalendars:create", "companyCalendars:update":
+			// Companies missing from the target are rejected by validation
+			cal := c.After.(models.BundleCompanyCalendar)
+			_, err = q.Exec(ctx, `
+				UPDATE companies SET timezone = $1, weekend_days = $2::smallint[], updated_at = NOW() WHERE id = $3
+			`, cal.Timezone, cal.WeekendDays, companyID(cal.Company))
+		case "companyCalendars:delete":
+			_, err = q.Exec(ctx, `
+				UPDATE companies SET timezone = $1, weekend_days = $2::smallint[], updated_at = NOW() WHERE name = $3
+			`, compliance.DefaultTimezone, defaultWeekendDays, c.Before.(models.BundleCompanyCalendar).Company)
+
+		case "renewalCosts:create":
+			rc := c.After.(models.BundleRenewalCost)
+			_, err = q.Exec(ctx, `
+				INSERT INTO renewal_costs (company_id, doc_type, component, amount, effective_from, effective_to, description)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)
+			`, companyID(rc.Company), rc.DocType, rc.Component, rc.Amount, rc.EffectiveFrom, rc.EffectiveTo, rc.Description)
+		case "renewalCosts:update":
+			rc := c.After.(models.BundleRenewalCost)
+			_, err = q.Exec(ctx, `
+				UPDATE renewal_costs SET amount = $1, effective_to = $2, description = $3, updated_at = NOW() WHERE id = $4
+			`, rc.Amount, rc.EffectiveTo, rc.Description, c.Before.(models.BundleRenewalCost).ID)
+		case "renewalCosts:delete":
+			_, err = q.Exec(ctx, `DELETE FROM renewal_costs WHERE id = $1`, c.Before.(models.BundleRenewalCost).ID)
+
+		case "scoreWeights:update":
+			sw := c.After.(models.ScoreWeights)
+			_, err = q.Exec(ctx, `
+				INSERT INTO compliance_score_weights (
+					id, penalty_weight, grace_weight, expiring_weight,
+					incomplete_weight, fine_rate_weight, optional_weight, updated_at
+				) VALUES (TRUE, $1, $2, $3, $4, $5, $6, NOW())
+				ON CONFLICT (id) DO UPDATE SET
+					penalty_weight    = EXCLUDED.penalty_weight,
+					grace_weight      = EXCLUDED.grace_weight,
+					expiring_weight   = EXCLUDED.expiring_weight,
+					incomplete_weight = EXCLUDED.incomplete_weight,
+					fine_rate_weight  = EXCLUDED.fine_rate_weight,
+					optional_weight   = EXCLUDED.optional_weight,
+					updated_at        = NOW()
+			`, sw.Penalty, sw.Grace, sw.Expiring, sw.Incomplete, sw.FineRate, sw.Optional)
+
+		case "exitChecklistRules:create", "exitChecklistRules:update":
+			er := c.After.(models.BundleExitChecklistRule)
+			_, err = q.Exec(ctx, `
+				INSERT INTO exit_checklist_rules (company_id, item, due_days, fine_per_day, fine_type, fine_cap, doc_types, description)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+				ON CONFLICT (COALESCE(company_id, '00000000-0000-0000-0000-000000000000'::uuid), item) DO UPDATE SET
+					due_days     = EXCLUDED.due_days,
+					fine_per_day = EXCLUDED.fine_per_day,
+					fine_type    = EXCLUDED.fine_type,
+					fine_cap     = EXCLUDED.fine_cap,
+					doc_types    = EXCLUDED.doc_types,
+					description  = EXCLUDED.description,
+					updated_at   = NOW()
+			`, companyID(er.Company), er.Item, er.DueDays, er.FinePerDay, er.FineType, er.FineCap, er.DocTypes, er.Description)
+		case "exitChecklistRules:delete":
+			_, err = q.Exec(ctx, `DELETE FROM exit_checklist_rules WHERE id = $1 AND company_id IS NOT NULL`,
+				c.Before.(models.BundleExitChecklistRule).ID)
+
+		case "customFields:create":
+			cf := c.After.(models.BundleCustomField)
+			_, err = q.Exec(ctx, `
+				INSERT INTO custom_field_definitions (
+					company_id, field_key, label, field_type, required, options,
+					pattern, min_value, max_value, help_text, sort_order, is_active
+				)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			`, companyID(cf.Company), cf.Key, cf.Label, cf.Type, cf.Required, cf.Options,
+				cf.Pattern, cf.MinValue, cf.MaxValue, cf.HelpText, cf.SortOrder, cf.IsActive)
+		case "customFields:update":
+			cf := c.After.(models.BundleCustomField)
+			_, err = q.Exec(ctx, `
+				UPDATE custom_field_definitions SET
+					label = $1, field_type = $2, required = $3, options = $4,
+					pattern = $5, min_value = $6, max_value = $7, help_text = $8,
+					sort_order = $9, is_active = $10, updated_at = NOW()
+				WHERE id = $11
+			`, cf.Label, cf.Type, cf.Required, cf.Options, cf.Pattern, cf.MinValue, cf.MaxValue,
+				cf.HelpText, cf.SortOrder, cf.IsActive, c.Before.(models.BundleCustomField).ID)
+		case "customFields:delete":
+			// Re-checked against employee values inside the transaction
+			cf := c.Before.(models.BundleCustomField)
+			_, err = q.Exec(ctx, `
+				DELETE FROM custom_field_definitions f
+				WHERE f.id = $1
+				  AND NOT EXISTS (SELECT 1 FROM employees e WHERE e.company_id = f.company_id AND e.custom_fields ? f.field_key)
+			`, cf.ID)
+
+		case "leaveTypes:create", "leaveTypes:update":
+			lt := c.After.(models.LeaveType)
+			_, err = q.Exec(ctx, `
+				INSERT INTO leave_types (code, name, is_paid, accrues, annual_days, once_per_employment, max_carry_over, sort_order)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+				ON CONFLICT (code) DO UPDATE SET
+					name = EXCLUDED.name, is_paid = EXCLUDED.is_paid, accrues = EXCLUDED.accrues,
+					annual_days = EXCLUDED.annual_days, once_per_employment = EXCLUDED.once_per_employment,
+					max_carry_over = EXCLUDED.max_carry_over, sort_order = EXCLUDED.sort_order
+			`, lt.Code, lt.Name, lt.IsPaid, lt.Accrues, lt.AnnualDays, lt.OncePerEmployment, lt.MaxCarryOver, lt.SortOrder)
+		case "leaveTypes:delete":
+			_, err = q.Exec(ctx, `
+				DELETE FROM leave_types t
+				WHERE t.code = $1 AND NOT EXISTS (SELECT 1 FROM leave_requests r WHERE r.leave_type = t.code)
+			`, c.Before.(models.LeaveType).Code)
+
+		case "emiratisationTargets:create":
+			et := c.After.(models.BundleEmiratisationTarget)
+			_, err = q.Exec(ctx, `
+				INSERT INTO emiratisation_targets (company_id, checkpoint_date, target_percent,
+				    min_headcount, min_workforce, fine_per_missing, description)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)
+			`, companyID(et.Company), et.CheckpointDate, et.TargetPercent,
+				et.MinHeadcount, et.MinWorkforce, et.FinePerMissing, et.Description)
+		case "emiratisationTargets:update":
+			et := c.After.(models.BundleEmiratisationTarget)
+			_, err = q.Exec(ctx, `
+				UPDATE emiratisation_targets SET target_percent = $1, min_headcount = $2, min_workforce = $3,
+				       fine_per_missing = $4, description = $5, updated_at = NOW()
+				WHERE id = $6
+			`, et.TargetPercent, et.MinHeadcount, et.MinWorkforce, et.FinePerMissing, et.Description,
+				c.Before.(models.BundleEmiratisationTarget).ID)
+		case "emiratisationTargets:delete":
+			_, err = q.Exec(ctx, `DELETE FROM emiratisation_targets WHERE id = $1`, c.Before.(models.BundleEmiratisationTarget).ID)
+		}
+		if err != nil {
+			return fmt.Errorf("%s %s %s: %w", c.Section, c.Action, c.Key, err)
+		}
+	}
+	return nil
+}
+
+// ── Handlers ──────────────────────────────────────────────────
+
+// ExportConfig handles GET /api/admin/config/export?format=json|yaml
+// and downloads every admin setting as one bundle.
+func (h *AdminHandler) ExportConfig(w http.ResponseWriter, r *http.Request) {
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "yaml" {
+		JSONError(w, http.StatusBadRequest, "format must be json or yaml")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	bundle, err := loadConfigBundle(ctx, h.db.GetPool())
+	if err != nil {
+		log.Printf("Failed to export admin config: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to export configuration")
+		return
+	}
+
+	var body []byte
+	contentType := "application/json"
+	if format == "yaml" {
+		body, err = configBundleYAML(bundle)
+		contentType = "application/yaml"
+	} else {
+		body, err = json.MarshalIndent(bundle, "", "  ")
+	}
+	if err != nil {
+		log.Printf("Failed to encode admin config: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to export configuration")
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q",
+		fmt.Sprintf("admin-config-%s.%s", time.Now().Format("20060102"), format)))
+	w.Header().Set("X-Config-Checksum", bundle.Checksum)
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// ImportConfig handles POST /api/admin/config/import[?dryRun=true][&format=json|yaml].
+// The body is a bundle produced by ExportConfig. The diff is always applied
+// inside a transaction (so dependency cycles are caught); dry runs roll it back.
+func (h *AdminHandler) ImportConfig(w http.ResponseWriter, r *http.Request) {
+	dryRun := r.URL.Query().Get("dryRun") == "true"
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" && strings.Contains(r.Header.Get("Content-Type"), "yaml") {
+		format = "yaml"
+	}
+	if format != "" && format != "json" && format != "yaml" {
+		JSONError(w, http.StatusBadRequest, "format must be json or yaml")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxConfigBundleSize+1))
+	if err != nil || len(body) > maxConfigBundleSize {
+		JSONError(w, http.StatusBadRequest, "Bundle is missing or larger than 5 MB")
+		return
+	}
+	incoming, err := parseConfigBundle(body, format)
+	if err != nil {
+		JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	companyIDs, errs, err := validateConfigBundle(ctx, pool, incoming)
+	if err != nil {
+		log.Printf("Failed to validate config bundle: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to import configuration")
+		return
+	}
+	if len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := loadConfigBundle(ctx, tx)
+	if err != nil {
+		log.Printf("Failed to load current config: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to import configuration")
+		return
+	}
+
+	usage := configUsage{
+		docTypes: map[string]bool{}, authorities: map[string]bool{},
+		customFields: map[string]bool{}, leaveTypes: map[string]bool{},
+	}
+	rows, err := tx.Query(ctx, `
+		SELECT DISTINCT 'doc', document_type FROM documents
+		UNION
+		SELECT DISTINCT 'authority', upper(regulatory_authority) FROM companies WHERE regulatory_authority IS NOT NULL
+		UNION
+		SELECT DISTINCT 'field', c.name || '/' || k
+		FROM employees e
+		JOIN companies c ON c.id = e.company_id
+		CROSS JOIN LATERAL jsonb_object_keys(e.custom_fields) k
+		UNION
+		SELECT DISTINCT 'leave', leave_type FROM leave_requests
+	`)
+	if err != nil {
+		log.Printf("Failed to load config usage: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to import configuration")
+		return
+	}
+	for rows.Next() {
+		var kind, value string
+		if err := rows.Scan(&kind, &value); err == nil {
+			switch kind {
+			case "doc":
+				usage.docTypes[value] = true
+			case "authority":
+				usage.authorities[value] = true
+			case "field":
+				usage.customFields[value] = true
+			case "leave":
+				usage.leaveTypes[value] = true
+			}
+		}
+	}
+	rows.Close()
+
+	changes := diffConfigBundle(current, incoming, usage)
+
+	result := models.ConfigImportResult{
+		Checksum: configBundleChecksum(incoming),
+		Summary:  map[string]int{"create": 0, "update": 0, "delete": 0, "retain": 0},
+		Changes:  changes,
+	}
+	mandatoryChanged := false
+	for _, c := range changes {
+		result.Summary[c.Action]++
+		if c.Action != "retain" && mandatorySections[c.Section] {
+			mandatoryChanged = true
+		}
+	}
+
+	// Slots required before the import; only these may be removed afterwards
+	var previousSlots map[string][]string
+	if mandatoryChanged && !dryRun {
+		if previousSlots, err = requiredSlots(ctx, tx, "TRUE"); err != nil {
+			log.Printf("Failed to load document slots: %v", err)
+			JSONError(w, http.StatusInternalServerError, "Failed to import configuration")
+			return
+		}
+	}
+
+	if err := applyConfigChanges(ctx, tx, changes, companyIDs, userID); err != nil {
+		log.Printf("Failed to apply config bundle: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to import configuration")
+		return
+	}
+
+	cycle, scopeLabel, err := findDependencyCycle(ctx, tx)
+	if err != nil {
+		log.Printf("Error checking dependency cycle: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to import configuration")
+		return
+	}
+	if cycle != nil {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error": fmt.Sprintf("Bundle dependencies contain a cycle (%s): %s", scopeLabel, strings.Join(cycle, " → ")),
+			"cycle": cycle,
+		})
+		return
+	}
+
+	if dryRun {
+		JSON(w, http.StatusOK, map[string]interface{}{"data": result})
+		return
+	}
+
+	// Mandatory-ness may have changed — bring every company's slots in line
+	if mandatoryChanged {
+		if _, err := reconcileCompanySlots(ctx, tx, "", previousSlots); err != nil {
+			log.Printf("Failed to reconcile document slots: %v", err)
+			JSONError(w, http.StatusInternalServerError, "Failed to import configuration")
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to import configuration")
+		return
+	}
+	result.Applied = true
+
+	go logActivity(pool, userID, "imported", "admin_config", result.Checksum, map[string]interface{}{
+		"summary": result.Summary,
+	})
+	refreshStatusAsync(pool, "")
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"data":    result,
+		"message": "Configuration imported",
+	})
+}
@@ -0,0 +1,368 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"manpower-backend/internal/ctxkeys"
+	"manpower-backend/internal/database"
+	"manpower-backend/internal/models"
+)
+
+// ContractHandler handles employees' labour contracts.
+type ContractHandler struct {
+	db database.Service
+}
+
+// NewContractHandler creates a new ContractHandler.
+func NewContractHandler(db database.Service) *ContractHandler {
+	return &ContractHandler{db: db}
+}
+
+// ── Labour Contracts ─────────────────────────────────────────────
+// Each employee has at most one current contract (migration 032). Adding a
+// contract supersedes the current one, so earlier contracts stay as history;
+// deleting the current contract restores the one it superseded. The current
+// contract's end date and probation end appear in the dashboard expiry
+// alerts and the daily notifier while it belongs to the employee's company.
+
+const contractCols = `ec.id, ec.employee_id, ec.company_id, ec.contract_type, ec.contract_number,
+	ec.start_date::text, ec.end_date::text, ec.probation_end_date::text, ec.notice_period_days,
+	ec.status, ec.superseded_by, ec.file_url, ec.file_name, ec.file_size, ec.file_type, ec.notes,
+	ec.created_by, ec.created_at::text, ec.updated_at::text, co.name,
+	ec.end_date - company_today(ec.company_id),
+	ec.probation_end_date - company_today(ec.company_id),
+	COALESCE(ec.status = 'current' AND ec.probation_end_date >= company_today(ec.company_id), FALSE)`
+
+const contractFrom = `
+	FROM employee_contracts ec
+	JOIN companies co ON co.id = ec.company_id`
+
+// scanContract scans contractCols.
+func scanContract(row pgx.Row, c *models.Contract) error {
+	return row.Scan(
+		&c.ID, &c.EmployeeID, &c.CompanyID, &c.ContractType, &c.ContractNumber,
+		&c.StartDate, &c.EndDate, &c.ProbationEndDate, &c.NoticePeriodDays,
+		&c.Status, &c.SupersededBy, &c.FileURL, &c.FileName, &c.FileSize, &c.FileType, &c.Notes,
+		&c.CreatedBy, &c.CreatedAt, &c.UpdatedAt, &c.CompanyName,
+		&c.DaysUntilEnd, &c.DaysUntilProbation, &c.OnProbation,
+	)
+}
+
+func loadContract(ctx context.Context, q querier, id string) (models.Contract, error) {
+	var c models.Contract
+	err := scanContract(q.QueryRow(ctx, `SELECT `+contractCols+contractFrom+` WHERE ec.id = $1`, id), &c)
+	return c, err
+}
+
+// ListByEmployee handles GET /api/employees/{id}/contracts
+// The current contract comes first, then the history newest first.
+func (h *ContractHandler) ListByEmployee(w http.ResponseWriter, r *http.Request) {
+	employeeID := chi.URLParam(r, "id")
+	if !checkEmployeeAccess(r.Context(), h.db.GetPool(), employeeID) {
+		JSONError(w, http.StatusForbidden, "Access denied to this employee")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	rows, err := h.db.GetPool().Query(ctx, `
+		SELECT `+contractCols+contractFrom+`
+		WHERE ec.employee_id = $1
+		ORDER BY ec.status = 'current' DESC, ec.start_date DESC, ec.created_at DESC
+	`, employeeID)
+	if err != nil {
+		log.Printf("Error fetching contracts: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch contracts")
+		return
+	}
+	defer rows.Close()
+
+	contracts := []models.Contract{}
+	for rows.Next() {
+		var c models.Contract
+		if err := scanContract(rows, &c); err != nil {
+			log.Printf("Error scanning contract: %v", err)
+			continue
+		}
+		contracts = append(contracts, c)
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{"data": contracts})
+}
+
+// Create handles POST /api/employees/{id}/contracts
+// The contract is made with the employee's current company and supersedes
+// the current contract, which it must start after.
+func (h *ContractHandler) Create(w http.ResponseWriter, r *http.Request) {
+	employeeID := chi.URLParam(r, "id")
+	if !checkEmployeeAccess(r.Context(), h.db.GetPool(), employeeID) {
+		JSONError(w, http.StatusForbidden, "Access denied to this employee")
+		return
+	}
+
+	var req models.ContractRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	if errs := req.Validate(); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var exited bool
+	err = tx.QueryRow(ctx,
+		`SELECT exit_type IS NOT NULL FROM employees WHERE id = $1 FOR UPDATE`, employeeID,
+	).Scan(&exited)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Employee not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching employee %s: %v", employeeID, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to create contract")
+		return
+	}
+	if exited {
+		JSONError(w, http.StatusConflict, "Cannot add a contract for an exited employee")
+		return
+	}
+
+	// Supersede the current contract first (one current per employee)
+	var previousID string
+	err = tx.QueryRow(ctx, `
+		UPDATE employee_contracts SET status = 'superseded', updated_at = NOW()
+		WHERE employee_id = $1 AND status = 'current' AND start_date < $2::date
+		RETURNING id
+	`, employeeID, req.StartDate).Scan(&previousID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Error superseding contract of employee %s: %v", employeeID, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to create contract")
+		return
+	}
+	if previousID == "" {
+		var hasCurrent bool
+		_ = tx.QueryRow(ctx, `
+			SELECT EXISTS(SELECT 1 FROM employee_contracts WHERE employee_id = $1 AND status = 'current')
+		`, employeeID).Scan(&hasCurrent)
+		if hasCurrent {
+			JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+				"error":   "Validation failed",
+				"details": map[string]string{"startDate": "A new contract must start after the current contract"},
+			})
+			return
+		}
+	}
+
+	var id string
+	err = tx.QueryRow(ctx, `
+		INSERT INTO employee_contracts (employee_id, company_id, contract_type, contract_number,
+		    start_date, end_date, probation_end_date, notice_period_days,
+		    file_url, file_name, file_size, file_type, notes, created_by)
+		SELECT e.id, e.company_id, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+		FROM employees e WHERE e.id = $1
+		RETURNING id
+	`, employeeID, req.ContractType, nilIfEmpty(req.ContractNumber),
+		req.StartDate, nilIfEmpty(req.EndDate), nilIfEmpty(req.ProbationEndDate), *req.NoticePeriodDays,
+		req.FileURL, req.FileName, req.FileSize, req.FileType, req.Notes, nilIfEmpty(userID)).Scan(&id)
+	if err != nil {
+		log.Printf("Error creating contract: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to create contract")
+		return
+	}
+
+	if previousID != "" {
+		if _, err := tx.Exec(ctx,
+			`UPDATE employee_contracts SET superseded_by = $2 WHERE id = $1`, previousID, id,
+		); err != nil {
+			log.Printf("Error linking superseded contract %s: %v", previousID, err)
+			JSONError(w, http.StatusInternalServerError, "Failed to create contract")
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to commit transaction")
+		return
+	}
+
+	c, err := loadContract(ctx, pool, id)
+	if err != nil {
+		log.Printf("Error fetching contract %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch contract")
+		return
+	}
+
+	go logActivity(pool, userID, "created", "contract", id, map[string]interface{}{
+		"employeeId": employeeID, "contractType": c.ContractType,
+		"startDate": c.StartDate, "endDate": c.EndDate, "supersedes": nilIfEmpty(previousID),
+	})
+
+	JSON(w, http.StatusCreated, map[string]interface{}{
+		"data":    c,
+		"message": "Contract created successfully",
+	})
+}
+
+// Update handles PUT /api/contracts/{id}
+// Corrects a contract's details; all fields are replaced.
+func (h *ContractHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !checkContractAccess(r.Context(), h.db.GetPool(), id) {
+		JSONError(w, http.StatusForbidden, "Access denied to this contract")
+		return
+	}
+
+	var req models.ContractRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	if errs := req.Validate(); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	var employeeID string
+	err := pool.QueryRow(ctx, `
+		UPDATE employee_contracts SET
+			contract_type = $2, contract_number = $3, start_date = $4, end_date = $5,
+			probation_end_date = $6, notice_period_days = $7,
+			file_url = $8, file_name = $9, file_size = $10, file_type = $11, notes = $12,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING employee_id
+	`, id, req.ContractType, nilIfEmpty(req.ContractNumber),
+		req.StartDate, nilIfEmpty(req.EndDate), nilIfEmpty(req.ProbationEndDate), *req.NoticePeriodDays,
+		req.FileURL, req.FileName, req.FileSize, req.FileType, req.Notes).Scan(&employeeID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Contract not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating contract %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to update contract")
+		return
+	}
+
+	c, err := loadContract(ctx, pool, id)
+	if err != nil {
+		log.Printf("Error fetching contract %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch contract")
+		return
+	}
+
+	go logActivity(pool, userID, "updated", "contract", id, map[string]interface{}{
+		"employeeId": employeeID, "contractType": c.ContractType,
+		"startDate": c.StartDate, "endDate": c.EndDate,
+	})
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"data":    c,
+		"message": "Contract updated successfully",
+	})
+}
+
+// Delete handles DELETE /api/contracts/{id}
+// Deleting the current contract makes the one it superseded current again.
+func (h *ContractHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !checkContractAccess(r.Context(), h.db.GetPool(), id) {
+		JSONError(w, http.StatusForbidden, "Access denied to this contract")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var employeeID, status string
+	err = tx.QueryRow(ctx,
+		`DELETE FROM employee_contracts WHERE id = $1 RETURNING employee_id, status`, id,
+	).Scan(&employeeID, &status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Contract not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error deleting contract %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to delete contract")
+		return
+	}
+
+	// superseded_by was cleared by the delete; restore the latest contract
+	var restored *string
+	if status == "current" {
+		err = tx.QueryRow(ctx, `
+			UPDATE employee_contracts SET status = 'current', updated_at = NOW()
+			WHERE id = (
+				SELECT id FROM employee_contracts
+				WHERE employee_id = $1 AND superseded_by IS NULL
+				ORDER BY start_date DESC, created_at DESC LIMIT 1
+			)
+			RETURNING id
+		`, employeeID).Scan(&restored)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("Error restoring previous contract of employee %s: %v", employeeID, err)
+			JSONError(w, http.StatusInternalServerError, "Failed to delete contract")
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to commit transaction")
+		return
+	}
+
+	go logActivity(pool, userID, "deleted", "contract", id, map[string]interface{}{
+		"employeeId": employeeID, "restored": restored,
+	})
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"restoredId": restored,
+		"message":    "Contract deleted successfully",
+	})
+}
@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"manpower-backend/internal/ctxkeys"
+	"manpower-backend/internal/models"
+)
+
+// ── Custom Employee Fields ───────────────────────────────────
+// Admin-defined fields per company (migration 029). Values are stored in
+// employees.custom_fields and validated by CreateEmployeeRequest /
+// UpdateEmployeeRequest against the company's active definitions.
+
+const customFieldCols = `id, company_id, field_key, label, field_type, required,
+	options, pattern, min_value::float8, max_value::float8, help_text,
+	sort_order, is_active, created_at::text, updated_at::text`
+
+func scanCustomField(scanner interface {
+	Scan(dest ...interface{}) error
+}, d *models.CustomFieldDefinition) error {
+	return scanner.Scan(
+		&d.ID, &d.CompanyID, &d.Key, &d.Label, &d.Type, &d.Required,
+		&d.Options, &d.Pattern, &d.MinValue, &d.MaxValue, &d.HelpText,
+		&d.SortOrder, &d.IsActive, &d.CreatedAt, &d.UpdatedAt,
+	)
+}
+
+// loadCustomFieldDefs returns the company's active definitions in display order.
+func loadCustomFieldDefs(ctx context.Context, q querier, companyID string) ([]models.CustomFieldDefinition, error) {
+	rows, err := q.Query(ctx, `
+		SELECT `+customFieldCols+` FROM custom_field_definitions
+		WHERE company_id::text = $1 AND is_active
+		ORDER BY sort_order, label
+	`, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	defs := []models.CustomFieldDefinition{}
+	for rows.Next() {
+		var d models.CustomFieldDefinition
+		if err := scanCustomField(rows, &d); err != nil {
+			return nil, err
+		}
+		defs = append(defs, d)
+	}
+	return defs, rows.Err()
+}
+
+var customFieldFilterRe = regexp.MustCompile(`^cf\.([a-z][a-z0-9_]{1,49})$`)
+
+// appendCustomFieldFilters adds one condition per cf.<key>=<value> query
+// parameter. Values are stored typed, so "12" also matches the number 12
+// and "true" the boolean; each candidate is a containment test the GIN
+// index on employees.custom_fields can serve.
+func appendCustomFieldFilters(q url.Values, where string, args []interface{}, argIdx int) (string, []interface{}, int) {
+	keys := make([]string, 0, len(q))
+	for param := range q {
+		keys = append(keys, param)
+	}
+	sort.Strings(keys)
+
+	for _, param := range keys {
+		m := customFieldFilterRe.FindStringSubmatch(param)
+		value := strings.TrimSpace(q.Get(param))
+		if m == nil || value == "" {
+			continue
+		}
+		candidates := []interface{}{value}
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			candidates = append(candidates, n)
+		}
+		if b, err := strconv.ParseBool(value); err == nil {
+			candidates = append(candidates, b)
+		}
+		clauses := []string{}
+		for _, c := range candidates {
+			doc, _ := json.Marshal(map[string]interface{}{m[1]: c})
+			clauses = append(clauses, fmt.Sprintf("e.custom_fields @> $%d::jsonb", argIdx))
+			args = append(args, string(doc))
+			argIdx++
+		}
+		where += " AND (" + strings.Join(clauses, " OR ") + ")"
+	}
+	return where, args, argIdx
+}
+
+// ListCustomFields handles GET /api/custom-fields?company_id=
+// Returns active definitions (all with include_inactive=true, admins only)
+// for the given company, or for every company in the caller's scope.
+func (h *AdminHandler) ListCustomFields(w http.ResponseWriter, r *http.Request) {
+	companyID := r.URL.Query().Get("company_id")
+	if companyID != "" && !checkCompanyAccess(r.Context(), companyID) {
+		JSONError(w, http.StatusForbidden, "Access denied to this company")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	where := "WHERE 1=1"
+	args := []interface{}{}
+	argIdx := 1
+	where, args, argIdx = appendCompanyScope(ctx, where, args, argIdx, "company_id")
+	if companyID != "" {
+		where += fmt.Sprintf(" AND company_id::text = $%d", argIdx)
+		args = append(args, companyID)
+	}
+	role, _ := ctx.Value(ctxkeys.UserRole).(string)
+	if r.URL.Query().Get("include_inactive") != "true" || ctxkeys.RoleLevel[role] < ctxkeys.RoleLevel["admin"] {
+		where += " AND is_active"
+	}
+
+	rows, err := pool.Query(ctx, `SELECT `+customFieldCols+` FROM custom_field_definitions `+where+`
+		ORDER BY company_id, sort_order, label`, args...)
+	if err != nil {
+		log.Printf("Failed to list custom fields: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch custom fields")
+		return
+	}
+	defer rows.Close()
+
+	defs := []models.CustomFieldDefinition{}
+	for rows.Next() {
+		var d models.CustomFieldDefinition
+		if err := scanCustomField(rows, &d); err != nil {
+			log.Printf("Failed to scan custom field: %v", err)
+			continue
+		}
+		defs = append(defs, d)
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{"data": defs})
+}
+
+// CreateCustomField handles POST /api/admin/custom-fields (admin-only).
+func (h *AdminHandler) CreateCustomField(w http.ResponseWriter, r *http.Request) {
+	h.saveCustomField(w, r, "")
+}
+
+// UpdateCustomField handles PUT /api/admin/custom-fields/{id} (admin-only).
+func (h *AdminHandler) UpdateCustomField(w http.ResponseWriter, r *http.Request) {
+	h.saveCustomField(w, r, chi.URLParam(r, "id"))
+}
+
+// saveCustomField creates (id == "") or updates a definition. The company
+// and key of an existing definition cannot change, since stored values are
+// keyed by them.
+func (h *AdminHandler) saveCustomField(w http.ResponseWriter, r *http.Request, id string) {
+	var req models.CustomFieldDefinitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	if errs := req.Validate(); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	sortOrder := 100
+	if req.SortOrder != nil {
+		sortOrder = *req.SortOrder
+	}
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	var d models.CustomFieldDefinition
+	var err error
+	action := "created"
+	if id == "" {
+		var exists bool
+		_ = pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM companies WHERE id::text = $1)`, req.CompanyID).Scan(&exists)
+		if !exists {
+			JSONError(w, http.StatusUnprocessableEntity, "Company not found")
+			return
+		}
+		err = scanCustomField(pool.QueryRow(ctx, `
+			INSERT INTO custom_field_definitions (
+				company_id, field_key, label, field_type, required, options,
+				pattern, min_value, max_value, help_text, sort_order, is_active
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			RETURNING `+customFieldCols,
+			req.CompanyID, req.Key, req.Label, req.Type, req.Required, req.Options,
+			req.Pattern, req.MinValue, req.MaxValue, req.HelpText, sortOrder, isActive,
+		), &d)
+	} else {
+		action = "updated"
+		var companyID, key string
+		err = pool.QueryRow(ctx,
+			`SELECT company_id::text, field_key FROM custom_field_definitions WHERE id = $1`, id,
+		).Scan(&companyID, &key)
+		if err == nil && (companyID != req.CompanyID || key != req.Key) {
+			JSONError(w, http.StatusConflict, "The company and key of a custom field cannot be changed")
+			return
+		}
+		if err == nil {
+			err = scanCustomField(pool.QueryRow(ctx, `
+				UPDATE custom_field_definitions SET
+					label = $1, field_type = $2, required = $3, options = $4,
+					pattern = $5, min_value = $6, max_value = $7, help_text = $8,
+					sort_order = $9, is_active = $10, updated_at = NOW()
+				WHERE id = $11
+				RETURNING `+customFieldCols,
+				req.Label, req.Type, req.Required, req.Options,
+				req.Pattern, req.MinValue, req.MaxValue, req.HelpText,
+				sortOrder, isActive, id,
+			), &d)
+		}
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Custom field not found")
+		return
+	}
+	if isDuplicateKeyError(err) {
+		JSONError(w, http.StatusConflict, "This company already has a field with key "+req.Key)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to save custom field: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to save custom field")
+		return
+	}
+
+	go logActivity(pool, userID, action, "custom_field", d.ID, map[string]interface{}{
+		"companyId": d.CompanyID, "key": d.Key, "type": d.Type, "required": d.Required,
+	})
+
+	status := http.StatusOK
+	if id == "" {
+		status = http.StatusCreated
+	}
+	JSON(w, status, map[string]interface{}{
+		"data":    d,
+		"message": "Custom field " + action,
+	})
+}
+
+// DeleteCustomField handles DELETE /api/admin/custom-fields/{id} (admin-only).
+// The field's values are removed from the company's employees; deactivate
+// the field instead to keep them.
+func (h *AdminHandler) DeleteCustomField(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to delete custom field")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var companyID, key string
+	err = tx.QueryRow(ctx, `
+		DELETE FROM custom_field_definitions WHERE id = $1
+		RETURNING company_id::text, field_key
+	`, id).Scan(&companyID, &key)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Custom field not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to delete custom field: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to delete custom field")
+		return
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE employees SET custom_fields = custom_fields - $2::text, updated_at = NOW()
+		WHERE company_id::text = $1 AND custom_fields ? $2::text
+	`, companyID, key)
+	if err != nil {
+		log.Printf("Failed to clear custom field values: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to delete custom field")
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Failed to commit custom field deletion: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to delete custom field")
+		return
+	}
+
+	go logActivity(pool, userID, "deleted", "custom_field", id, map[string]interface{}{
+		"companyId": companyID, "key": key, "valuesCleared": tag.RowsAffected(),
+	})
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"message":       "Custom field deleted",
+		"valuesCleared": tag.RowsAffected(),
+	})
+}
@@ -51,47 +51,18 @@ func (h *DashboardHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Document counts come from the persisted statuses (document_status)
 	err = pool.QueryRow(ctx, fmt.Sprintf(`
-		SELECT COUNT(*) FROM documents d
-		JOIN employees e ON d.employee_id = e.id
-		LEFT JOIN document_types dt ON dt.doc_type = d.document_type AND dt.is_active = TRUE
-		WHERE COALESCE(dt.is_mandatory, FALSE) = TRUE AND d.expiry_date IS NOT NULL
-		  AND d.expiry_date > CURRENT_DATE + INTERVAL '30 days'
-		  AND e.exit_type IS NULL%s
-	`, scopeFilter), scopeArgs...).Scan(&metrics.ActiveDocuments)
-	if err != nil {
-		log.Printf("Error querying active documents: %v", err)
-		JSONError(w, http.StatusInternalServerError, "Failed to fetch metrics")
-		return
-	}
-
-	err = pool.QueryRow(ctx, fmt.Sprintf(`
-		SELECT COUNT(*) FROM documents d
-		JOIN employees e ON d.employee_id = e.id
-		LEFT JOIN document_types dt ON dt.doc_type = d.document_type AND dt.is_active = TRUE
-		WHERE COALESCE(dt.is_mandatory, FALSE) = TRUE AND d.expiry_date IS NOT NULL
-		  AND d.expiry_date BETWEEN CURRENT_DATE AND CURRENT_DATE + INTERVAL '30 days'
-		  AND e.exit_type IS NULL%s
-	`, scopeFilter), scopeArgs...).Scan(&metrics.ExpiringSoon)
-	if err != nil {
-		log.Printf("Error querying expiring soon: %v", err)
-		JSONError(w, http.StatusInternalServerError, "Failed to fetch metrics")
-		return
-	}
-
-	err = pool.QueryRow(ctx, fmt.Sprintf(`
-		SELECT COUNT(*) FROM documents d
-		JOIN employees e ON d.employee_id = e.id
-		LEFT JOIN document_types dt ON dt.doc_type = d.document_type AND dt.is_active = TRUE
-		LEFT JOIN compliance_rules cr ON cr.doc_type = d.document_type AND cr.company_id = e.company_id
-		LEFT JOIN compliance_rules gr ON gr.doc_type = d.document_type AND gr.company_id IS NULL
-		WHERE COALESCE(dt.is_mandatory, FALSE) = TRUE AND d.expiry_date IS NOT NULL
-		  AND d.expiry_date < CURRENT_DATE
-		  AND (d.expiry_date + COALESCE(cr.grace_period_days, gr.grace_period_days, 0) * INTERVAL '1 day') < CURRENT_DATE
-		  AND e.exit_type IS NULL%s
-	`, scopeFilter), scopeArgs...).Scan(&metrics.Expired)
+		SELECT
+			COUNT(*) FILTER (WHERE ds.status = 'valid'),
+			COUNT(*) FILTER (WHERE ds.status = 'expiring_soon'),
+			COUNT(*) FILTER (WHERE ds.status = 'penalty_active')
+		FROM document_status ds
+		JOIN employees e ON ds.employee_id = e.id
+		WHERE ds.is_required AND e.exit_type IS NULL%s
+	`, scopeFilter), scopeArgs...).Scan(&metrics.ActiveDocuments, &metrics.ExpiringSoon, &metrics.Expired)
 	if err != nil {
-		log.Printf("Error querying expired: %v", err)
+		log.Printf("Error querying document status counts: %v", err)
 		JSONError(w, http.StatusInternalServerError, "Failed to fetch metrics")
 		return
 	}
@@ -114,27 +85,50 @@ func (h *DashboardHandler) GetExpiryAlerts(w http.ResponseWriter, r *http.Reques
 		scopeArgs = []interface{}{scopeArg}
 	}
 
+	// Current contracts with the employee's company: the end date from
+	// ExpiringSoonDays before until renewed, the probation end until it passes
 	rows, err := pool.Query(ctx, fmt.Sprintf(`
 		SELECT
-			d.id, e.id, e.name, c.name, d.document_type,
-			d.expiry_date::text,
-			(d.expiry_date - CURRENT_DATE) AS days_left,
-			COALESCE(cr.grace_period_days, gr.grace_period_days, 0) AS grace_period_days,
-			COALESCE(cr.fine_per_day, gr.fine_per_day, 0) AS fine_per_day,
-			COALESCE(cr.fine_type, gr.fine_type, 'daily') AS fine_type,
-			COALESCE(cr.fine_cap, gr.fine_cap, 0) AS fine_cap,
-			d.document_number
-		FROM documents d
-		JOIN employees e ON d.employee_id = e.id
+			'document' AS kind, ds.document_id::text, NULL::uuid,
+			e.id, e.name, c.name, ds.document_type,
+			ds.expiry_date AS expiry_date, ds.days_remaining, ds.status,
+			ds.estimated_fine::float8, ds.fine_per_day::float8,
+			ds.grace_days_remaining, ds.days_in_penalty,
+			dp.id, dp.name
+		FROM document_status ds
+		JOIN employees e ON ds.employee_id = e.id
 		JOIN companies c ON e.company_id = c.id
-		LEFT JOIN document_types dt ON dt.doc_type = d.document_type AND dt.is_active = TRUE
-		LEFT JOIN compliance_rules cr ON cr.doc_type = d.document_type AND cr.company_id = e.company_id
-		LEFT JOIN compliance_rules gr ON gr.doc_type = d.document_type AND gr.company_id IS NULL
-		WHERE COALESCE(dt.is_mandatory, FALSE) = TRUE AND d.expiry_date IS NOT NULL
-		  AND d.expiry_date <= CURRENT_DATE + INTERVAL '30 days'
-		  AND e.exit_type IS NULL%s
-		ORDER BY d.expiry_date ASC
-	`, scopeFilter), scopeArgs...)
+		JOIN documents d ON d.id = ds.document_id
+		LEFT JOIN dependents dp ON dp.id = d.dependent_id
+		WHERE ds.is_required
+		  AND ds.status IN ('expiring_soon', 'in_grace', 'penalty_active')
+		  AND e.exit_type IS NULL%[1]s
+
+		UNION ALL
+		SELECT 'contract_end', '', ec.id, e.id, e.name, c.name, 'labour_contract',
+			ec.end_date, ec.end_date - company_today(e.company_id),
+			CASE WHEN ec.end_date < company_today(e.company_id) THEN 'expired' ELSE 'expiring_soon' END,
+			0, 0, NULL, NULL, NULL, NULL
+		FROM employee_contracts ec
+		JOIN employees e ON e.id = ec.employee_id AND e.company_id = ec.company_id
+		JOIN companies c ON e.company_id = c.id
+		WHERE ec.status = 'current'
+		  AND ec.end_date <= company_today(e.company_id) + %[2]d
+		  AND e.exit_type IS NULL%[1]s
+
+		UNION ALL
+		SELECT 'probation_end', '', ec.id, e.id, e.name, c.name, 'probation',
+			ec.probation_end_date, ec.probation_end_date - company_today(e.company_id), 'expiring_soon',
+			0, 0, NULL, NULL, NULL, NULL
+		FROM employee_contracts ec
+		JOIN employees e ON e.id = ec.employee_id AND e.company_id = ec.company_id
+		JOIN companies c ON e.company_id = c.id
+		WHERE ec.status = 'current'
+		  AND ec.probation_end_date BETWEEN company_today(e.company_id) AND company_today(e.company_id) + %[2]d
+		  AND e.exit_type IS NULL%[1]s
+
+		ORDER BY expiry_date ASC
+	`, scopeFilter, compliance.ExpiringSoonDays), scopeArgs...)
 	if err != nil {
 		log.Printf("Error fetching expiry alerts: %v", err)
 		JSONError(w, http.StatusInternalServerError, "Failed to fetch alerts")
@@ -142,39 +136,23 @@ func (h *DashboardHandler) GetExpiryAlerts(w http.ResponseWriter, r *http.Reques
 	}
 	defer rows.Close()
 
-	now := time.Now()
 	alerts := []models.ExpiryAlert{}
 	for rows.Next() {
 		var a models.ExpiryAlert
-		var graceDays int
-		var finePerDay, fineCap float64
-		var fineType string
-		var docNumber *string
-
+		var expiry time.Time
 		if err := rows.Scan(
-			&a.DocumentID, &a.EmployeeID, &a.EmployeeName,
-			&a.CompanyName, &a.DocumentType, &a.ExpiryDate,
-			&a.DaysLeft,
-			&graceDays, &finePerDay, &fineType, &fineCap,
-			&docNumber,
+			&a.Kind, &a.DocumentID, &a.ContractID,
+			&a.EmployeeID, &a.EmployeeName,
+			&a.CompanyName, &a.DocumentType, &expiry,
+			&a.DaysLeft, &a.Status,
+			&a.EstimatedFine, &a.FinePerDay,
+			&a.GraceDaysRemaining, &a.DaysInPenalty,
+			&a.DependentID, &a.DependentName,
 		); err != nil {
 			log.Printf("Error scanning alert: %v", err)
 			continue
 		}
-
-		// Use the compliance engine for accurate status (in_grace vs penalty_active)
-		if expiryTime, parseErr := time.Parse("2006-01-02", a.ExpiryDate); parseErr == nil {
-			docNum := ""
-			if docNumber != nil {
-				docNum = *docNumber
-			}
-			a.Status = compliance.ComputeStatus(&expiryTime, graceDays, docNum, now)
-			a.EstimatedFine = compliance.ComputeFine(expiryTime, graceDays, finePerDay, fineType, fineCap, now)
-			a.GraceDaysRemaining = compliance.GraceDaysRemaining(&expiryTime, graceDays, now)
-			a.DaysInPenalty = compliance.DaysInPenalty(&expiryTime, graceDays, now)
-		}
-		a.FinePerDay = finePerDay
-
+		a.ExpiryDate = expiry.Format("2006-01-02")
 		alerts = append(alerts, a)
 	}
 
@@ -200,9 +178,12 @@ func (h *DashboardHandler) GetCompanySummary(w http.ResponseWriter, r *http.Requ
 	}
 
 	rows, err := pool.Query(ctx, fmt.Sprintf(`
-		SELECT c.id, c.name, COALESCE(c.currency, 'AED'), COUNT(e.id) AS employee_count
+		SELECT c.id, c.name, COALESCE(c.currency, 'AED'), COUNT(e.id) AS employee_count,
+			COALESCE(SUM(ec.score_lost) FILTER (WHERE e.exit_type IS NULL), 0)::float8,
+			COALESCE(SUM(ec.score_max) FILTER (WHERE e.exit_type IS NULL), 0)::float8
 		FROM companies c
 		LEFT JOIN employees e ON e.company_id = c.id
+		LEFT JOIN employee_compliance ec ON ec.employee_id = e.id
 		WHERE 1=1%s
 		GROUP BY c.id, c.name, c.currency
 		ORDER BY employee_count DESC
@@ -217,10 +198,12 @@ func (h *DashboardHandler) GetCompanySummary(w http.ResponseWriter, r *http.Requ
 	companies := []models.CompanySummary{}
 	for rows.Next() {
 		var cs models.CompanySummary
-		if err := rows.Scan(&cs.ID, &cs.Name, &cs.Currency, &cs.EmployeeCount); err != nil {
+		var scoreLost, scoreMax float64
+		if err := rows.Scan(&cs.ID, &cs.Name, &cs.Currency, &cs.EmployeeCount, &scoreLost, &scoreMax); err != nil {
 			log.Printf("Error scanning company summary: %v", err)
 			continue
 		}
+		cs.ComplianceScore = compliance.ScoreValue(scoreLost, scoreMax)
 		companies = append(companies, cs)
 	}
 
@@ -239,7 +222,6 @@ func (h *DashboardHandler) GetComplianceStats(w http.ResponseWriter, r *http.Req
 	defer cancel()
 
 	pool := h.db.GetPool()
-	now := time.Now()
 
 	stats := models.ComplianceStats{
 		DocumentsByStatus: make(map[string]int),
@@ -257,26 +239,15 @@ func (h *DashboardHandler) GetComplianceStats(w http.ResponseWriter, r *http.Req
 
 	pool.QueryRow(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM employees e WHERE exit_type IS NULL%s`, empScopeFilter), scopeArgs...).Scan(&stats.TotalEmployees)
 
-	pool.QueryRow(ctx, fmt.Sprintf(`
-		SELECT COUNT(*) FROM documents d
-		JOIN employees e ON d.employee_id = e.id
-		LEFT JOIN document_types dt ON dt.doc_type = d.document_type AND dt.is_active = TRUE
-		WHERE COALESCE(dt.is_mandatory, FALSE) = TRUE%s
-	`, scopeFilter), scopeArgs...).Scan(&stats.TotalDocuments)
-
+	// Per-status counts and fines come from the persisted statuses (document_status)
 	rows, err := pool.Query(ctx, fmt.Sprintf(`
-		SELECT d.document_number, d.expiry_date::text,
-			COALESCE(cr.grace_period_days, gr.grace_period_days, 0) AS grace_period_days,
-			COALESCE(cr.fine_per_day, gr.fine_per_day, 0) AS fine_per_day,
-			COALESCE(cr.fine_type, gr.fine_type, 'daily') AS fine_type,
-			COALESCE(cr.fine_cap, gr.fine_cap, 0) AS fine_cap,
-			d.file_url
-		FROM documents d
-		JOIN employees e ON d.employee_id = e.id
-		LEFT JOIN document_types dt ON dt.doc_type = d.document_type AND dt.is_active = TRUE
-		LEFT JOIN compliance_rules cr ON cr.doc_type = d.document_type AND cr.company_id = e.company_id
-		LEFT JOIN compliance_rules gr ON gr.doc_type = d.document_type AND gr.company_id IS NULL
-		WHERE COALESCE(dt.is_mandatory, FALSE) = TRUE AND e.exit_type IS NULL%s
+		SELECT ds.status, COUNT(*),
+			COALESCE(SUM(ds.estimated_fine) FILTER (WHERE ds.status = 'penalty_active'), 0)::float8,
+			COALESCE(SUM(ds.fine_per_day) FILTER (WHERE ds.status = 'penalty_active' AND ds.fine_type = 'daily'), 0)::float8
+		FROM document_status ds
+		JOIN employees e ON ds.employee_id = e.id
+		WHERE ds.is_required AND e.exit_type IS NULL%s
+		GROUP BY ds.status
 	`, scopeFilter), scopeArgs...)
 	if err != nil {
 		log.Printf("Error fetching compliance stats: %v", err)
@@ -287,43 +258,20 @@ func (h *DashboardHandler) GetComplianceStats(w http.ResponseWriter, r *http.Req
 
 	totalComplete := 0
 	for rows.Next() {
-		var docNumber *string
-		var expiryRaw string
-		var graceDays int
-		var finePerDay, fineCap float64
-		var fineType, fileURL string
-
-		if err := rows.Scan(&docNumber, &expiryRaw, &graceDays, &finePerDay, &fineType, &fineCap, &fileURL); err != nil {
+		var status string
+		var count int
+		var accumulated, dailyFine float64
+		if err := rows.Scan(&status, &count, &accumulated, &dailyFine); err != nil {
 			continue
 		}
-
-		// Compute status
-		var expiryTime *time.Time
-		if expiryRaw != "" {
-			if t, err := time.Parse("2006-01-02", expiryRaw); err == nil {
-				expiryTime = &t
-			}
-		}
-		docNum := ""
-		if docNumber != nil {
-			docNum = *docNumber
-		}
-		status := compliance.ComputeStatus(expiryTime, graceDays, docNum, now)
-		stats.DocumentsByStatus[status]++
-
+		stats.DocumentsByStatus[status] = count
+		stats.TotalDocuments += count
 		if status != compliance.StatusIncomplete {
-			totalComplete++
-		}
-
-		// Accumulate fines
-		if expiryTime != nil && status == compliance.StatusPenaltyActive {
-			fine := compliance.ComputeFine(*expiryTime, graceDays, finePerDay, fineType, fineCap, now)
-			stats.TotalAccumulated += fine
-			// Only add to daily exposure for actual daily-type fines
-			if fineType == compliance.FineTypeDaily {
-				stats.TotalDailyFine += finePerDay
-			}
+			totalComplete += count
 		}
+		stats.TotalAccumulated += accumulated
+		// Only daily-type fines add to daily exposure
+		stats.TotalDailyFine += dailyFine
 	}
 
 	// Completion rate
@@ -339,34 +287,34 @@ func (h *DashboardHandler) GetComplianceStats(w http.ResponseWriter, r *http.Req
 
 	companyRows, err := pool.Query(ctx, fmt.Sprintf(`
 		SELECT c.id, c.name, COUNT(DISTINCT e.id) AS emp_count,
-			COUNT(d.id) FILTER (WHERE d.expiry_date IS NOT NULL 
-				AND d.expiry_date < CURRENT_DATE
-				AND (d.expiry_date + COALESCE(cr2.grace_period_days, gr2.grace_period_days, 0) * INTERVAL '1 day') < CURRENT_DATE
-			) AS penalty_count,
-			COUNT(d.id) FILTER (WHERE d.document_number IS NULL 
-				OR d.document_number = ''
-				OR d.expiry_date IS NULL
-			) AS incomplete_count
+			COUNT(ds.document_id) FILTER (WHERE ds.status = 'penalty_active') AS penalty_count,
+			COUNT(ds.document_id) FILTER (WHERE ds.status = 'incomplete') AS incomplete_count,
+			COALESCE(sc.lost, 0)::float8, COALESCE(sc.max, 0)::float8
 		FROM companies c
 		LEFT JOIN employees e ON e.company_id = c.id AND e.exit_type IS NULL
-		LEFT JOIN documents d ON d.employee_id = e.id
-		LEFT JOIN document_types dt ON dt.doc_type = d.document_type AND dt.is_active = TRUE
-		LEFT JOIN compliance_rules cr2 ON cr2.doc_type = d.document_type AND cr2.company_id = c.id
-		LEFT JOIN compliance_rules gr2 ON gr2.doc_type = d.document_type AND gr2.company_id IS NULL
-		WHERE (COALESCE(dt.is_mandatory, FALSE) = TRUE OR d.id IS NULL)%s
-		GROUP BY c.id, c.name
+		LEFT JOIN document_status ds ON ds.employee_id = e.id AND ds.is_required
+		LEFT JOIN LATERAL (
+			SELECT SUM(ec.score_lost) AS lost, SUM(ec.score_max) AS max
+			FROM employee_compliance ec
+			JOIN employees se ON se.id = ec.employee_id
+			WHERE ec.company_id = c.id AND se.exit_type IS NULL
+		) sc ON TRUE
+		WHERE 1=1%s
+		GROUP BY c.id, c.name, sc.lost, sc.max
 		ORDER BY penalty_count DESC
 	`, companyScopeF), compScopeArgs...)
 	if err == nil {
 		defer companyRows.Close()
 		for companyRows.Next() {
 			var cc models.CompanyCompliance
+			var scoreLost, scoreMax float64
 			if err := companyRows.Scan(
 				&cc.CompanyID, &cc.CompanyName, &cc.EmployeeCount,
-				&cc.PenaltyCount, &cc.IncompleteCount,
+				&cc.PenaltyCount, &cc.IncompleteCount, &scoreLost, &scoreMax,
 			); err != nil {
 				continue
 			}
+			cc.ComplianceScore = compliance.ScoreValue(scoreLost, scoreMax)
 			stats.CompanyBreakdown = append(stats.CompanyBreakdown, cc)
 		}
 	}
@@ -396,8 +344,11 @@ func (h *DashboardHandler) GetDependencyAlerts(w http.ResponseWriter, r *http.Re
 
 	pool := h.db.GetPool()
 
-	// Fetch dependency rules
-	depRows, err := pool.Query(ctx, `SELECT blocking_doc_type, blocked_doc_type, description FROM document_dependencies`)
+	// Fetch dependency rules in force for the employee's company (company → authority → global)
+	depRows, err := pool.Query(ctx, `
+		SELECT blocking_doc_type, blocked_doc_type, description, min_validity_days
+		FROM effective_dependencies((SELECT company_id FROM employees WHERE id = $1), NULL)
+	`, employeeID)
 	if err != nil {
 		log.Printf("Error fetching dependencies: %v", err)
 		JSONError(w, http.StatusInternalServerError, "Failed to fetch dependency alerts")
@@ -409,11 +360,12 @@ func (h *DashboardHandler) GetDependencyAlerts(w http.ResponseWriter, r *http.Re
 		Blocking    string
 		Blocked     string
 		Description string
+		MinDays     int
 	}
 	deps := []dep{}
 	for depRows.Next() {
 		var d dep
-		if err := depRows.Scan(&d.Blocking, &d.Blocked, &d.Description); err != nil {
+		if err := depRows.Scan(&d.Blocking, &d.Blocked, &d.Description, &d.MinDays); err != nil {
 			continue
 		}
 		deps = append(deps, d)
@@ -424,6 +376,7 @@ func (h *DashboardHandler) GetDependencyAlerts(w http.ResponseWriter, r *http.Re
 		FROM documents d
 		LEFT JOIN document_types dt ON dt.doc_type = d.document_type AND dt.is_active = TRUE
 		WHERE d.employee_id = $1 AND COALESCE(dt.is_mandatory, FALSE) = TRUE
+		ORDER BY d.expiry_date ASC NULLS FIRST
 	`, employeeID)
 	if err != nil {
 		log.Printf("Error fetching employee docs for dependency check: %v", err)
@@ -432,7 +385,7 @@ func (h *DashboardHandler) GetDependencyAlerts(w http.ResponseWriter, r *http.Re
 	}
 	defer docRows.Close()
 
-	// Map doc type → expiry date string
+	// Map doc type → expiry date string (latest expiry wins for renewed types)
 	docExpiry := map[string]string{}
 	docExists := map[string]bool{}
 	for docRows.Next() {
@@ -445,8 +398,10 @@ func (h *DashboardHandler) GetDependencyAlerts(w http.ResponseWriter, r *http.Re
 		docExists[docType] = docNumber != nil && *docNumber != ""
 	}
 
-	// Evaluate dependency rules
-	now := time.Now()
+	// Evaluate dependency rules in the company's timezone
+	timezone := compliance.DefaultTimezone
+	_ = pool.QueryRow(ctx, `SELECT company_timezone(company_id) FROM employees WHERE id = $1`, employeeID).Scan(&timezone)
+	now := compliance.Today(time.Now(), timezone)
 	alerts := []models.DependencyAlert{}
 	for _, d := range deps {
 		blockingExpiry := docExpiry[d.Blocking]
@@ -462,21 +417,30 @@ func (h *DashboardHandler) GetDependencyAlerts(w http.ResponseWriter, r *http.Re
 			continue
 		}
 
-		daysUntilBlockingExpiry := int(blockingTime.Sub(now).Hours() / 24)
+		daysUntilBlockingExpiry := *compliance.DaysRemaining(&blockingTime, now)
 
 		alert := models.DependencyAlert{
-			BlockingDoc:    d.Blocking,
-			BlockedDoc:     d.Blocked,
-			Message:        d.Description,
-			BlockingExpiry: blockingExpiry,
-			BlockedExpiry:  blockedExpiry,
+			BlockingDoc:     d.Blocking,
+			BlockedDoc:      d.Blocked,
+			Message:         d.Description,
+			BlockingExpiry:  blockingExpiry,
+			BlockedExpiry:   blockedExpiry,
+			MinValidityDays: d.MinDays,
 		}
 
+		// Critical once the blocking doc is below its minimum validity (renewal
+		// of the blocked doc is refused); warning in the 30 days before that.
 		if daysUntilBlockingExpiry < 0 {
 			alert.Severity = "critical"
 			alert.Message = fmt.Sprintf("%s has EXPIRED — %s", compliance.DisplayName(d.Blocking), d.Description)
 			alerts = append(alerts, alert)
-		} else if daysUntilBlockingExpiry <= 30 {
+		} else if daysUntilBlockingExpiry < d.MinDays {
+			alert.Severity = "critical"
+			alert.Message = fmt.Sprintf("%s has %d days left, %d required to renew %s — %s",
+				compliance.DisplayName(d.Blocking), daysUntilBlockingExpiry, d.MinDays,
+				compliance.DisplayName(d.Blocked), d.Description)
+			alerts = append(alerts, alert)
+		} else if daysUntilBlockingExpiry <= d.MinDays+30 {
 			alert.Severity = "warning"
 			alert.Message = fmt.Sprintf("%s expires in %d days — %s",
 				compliance.DisplayName(d.Blocking), daysUntilBlockingExpiry, d.Description)
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"manpower-backend/internal/compliance"
+	"manpower-backend/internal/ctxkeys"
+	"manpower-backend/internal/models"
+)
+
+// dependencyOverride is embedded in renew/update requests so an admin can
+// push a change through despite failing dependencies.
+type dependencyOverride struct {
+	OverrideDependencies bool   `json:"overrideDependencies,omitempty"`
+	OverrideReason       string `json:"overrideReason,omitempty"`
+}
+
+// checkDocumentDependencies returns the dependencies blocking a renewal/update
+// of blockedDocType for the employee, using the rules in force for the
+// employee's company (company → authority → global). The blocking document
+// considered is the one with the latest expiry for its type held by the same
+// person — the employee, or the dependent when dependentID is set; if they
+// have no document of the blocking type at all the rule does not apply.
+func checkDocumentDependencies(ctx context.Context, q querier, employeeID string, dependentID *string, blockedDocType string) ([]models.DependencyViolation, error) {
+	rows, err := q.Query(ctx, `
+		SELECT dep.blocking_doc_type, dep.min_validity_days, dep.description,
+		       b.id, b.expiry_date,
+		       company_timezone((SELECT company_id FROM employees WHERE id = $1))
+		FROM effective_dependencies((SELECT company_id FROM employees WHERE id = $1), NULL) dep
+		JOIN LATERAL (
+			SELECT d.id, d.expiry_date
+			FROM documents d
+			WHERE d.employee_id = $1 AND d.document_type = dep.blocking_doc_type
+			  AND d.dependent_id IS NOT DISTINCT FROM $3::uuid
+			ORDER BY d.expiry_date DESC NULLS LAST, d.created_at DESC
+			LIMIT 1
+		) b ON TRUE
+		WHERE dep.blocked_doc_type = $2
+		ORDER BY dep.blocking_doc_type
+	`, employeeID, blockedDocType, dependentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	violations := []models.DependencyViolation{}
+	for rows.Next() {
+		var blockingType, description, blockingID, timezone string
+		var minDays int
+		var expiry *time.Time
+		if err := rows.Scan(&blockingType, &minDays, &description, &blockingID, &expiry, &timezone); err != nil {
+			return nil, err
+		}
+
+		ok, days := compliance.MeetsMinValidity(expiry, minDays, compliance.Today(time.Now(), timezone))
+		if ok {
+			continue
+		}
+
+		v := models.DependencyViolation{
+			BlockingDocType: blockingType,
+			BlockedDocType:  blockedDocType,
+			BlockingDocID:   blockingID,
+			DaysRemaining:   days,
+			MinValidityDays: minDays,
+		}
+		name := compliance.DisplayName(blockingType)
+		switch {
+		case expiry == nil:
+			v.Message = fmt.Sprintf("%s has no expiry date on file", name)
+		case *days < 0:
+			v.Message = fmt.Sprintf("%s has expired", name)
+		default:
+			v.Message = fmt.Sprintf("%s has %d days validity left, %d required", name, *days, minDays)
+		}
+		if expiry != nil {
+			s := expiry.Format("2006-01-02")
+			v.BlockingExpiry = &s
+		}
+		if description != "" {
+			v.Message += " — " + description
+		}
+		violations = append(violations, v)
+	}
+	return violations, rows.Err()
+}
+
+// enforceDocumentDependencies runs the dependency check for a renewal/update.
+// It writes the 409/403 response itself and returns false when the request
+// must stop. On an accepted admin override the violations are returned so the
+// caller can record them once the change is committed.
+func enforceDocumentDependencies(w http.ResponseWriter, r *http.Request, q querier, employeeID string, dependentID *string, docType string, override dependencyOverride) ([]models.DependencyViolation, bool) {
+	violations, err := checkDocumentDependencies(r.Context(), q, employeeID, dependentID, docType)
+	if err != nil {
+		log.Printf("Error checking document dependencies: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to check document dependencies")
+		return nil, false
+	}
+	if len(violations) == 0 {
+		return nil, true
+	}
+
+	role, _ := r.Context().Value(ctxkeys.UserRole).(string)
+	isAdmin := ctxkeys.RoleLevel[role] >= ctxkeys.RoleLevel["admin"]
+
+	if !override.OverrideDependencies {
+		JSON(w, http.StatusConflict, map[string]interface{}{
+			"error":       "Blocking documents do not meet the required validity",
+			"violations":  violations,
+			"canOverride": isAdmin,
+		})
+		return nil, false
+	}
+	if !isAdmin {
+		JSONError(w, http.StatusForbidden, "Only admins can override document dependencies")
+		return nil, false
+	}
+	if strings.TrimSpace(override.OverrideReason) == "" {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": map[string]string{"overrideReason": "A reason is required to override dependencies"},
+		})
+		return nil, false
+	}
+	return violations, true
+}
+
+// recordDependencyOverride stores an accepted override in dependency_overrides.
+// Every override must be recorded, so callers run it in the transaction of
+// the document write and roll back when it fails.
+func recordDependencyOverride(ctx context.Context, q querier, userID, documentID, employeeID, action, reason string, violations []models.DependencyViolation) error {
+	violationsJSON, _ := json.Marshal(violations)
+	var uid interface{}
+	if userID != "" {
+		uid = userID
+	}
+	_, err := q.Exec(ctx, `
+		INSERT INTO dependency_overrides (document_id, employee_id, action, violations, reason, user_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, documentID, employeeID, action, string(violationsJSON), strings.TrimSpace(reason), uid)
+	return err
+}
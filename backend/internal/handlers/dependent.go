@@ -0,0 +1,361 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"manpower-backend/internal/compliance"
+	"manpower-backend/internal/ctxkeys"
+	"manpower-backend/internal/database"
+	"manpower-backend/internal/models"
+)
+
+// DependentHandler handles the family members an employee sponsors.
+type DependentHandler struct {
+	db database.Service
+}
+
+// NewDependentHandler creates a new DependentHandler.
+func NewDependentHandler(db database.Service) *DependentHandler {
+	return &DependentHandler{db: db}
+}
+
+// ── Dependents ───────────────────────────────────────────────────
+// A dependent (migration 031) belongs to its sponsoring employee. Its
+// mandatory document slots come from the dependent document types
+// (document_types.applies_to = 'dependent') and are stored under the
+// sponsor with dependent_id set, so their status and fines roll up into the
+// sponsor's compliance, the dashboard and the notifier. Cancelling a
+// dependent's sponsorship makes its documents optional.
+
+const dependentCols = `dp.id, dp.employee_id, dp.name, dp.relationship, dp.gender, dp.nationality,
+	dp.date_of_birth::text, dp.passport_number, dp.status, dp.cancelled_on::text, dp.notes,
+	dp.created_by, dp.created_at::text, dp.updated_at::text,
+	COALESCE(ds.statuses, '{}'), COALESCE(ds.total, 0), COALESCE(ds.complete, 0), COALESCE(ds.fine, 0)::float8`
+
+// dependentFrom rolls up each dependent's required documents from
+// document_status.
+const dependentFrom = `
+	FROM dependents dp
+	LEFT JOIN LATERAL (
+		SELECT array_agg(s.status::text) AS statuses, COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE s.status <> 'incomplete') AS complete,
+			SUM(s.estimated_fine) AS fine
+		FROM documents d
+		JOIN document_status s ON s.document_id = d.id
+		WHERE d.dependent_id = dp.id AND s.is_required
+	) ds ON TRUE`
+
+// scanDependent scans dependentCols.
+func scanDependent(row pgx.Row, d *models.Dependent) error {
+	var statuses []string
+	if err := row.Scan(
+		&d.ID, &d.EmployeeID, &d.Name, &d.Relationship, &d.Gender, &d.Nationality,
+		&d.DateOfBirth, &d.PassportNumber, &d.Status, &d.CancelledOn, &d.Notes,
+		&d.CreatedBy, &d.CreatedAt, &d.UpdatedAt,
+		&statuses, &d.DocsTotal, &d.DocsComplete, &d.EstimatedFine,
+	); err != nil {
+		return err
+	}
+	d.ComplianceStatus = compliance.RollupStatus(statuses)
+	return nil
+}
+
+func loadDependent(ctx context.Context, q querier, id string) (models.Dependent, error) {
+	var d models.Dependent
+	err := scanDependent(q.QueryRow(ctx, `SELECT `+dependentCols+dependentFrom+` WHERE dp.id = $1`, id), &d)
+	return d, err
+}
+
+// List handles GET /api/employees/{id}/dependents
+func (h *DependentHandler) List(w http.ResponseWriter, r *http.Request) {
+	employeeID := chi.URLParam(r, "id")
+	if !checkEmployeeAccess(r.Context(), h.db.GetPool(), employeeID) {
+		JSONError(w, http.StatusForbidden, "Access denied to this employee")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	rows, err := h.db.GetPool().Query(ctx, `
+		SELECT `+dependentCols+dependentFrom+`
+		WHERE dp.employee_id = $1
+		ORDER BY dp.status, dp.created_at
+	`, employeeID)
+	if err != nil {
+		log.Printf("Error fetching dependents: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch dependents")
+		return
+	}
+	defer rows.Close()
+
+	dependents := []models.Dependent{}
+	for rows.Next() {
+		var d models.Dependent
+		if err := scanDependent(rows, &d); err != nil {
+			log.Printf("Error scanning dependent: %v", err)
+			continue
+		}
+		dependents = append(dependents, d)
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{"data": dependents})
+}
+
+// Create handles POST /api/employees/{id}/dependents
+// The dependent's mandatory document slots are created with it.
+func (h *DependentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	employeeID := chi.URLParam(r, "id")
+	if !checkEmployeeAccess(r.Context(), h.db.GetPool(), employeeID) {
+		JSONError(w, http.StatusForbidden, "Access denied to this employee")
+		return
+	}
+
+	var req models.DependentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	if errs := req.Validate(true); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	var exited bool
+	err := pool.QueryRow(ctx, `SELECT exit_type IS NOT NULL FROM employees WHERE id = $1`, employeeID).Scan(&exited)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Employee not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching employee %s: %v", employeeID, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to create dependent")
+		return
+	}
+	if exited {
+		JSONError(w, http.StatusConflict, "Cannot add a dependent to an exited employee")
+		return
+	}
+
+	status := "active"
+	if req.Status != nil {
+		status = *req.Status
+	}
+	notes := ""
+	if req.Notes != nil {
+		notes = *req.Notes
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var id string
+	err = tx.QueryRow(ctx, `
+		INSERT INTO dependents (employee_id, name, relationship, gender, nationality,
+		    date_of_birth, passport_number, status, cancelled_on, notes, created_by)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, '')::date, NULLIF($7, ''), $8,
+		    CASE WHEN $8 = 'cancelled' THEN COALESCE(NULLIF($9, '')::date,
+		        (SELECT company_today(e.company_id) FROM employees e WHERE e.id = $1)) END, $10, $11)
+		RETURNING id
+	`, employeeID, *req.Name, *req.Relationship, req.Gender, req.Nationality,
+		req.DateOfBirth, req.PassportNumber, status,
+		req.CancelledOn, notes, nilIfEmpty(userID)).Scan(&id)
+	if err != nil {
+		log.Printf("Error creating dependent: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to create dependent")
+		return
+	}
+
+	slots, err := reconcileDependentSlots(ctx, tx, id)
+	if err != nil {
+		log.Printf("Error creating document slots for dependent %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to create dependent")
+		return
+	}
+	refreshEmployeeStatus(ctx, tx, employeeID)
+
+	if err := tx.Commit(ctx); err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to commit transaction")
+		return
+	}
+
+	d, err := loadDependent(ctx, pool, id)
+	if err != nil {
+		log.Printf("Error fetching dependent %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch dependent")
+		return
+	}
+
+	go logActivity(pool, userID, "created", "dependent", id, map[string]interface{}{
+		"employeeId": employeeID, "name": d.Name, "relationship": d.Relationship,
+		"slotsAdded": slots.Added,
+	})
+
+	JSON(w, http.StatusCreated, map[string]interface{}{
+		"data":    d,
+		"slots":   slots,
+		"message": "Dependent created successfully",
+	})
+}
+
+// Update handles PUT /api/dependents/{id}
+// Only the fields present are changed. Cancelling the sponsorship records
+// cancelled_on (default today) and makes the dependent's documents optional;
+// reactivating clears it and restores the mandatory slots.
+func (h *DependentHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !checkDependentAccess(r.Context(), h.db.GetPool(), id) {
+		JSONError(w, http.StatusForbidden, "Access denied to this dependent")
+		return
+	}
+
+	var req models.DependentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	if errs := req.Validate(false); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// Empty strings clear the optional fields; nil keeps them
+	var employeeID string
+	err = tx.QueryRow(ctx, `
+		UPDATE dependents SET
+			name            = COALESCE($2, name),
+			relationship    = COALESCE($3, relationship),
+			gender          = CASE WHEN $4::text IS NULL THEN gender ELSE NULLIF($4, '') END,
+			nationality     = CASE WHEN $5::text IS NULL THEN nationality ELSE NULLIF($5, '') END,
+			date_of_birth   = CASE WHEN $6::text IS NULL THEN date_of_birth ELSE NULLIF($6, '')::date END,
+			passport_number = CASE WHEN $7::text IS NULL THEN passport_number ELSE NULLIF($7, '') END,
+			cancelled_on    = CASE
+				WHEN COALESCE($8, status) = 'active' THEN NULL
+				WHEN NULLIF($9, '') IS NOT NULL THEN $9::date
+				ELSE COALESCE(cancelled_on,
+					(SELECT company_today(e.company_id) FROM employees e WHERE e.id = dependents.employee_id)) END,
+			status          = COALESCE($8, status),
+			notes           = COALESCE($10, notes),
+			updated_at      = NOW()
+		WHERE id = $1
+		RETURNING employee_id
+	`, id, req.Name, req.Relationship, req.Gender, req.Nationality, req.DateOfBirth,
+		req.PassportNumber, req.Status, req.CancelledOn, req.Notes).Scan(&employeeID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Dependent not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating dependent %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to update dependent")
+		return
+	}
+
+	// Nationality, gender or status may change which documents are required
+	slots, err := reconcileDependentSlots(ctx, tx, id)
+	if err != nil {
+		log.Printf("Error reconciling document slots for dependent %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to update dependent")
+		return
+	}
+	refreshEmployeeStatus(ctx, tx, employeeID)
+
+	if err := tx.Commit(ctx); err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to commit transaction")
+		return
+	}
+
+	d, err := loadDependent(ctx, pool, id)
+	if err != nil {
+		log.Printf("Error fetching dependent %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch dependent")
+		return
+	}
+
+	go logActivity(pool, userID, "updated", "dependent", id, map[string]interface{}{
+		"employeeId": employeeID, "status": d.Status,
+		"slotsAdded": slots.Added, "slotsRemoved": slots.Removed,
+	})
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"data":    d,
+		"slots":   slots,
+		"message": "Dependent updated successfully",
+	})
+}
+
+// Delete handles DELETE /api/dependents/{id}
+// The dependent's documents are deleted with it.
+func (h *DependentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !checkDependentAccess(r.Context(), h.db.GetPool(), id) {
+		JSONError(w, http.StatusForbidden, "Access denied to this dependent")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	var employeeID, name string
+	err := pool.QueryRow(ctx,
+		`DELETE FROM dependents WHERE id = $1 RETURNING employee_id, name`, id,
+	).Scan(&employeeID, &name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Dependent not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error deleting dependent %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to delete dependent")
+		return
+	}
+	refreshEmployeeStatus(ctx, pool, employeeID)
+
+	go logActivity(pool, userID, "deleted", "dependent", id, map[string]interface{}{
+		"employeeId": employeeID, "name": name,
+	})
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Dependent deleted successfully",
+	})
+}
@@ -0,0 +1,353 @@
+package handlers
+
+import (
+	"context"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"manpower-backend/internal/compliance"
+	"manpower-backend/internal/models"
+)
+
+// querier is satisfied by both *pgxpool.Pool and pgx.Tx so slot helpers
+// can run inside or outside a transaction.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+}
+
+// requiredDocTypes returns the doc types that are mandatory for this employee.
+// The decision is made by the doc_required_for() SQL function (migration 011),
+// which applies requirement conditions on nationality, trade, gender and status
+// on top of compliance_rules / document_types defaults.
+// Falls back to the hardcoded defaults only when no document types are configured.
+func requiredDocTypes(ctx context.Context, q querier, emp *models.Employee) ([]string, error) {
+	rows, err := q.Query(ctx, `
+		SELECT dt.doc_type
+		FROM document_types dt
+		WHERE dt.is_active = TRUE
+		  AND doc_required_for($1, dt.doc_type, $2, $3, $4, $5)
+		ORDER BY dt.sort_order
+	`, emp.CompanyID, emp.Nationality, emp.Trade, emp.Gender, emp.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docTypes := []string{}
+	for rows.Next() {
+		var docType string
+		if err := rows.Scan(&docType); err != nil {
+			return nil, err
+		}
+		docTypes = append(docTypes, docType)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(docTypes) == 0 {
+		var configured bool
+		if err := q.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM document_types)`).Scan(&configured); err == nil && !configured {
+			for _, md := range compliance.MandatoryDocs {
+				docTypes = append(docTypes, md.DocType)
+			}
+		}
+	}
+
+	return docTypes, nil
+}
+
+// documentRequiredExpr decides whether document d (joined to its employee
+// e) is mandatory: by the employee's attributes, or for a dependent's
+// document by the dependent's (migration 031).
+const documentRequiredExpr = `CASE WHEN d.dependent_id IS NULL
+		THEN doc_required_for(e.company_id, d.document_type, e.nationality, e.trade, e.gender, e.status)
+		ELSE dependent_doc_required_for(e.company_id, d.document_type, d.dependent_id) END`
+
+// docRequiredForDocument reports whether the document's type is mandatory
+// for its employee or dependent. Errors are treated as "not mandatory"
+// (display-only flag).
+func docRequiredForDocument(ctx context.Context, q querier, doc *models.Document) bool {
+	var required bool
+	_ = q.QueryRow(ctx, `
+		SELECT COALESCE((
+			SELECT `+documentRequiredExpr+`
+			FROM documents d JOIN employees e ON e.id = d.employee_id
+			WHERE d.id = $1
+		), FALSE)
+	`, doc.ID).Scan(&required)
+	return required
+}
+
+// requiredSlots returns the doc types currently mandatory for each employee
+// matched by filter (a condition on employees e), keyed by employee ID.
+// Taken before an attribute or rule change, it tells reconcileDocumentSlots
+// which slots were required and may be removed afterwards.
+func requiredSlots(ctx context.Context, q querier, filter string, args ...interface{}) (map[string][]string, error) {
+	rows, err := q.Query(ctx, `
+		SELECT e.id::text, dt.doc_type
+		FROM employees e
+		JOIN document_types dt ON dt.is_active = TRUE
+		WHERE `+filter+`
+		  AND doc_required_for(e.company_id, dt.doc_type, e.nationality, e.trade, e.gender, COALESCE(e.status, 'active'))
+		ORDER BY dt.sort_order
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	required := map[string][]string{}
+	for rows.Next() {
+		var employeeID, docType string
+		if err := rows.Scan(&employeeID, &docType); err != nil {
+			return nil, err
+		}
+		required[employeeID] = append(required[employeeID], docType)
+	}
+	return required, rows.Err()
+}
+
+// slotReconciliation summarises what reconcileDocumentSlots changed.
+type slotReconciliation struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// reconcileDocumentSlots brings an employee's mandatory slots in line with
+// their current attributes: missing required slots are created, and empty
+// slots (no number, dates, metadata or file) of types in previous — those
+// required before the change — that are no longer required are removed.
+// Optional slots a user added are kept, and documents that carry any data
+// are never deleted. Dependents' documents are left to reconcileDependentSlots.
+func reconcileDocumentSlots(ctx context.Context, q querier, emp *models.Employee, previous []string) (slotReconciliation, error) {
+	result := slotReconciliation{Added: []string{}, Removed: []string{}}
+
+	required, err := requiredDocTypes(ctx, q, emp)
+	if err != nil {
+		return result, err
+	}
+
+	rows, err := q.Query(ctx, `SELECT DISTINCT document_type FROM documents WHERE employee_id = $1 AND dependent_id IS NULL`, emp.ID)
+	if err != nil {
+		return result, err
+	}
+	existing := map[string]bool{}
+	for rows.Next() {
+		var docType string
+		if err := rows.Scan(&docType); err != nil {
+			rows.Close()
+			return result, err
+		}
+		existing[docType] = true
+	}
+	rows.Close()
+
+	for _, docType := range required {
+		if existing[docType] {
+			continue
+		}
+		if _, err := q.Exec(ctx, `
+			INSERT INTO documents (
+				employee_id, document_type, is_primary,
+				file_url, file_name, file_size, file_type
+			)
+			VALUES ($1, $2, FALSE, '', '', 0, '')
+		`, emp.ID, docType); err != nil {
+			return result, err
+		}
+		result.Added = append(result.Added, docType)
+	}
+
+	removedRows, err := q.Query(ctx, `
+		DELETE FROM documents
+		WHERE employee_id = $1 AND dependent_id IS NULL
+		  AND document_type = ANY($3)
+		  AND NOT (document_type = ANY($2))
+		  AND COALESCE(document_number, '') = ''
+		  AND issue_date IS NULL AND expiry_date IS NULL
+		  AND COALESCE(file_url, '') = ''
+		  AND COALESCE(metadata, '{}'::jsonb) = '{}'::jsonb
+		RETURNING document_type
+	`, emp.ID, required, previous)
+	if err != nil {
+		return result, err
+	}
+	defer removedRows.Close()
+	for removedRows.Next() {
+		var docType string
+		if err := removedRows.Scan(&docType); err != nil {
+			log.Printf("Error scanning removed slot: %v", err)
+			continue
+		}
+		result.Removed = append(result.Removed, docType)
+	}
+
+	return result, removedRows.Err()
+}
+
+// reconcileCompanySlots reconciles the slots of every employee in a company
+// (every company when companyID is ""), e.g. after mandatory rules or
+// requirement conditions change. previous is requiredSlots taken before the
+// change. Returns how many employees had slots added or removed.
+func reconcileCompanySlots(ctx context.Context, q querier, companyID string, previous map[string][]string) (int, error) {
+	rows, err := q.Query(ctx, `
+		SELECT id, company_id, trade, gender, nationality, COALESCE(status, 'active')
+		FROM employees WHERE $1 = '' OR company_id::text = $1
+	`, companyID)
+	if err != nil {
+		return 0, err
+	}
+	employees := []models.Employee{}
+	for rows.Next() {
+		var e models.Employee
+		if err := rows.Scan(&e.ID, &e.CompanyID, &e.Trade, &e.Gender, &e.Nationality, &e.Status); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		employees = append(employees, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for i := range employees {
+		result, err := reconcileDocumentSlots(ctx, q, &employees[i], previous[employees[i].ID])
+		if err != nil {
+			return changed, err
+		}
+		if len(result.Added) > 0 || len(result.Removed) > 0 {
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// slotScope is the set of companies whose mandatory slots a configuration
+// change (requirement condition, mandatory rule) affects, with the slots
+// they required before it.
+type slotScope struct {
+	all        bool // a global row changed: every company
+	companyIDs []string
+	previous   map[string][]string
+}
+
+// snapshotSlots records the slots required in the given scopes (nil =
+// global) before a change. Call reconcile on the result after the change,
+// in the same transaction.
+func snapshotSlots(ctx context.Context, q querier, companyIDs ...*string) (*slotScope, error) {
+	scope := &slotScope{}
+	seen := map[string]bool{}
+	for _, id := range companyIDs {
+		if id == nil {
+			scope.all = true
+		} else if !seen[*id] {
+			seen[*id] = true
+			scope.companyIDs = append(scope.companyIDs, *id)
+		}
+	}
+
+	var err error
+	if scope.all {
+		scope.previous, err = requiredSlots(ctx, q, "TRUE")
+	} else {
+		scope.previous, err = requiredSlots(ctx, q, "e.company_id::text = ANY($1)", scope.companyIDs)
+	}
+	return scope, err
+}
+
+// reconcile brings the scope's slots in line with the changed configuration.
+func (s *slotScope) reconcile(ctx context.Context, q querier) error {
+	if s.all {
+		_, err := reconcileCompanySlots(ctx, q, "", s.previous)
+		return err
+	}
+	for _, id := range s.companyIDs {
+		if _, err := reconcileCompanySlots(ctx, q, id, s.previous); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileDependentSlots does for a dependent what reconcileDocumentSlots
+// does for an employee, using the dependent document types
+// (dependent_doc_required_for). Slots are stored under the sponsor.
+func reconcileDependentSlots(ctx context.Context, q querier, dependentID string) (slotReconciliation, error) {
+	result := slotReconciliation{Added: []string{}, Removed: []string{}}
+
+	rows, err := q.Query(ctx, `
+		SELECT dt.doc_type
+		FROM dependents dp
+		JOIN employees e ON e.id = dp.employee_id
+		JOIN document_types dt ON dt.is_active = TRUE AND dt.applies_to = 'dependent'
+		WHERE dp.id = $1
+		  AND dependent_doc_required_for(e.company_id, dt.doc_type, dp.id)
+		  AND NOT EXISTS (
+			SELECT 1 FROM documents d WHERE d.dependent_id = dp.id AND d.document_type = dt.doc_type
+		  )
+		ORDER BY dt.sort_order
+	`, dependentID)
+	if err != nil {
+		return result, err
+	}
+	missing := []string{}
+	for rows.Next() {
+		var docType string
+		if err := rows.Scan(&docType); err != nil {
+			rows.Close()
+			return result, err
+		}
+		missing = append(missing, docType)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+
+	for _, docType := range missing {
+		if _, err := q.Exec(ctx, `
+			INSERT INTO documents (
+				employee_id, dependent_id, document_type, is_primary,
+				file_url, file_name, file_size, file_type
+			)
+			SELECT employee_id, id, $2, FALSE, '', '', 0, ''
+			FROM dependents WHERE id = $1
+		`, dependentID, docType); err != nil {
+			return result, err
+		}
+		result.Added = append(result.Added, docType)
+	}
+
+	removedRows, err := q.Query(ctx, `
+		DELETE FROM documents d
+		USING dependents dp, employees e
+		WHERE d.dependent_id = $1 AND dp.id = d.dependent_id AND e.id = dp.employee_id
+		  AND NOT dependent_doc_required_for(e.company_id, d.document_type, dp.id)
+		  AND COALESCE(d.document_number, '') = ''
+		  AND d.issue_date IS NULL AND d.expiry_date IS NULL
+		  AND COALESCE(d.file_url, '') = ''
+		  AND COALESCE(d.metadata, '{}'::jsonb) = '{}'::jsonb
+		RETURNING d.document_type
+	`, dependentID)
+	if err != nil {
+		return result, err
+	}
+	defer removedRows.Close()
+	for removedRows.Next() {
+		var docType string
+		if err := removedRows.Scan(&docType); err != nil {
+			log.Printf("Error scanning removed slot: %v", err)
+			continue
+		}
+		result.Removed = append(result.Removed, docType)
+	}
+
+	return result, removedRows.Err()
+}
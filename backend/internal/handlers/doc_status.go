@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"manpower-backend/internal/docstatus"
+)
+
+// ── Persisted Compliance Status ──────────────────────────────────
+// document_status / employee_compliance (migration 016) hold the status the
+// compliance engine computed for every document. Every write that can change
+// a status refreshes them; cron.StartStatusRefresher repairs anything missed
+// and rolls statuses over at each company's local midnight.
+
+// refreshEmployeeStatus recomputes the persisted status of the employees'
+// documents after a write. Failures are logged rather than returned: the
+// write itself has succeeded and the background refresher repairs the rows.
+func refreshEmployeeStatus(ctx context.Context, q querier, employeeIDs ...string) {
+	if err := docstatus.RefreshEmployees(ctx, q, employeeIDs...); err != nil {
+		log.Printf("Error refreshing document status for employees %v: %v", employeeIDs, err)
+	}
+}
+
+// refreshStatusAsync recomputes one company's statuses ("" = every company)
+// in the background after a rule, requirement or calendar change.
+func refreshStatusAsync(pool *pgxpool.Pool, companyID string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+
+		var err error
+		if companyID == "" {
+			err = docstatus.RefreshAll(ctx, pool)
+		} else {
+			err = docstatus.RefreshCompany(ctx, pool, companyID)
+		}
+		if err != nil {
+			log.Printf("Error refreshing document status (company %q): %v", companyID, err)
+		}
+	}()
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"manpower-backend/internal/compliance"
+	"manpower-backend/internal/models"
+)
+
+// ── Standard Validity ────────────────────────────────────────────
+// Document types carry a standard term (migration 022) that a company or
+// global compliance rule can override. Create, Update and Renew use it to
+// fill in a missing expiry from the issue date and to flag an entered expiry
+// far off the term.
+
+// loadDocumentValidity returns the standard validity of docType for the
+// employee's company: company rule, then global rule, then the document
+// type. Types without an expiry have no standard term.
+func loadDocumentValidity(ctx context.Context, q querier, employeeID, docType string) compliance.Validity {
+	var v compliance.Validity
+	_ = q.QueryRow(ctx, `
+		SELECT CASE WHEN dt.has_expiry IS FALSE THEN 0
+		            ELSE COALESCE(cr.validity_period, gr.validity_period, dt.validity_period, 0) END,
+		       COALESCE(cr.validity_unit, gr.validity_unit, dt.validity_unit, 'months')
+		FROM employees e
+		LEFT JOIN document_types dt ON dt.doc_type = $2
+		LEFT JOIN compliance_rules cr ON cr.doc_type = $2 AND cr.company_id = e.company_id
+		LEFT JOIN compliance_rules gr ON gr.doc_type = $2 AND gr.company_id IS NULL
+		WHERE e.id = $1
+	`, employeeID, docType).Scan(&v.Period, &v.Unit)
+	return v
+}
+
+// checkDocumentValidity applies the standard validity to a document's
+// dates. A missing expiry is derived from the issue date (*expiry is set);
+// an entered one is compared with the expected expiry. Returns nil when no
+// term applies or the issue date is missing or unparsable.
+func checkDocumentValidity(v compliance.Validity, issueDate *string, expiry **string) *models.ValidityCheck {
+	if !v.IsSet() || issueDate == nil {
+		return nil
+	}
+	issue, err := time.Parse("2006-01-02", *issueDate)
+	if err != nil {
+		return nil
+	}
+
+	check := &models.ValidityCheck{
+		ValidityPeriod: v.Period,
+		ValidityUnit:   v.Unit,
+		ExpectedExpiry: v.ExpiryFrom(issue).Format("2006-01-02"),
+	}
+	if *expiry == nil || **expiry == "" {
+		derived := check.ExpectedExpiry
+		*expiry = &derived
+		check.Derived = true
+		return check
+	}
+
+	entered, err := time.Parse("2006-01-02", **expiry)
+	if err != nil {
+		return check
+	}
+	_, days, off := v.ExpiryDeviation(issue, entered)
+	check.DeviationDays = days
+	if off {
+		direction := "after"
+		if days < 0 {
+			direction, days = "before", -days
+		}
+		check.Warning = fmt.Sprintf("Expiry date is %d days %s the standard %d %s validity (expected %s); please check for a typo",
+			days, direction, v.Period, v.Unit, check.ExpectedExpiry)
+	}
+	return check
+}
+
+// SuggestExpiry handles GET /api/employees/{id}/documents/validity.
+// Returns the expected expiry for document_type issued on issue_date, and
+// the deviation of expiry_date when given, so forms can pre-fill and warn
+// before saving. data is null when the type has no standard validity.
+func (h *DocumentHandler) SuggestExpiry(w http.ResponseWriter, r *http.Request) {
+	employeeID := chi.URLParam(r, "id")
+	if !checkEmployeeAccess(r.Context(), h.db.GetPool(), employeeID) {
+		JSONError(w, http.StatusForbidden, "Access denied to this employee")
+		return
+	}
+
+	q := r.URL.Query()
+	docType := q.Get("document_type")
+	issueDate := q.Get("issue_date")
+	if docType == "" || issueDate == "" {
+		JSONError(w, http.StatusBadRequest, "document_type and issue_date are required")
+		return
+	}
+	if _, err := time.Parse("2006-01-02", issueDate); err != nil {
+		JSONError(w, http.StatusBadRequest, "issue_date must be YYYY-MM-DD")
+		return
+	}
+	expiry := nilIfEmpty(q.Get("expiry_date"))
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	validity := loadDocumentValidity(ctx, h.db.GetPool(), employeeID, docType)
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"data": checkDocumentValidity(validity, &issueDate, &expiry),
+	})
+}
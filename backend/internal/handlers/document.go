@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -13,6 +14,7 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
 
 	"manpower-backend/internal/compliance"
 	"manpower-backend/internal/ctxkeys"
@@ -37,13 +39,13 @@ const docCols = `d.id, d.employee_id, d.document_type,
 	d.document_number, COALESCE(d.issue_date::text, ''), COALESCE(d.expiry_date::text, ''),
 	d.is_primary, COALESCE(d.metadata::text, '{}'),
 	d.file_url, d.file_name, d.file_size, d.file_type,
-	d.last_updated, d.created_at`
+	d.last_updated, d.created_at, d.dependent_id`
 
 const docRetCols = `id, employee_id, document_type,
 	document_number, COALESCE(issue_date::text, ''), COALESCE(expiry_date::text, ''),
 	is_primary, COALESCE(metadata::text, '{}'),
 	file_url, file_name, file_size, file_type,
-	last_updated, created_at`
+	last_updated, created_at, dependent_id`
 
 // scanDocument reads all Document columns from a row/rows scanner.
 func scanDocument(scanner interface {
@@ -57,7 +59,7 @@ func scanDocument(scanner interface {
 		&docNumber, &issueDateRaw, &expiryRaw,
 		&doc.IsPrimary, &metadataRaw,
 		&doc.FileURL, &doc.FileName, &doc.FileSize, &doc.FileType,
-		&doc.LastUpdated, &doc.CreatedAt,
+		&doc.LastUpdated, &doc.CreatedAt, &doc.DependentID,
 	)
 	if err != nil {
 		return err
@@ -82,7 +84,7 @@ func scanDocument(scanner interface {
 // scanDocumentWithRule scans a document row plus the 4 compliance rule columns and an optional mandatory flag.
 func scanDocumentWithRule(scanner interface {
 	Scan(dest ...interface{}) error
-}, doc *models.Document, rule *ComplianceRule, dtMandatory **bool) error {
+}, doc *models.Document, rule *ComplianceRule, timezone *string, dtMandatory **bool) error {
 	var issueDateRaw, expiryRaw, metadataRaw string
 	var docNumber *string
 
@@ -91,8 +93,8 @@ func scanDocumentWithRule(scanner interface {
 		&docNumber, &issueDateRaw, &expiryRaw,
 		&doc.IsPrimary, &metadataRaw,
 		&doc.FileURL, &doc.FileName, &doc.FileSize, &doc.FileType,
-		&doc.LastUpdated, &doc.CreatedAt,
-		&rule.GracePeriodDays, &rule.FinePerDay, &rule.FineType, &rule.FineCap,
+		&doc.LastUpdated, &doc.CreatedAt, &doc.DependentID,
+		&rule.GracePeriodDays, &rule.FinePerDay, &rule.FineType, &rule.FineCap, timezone,
 		dtMandatory,
 	)
 	if err != nil {
@@ -116,6 +118,7 @@ func scanDocumentWithRule(scanner interface {
 }
 
 // ComplianceRule holds the effective compliance rule for a document (from compliance_rules table).
+// GracePeriodDays is in calendar days — working-day grace is converted by grace_days_for().
 type ComplianceRule struct {
 	GracePeriodDays int
 	FinePerDay      float64
@@ -123,10 +126,42 @@ type ComplianceRule struct {
 	FineCap         float64
 }
 
+// documentRuleCols selects the effective rule for d (documents) of employee e.
+// Scan order matches ComplianceRule, followed by the company timezone.
+const documentRuleCols = `
+			grace_days_for(e.company_id, d.expiry_date,
+				COALESCE(cr.grace_period_days, gr.grace_period_days, 0),
+				COALESCE(cr.grace_period_unit, gr.grace_period_unit, 'calendar')),
+			COALESCE(cr.fine_per_day, gr.fine_per_day, 0),
+			COALESCE(cr.fine_type, gr.fine_type, 'daily'),
+			COALESCE(cr.fine_cap, gr.fine_cap, 0),
+			company_timezone(e.company_id)`
+
+// loadDocumentRule fetches the effective compliance rule and company timezone
+// for a document. The rule is nil when the doc type has no grace or fine.
+func loadDocumentRule(ctx context.Context, q querier, doc *models.Document) (*ComplianceRule, string) {
+	var rule ComplianceRule
+	timezone := compliance.DefaultTimezone
+	_ = q.QueryRow(ctx, `
+		SELECT`+documentRuleCols+`
+		FROM documents d
+		JOIN employees e ON e.id = d.employee_id
+		LEFT JOIN compliance_rules cr ON cr.doc_type = d.document_type AND cr.company_id = e.company_id
+		LEFT JOIN compliance_rules gr ON gr.doc_type = d.document_type AND gr.company_id IS NULL
+		WHERE d.id = $1
+	`, doc.ID).Scan(&rule.GracePeriodDays, &rule.FinePerDay, &rule.FineType, &rule.FineCap, &timezone)
+
+	if rule.FinePerDay > 0 || rule.GracePeriodDays > 0 {
+		return &rule, timezone
+	}
+	return nil, timezone
+}
+
 // enrichWithCompliance computes status, fine, and days fields for a document.
-// rule can be nil for documents without compliance tracking.
-func enrichWithCompliance(doc *models.Document, rule *ComplianceRule) models.DocumentWithCompliance {
-	now := time.Now()
+// rule can be nil for documents without compliance tracking. Dates are
+// evaluated in the company's timezone.
+func enrichWithCompliance(doc *models.Document, rule *ComplianceRule, timezone string) models.DocumentWithCompliance {
+	now := compliance.Today(time.Now(), timezone)
 	dwc := models.DocumentWithCompliance{
 		Document:    *doc,
 		DisplayName: compliance.DisplayName(doc.DocumentType),
@@ -209,6 +244,33 @@ func (h *DocumentHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Dependent document types are filed against one of the employee's
+	// dependents, employee types against the employee
+	if req.DependentID != nil {
+		req.DependentID = nilIfEmpty(strings.TrimSpace(*req.DependentID))
+	}
+	var appliesTo string
+	_ = pool.QueryRow(ctx, `SELECT applies_to FROM document_types WHERE doc_type = $1`, req.DocumentType).Scan(&appliesTo)
+	if req.DependentID != nil {
+		var ok bool
+		if err := pool.QueryRow(ctx, `
+			SELECT EXISTS(SELECT 1 FROM dependents WHERE id::text = $1 AND employee_id = $2)
+		`, *req.DependentID, employeeID).Scan(&ok); err != nil || !ok {
+			JSONError(w, http.StatusUnprocessableEntity, "Dependent not found for this employee")
+			return
+		}
+		if appliesTo != "dependent" {
+			JSONError(w, http.StatusUnprocessableEntity, "Document type does not apply to dependents")
+			return
+		}
+	} else if appliesTo == "dependent" {
+		JSONError(w, http.StatusUnprocessableEntity, "Dependent ID is required for this document type")
+		return
+	}
+
+	// Fill in a missing expiry from the standard validity, flag a likely typo
+	validity := checkDocumentValidity(loadDocumentValidity(ctx, pool, employeeID, req.DocumentType), req.IssueDate, &req.ExpiryDate)
+
 	// Default metadata to empty JSON object
 	metadata := req.Metadata
 	if len(metadata) == 0 {
@@ -219,15 +281,15 @@ func (h *DocumentHandler) Create(w http.ResponseWriter, r *http.Request) {
 	err := pool.QueryRow(ctx, fmt.Sprintf(`
 		INSERT INTO documents (
 			employee_id, document_type, document_number, issue_date, expiry_date,
-			metadata, file_url, file_name, file_size, file_type
+			metadata, file_url, file_name, file_size, file_type, dependent_id
 		)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
 		RETURNING %s
 	`, docRetCols),
 		employeeID, req.DocumentType,
 		req.DocumentNumber, req.IssueDate, req.ExpiryDate,
 		string(metadata),
-		req.FileURL, req.FileName, req.FileSize, req.FileType,
+		req.FileURL, req.FileName, req.FileSize, req.FileType, req.DependentID,
 	)
 	if err2 := scanDocument(err, &doc); err2 != nil {
 		log.Printf("Error creating document: %v", err2)
@@ -235,46 +297,31 @@ func (h *DocumentHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Populate IsMandatory from document_types
-	_ = pool.QueryRow(ctx,
-		`SELECT COALESCE((SELECT is_mandatory FROM document_types WHERE doc_type = $1 AND is_active = TRUE), FALSE)`,
-		doc.DocumentType,
-	).Scan(&doc.IsMandatory)
+	// Populate IsMandatory (document_types + requirement conditions for this employee)
+	doc.IsMandatory = docRequiredForDocument(ctx, pool, &doc)
+	refreshEmployeeStatus(ctx, pool, doc.EmployeeID)
 
 	// Audit trail
 	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
 	logActivity(pool, userID, "created", "document", doc.ID, map[string]interface{}{
-		"type": doc.DocumentType, "employeeId": employeeID,
+		"type": doc.DocumentType, "employeeId": employeeID, "dependentId": doc.DependentID,
 	})
 
 	// Fetch the effective compliance rule for this doc type
-	var rule ComplianceRule
-	_ = pool.QueryRow(ctx, `
-		SELECT COALESCE(cr.grace_period_days, gr.grace_period_days, 0),
-		       COALESCE(cr.fine_per_day, gr.fine_per_day, 0),
-		       COALESCE(cr.fine_type, gr.fine_type, 'daily'),
-		       COALESCE(cr.fine_cap, gr.fine_cap, 0)
-		FROM employees emp
-		LEFT JOIN compliance_rules cr ON cr.doc_type = $2 AND cr.company_id = emp.company_id
-		LEFT JOIN compliance_rules gr ON gr.doc_type = $2 AND gr.company_id IS NULL
-		WHERE emp.id = $1
-	`, employeeID, doc.DocumentType).Scan(&rule.GracePeriodDays, &rule.FinePerDay, &rule.FineType, &rule.FineCap)
-
-	var rulePtr *ComplianceRule
-	if rule.FinePerDay > 0 || rule.GracePeriodDays > 0 {
-		rulePtr = &rule
-	}
-
-	result := enrichWithCompliance(&doc, rulePtr)
+	rulePtr, timezone := loadDocumentRule(ctx, pool, &doc)
+	result := enrichWithCompliance(&doc, rulePtr, timezone)
 	JSON(w, http.StatusCreated, map[string]interface{}{
-		"data":    result,
-		"message": "Document created successfully",
+		"data":     result,
+		"validity": validity,
+		"message":  "Document created successfully",
 	})
 }
 
 // ── List by Employee ─────────────────────────────────────────────
 
 // ListByEmployee handles GET /api/employees/{id}/documents
+// Dependents' documents are included after the employee's own; ?dependentId=
+// narrows to one dependent, or "none" to the employee's own documents.
 func (h *DocumentHandler) ListByEmployee(w http.ResponseWriter, r *http.Request) {
 	employeeID := chi.URLParam(r, "id")
 	if employeeID == "" {
@@ -296,20 +343,18 @@ func (h *DocumentHandler) ListByEmployee(w http.ResponseWriter, r *http.Request)
 	pool := h.db.GetPool()
 
 	rows, err := pool.Query(ctx, fmt.Sprintf(`
-		SELECT %s,
-			COALESCE(cr.grace_period_days, gr.grace_period_days, 0),
-			COALESCE(cr.fine_per_day, gr.fine_per_day, 0),
-			COALESCE(cr.fine_type, gr.fine_type, 'daily'),
-			COALESCE(cr.fine_cap, gr.fine_cap, 0),
-			dt.is_mandatory
+		SELECT %s,`+documentRuleCols+`,
+			`+documentRequiredExpr+` AS is_required
 		FROM documents d
 		LEFT JOIN employees e ON d.employee_id = e.id
 		LEFT JOIN compliance_rules cr ON cr.doc_type = d.document_type AND cr.company_id = e.company_id
 		LEFT JOIN compliance_rules gr ON gr.doc_type = d.document_type AND gr.company_id IS NULL
 		LEFT JOIN document_types dt ON dt.doc_type = d.document_type AND dt.is_active = TRUE
 		WHERE d.employee_id = $1
-		ORDER BY COALESCE(dt.is_mandatory, FALSE) DESC, COALESCE(dt.sort_order, 100) ASC, d.created_at DESC
-	`, docCols), employeeID)
+		  AND ($2 = '' OR ($2 = 'none' AND d.dependent_id IS NULL) OR d.dependent_id::text = $2)
+		ORDER BY d.dependent_id IS NOT NULL, d.dependent_id, is_required DESC,
+			COALESCE(dt.sort_order, 100) ASC, d.created_at DESC
+	`, docCols), employeeID, r.URL.Query().Get("dependentId"))
 	if err != nil {
 		log.Printf("Error fetching documents: %v", err)
 		JSONError(w, http.StatusInternalServerError, "Failed to fetch documents")
@@ -321,8 +366,9 @@ func (h *DocumentHandler) ListByEmployee(w http.ResponseWriter, r *http.Request)
 	for rows.Next() {
 		var doc models.Document
 		var rule ComplianceRule
+		var timezone string
 		var dtMandatory *bool
-		if err := scanDocumentWithRule(rows, &doc, &rule, &dtMandatory); err != nil {
+		if err := scanDocumentWithRule(rows, &doc, &rule, &timezone, &dtMandatory); err != nil {
 			log.Printf("Error scanning document: %v", err)
 			continue
 		}
@@ -333,7 +379,7 @@ func (h *DocumentHandler) ListByEmployee(w http.ResponseWriter, r *http.Request)
 		if rule.FinePerDay > 0 || rule.GracePeriodDays > 0 {
 			rulePtr = &rule
 		}
-		documents = append(documents, enrichWithCompliance(&doc, rulePtr))
+		documents = append(documents, enrichWithCompliance(&doc, rulePtr, timezone))
 	}
 
 	mandatoryTotal := 0
@@ -377,24 +423,20 @@ func (h *DocumentHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	pool := h.db.GetPool()
 
 	row := pool.QueryRow(ctx, fmt.Sprintf(`
-		SELECT %s,
-			COALESCE(cr.grace_period_days, gr.grace_period_days, 0),
-			COALESCE(cr.fine_per_day, gr.fine_per_day, 0),
-			COALESCE(cr.fine_type, gr.fine_type, 'daily'),
-			COALESCE(cr.fine_cap, gr.fine_cap, 0),
-			COALESCE(dt.is_mandatory, FALSE),
+		SELECT %s,`+documentRuleCols+`,
+			`+documentRequiredExpr+`,
 			e.name AS employee_name, c.name AS company_name
 		FROM documents d
 		JOIN employees e ON d.employee_id = e.id
 		JOIN companies c ON e.company_id = c.id
 		LEFT JOIN compliance_rules cr ON cr.doc_type = d.document_type AND cr.company_id = e.company_id
 		LEFT JOIN compliance_rules gr ON gr.doc_type = d.document_type AND gr.company_id IS NULL
-		LEFT JOIN document_types dt ON dt.doc_type = d.document_type AND dt.is_active = TRUE
 		WHERE d.id = $1
 	`, docCols), id)
 
 	var doc models.Document
 	var rule ComplianceRule
+	var timezone string
 	var employeeName, companyName string
 
 	var issueDateRaw, expiryRaw, metadataRaw string
@@ -404,8 +446,8 @@ func (h *DocumentHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		&docNumber, &issueDateRaw, &expiryRaw,
 		&doc.IsPrimary, &metadataRaw,
 		&doc.FileURL, &doc.FileName, &doc.FileSize, &doc.FileType,
-		&doc.LastUpdated, &doc.CreatedAt,
-		&rule.GracePeriodDays, &rule.FinePerDay, &rule.FineType, &rule.FineCap,
+		&doc.LastUpdated, &doc.CreatedAt, &doc.DependentID,
+		&rule.GracePeriodDays, &rule.FinePerDay, &rule.FineType, &rule.FineCap, &timezone,
 		&doc.IsMandatory,
 		&employeeName, &companyName,
 	)
@@ -429,7 +471,7 @@ func (h *DocumentHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		rulePtr = &rule
 	}
 
-	result := enrichWithCompliance(&doc, rulePtr)
+	result := enrichWithCompliance(&doc, rulePtr, timezone)
 	JSON(w, http.StatusOK, map[string]interface{}{
 		"data": map[string]interface{}{
 			"document":     result,
@@ -531,7 +573,10 @@ func (h *DocumentHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req models.UpdateDocumentRequest
+	var req struct {
+		models.UpdateDocumentRequest
+		dependencyOverride
+	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
 		return
@@ -542,6 +587,51 @@ func (h *DocumentHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 	pool := h.db.GetPool()
 
+	// Dates against the standard validity: an issue date without any expiry
+	// derives one, an expiry far off the term is flagged
+	var validity *models.ValidityCheck
+	if req.IssueDate != nil || req.ExpiryDate != nil {
+		var employeeID, docType string
+		var issue, expiry *string
+		if err := pool.QueryRow(ctx, `
+			SELECT employee_id, document_type, issue_date::text, expiry_date::text FROM documents WHERE id = $1
+		`, id).Scan(&employeeID, &docType, &issue, &expiry); err != nil {
+			JSONError(w, http.StatusNotFound, "Document not found")
+			return
+		}
+		if req.DocumentType != nil {
+			docType = *req.DocumentType
+		}
+		if req.IssueDate != nil {
+			issue = req.IssueDate
+		}
+		if req.ExpiryDate != nil {
+			expiry = req.ExpiryDate
+		}
+		validity = checkDocumentValidity(loadDocumentValidity(ctx, pool, employeeID, docType), issue, &expiry)
+		if validity != nil && validity.Derived {
+			req.ExpiryDate = expiry
+		}
+	}
+
+	// A new expiry date is effectively a renewal in place — enforce dependencies
+	var overridden []models.DependencyViolation
+	if req.ExpiryDate != nil {
+		var employeeID, docType string
+		var dependentID *string
+		if err := pool.QueryRow(ctx, `SELECT employee_id, dependent_id::text, document_type FROM documents WHERE id = $1`, id).Scan(&employeeID, &dependentID, &docType); err != nil {
+			JSONError(w, http.StatusNotFound, "Document not found")
+			return
+		}
+		if req.DocumentType != nil {
+			docType = *req.DocumentType
+		}
+		var ok bool
+		if overridden, ok = enforceDocumentDependencies(w, r, pool, employeeID, dependentID, docType, req.dependencyOverride); !ok {
+			return
+		}
+	}
+
 	// Build dynamic SET clause
 	setClauses := []string{}
 	args := []interface{}{}
@@ -615,47 +705,56 @@ func (h *DocumentHandler) Update(w http.ResponseWriter, r *http.Request) {
 	`, setStr, argIdx, docRetCols)
 	args = append(args, id)
 
+	// Transaction: the update and its dependency override record
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback(ctx)
+
 	var doc models.Document
-	if err := scanDocument(pool.QueryRow(ctx, query, args...), &doc); err != nil {
+	if err := scanDocument(tx.QueryRow(ctx, query, args...), &doc); err != nil {
 		log.Printf("Error updating document %s: %v", id, err)
 		JSONError(w, http.StatusNotFound, "Document not found")
 		return
 	}
 
-	// Populate IsMandatory from document_types
-	_ = pool.QueryRow(ctx,
-		`SELECT COALESCE((SELECT is_mandatory FROM document_types WHERE doc_type = $1 AND is_active = TRUE), FALSE)`,
-		doc.DocumentType,
-	).Scan(&doc.IsMandatory)
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+	if len(overridden) > 0 {
+		if err := recordDependencyOverride(ctx, tx, userID, doc.ID, doc.EmployeeID, "update", req.OverrideReason, overridden); err != nil {
+			log.Printf("Error recording dependency override for document %s: %v", doc.ID, err)
+			JSONError(w, http.StatusInternalServerError, "Failed to record dependency override")
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to commit document update")
+		return
+	}
+
+	// Populate IsMandatory (document_types + requirement conditions for this employee)
+	doc.IsMandatory = docRequiredForDocument(ctx, pool, &doc)
+	refreshEmployeeStatus(ctx, pool, doc.EmployeeID)
 
 	// Audit trail
-	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
 	logActivity(pool, userID, "updated", "document", doc.ID, map[string]interface{}{
 		"type": doc.DocumentType,
 	})
-
-	// Fetch compliance rule for enrichment
-	var rule ComplianceRule
-	_ = pool.QueryRow(ctx, `
-		SELECT COALESCE(cr.grace_period_days, gr.grace_period_days, 0),
-		       COALESCE(cr.fine_per_day, gr.fine_per_day, 0),
-		       COALESCE(cr.fine_type, gr.fine_type, 'daily'),
-		       COALESCE(cr.fine_cap, gr.fine_cap, 0)
-		FROM employees emp
-		LEFT JOIN compliance_rules cr ON cr.doc_type = $2 AND cr.company_id = emp.company_id
-		LEFT JOIN compliance_rules gr ON gr.doc_type = $2 AND gr.company_id IS NULL
-		WHERE emp.id = $1
-	`, doc.EmployeeID, doc.DocumentType).Scan(&rule.GracePeriodDays, &rule.FinePerDay, &rule.FineType, &rule.FineCap)
-
-	var rulePtr *ComplianceRule
-	if rule.FinePerDay > 0 || rule.GracePeriodDays > 0 {
-		rulePtr = &rule
+	if len(overridden) > 0 {
+		logActivity(pool, userID, "dependency_override", "document", doc.ID, map[string]interface{}{
+			"action": "update", "reason": req.OverrideReason, "violations": overridden,
+		})
 	}
 
-	result := enrichWithCompliance(&doc, rulePtr)
+	// Fetch compliance rule for enrichment
+	rulePtr, timezone := loadDocumentRule(ctx, pool, &doc)
+	result := enrichWithCompliance(&doc, rulePtr, timezone)
 	JSON(w, http.StatusOK, map[string]interface{}{
-		"data":    result,
-		"message": "Document updated successfully",
+		"data":     result,
+		"validity": validity,
+		"message":  "Document updated successfully",
 	})
 }
 
@@ -728,17 +827,18 @@ func (h *DocumentHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	pool := h.db.GetPool()
 
-	tag, err := pool.Exec(ctx, "DELETE FROM documents WHERE id = $1", id)
+	var employeeID string
+	err := pool.QueryRow(ctx, "DELETE FROM documents WHERE id = $1 RETURNING employee_id", id).Scan(&employeeID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Document not found")
+		return
+	}
 	if err != nil {
 		log.Printf("Error deleting document %s: %v", id, err)
 		JSONError(w, http.StatusInternalServerError, "Failed to delete document")
 		return
 	}
-
-	if tag.RowsAffected() == 0 {
-		JSONError(w, http.StatusNotFound, "Document not found")
-		return
-	}
+	refreshEmployeeStatus(ctx, pool, employeeID)
 
 	// Audit trail
 	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
@@ -771,12 +871,33 @@ func (h *DocumentHandler) BatchDelete(w http.ResponseWriter, r *http.Request) {
 
 	pool := h.db.GetPool()
 
-	tag, err := pool.Exec(ctx, "DELETE FROM documents WHERE id = ANY($1::uuid[])", req.IDs)
+	rows, err := pool.Query(ctx, "DELETE FROM documents WHERE id = ANY($1::uuid[]) RETURNING employee_id", req.IDs)
 	if err != nil {
 		log.Printf("Error batch deleting documents: %v", err)
 		JSONError(w, http.StatusInternalServerError, "Failed to delete documents")
 		return
 	}
+	deleted := 0
+	employeeIDs := []string{}
+	seen := map[string]bool{}
+	for rows.Next() {
+		var employeeID string
+		if err := rows.Scan(&employeeID); err != nil {
+			continue
+		}
+		deleted++
+		if !seen[employeeID] {
+			seen[employeeID] = true
+			employeeIDs = append(employeeIDs, employeeID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		log.Printf("Error batch deleting documents: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to delete documents")
+		return
+	}
+	refreshEmployeeStatus(ctx, pool, employeeIDs...)
 
 	// Audit trail
 	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
@@ -785,8 +906,8 @@ func (h *DocumentHandler) BatchDelete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	JSON(w, http.StatusOK, map[string]interface{}{
-		"message": fmt.Sprintf("%d document(s) deleted successfully", tag.RowsAffected()),
-		"deleted": tag.RowsAffected(),
+		"message": fmt.Sprintf("%d document(s) deleted successfully", deleted),
+		"deleted": deleted,
 	})
 }
 
@@ -809,21 +930,19 @@ func (h *DocumentHandler) Renew(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		DocumentNumber *string         `json:"documentNumber,omitempty"`
 		IssueDate      *string         `json:"issueDate,omitempty"`
-		ExpiryDate     string          `json:"expiryDate"`
+		ExpiryDate     string          `json:"expiryDate"` // derived from issueDate when empty and the type has a standard validity
 		Metadata       json.RawMessage `json:"metadata,omitempty"`
 		FileURL        string          `json:"fileUrl,omitempty"`
 		FileName       string          `json:"fileName,omitempty"`
 		FileSize       int64           `json:"fileSize,omitempty"`
 		FileType       string          `json:"fileType,omitempty"`
+		SkipLinked     bool            `json:"skipLinked,omitempty"` // propose auto links instead of renewing them
+		dependencyOverride
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
 		return
 	}
-	if req.ExpiryDate == "" {
-		JSONError(w, http.StatusUnprocessableEntity, "New expiry date is required")
-		return
-	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
@@ -838,6 +957,25 @@ func (h *DocumentHandler) Renew(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Without an expiry the new issue date plus the standard validity is used;
+	// the old issue date would only reproduce the old expiry
+	expiry := nilIfEmpty(req.ExpiryDate)
+	var validity *models.ValidityCheck
+	if req.IssueDate != nil {
+		validity = checkDocumentValidity(loadDocumentValidity(ctx, pool, oldDoc.EmployeeID, oldDoc.DocumentType), req.IssueDate, &expiry)
+	}
+	if expiry == nil {
+		JSONError(w, http.StatusUnprocessableEntity, "New expiry date is required")
+		return
+	}
+	req.ExpiryDate = *expiry
+
+	// Blocking documents (e.g. passport for a visa) must meet their minimum validity
+	overridden, ok := enforceDocumentDependencies(w, r, pool, oldDoc.EmployeeID, oldDoc.DependentID, oldDoc.DocumentType, req.dependencyOverride)
+	if !ok {
+		return
+	}
+
 	// Use new values if provided, otherwise keep old ones
 	docNumber := oldDoc.DocumentNumber
 	if req.DocumentNumber != nil {
@@ -881,15 +1019,15 @@ func (h *DocumentHandler) Renew(w http.ResponseWriter, r *http.Request) {
 		INSERT INTO documents (
 			employee_id, document_type, document_number, issue_date, expiry_date,
 			is_primary, metadata,
-			file_url, file_name, file_size, file_type
+			file_url, file_name, file_size, file_type, dependent_id
 		)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
 		RETURNING %s
 	`, docRetCols),
 		oldDoc.EmployeeID, oldDoc.DocumentType,
 		docNumber, issueDate, req.ExpiryDate,
 		oldDoc.IsPrimary, string(metadata),
-		fileURL, fileName, fileSize, fileType,
+		fileURL, fileName, fileSize, fileType, oldDoc.DependentID,
 	)
 
 	if err := scanDocument(newRow, &newDoc); err != nil {
@@ -904,45 +1042,76 @@ func (h *DocumentHandler) Renew(w http.ResponseWriter, r *http.Request) {
 		log.Printf("Error archiving old document: %v", err)
 	}
 
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+	if len(overridden) > 0 {
+		if err := recordDependencyOverride(ctx, tx, userID, newDoc.ID, newDoc.EmployeeID, "renew", req.OverrideReason, overridden); err != nil {
+			log.Printf("Error recording dependency override for document %s: %v", newDoc.ID, err)
+			JSONError(w, http.StatusInternalServerError, "Failed to record dependency override")
+			return
+		}
+	}
+
+	// The renewal completes the document's open renewal case
+	caseID, err := closeRenewalCase(ctx, tx, oldID, newDoc.ID, userID)
+	if err != nil {
+		log.Printf("Error closing renewal case: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to close renewal case")
+		return
+	}
+
+	// Linked documents (e.g. Emirates ID with the visa): auto links renew
+	// in this transaction, the rest come back as proposals
+	var companyID string
+	if err := tx.QueryRow(ctx, `SELECT company_id::text FROM employees WHERE id = $1`, newDoc.EmployeeID).Scan(&companyID); err != nil {
+		log.Printf("Error loading employee company for linked renewals: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to renew linked documents")
+		return
+	}
+	linked, err := propagateLinkedRenewals(ctx, tx, &newDoc, companyID, req.SkipLinked)
+	if err != nil {
+		log.Printf("Error renewing linked documents: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to renew linked documents")
+		return
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		JSONError(w, http.StatusInternalServerError, "Failed to commit renewal")
 		return
 	}
 
-	// Populate IsMandatory from document_types
-	_ = pool.QueryRow(ctx,
-		`SELECT COALESCE((SELECT is_mandatory FROM document_types WHERE doc_type = $1 AND is_active = TRUE), FALSE)`,
-		newDoc.DocumentType,
-	).Scan(&newDoc.IsMandatory)
+	// Populate IsMandatory (document_types + requirement conditions for this employee)
+	newDoc.IsMandatory = docRequiredForDocument(ctx, pool, &newDoc)
+	refreshEmployeeStatus(ctx, pool, newDoc.EmployeeID)
 
 	// Audit trail
-	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
 	logActivity(pool, userID, "renewed", "document", newDoc.ID, map[string]interface{}{
 		"previousDocId": oldID, "type": oldDoc.DocumentType, "newExpiry": req.ExpiryDate,
+		"linked": linked, "renewalCaseId": nilIfEmpty(caseID),
 	})
-
-	// Fetch compliance rule for enrichment
-	var rule ComplianceRule
-	_ = pool.QueryRow(ctx, `
-		SELECT COALESCE(cr.grace_period_days, gr.grace_period_days, 0),
-		       COALESCE(cr.fine_per_day, gr.fine_per_day, 0),
-		       COALESCE(cr.fine_type, gr.fine_type, 'daily'),
-		       COALESCE(cr.fine_cap, gr.fine_cap, 0)
-		FROM employees emp
-		LEFT JOIN compliance_rules cr ON cr.doc_type = $2 AND cr.company_id = emp.company_id
-		LEFT JOIN compliance_rules gr ON gr.doc_type = $2 AND gr.company_id IS NULL
-		WHERE emp.id = $1
-	`, newDoc.EmployeeID, newDoc.DocumentType).Scan(&rule.GracePeriodDays, &rule.FinePerDay, &rule.FineType, &rule.FineCap)
-
-	var rulePtr *ComplianceRule
-	if rule.FinePerDay > 0 || rule.GracePeriodDays > 0 {
-		rulePtr = &rule
+	for _, lr := range linked {
+		if lr.NewDocumentID == nil {
+			continue
+		}
+		logActivity(pool, userID, "linked_renewal", "document", *lr.NewDocumentID, map[string]interface{}{
+			"sourceDocId": newDoc.ID, "sourceType": newDoc.DocumentType, "linkId": lr.LinkID,
+			"previousDocId": lr.DocumentID, "type": lr.DocumentType,
+			"previousExpiry": lr.CurrentExpiry, "newIssueDate": lr.IssueDate, "newExpiry": lr.ExpiryDate,
+		})
+	}
+	if len(overridden) > 0 {
+		logActivity(pool, userID, "dependency_override", "document", newDoc.ID, map[string]interface{}{
+			"action": "renew", "reason": req.OverrideReason, "violations": overridden,
+		})
 	}
 
-	result := enrichWithCompliance(&newDoc, rulePtr)
+	// Fetch compliance rule for enrichment
+	rulePtr, timezone := loadDocumentRule(ctx, pool, &newDoc)
+	result := enrichWithCompliance(&newDoc, rulePtr, timezone)
 	JSON(w, http.StatusCreated, map[string]interface{}{
-		"data":    result,
-		"message": "Document renewed successfully",
+		"data":     result,
+		"linked":   linked,
+		"validity": validity,
+		"message":  "Document renewed successfully",
 	})
 }
 
@@ -0,0 +1,350 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"manpower-backend/internal/compliance"
+	"manpower-backend/internal/ctxkeys"
+	"manpower-backend/internal/models"
+)
+
+// ── Linked Expiry Rules ──────────────────────────────────────
+// Links between document types (migration 021). On renewal of the source,
+// Renew proposes — or for action "auto" creates — the renewal of the target.
+
+const documentLinkCols = `id, company_id, source_doc_type, target_doc_type, mode,
+	offset_days, validity_months, validity_days, action, is_active, description,
+	created_at::text, updated_at::text`
+
+func scanDocumentLink(scanner interface {
+	Scan(dest ...interface{}) error
+}, l *models.DocumentLink) error {
+	return scanner.Scan(
+		&l.ID, &l.CompanyID, &l.SourceDocType, &l.TargetDocType, &l.Mode,
+		&l.OffsetDays, &l.ValidityMonths, &l.ValidityDays, &l.Action, &l.IsActive, &l.Description,
+		&l.CreatedAt, &l.UpdatedAt,
+	)
+}
+
+// ListDocumentLinks handles GET /api/admin/document-links?company_id=&source_doc_type=
+// With company_id, returns the links in force for that company (company
+// rows replacing global rows, inactive ones included).
+func (h *AdminHandler) ListDocumentLinks(w http.ResponseWriter, r *http.Request) {
+	companyID := r.URL.Query().Get("company_id")
+	sourceDocType := r.URL.Query().Get("source_doc_type")
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	var links []models.DocumentLink
+	var err error
+	if companyID != "" {
+		links, err = effectiveDocumentLinks(ctx, pool, companyID, sourceDocType)
+	} else {
+		links, err = listDocumentLinks(ctx, pool, sourceDocType)
+	}
+	if err != nil {
+		log.Printf("Failed to list document links: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch document links")
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{"data": links})
+}
+
+func listDocumentLinks(ctx context.Context, q querier, sourceDocType string) ([]models.DocumentLink, error) {
+	query := `SELECT ` + documentLinkCols + ` FROM document_links WHERE ($1 = '' OR source_doc_type = $1)
+		ORDER BY source_doc_type, target_doc_type, company_id NULLS FIRST`
+	rows, err := q.Query(ctx, query, sourceDocType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []models.DocumentLink{}
+	for rows.Next() {
+		var l models.DocumentLink
+		if err := scanDocumentLink(rows, &l); err != nil {
+			return nil, err
+		}
+		links = append(links, l)
+	}
+	return links, rows.Err()
+}
+
+// effectiveDocumentLinks returns the links in force for a company: per
+// source/target pair the company row if any, else the global row.
+func effectiveDocumentLinks(ctx context.Context, q querier, companyID, sourceDocType string) ([]models.DocumentLink, error) {
+	rows, err := q.Query(ctx, `
+		SELECT DISTINCT ON (source_doc_type, target_doc_type) `+documentLinkCols+`
+		FROM document_links
+		WHERE (company_id::text = $1 OR company_id IS NULL)
+		  AND ($2 = '' OR source_doc_type = $2)
+		ORDER BY source_doc_type, target_doc_type, company_id NULLS LAST
+	`, companyID, sourceDocType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []models.DocumentLink{}
+	for rows.Next() {
+		var l models.DocumentLink
+		if err := scanDocumentLink(rows, &l); err != nil {
+			return nil, err
+		}
+		links = append(links, l)
+	}
+	return links, rows.Err()
+}
+
+// CreateDocumentLink handles POST /api/admin/document-links (admin-only).
+func (h *AdminHandler) CreateDocumentLink(w http.ResponseWriter, r *http.Request) {
+	h.saveDocumentLink(w, r, "")
+}
+
+// UpdateDocumentLink handles PUT /api/admin/document-links/{id} (admin-only).
+func (h *AdminHandler) UpdateDocumentLink(w http.ResponseWriter, r *http.Request) {
+	h.saveDocumentLink(w, r, chi.URLParam(r, "id"))
+}
+
+// saveDocumentLink creates (id == "") or replaces a link.
+func (h *AdminHandler) saveDocumentLink(w http.ResponseWriter, r *http.Request, id string) {
+	var req models.DocumentLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	if errs := req.Validate(); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	// Both doc types must exist
+	var known int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM document_types WHERE doc_type IN ($1, $2)`,
+		req.SourceDocType, req.TargetDocType).Scan(&known); err != nil || known < 2 {
+		JSONError(w, http.StatusUnprocessableEntity, "Unknown document type")
+		return
+	}
+	if req.CompanyID != nil {
+		var exists bool
+		_ = pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM companies WHERE id::text = $1)`, *req.CompanyID).Scan(&exists)
+		if !exists {
+			JSONError(w, http.StatusUnprocessableEntity, "Company not found")
+			return
+		}
+	}
+
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+	args := []interface{}{
+		req.CompanyID, req.SourceDocType, req.TargetDocType, req.Mode,
+		req.OffsetDays, req.ValidityMonths, req.ValidityDays, req.Action, isActive, req.Description,
+	}
+
+	var l models.DocumentLink
+	var err error
+	action := "created"
+	if id == "" {
+		err = scanDocumentLink(pool.QueryRow(ctx, `
+			INSERT INTO document_links (
+				company_id, source_doc_type, target_doc_type, mode,
+				offset_days, validity_months, validity_days, action, is_active, description
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING `+documentLinkCols, args...), &l)
+	} else {
+		action = "updated"
+		err = scanDocumentLink(pool.QueryRow(ctx, `
+			UPDATE document_links SET
+				company_id = $1, source_doc_type = $2, target_doc_type = $3, mode = $4,
+				offset_days = $5, validity_months = $6, validity_days = $7, action = $8,
+				is_active = $9, description = $10, updated_at = NOW()
+			WHERE id::text = $11
+			RETURNING `+documentLinkCols, append(args, id)...), &l)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Document link not found")
+		return
+	}
+	if isDuplicateKeyError(err) {
+		JSONError(w, http.StatusConflict, "A link between these document types already exists for this scope")
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to save document link: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to save document link")
+		return
+	}
+
+	go logActivity(pool, userID, action, "document_link", l.ID, map[string]interface{}{
+		"source": l.SourceDocType, "target": l.TargetDocType, "mode": l.Mode,
+		"action": l.Action, "isActive": l.IsActive, "companyId": l.CompanyID,
+	})
+
+	status := http.StatusOK
+	if id == "" {
+		status = http.StatusCreated
+	}
+	JSON(w, status, map[string]interface{}{
+		"data":    l,
+		"message": "Document link " + action,
+	})
+}
+
+// DeleteDocumentLink handles DELETE /api/admin/document-links/{id} (admin-only).
+func (h *AdminHandler) DeleteDocumentLink(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	tag, err := pool.Exec(ctx, `DELETE FROM document_links WHERE id::text = $1`, id)
+	if err != nil {
+		log.Printf("Failed to delete document link: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to delete document link")
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		JSONError(w, http.StatusNotFound, "Document link not found")
+		return
+	}
+
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+	go logActivity(pool, userID, "deleted", "document_link", id, nil)
+
+	JSON(w, http.StatusOK, map[string]interface{}{"message": "Document link deleted"})
+}
+
+// ── Propagation on Renew ─────────────────────────────────────
+
+// propagateLinkedRenewals applies the links of a renewed document inside
+// the renewal transaction. Each linked document of the same holder (the
+// employee, or the same dependent) — the primary one, else the latest — gets
+// dates from compliance.LinkedDates; it is renewed when the link's action is
+// "auto" (unless skipAuto), proposed otherwise, and reported as up to date
+// when it already runs as long.
+func propagateLinkedRenewals(ctx context.Context, q querier, source *models.Document, companyID string, skipAuto bool) ([]models.LinkedRenewal, error) {
+	links, err := effectiveDocumentLinks(ctx, q, companyID, source.DocumentType)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceExpiry, err := time.Parse("2006-01-02", *source.ExpiryDate)
+	if err != nil {
+		return nil, err
+	}
+	var sourceIssue time.Time
+	if source.IssueDate != nil && *source.IssueDate != "" {
+		sourceIssue, _ = time.Parse("2006-01-02", *source.IssueDate)
+	}
+	if sourceIssue.IsZero() {
+		var timezone string
+		_ = q.QueryRow(ctx, `SELECT timezone FROM companies WHERE id::text = $1`, companyID).Scan(&timezone)
+		sourceIssue = compliance.Today(time.Now(), timezone)
+	}
+
+	results := []models.LinkedRenewal{}
+	for _, link := range links {
+		if !link.IsActive {
+			continue
+		}
+
+		var target models.Document
+		err := scanDocument(q.QueryRow(ctx, fmt.Sprintf(`
+			SELECT %s FROM documents d
+			WHERE d.employee_id = $1 AND d.document_type = $2
+			  AND d.dependent_id IS NOT DISTINCT FROM $3::uuid
+			ORDER BY d.is_primary DESC, d.expiry_date DESC NULLS LAST, d.created_at DESC
+			LIMIT 1
+		`, docCols), source.EmployeeID, link.TargetDocType, source.DependentID), &target)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue // the employee has no such document
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		issue, expiry := compliance.LinkedDates(compliance.ExpiryLink{
+			Mode: link.Mode, OffsetDays: link.OffsetDays,
+			ValidityMonths: link.ValidityMonths, ValidityDays: link.ValidityDays,
+		}, sourceIssue, sourceExpiry)
+
+		lr := models.LinkedRenewal{
+			LinkID:        link.ID,
+			DocumentID:    target.ID,
+			DocumentType:  target.DocumentType,
+			CurrentExpiry: target.ExpiryDate,
+			IssueDate:     issue.Format("2006-01-02"),
+			ExpiryDate:    expiry.Format("2006-01-02"),
+			Result:        "proposed",
+		}
+
+		switch {
+		case target.ExpiryDate != nil && *target.ExpiryDate >= lr.ExpiryDate:
+			lr.Result = "up_to_date"
+		case link.Action == compliance.LinkActionAuto && !skipAuto:
+			newID, err := renewLinkedDocument(ctx, q, target.ID, lr.IssueDate, lr.ExpiryDate)
+			if err != nil {
+				return nil, err
+			}
+			lr.Result = "renewed"
+			lr.NewDocumentID = &newID
+		}
+		results = append(results, lr)
+	}
+	return results, nil
+}
+
+// renewLinkedDocument renews a document with new dates, keeping its number,
+// metadata and file, and archives the old one — like Renew without a body.
+func renewLinkedDocument(ctx context.Context, q querier, oldID, issueDate, expiryDate string) (string, error) {
+	var newID string
+	err := q.QueryRow(ctx, `
+		INSERT INTO documents (
+			employee_id, dependent_id, document_type, document_number, issue_date, expiry_date,
+			is_primary, metadata,
+			file_url, file_name, file_size, file_type
+		)
+		SELECT employee_id, dependent_id, document_type, document_number, $2::date, $3::date,
+			is_primary, metadata,
+			file_url, file_name, file_size, file_type
+		FROM documents WHERE id = $1
+		RETURNING id
+	`, oldID, issueDate, expiryDate).Scan(&newID)
+	if err != nil {
+		return "", err
+	}
+	if _, err := q.Exec(ctx, `UPDATE documents SET is_primary = FALSE WHERE id = $1`, oldID); err != nil {
+		return "", err
+	}
+	if _, err := closeRenewalCase(ctx, q, oldID, newID, ""); err != nil {
+		return "", err
+	}
+	return newID, nil
+}
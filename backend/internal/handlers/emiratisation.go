@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"manpower-backend/internal/compliance"
+	"manpower-backend/internal/ctxkeys"
+	"manpower-backend/internal/emiratisation"
+	"manpower-backend/internal/models"
+)
+
+// ── Emiratisation Targets ────────────────────────────────────
+// Targets per checkpoint date (migration 033). Company rows replace the
+// global row for the same date; see the emiratisation package for how
+// headcount is projected onto them.
+
+const emiratisationTargetCols = `id, company_id, checkpoint_date::text, target_percent::float8,
+	min_headcount, min_workforce, fine_per_missing::float8, description,
+	created_at::text, updated_at::text`
+
+func scanEmiratisationTarget(row pgx.Row, t *models.EmiratisationTarget) error {
+	return row.Scan(
+		&t.ID, &t.CompanyID, &t.CheckpointDate, &t.TargetPercent,
+		&t.MinHeadcount, &t.MinWorkforce, &t.FinePerMissing, &t.Description,
+		&t.CreatedAt, &t.UpdatedAt,
+	)
+}
+
+// ListEmiratisationTargets handles GET /api/admin/emiratisation-targets?company_id=&year=
+// With company_id, returns the global targets plus that company's own.
+func (h *AdminHandler) ListEmiratisationTargets(w http.ResponseWriter, r *http.Request) {
+	companyID := r.URL.Query().Get("company_id")
+	year := r.URL.Query().Get("year")
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + emiratisationTargetCols + ` FROM emiratisation_targets WHERE 1=1`
+	args := []interface{}{}
+	if companyID != "" {
+		args = append(args, companyID)
+		query += fmt.Sprintf(` AND (company_id IS NULL OR company_id::text = $%d)`, len(args))
+	}
+	if year != "" {
+		args = append(args, year)
+		query += fmt.Sprintf(` AND EXTRACT(YEAR FROM checkpoint_date)::text = $%d`, len(args))
+	}
+	query += ` ORDER BY checkpoint_date, company_id NULLS FIRST`
+
+	rows, err := h.db.GetPool().Query(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to list Emiratisation targets: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch Emiratisation targets")
+		return
+	}
+	defer rows.Close()
+
+	targets := []models.EmiratisationTarget{}
+	for rows.Next() {
+		var t models.EmiratisationTarget
+		if err := scanEmiratisationTarget(rows, &t); err != nil {
+			log.Printf("Failed to scan Emiratisation target: %v", err)
+			continue
+		}
+		targets = append(targets, t)
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{"data": targets})
+}
+
+// CreateEmiratisationTarget handles POST /api/admin/emiratisation-targets (admin-only).
+func (h *AdminHandler) CreateEmiratisationTarget(w http.ResponseWriter, r *http.Request) {
+	h.saveEmiratisationTarget(w, r, "")
+}
+
+// UpdateEmiratisationTarget handles PUT /api/admin/emiratisation-targets/{id} (admin-only).
+func (h *AdminHandler) UpdateEmiratisationTarget(w http.ResponseWriter, r *http.Request) {
+	h.saveEmiratisationTarget(w, r, chi.URLParam(r, "id"))
+}
+
+// saveEmiratisationTarget creates (id == "") or replaces a target. Each scope
+// has at most one target per checkpoint date.
+func (h *AdminHandler) saveEmiratisationTarget(w http.ResponseWriter, r *http.Request, id string) {
+	var req models.EmiratisationTargetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	if errs := req.Validate(); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	if req.CompanyID != nil {
+		var exists bool
+		_ = pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM companies WHERE id::text = $1)`, *req.CompanyID).Scan(&exists)
+		if !exists {
+			JSONError(w, http.StatusUnprocessableEntity, "Company not found")
+			return
+		}
+	}
+
+	var t models.EmiratisationTarget
+	var err error
+	action := "created"
+	if id == "" {
+		err = scanEmiratisationTarget(pool.QueryRow(ctx, `
+			INSERT INTO emiratisation_targets (company_id, checkpoint_date, target_percent,
+			    min_headcount, min_workforce, fine_per_missing, description)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING `+emiratisationTargetCols,
+			req.CompanyID, req.CheckpointDate, req.TargetPercent,
+			req.MinHeadcount, *req.MinWorkforce, req.FinePerMissing, req.Description,
+		), &t)
+	} else {
+		action = "updated"
+		err = scanEmiratisationTarget(pool.QueryRow(ctx, `
+			UPDATE emiratisation_targets SET
+				company_id = $1, checkpoint_date = $2, target_percent = $3,
+				min_headcount = $4, min_workforce = $5, fine_per_missing = $6, description = $7,
+				updated_at = NOW()
+			WHERE id = $8
+			RETURNING `+emiratisationTargetCols,
+			req.CompanyID, req.CheckpointDate, req.TargetPercent,
+			req.MinHeadcount, *req.MinWorkforce, req.FinePerMissing, req.Description, id,
+		), &t)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Emiratisation target not found")
+		return
+	}
+	if isDuplicateKeyError(err) {
+		JSONError(w, http.StatusConflict, "A target for this checkpoint date already exists")
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to save Emiratisation target: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to save Emiratisation target")
+		return
+	}
+
+	go logActivity(pool, userID, action, "emiratisation_target", t.ID, map[string]interface{}{
+		"checkpointDate": t.CheckpointDate, "targetPercent": t.TargetPercent,
+		"minHeadcount": t.MinHeadcount, "companyId": t.CompanyID,
+	})
+
+	status := http.StatusOK
+	if id == "" {
+		status = http.StatusCreated
+	}
+	JSON(w, status, map[string]interface{}{
+		"data":    t,
+		"message": "Emiratisation target " + action,
+	})
+}
+
+// DeleteEmiratisationTarget handles DELETE /api/admin/emiratisation-targets/{id} (admin-only).
+func (h *AdminHandler) DeleteEmiratisationTarget(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	tag, err := pool.Exec(ctx, `DELETE FROM emiratisation_targets WHERE id = $1`, id)
+	if err != nil {
+		log.Printf("Failed to delete Emiratisation target: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to delete Emiratisation target")
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		JSONError(w, http.StatusNotFound, "Emiratisation target not found")
+		return
+	}
+
+	go logActivity(pool, userID, "deleted", "emiratisation_target", id, nil)
+
+	JSON(w, http.StatusOK, map[string]interface{}{"message": "Emiratisation target deleted"})
+}
+
+// ── Emiratisation Report ─────────────────────────────────────
+
+// GetEmiratisation handles GET /api/dashboard/emiratisation?company_id=
+// Lists each company's workforce and UAE nationals against its upcoming
+// checkpoints, with the shortfall and fine projected from today's
+// headcount. The summary totals each company's next checkpoint.
+func (h *DashboardHandler) GetEmiratisation(w http.ResponseWriter, r *http.Request) {
+	companyID := r.URL.Query().Get("company_id")
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	ids := ctxkeys.GetCompanyScope(ctx)
+	if companyID != "" {
+		if !checkCompanyAccess(ctx, companyID) {
+			JSONError(w, http.StatusForbidden, "Access denied to this company")
+			return
+		}
+		ids = []string{companyID}
+	}
+
+	companies, err := emiratisation.Load(ctx, h.db.GetPool(), ids)
+	if err != nil {
+		log.Printf("Error computing Emiratisation quota: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to compute Emiratisation quota")
+		return
+	}
+
+	data := make([]models.EmiratisationCompany, 0, len(companies))
+	summary := models.EmiratisationSummary{Companies: len(companies)}
+	for i := range companies {
+		c := &companies[i]
+		out := models.EmiratisationCompany{
+			CompanyID:           c.CompanyID,
+			CompanyName:         c.CompanyName,
+			RegulatoryAuthority: c.Authority,
+			Subject:             c.Subject,
+			Workforce:           c.Workforce,
+			Nationals:           c.Nationals,
+			Status:              compliance.StatusNone,
+			Checkpoints:         make([]models.EmiratisationCheckpoint, 0, len(c.Checkpoints)),
+		}
+		if c.Workforce > 0 {
+			out.NationalsPercent = math.Round(float64(c.Nationals)/float64(c.Workforce)*10000) / 100
+		}
+		for _, cp := range c.Checkpoints {
+			out.Checkpoints = append(out.Checkpoints, models.EmiratisationCheckpoint{
+				TargetID:        cp.TargetID,
+				CheckpointDate:  cp.Target.Checkpoint.Format("2006-01-02"),
+				Description:     cp.Description,
+				CompanySpecific: cp.CompanySpecific,
+				TargetPercent:   cp.Target.TargetPercent,
+				MinHeadcount:    cp.Target.MinHeadcount,
+				MinWorkforce:    cp.Target.MinWorkforce,
+				FinePerMissing:  cp.Target.FinePerMissing,
+				Status:          cp.Result.Status,
+				Required:        cp.Result.Required,
+				Missing:         cp.Result.Missing,
+				HiresNeeded:     cp.Result.HiresNeeded,
+				ProjectedFine:   cp.Result.ProjectedFine,
+				DaysUntil:       cp.Result.DaysUntil,
+			})
+		}
+
+		if len(out.Checkpoints) > 0 {
+			next := out.Checkpoints[0]
+			out.NextCheckpoint = &next
+			out.Status = next.Status
+			if summary.NextCheckpoint == nil || next.CheckpointDate < *summary.NextCheckpoint {
+				summary.NextCheckpoint = &next.CheckpointDate
+			}
+			if next.Status != compliance.QuotaExempt {
+				summary.Subject++
+			}
+			if next.Status == compliance.QuotaShortfall {
+				summary.Shortfall++
+				summary.Missing += next.Missing
+				summary.HiresNeeded += next.HiresNeeded
+				summary.ProjectedFine += next.ProjectedFine
+			}
+		}
+		data = append(data, out)
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"data":    data,
+		"summary": summary,
+	})
+}
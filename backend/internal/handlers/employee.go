@@ -13,7 +13,6 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
-	"manpower-backend/internal/compliance"
 	"manpower-backend/internal/ctxkeys"
 	"manpower-backend/internal/database"
 	"manpower-backend/internal/models"
@@ -36,7 +35,7 @@ const employeeCols = `e.id, e.company_id, e.name, e.trade, e.mobile,
 	e.joining_date::text, e.photo_url,
 	e.gender, e.date_of_birth::text, e.nationality, e.passport_number,
 	e.native_location, e.current_location, e.salary, e.status,
-	e.exit_type, e.exit_date::text, e.exit_notes,
+	e.exit_type, e.exit_date::text, e.exit_notes, e.custom_fields,
 	e.created_at, e.updated_at`
 
 // Unaliased version (for INSERT/UPDATE RETURNING):
@@ -44,7 +43,7 @@ const employeeRetCols = `id, company_id, name, trade, mobile,
 	joining_date::text, photo_url,
 	gender, date_of_birth::text, nationality, passport_number,
 	native_location, current_location, salary, status,
-	exit_type, exit_date::text, exit_notes,
+	exit_type, exit_date::text, exit_notes, custom_fields,
 	created_at, updated_at`
 
 // ── Scan Helpers ───────────────────────────────────────────────
@@ -57,7 +56,7 @@ func scanEmployee(scanner interface {
 		&emp.JoiningDate, &emp.PhotoURL,
 		&emp.Gender, &emp.DateOfBirth, &emp.Nationality, &emp.PassportNumber,
 		&emp.NativeLocation, &emp.CurrentLocation, &emp.Salary, &emp.Status,
-		&emp.ExitType, &emp.ExitDate, &emp.ExitNotes,
+		&emp.ExitType, &emp.ExitDate, &emp.ExitNotes, &emp.CustomFields,
 		&emp.CreatedAt, &emp.UpdatedAt,
 	)
 }
@@ -70,12 +69,12 @@ func scanEmployeeWithCompany(scanner interface {
 		&emp.JoiningDate, &emp.PhotoURL,
 		&emp.Gender, &emp.DateOfBirth, &emp.Nationality, &emp.PassportNumber,
 		&emp.NativeLocation, &emp.CurrentLocation, &emp.Salary, &emp.Status,
-		&emp.ExitType, &emp.ExitDate, &emp.ExitNotes,
+		&emp.ExitType, &emp.ExitDate, &emp.ExitNotes, &emp.CustomFields,
 		&emp.CreatedAt, &emp.UpdatedAt,
 		&emp.CompanyName, &emp.CompanyCurrency,
 		&emp.ComplianceStatus, &emp.NearestExpiryDays,
 		&emp.DocsComplete, &emp.DocsTotal, &emp.UrgentDocType,
-		&emp.ExpiredCount, &emp.ExpiringCount,
+		&emp.ExpiredCount, &emp.ExpiringCount, &emp.ComplianceScore,
 	)
 }
 
@@ -83,7 +82,7 @@ func scanEmployeeWithCompany(scanner interface {
 
 // Create handles POST /api/employees
 // After inserting the employee, it auto-creates mandatory document
-// slots (from document_types table) so that compliance posture is immediately visible.
+// slots (document_types + requirement conditions) so that compliance posture is immediately visible.
 func (h *EmployeeHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateEmployeeRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -114,6 +113,20 @@ func (h *EmployeeHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	pool := h.db.GetPool()
 
+	defs, err := loadCustomFieldDefs(ctx, pool, req.CompanyID)
+	if err != nil {
+		log.Printf("Error loading custom fields for company %s: %v", req.CompanyID, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to create employee")
+		return
+	}
+	if errs := req.ValidateCustomFields(defs); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
 	// Use a transaction: insert employee + mandatory doc slots
 	tx, err := pool.Begin(ctx)
 	if err != nil {
@@ -125,62 +138,32 @@ func (h *EmployeeHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	// 1. Insert the employee
 	var employee models.Employee
-	err = tx.QueryRow(ctx, `
+	err = scanEmployee(tx.QueryRow(ctx, `
 		INSERT INTO employees (
 			company_id, name, trade, mobile, joining_date, photo_url,
 			gender, date_of_birth, nationality, passport_number,
-			native_location, current_location, salary, status
+			native_location, current_location, salary, status, custom_fields
 		)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)
 		RETURNING `+employeeRetCols,
 		req.CompanyID, req.Name, req.Trade, req.Mobile, req.JoiningDate,
 		nilIfEmpty(req.PhotoURL),
 		req.Gender, req.DateOfBirth, req.Nationality, req.PassportNumber,
-		req.NativeLocation, req.CurrentLocation, req.Salary, req.Status,
-	).Scan(
-		&employee.ID, &employee.CompanyID, &employee.Name,
-		&employee.Trade, &employee.Mobile, &employee.JoiningDate,
-		&employee.PhotoURL,
-		&employee.Gender, &employee.DateOfBirth, &employee.Nationality, &employee.PassportNumber,
-		&employee.NativeLocation, &employee.CurrentLocation, &employee.Salary, &employee.Status,
-		&employee.ExitType, &employee.ExitDate, &employee.ExitNotes,
-		&employee.CreatedAt, &employee.UpdatedAt,
-	)
+		req.NativeLocation, req.CurrentLocation, req.Salary, req.Status, req.CustomFields,
+	), &employee)
 	if err != nil {
 		log.Printf("Error creating employee: %v", err)
 		JSONError(w, http.StatusInternalServerError, "Failed to create employee")
 		return
 	}
 
-	// 2. Auto-create mandatory document slots from DB (falls back to hardcoded if DB empty)
-	mandatoryRows, mandErr := tx.Query(ctx, `
-		SELECT dt.doc_type
-		FROM document_types dt
-		LEFT JOIN compliance_rules cr ON cr.doc_type = dt.doc_type AND cr.company_id = $1
-		WHERE dt.is_active = TRUE
-		  AND COALESCE(cr.is_mandatory, dt.is_mandatory) = TRUE
-		ORDER BY dt.sort_order
-	`, req.CompanyID)
-
-	var mandatoryDocTypes []string
-
-	if mandErr == nil {
-		defer mandatoryRows.Close()
-		for mandatoryRows.Next() {
-			var docType string
-			if err := mandatoryRows.Scan(&docType); err != nil {
-				log.Printf("Error scanning mandatory doc type: %v", err)
-				continue
-			}
-			mandatoryDocTypes = append(mandatoryDocTypes, docType)
-		}
-	}
-
-	// Fallback: if DB tables are empty or query failed, use hardcoded defaults
-	if len(mandatoryDocTypes) == 0 {
-		for _, md := range compliance.MandatoryDocs {
-			mandatoryDocTypes = append(mandatoryDocTypes, md.DocType)
-		}
+	// 2. Auto-create mandatory document slots. Which types are mandatory depends on
+	// the employee's nationality, trade, gender and status (requirement conditions).
+	mandatoryDocTypes, err := requiredDocTypes(ctx, tx, &employee)
+	if err != nil {
+		log.Printf("Error resolving mandatory doc types for employee %s: %v", employee.ID, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to create employee")
+		return
 	}
 
 	for _, docType := range mandatoryDocTypes {
@@ -197,11 +180,19 @@ func (h *EmployeeHandler) Create(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// 3. Open the first sponsorship period (migration 026)
+	if err := openSponsorship(ctx, tx, employee.ID); err != nil {
+		log.Printf("Error opening sponsorship for employee %s: %v", employee.ID, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to create employee")
+		return
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		log.Printf("Error committing employee creation: %v", err)
 		JSONError(w, http.StatusInternalServerError, "Failed to create employee")
 		return
 	}
+	refreshEmployeeStatus(ctx, pool, employee.ID)
 
 	// Audit trail
 	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
@@ -237,6 +228,8 @@ func (h *EmployeeHandler) List(w http.ResponseWriter, r *http.Request) {
 	docStatus := q.Get("status")     // document status filter
 	empStatus := q.Get("emp_status") // employee active/inactive filter
 	nationality := q.Get("nationality")
+	minScore := q.Get("min_score") // compliance score range, 0–100
+	maxScore := q.Get("max_score")
 	sortBy := q.Get("sort_by")
 	sortOrder := q.Get("sort_order")
 
@@ -246,6 +239,7 @@ func (h *EmployeeHandler) List(w http.ResponseWriter, r *http.Request) {
 		"joining_date": "e.joining_date",
 		"created_at":   "e.created_at",
 		"salary":       "e.salary",
+		"score":        "COALESCE(ds.score, 100)",
 	}
 	sortCol, ok := allowedSorts[sortBy]
 	if !ok {
@@ -293,8 +287,10 @@ func (h *EmployeeHandler) List(w http.ResponseWriter, r *http.Request) {
 		args = append(args, "%"+nationality+"%")
 		argIdx++
 	}
+	// Custom fields: cf.<key>=<value>
+	where, args, argIdx = appendCustomFieldFilters(q, where, args, argIdx)
 
-	// Doc status filter — uses the compliance status from LATERAL subquery
+	// Doc status filter — uses the persisted rollup (employee_compliance)
 	var statusFilter string
 	switch docStatus {
 	case "expiring", "expiring_soon":
@@ -308,33 +304,29 @@ func (h *EmployeeHandler) List(w http.ResponseWriter, r *http.Request) {
 	case "incomplete":
 		statusFilter = " AND ds.compliance_status = 'incomplete'"
 	}
+	// Compliance score range — also on the rollup
+	for _, bound := range []struct{ value, op string }{{minScore, ">="}, {maxScore, "<="}} {
+		if bound.value == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(bound.value, 64)
+		if err != nil {
+			JSONError(w, http.StatusBadRequest, "min_score and max_score must be numbers")
+			return
+		}
+		statusFilter += fmt.Sprintf(" AND COALESCE(ds.score, 100) %s $%d", bound.op, argIdx)
+		args = append(args, v)
+		argIdx++
+	}
 
 	// Count total for pagination
-	// Per-document status hierarchy (severity-first):
-	//   penalty_active = expired AND past grace period
-	//   in_grace       = expired but within grace period
-	//   expiring_soon  = within 30 days of expiry
-	//   incomplete     = missing expiry_date or document_number
-	//   valid          = all docs have expiry > 30 days
-	//   none           = no mandatory docs at all
+	// Compliance status is the rollup persisted by the docstatus package:
+	// the most severe status among the employee's required documents
+	// (penalty_active > in_grace > expiring_soon > incomplete > valid),
+	// or 'none' when no documents are required.
 	countQuery := fmt.Sprintf(`
 		SELECT COUNT(*) FROM employees e
-		LEFT JOIN LATERAL (
-			SELECT
-				CASE
-					WHEN COUNT(*) = 0 THEN 'none'
-					WHEN COUNT(*) FILTER (WHERE d2.expiry_date IS NOT NULL AND d2.expiry_date < CURRENT_DATE AND (d2.expiry_date + COALESCE(cr2.grace_period_days, gr2.grace_period_days, 0) * INTERVAL '1 day') < CURRENT_DATE) > 0 THEN 'penalty_active'
-					WHEN COUNT(*) FILTER (WHERE d2.expiry_date IS NOT NULL AND d2.expiry_date < CURRENT_DATE AND (d2.expiry_date + COALESCE(cr2.grace_period_days, gr2.grace_period_days, 0) * INTERVAL '1 day') >= CURRENT_DATE) > 0 THEN 'in_grace'
-					WHEN COUNT(*) FILTER (WHERE d2.expiry_date IS NOT NULL AND d2.expiry_date >= CURRENT_DATE AND d2.expiry_date <= CURRENT_DATE + INTERVAL '30 days') > 0 THEN 'expiring_soon'
-					WHEN COUNT(*) FILTER (WHERE d2.expiry_date IS NULL OR d2.document_number IS NULL OR d2.document_number = '') > 0 THEN 'incomplete'
-					ELSE 'valid'
-				END AS compliance_status
-			FROM documents d2
-			LEFT JOIN document_types dt2 ON dt2.doc_type = d2.document_type AND dt2.is_active = TRUE
-			LEFT JOIN compliance_rules cr2 ON cr2.doc_type = d2.document_type AND cr2.company_id = e.company_id
-			LEFT JOIN compliance_rules gr2 ON gr2.doc_type = d2.document_type AND gr2.company_id IS NULL
-			WHERE d2.employee_id = e.id AND COALESCE(dt2.is_mandatory, FALSE) = TRUE
-		) ds ON TRUE
+		LEFT JOIN employee_compliance ds ON ds.employee_id = e.id
 		%s %s
 	`, where, statusFilter)
 	var total int
@@ -350,42 +342,17 @@ func (h *EmployeeHandler) List(w http.ResponseWriter, r *http.Request) {
 			%s,
 			c.name AS company_name,
 			COALESCE(c.currency, 'AED') AS company_currency,
-			ds.compliance_status,
+			COALESCE(ds.compliance_status, 'none'),
 			ds.nearest_expiry_days,
-			ds.docs_complete,
-			ds.docs_total,
+			COALESCE(ds.docs_complete, 0),
+			COALESCE(ds.docs_total, 0),
 			ds.urgent_doc_type,
-			ds.expired_count,
-			ds.expiring_count
+			COALESCE(ds.expired_count, 0),
+			COALESCE(ds.expiring_count, 0),
+			COALESCE(ds.score, 100)::float8
 		FROM employees e
 		JOIN companies c ON e.company_id = c.id
-		LEFT JOIN LATERAL (
-			SELECT
-				CASE
-					WHEN COUNT(*) = 0 THEN 'none'
-					WHEN COUNT(*) FILTER (WHERE d2.expiry_date IS NOT NULL AND d2.expiry_date < CURRENT_DATE AND (d2.expiry_date + COALESCE(cr2.grace_period_days, gr2.grace_period_days, 0) * INTERVAL '1 day') < CURRENT_DATE) > 0 THEN 'penalty_active'
-					WHEN COUNT(*) FILTER (WHERE d2.expiry_date IS NOT NULL AND d2.expiry_date < CURRENT_DATE AND (d2.expiry_date + COALESCE(cr2.grace_period_days, gr2.grace_period_days, 0) * INTERVAL '1 day') >= CURRENT_DATE) > 0 THEN 'in_grace'
-					WHEN COUNT(*) FILTER (WHERE d2.expiry_date IS NOT NULL AND d2.expiry_date >= CURRENT_DATE AND d2.expiry_date <= CURRENT_DATE + INTERVAL '30 days') > 0 THEN 'expiring_soon'
-					WHEN COUNT(*) FILTER (WHERE d2.expiry_date IS NULL OR d2.document_number IS NULL OR d2.document_number = '') > 0 THEN 'incomplete'
-					ELSE 'valid'
-				END AS compliance_status,
-				MIN(d2.expiry_date) - CURRENT_DATE AS nearest_expiry_days,
-				COUNT(*) FILTER (WHERE d2.expiry_date IS NOT NULL AND d2.document_number IS NOT NULL AND d2.document_number != '')::int AS docs_complete,
-				COUNT(*)::int AS docs_total,
-				(SELECT dd.document_type FROM documents dd
-				 LEFT JOIN document_types ddt ON ddt.doc_type = dd.document_type AND ddt.is_active = TRUE
-				 WHERE dd.employee_id = e.id AND COALESCE(ddt.is_mandatory, FALSE) = TRUE
-				   AND dd.expiry_date IS NOT NULL
-				 ORDER BY dd.expiry_date ASC LIMIT 1
-				) AS urgent_doc_type,
-				COUNT(*) FILTER (WHERE d2.expiry_date < CURRENT_DATE AND (d2.expiry_date + COALESCE(cr2.grace_period_days, gr2.grace_period_days, 0) * INTERVAL '1 day') < CURRENT_DATE)::int AS expired_count,
-				COUNT(*) FILTER (WHERE d2.expiry_date IS NOT NULL AND d2.expiry_date >= CURRENT_DATE AND d2.expiry_date <= CURRENT_DATE + INTERVAL '30 days')::int AS expiring_count
-			FROM documents d2
-			LEFT JOIN document_types dt2 ON dt2.doc_type = d2.document_type AND dt2.is_active = TRUE
-			LEFT JOIN compliance_rules cr2 ON cr2.doc_type = d2.document_type AND cr2.company_id = e.company_id
-			LEFT JOIN compliance_rules gr2 ON gr2.doc_type = d2.document_type AND gr2.company_id IS NULL
-			WHERE d2.employee_id = e.id AND COALESCE(dt2.is_mandatory, FALSE) = TRUE
-		) ds ON TRUE
+		LEFT JOIN employee_compliance ds ON ds.employee_id = e.id
 		%s %s
 		ORDER BY %s %s
 		LIMIT $%d OFFSET $%d
@@ -439,61 +406,25 @@ func (h *EmployeeHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	pool := h.db.GetPool()
 
 	var emp models.EmployeeWithCompany
-	err := pool.QueryRow(ctx, fmt.Sprintf(`
+	err := scanEmployeeWithCompany(pool.QueryRow(ctx, fmt.Sprintf(`
 		SELECT 
 			%s,
 			c.name AS company_name,
 			COALESCE(c.currency, 'AED') AS company_currency,
-			ds.compliance_status,
+			COALESCE(ds.compliance_status, 'none'),
 			ds.nearest_expiry_days,
-			ds.docs_complete,
-			ds.docs_total,
+			COALESCE(ds.docs_complete, 0),
+			COALESCE(ds.docs_total, 0),
 			ds.urgent_doc_type,
-			ds.expired_count,
-			ds.expiring_count
+			COALESCE(ds.expired_count, 0),
+			COALESCE(ds.expiring_count, 0),
+			COALESCE(ds.score, 100)::float8
 		FROM employees e
 		JOIN companies c ON e.company_id = c.id
-		LEFT JOIN LATERAL (
-			SELECT
-				CASE
-					WHEN COUNT(*) = 0 THEN 'none'
-					WHEN COUNT(*) FILTER (WHERE d2.expiry_date IS NOT NULL AND d2.expiry_date < CURRENT_DATE AND (d2.expiry_date + COALESCE(cr2.grace_period_days, gr2.grace_period_days, 0) * INTERVAL '1 day') < CURRENT_DATE) > 0 THEN 'penalty_active'
-					WHEN COUNT(*) FILTER (WHERE d2.expiry_date IS NOT NULL AND d2.expiry_date < CURRENT_DATE AND (d2.expiry_date + COALESCE(cr2.grace_period_days, gr2.grace_period_days, 0) * INTERVAL '1 day') >= CURRENT_DATE) > 0 THEN 'in_grace'
-					WHEN COUNT(*) FILTER (WHERE d2.expiry_date IS NOT NULL AND d2.expiry_date >= CURRENT_DATE AND d2.expiry_date <= CURRENT_DATE + INTERVAL '30 days') > 0 THEN 'expiring_soon'
-					WHEN COUNT(*) FILTER (WHERE d2.expiry_date IS NULL OR d2.document_number IS NULL OR d2.document_number = '') > 0 THEN 'incomplete'
-					ELSE 'valid'
-				END AS compliance_status,
-				MIN(d2.expiry_date) - CURRENT_DATE AS nearest_expiry_days,
-				COUNT(*) FILTER (WHERE d2.expiry_date IS NOT NULL AND d2.document_number IS NOT NULL AND d2.document_number != '')::int AS docs_complete,
-				COUNT(*)::int AS docs_total,
-				(SELECT dd.document_type FROM documents dd
-				 LEFT JOIN document_types ddt ON ddt.doc_type = dd.document_type AND ddt.is_active = TRUE
-				 WHERE dd.employee_id = e.id AND COALESCE(ddt.is_mandatory, FALSE) = TRUE
-				   AND dd.expiry_date IS NOT NULL
-				 ORDER BY dd.expiry_date ASC LIMIT 1
-				) AS urgent_doc_type,
-				COUNT(*) FILTER (WHERE d2.expiry_date < CURRENT_DATE AND (d2.expiry_date + COALESCE(cr2.grace_period_days, gr2.grace_period_days, 0) * INTERVAL '1 day') < CURRENT_DATE)::int AS expired_count,
-				COUNT(*) FILTER (WHERE d2.expiry_date IS NOT NULL AND d2.expiry_date >= CURRENT_DATE AND d2.expiry_date <= CURRENT_DATE + INTERVAL '30 days')::int AS expiring_count
-			FROM documents d2
-			LEFT JOIN document_types dt2 ON dt2.doc_type = d2.document_type AND dt2.is_active = TRUE
-			LEFT JOIN compliance_rules cr2 ON cr2.doc_type = d2.document_type AND cr2.company_id = e.company_id
-			LEFT JOIN compliance_rules gr2 ON gr2.doc_type = d2.document_type AND gr2.company_id IS NULL
-			WHERE d2.employee_id = e.id AND COALESCE(dt2.is_mandatory, FALSE) = TRUE
-		) ds ON TRUE
+		LEFT JOIN employee_compliance ds ON ds.employee_id = e.id
 		WHERE e.id = $1
 	`, employeeCols), id,
-	).Scan(
-		&emp.ID, &emp.CompanyID, &emp.Name, &emp.Trade, &emp.Mobile,
-		&emp.JoiningDate, &emp.PhotoURL,
-		&emp.Gender, &emp.DateOfBirth, &emp.Nationality, &emp.PassportNumber,
-		&emp.NativeLocation, &emp.CurrentLocation, &emp.Salary, &emp.Status,
-		&emp.ExitType, &emp.ExitDate, &emp.ExitNotes,
-		&emp.CreatedAt, &emp.UpdatedAt,
-		&emp.CompanyName, &emp.CompanyCurrency,
-		&emp.ComplianceStatus, &emp.NearestExpiryDays,
-		&emp.DocsComplete, &emp.DocsTotal, &emp.UrgentDocType,
-		&emp.ExpiredCount, &emp.ExpiringCount,
-	)
+	), &emp)
 	if err != nil {
 		log.Printf("Error fetching employee %s: %v", id, err)
 		JSONError(w, http.StatusNotFound, "Employee not found")
@@ -513,7 +444,11 @@ func (h *EmployeeHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 // ── Exit ───────────────────────────────────────────────────────
 
 // Exit handles PATCH /api/employees/{id}/exit
-// Records an employee exit (resignation, termination, absconsion).
+// Records an employee exit (resignation, termination, absconsion) and
+// generates the exit checklist (work permit and visa cancellation, final
+// dues, assets). The employee's documents switch to cancellation tracking.
+// An absconsion also opens an absconding case, with the exit date as the
+// last working day.
 func (h *EmployeeHandler) Exit(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	if id == "" {
@@ -555,38 +490,64 @@ func (h *EmployeeHandler) Exit(w http.ResponseWriter, r *http.Request) {
 		"absconded":  "terminated",
 	}
 
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback(ctx)
+
 	var employee models.Employee
-	err := pool.QueryRow(ctx, `
+	err = scanEmployee(tx.QueryRow(ctx, `
 		UPDATE employees SET
 			status = $1, exit_type = $2, exit_date = $3, exit_notes = $4,
 			updated_at = NOW()
 		WHERE id = $5
 		RETURNING `+employeeRetCols,
 		statusMap[req.ExitType], req.ExitType, req.ExitDate, req.ExitNotes, id,
-	).Scan(
-		&employee.ID, &employee.CompanyID, &employee.Name,
-		&employee.Trade, &employee.Mobile, &employee.JoiningDate,
-		&employee.PhotoURL,
-		&employee.Gender, &employee.DateOfBirth, &employee.Nationality, &employee.PassportNumber,
-		&employee.NativeLocation, &employee.CurrentLocation, &employee.Salary, &employee.Status,
-		&employee.ExitType, &employee.ExitDate, &employee.ExitNotes,
-		&employee.CreatedAt, &employee.UpdatedAt,
-	)
+	), &employee)
 	if err != nil {
 		log.Printf("Error recording employee exit %s: %v", id, err)
 		JSONError(w, http.StatusNotFound, "Employee not found")
 		return
 	}
+	if _, err := generateExitChecklist(ctx, tx, employee.ID, req.ExitDate); err != nil {
+		log.Printf("Error generating exit checklist for %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to generate exit checklist")
+		return
+	}
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+	var abscondingCaseID *string
+	if req.ExitType == "absconded" {
+		caseID, err := openAbscondingCase(ctx, tx, employee.ID, req.ExitDate, userID)
+		if err != nil {
+			log.Printf("Error opening absconding case for %s: %v", id, err)
+			JSONError(w, http.StatusInternalServerError, "Failed to open absconding case")
+			return
+		}
+		abscondingCaseID = &caseID
+	}
+	if err := tx.Commit(ctx); err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to record employee exit")
+		return
+	}
+	refreshEmployeeStatus(ctx, pool, employee.ID)
+
+	checklist, err := loadExitChecklist(ctx, pool, employee.ID)
+	if err != nil {
+		log.Printf("Error fetching exit checklist for %s: %v", id, err)
+	}
 
 	// Audit trail
-	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
 	logActivity(pool, userID, "exited", "employee", employee.ID, map[string]interface{}{
-		"name": employee.Name, "exitType": req.ExitType,
+		"name": employee.Name, "exitType": req.ExitType, "abscondingCaseId": abscondingCaseID,
 	})
 
 	JSON(w, http.StatusOK, map[string]interface{}{
-		"data":    employee,
-		"message": "Employee exit recorded successfully",
+		"data":             employee,
+		"checklist":        checklist,
+		"abscondingCaseId": abscondingCaseID,
+		"message":          "Employee exit recorded successfully",
 	})
 }
 
@@ -611,11 +572,47 @@ func (h *EmployeeHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	if req.CompanyID != nil && !checkCompanyAccess(r.Context(), *req.CompanyID) {
+		JSONError(w, http.StatusForbidden, "Access denied to this company")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
 	pool := h.db.GetPool()
 
+	// Custom fields are merged into the current values and validated against
+	// the definitions of the company the employee will belong to; a company
+	// change revalidates them even when none are submitted.
+	if req.CustomFields != nil || req.CompanyID != nil {
+		var companyID string
+		var current map[string]interface{}
+		if err := pool.QueryRow(ctx,
+			"SELECT company_id::text, custom_fields FROM employees WHERE id = $1", id,
+		).Scan(&companyID, &current); err != nil {
+			JSONError(w, http.StatusNotFound, "Employee not found")
+			return
+		}
+		companyChanged := req.CompanyID != nil && *req.CompanyID != companyID
+		if companyChanged {
+			companyID = *req.CompanyID
+		}
+		defs, err := loadCustomFieldDefs(ctx, pool, companyID)
+		if err != nil {
+			log.Printf("Error loading custom fields for company %s: %v", companyID, err)
+			JSONError(w, http.StatusInternalServerError, "Failed to update employee")
+			return
+		}
+		if errs := req.ValidateCustomFields(defs, current, companyChanged); len(errs) > 0 {
+			JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+				"error":   "Validation failed",
+				"details": errs,
+			})
+			return
+		}
+	}
+
 	// Build dynamic SET clause — only update provided fields
 	setClauses := []string{}
 	args := []interface{}{}
@@ -669,6 +666,9 @@ func (h *EmployeeHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if req.Status != nil {
 		addField("status", *req.Status)
 	}
+	if req.CustomFields != nil {
+		addField("custom_fields", req.CustomFields)
+	}
 
 	if len(setClauses) == 0 {
 		JSONError(w, http.StatusBadRequest, "No fields to update")
@@ -685,23 +685,115 @@ func (h *EmployeeHandler) Update(w http.ResponseWriter, r *http.Request) {
 	`, strings.Join(setClauses, ", "), argIdx, employeeRetCols)
 	args = append(args, id)
 
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to update employee")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// A company change is a transfer effective today and a status change is
+	// kept for the timeline — remember the previous values.
+	var fromCompanyID, fromStatus string
+	if req.CompanyID != nil || req.Status != nil {
+		if err := tx.QueryRow(ctx,
+			"SELECT company_id::text, COALESCE(status, '') FROM employees WHERE id = $1 FOR UPDATE", id,
+		).Scan(&fromCompanyID, &fromStatus); err != nil {
+			JSONError(w, http.StatusNotFound, "Employee not found")
+			return
+		}
+	}
+
+	// A company change gets the same checks as the transfer endpoint, dated
+	// the current company's local date.
+	var transfer *transferState
+	if req.CompanyID != nil && *req.CompanyID != fromCompanyID {
+		if transfer, err = loadTransferState(ctx, tx, id); err != nil {
+			log.Printf("Error fetching employee %s for transfer: %v", id, err)
+			JSONError(w, http.StatusInternalServerError, "Failed to update employee")
+			return
+		}
+		if msg := transfer.conflict(); msg != "" {
+			JSONError(w, http.StatusConflict, msg)
+			return
+		}
+		if errs := transfer.validate(*req.CompanyID, transfer.Today); len(errs) > 0 {
+			JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+				"error":   "Validation failed",
+				"details": errs,
+			})
+			return
+		}
+	}
+
+	// Mandatory slots depend on these attributes — reconcile when any of them
+	// change, removing only slots that were required before the update.
+	slotsChanged := req.CompanyID != nil || req.Nationality != nil || req.Trade != nil || req.Gender != nil || req.Status != nil
+	var previousSlots map[string][]string
+	if slotsChanged {
+		if previousSlots, err = requiredSlots(ctx, tx, "e.id = $1", id); err != nil {
+			log.Printf("Error loading document slots for employee %s: %v", id, err)
+			JSONError(w, http.StatusInternalServerError, "Failed to update employee")
+			return
+		}
+	}
+
 	var employee models.Employee
-	if err := scanEmployee(pool.QueryRow(ctx, query, args...), &employee); err != nil {
+	if err := scanEmployee(tx.QueryRow(ctx, query, args...), &employee); err != nil {
 		log.Printf("Error updating employee %s: %v", id, err)
 		JSONError(w, http.StatusNotFound, "Employee not found")
 		return
 	}
 
-	// Audit trail
+	var slots *slotReconciliation
+	if slotsChanged {
+		result, err := reconcileDocumentSlots(ctx, tx, &employee, previousSlots[id])
+		if err != nil {
+			log.Printf("Error reconciling document slots for employee %s: %v", id, err)
+			JSONError(w, http.StatusInternalServerError, "Failed to update employee")
+			return
+		}
+		slots = &result
+	}
+
 	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
-	logActivity(pool, userID, "updated", "employee", employee.ID, map[string]interface{}{
-		"name": employee.Name,
-	})
+	if transfer != nil {
+		_, _, err := recordTransfer(ctx, tx, employee.ID, fromCompanyID, employee.CompanyID, transfer.Today, "", userID, "", *slots)
+		if err != nil {
+			log.Printf("Error recording transfer for employee %s: %v", id, err)
+			JSONError(w, http.StatusInternalServerError, "Failed to update employee")
+			return
+		}
+	}
 
-	JSON(w, http.StatusOK, map[string]interface{}{
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error committing employee update: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to update employee")
+		return
+	}
+	refreshEmployeeStatus(ctx, pool, employee.ID)
+
+	// Audit trail
+	details := map[string]interface{}{"name": employee.Name}
+	if req.Status != nil && fromStatus != employee.Status {
+		details["statusFrom"] = fromStatus
+		details["status"] = employee.Status
+	}
+	if slots != nil && (len(slots.Added) > 0 || len(slots.Removed) > 0) {
+		details["slotsAdded"] = slots.Added
+		details["slotsRemoved"] = slots.Removed
+	}
+	logActivity(pool, userID, "updated", "employee", employee.ID, details)
+
+	resp := map[string]interface{}{
 		"data":    employee,
 		"message": "Employee updated successfully",
-	})
+	}
+	if slots != nil {
+		resp["documentSlots"] = slots
+	}
+	JSON(w, http.StatusOK, resp)
 }
 
 // ── Delete ─────────────────────────────────────────────────────
@@ -802,18 +894,52 @@ func (h *EmployeeHandler) Export(w http.ResponseWriter, r *http.Request) {
 
 	pool := h.db.GetPool()
 
+	q := r.URL.Query()
 	where := "WHERE 1=1"
 	exportArgs := []interface{}{}
 	exportArgIdx := 1
 	where, exportArgs, exportArgIdx = appendCompanyScope(ctx, where, exportArgs, exportArgIdx, "e.company_id")
-	_ = exportArgIdx
+	if companyID := q.Get("company_id"); companyID != "" {
+		where += fmt.Sprintf(" AND e.company_id::text = $%d", exportArgIdx)
+		exportArgs = append(exportArgs, companyID)
+		exportArgIdx++
+	}
+	where, exportArgs, _ = appendCustomFieldFilters(q, where, exportArgs, exportArgIdx)
+
+	// One column per custom field key of the exported companies.
+	fieldsWhere, fieldsArgs, fieldsArgIdx := appendCompanyScope(ctx, "WHERE is_active", []interface{}{}, 1, "company_id")
+	if companyID := q.Get("company_id"); companyID != "" {
+		fieldsWhere += fmt.Sprintf(" AND company_id::text = $%d", fieldsArgIdx)
+		fieldsArgs = append(fieldsArgs, companyID)
+	}
+	type exportField struct{ key, label string }
+	customCols := []exportField{}
+	fieldRows, err := pool.Query(ctx, `
+		SELECT field_key, label FROM (
+			SELECT DISTINCT ON (field_key) field_key, label, sort_order
+			FROM custom_field_definitions `+fieldsWhere+`
+			ORDER BY field_key, sort_order
+		) f ORDER BY sort_order, label
+	`, fieldsArgs...)
+	if err != nil {
+		log.Printf("Error loading custom fields for export: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to export")
+		return
+	}
+	for fieldRows.Next() {
+		var f exportField
+		if err := fieldRows.Scan(&f.key, &f.label); err == nil {
+			customCols = append(customCols, f)
+		}
+	}
+	fieldRows.Close()
 
 	rows, err := pool.Query(ctx, fmt.Sprintf(`
 		SELECT e.name, e.trade, e.mobile, e.joining_date::text,
 			COALESCE(e.gender,''), COALESCE(e.nationality,''),
 			COALESCE(e.passport_number,''), COALESCE(e.native_location,''),
 			COALESCE(e.current_location,''), COALESCE(e.salary::text,''),
-			e.status, c.name
+			e.status, c.name, e.custom_fields
 		FROM employees e
 		JOIN companies c ON e.company_id = c.id
 		%s
@@ -830,17 +956,29 @@ func (h *EmployeeHandler) Export(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Disposition", "attachment; filename=employees.csv")
 
 	// Write CSV header
-	fmt.Fprintln(w, "Name,Trade,Mobile,Joining Date,Gender,Nationality,Passport,Native Location,Current Location,Salary,Status,Company")
+	header := "Name,Trade,Mobile,Joining Date,Gender,Nationality,Passport,Native Location,Current Location,Salary,Status,Company"
+	for _, f := range customCols {
+		header += "," + csvEscape(f.label)
+	}
+	fmt.Fprintln(w, header)
 
 	for rows.Next() {
 		var name, trade, mobile, joiningDate, gender, nationality, passport, nativeLoc, currentLoc, salary, status, company string
-		if err := rows.Scan(&name, &trade, &mobile, &joiningDate, &gender, &nationality, &passport, &nativeLoc, &currentLoc, &salary, &status, &company); err != nil {
+		var custom map[string]interface{}
+		if err := rows.Scan(&name, &trade, &mobile, &joiningDate, &gender, &nationality, &passport, &nativeLoc, &currentLoc, &salary, &status, &company, &custom); err != nil {
 			continue
 		}
-		fmt.Fprintf(w, "%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s\n",
+		extra := ""
+		for _, f := range customCols {
+			extra += ","
+			if v, ok := custom[f.key]; ok && v != nil {
+				extra += csvEscape(fmt.Sprint(v))
+			}
+		}
+		fmt.Fprintf(w, "%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s%s\n",
 			csvEscape(name), csvEscape(trade), csvEscape(mobile), joiningDate,
 			gender, nationality, passport,
-			csvEscape(nativeLoc), csvEscape(currentLoc), salary, status, csvEscape(company))
+			csvEscape(nativeLoc), csvEscape(currentLoc), salary, status, csvEscape(company), extra)
 	}
 }
 
@@ -0,0 +1,478 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"manpower-backend/internal/compliance"
+	"manpower-backend/internal/ctxkeys"
+	"manpower-backend/internal/database"
+	"manpower-backend/internal/models"
+)
+
+// ExitChecklistHandler handles the checklist generated when an employee exits.
+type ExitChecklistHandler struct {
+	db database.Service
+}
+
+// NewExitChecklistHandler creates a new ExitChecklistHandler.
+func NewExitChecklistHandler(db database.Service) *ExitChecklistHandler {
+	return &ExitChecklistHandler{db: db}
+}
+
+// ── Exit Checklist ───────────────────────────────────────────────
+// EmployeeHandler.Exit generates one item per exit_checklist_rules item
+// (migration 024), due a number of days after the exit date. Items that
+// cancel documents drive those documents' status (docstatus tracks them
+// for cancellation instead of renewal); the others are alerted by the
+// notifier directly.
+
+const exitItemCols = `x.id, x.employee_id, x.company_id, x.item, x.doc_types,
+	x.due_date::text, x.fine_per_day::float8, x.fine_type, x.fine_cap::float8,
+	x.status, x.completed_on::text, x.completed_by, x.notes,
+	x.created_at::text, x.updated_at::text, e.name, co.name,
+	company_today(x.company_id)`
+
+const exitItemFrom = `
+	FROM exit_checklist_items x
+	JOIN employees e ON e.id = x.employee_id
+	JOIN companies co ON co.id = x.company_id`
+
+// scanExitItem scans exitItemCols and computes the item's status and fine.
+func scanExitItem(row pgx.Row, x *models.ExitChecklistItem) error {
+	var today time.Time
+	if err := row.Scan(
+		&x.ID, &x.EmployeeID, &x.CompanyID, &x.Item, &x.DocTypes,
+		&x.DueDate, &x.FinePerDay, &x.FineType, &x.FineCap,
+		&x.Status, &x.CompletedOn, &x.CompletedBy, &x.Notes,
+		&x.CreatedAt, &x.UpdatedAt, &x.EmployeeName, &x.CompanyName,
+		&today,
+	); err != nil {
+		return err
+	}
+	due, _ := time.Parse("2006-01-02", x.DueDate)
+	var completed *time.Time
+	if x.CompletedOn != nil {
+		if t, err := time.Parse("2006-01-02", *x.CompletedOn); err == nil {
+			completed = &t
+		}
+	}
+	x.Compliance = compliance.ExitItemStatus(due, x.Status != "pending", today)
+	if days := compliance.DaysRemaining(&due, today); days != nil {
+		x.DaysRemaining = *days
+	}
+	x.Overdue = x.Status == "pending" && x.Compliance == compliance.StatusPenaltyActive
+	if x.Status != "waived" {
+		x.FineExposure = compliance.ExitItemFine(due, completed, x.FinePerDay, x.FineType, x.FineCap, today)
+	}
+	return nil
+}
+
+// generateExitChecklist creates the checklist for an employee who exited on
+// exitDate from the company's rules (falling back to the global rules).
+// Recording the exit again moves the deadlines of pending items only.
+func generateExitChecklist(ctx context.Context, q querier, employeeID, exitDate string) (int64, error) {
+	tag, err := q.Exec(ctx, `
+		INSERT INTO exit_checklist_items (employee_id, company_id, item, doc_types, due_date,
+		    fine_per_day, fine_type, fine_cap)
+		SELECT e.id, e.company_id, r.item, r.doc_types, $2::date + r.due_days,
+		    r.fine_per_day, r.fine_type, r.fine_cap
+		FROM employees e
+		CROSS JOIN LATERAL (
+		    SELECT DISTINCT ON (item) * FROM exit_checklist_rules
+		    WHERE company_id = e.company_id OR company_id IS NULL
+		    ORDER BY item, company_id NULLS LAST
+		) r
+		WHERE e.id = $1
+		ON CONFLICT (employee_id, item) DO UPDATE SET
+		    doc_types    = EXCLUDED.doc_types,
+		    due_date     = EXCLUDED.due_date,
+		    fine_per_day = EXCLUDED.fine_per_day,
+		    fine_type    = EXCLUDED.fine_type,
+		    fine_cap     = EXCLUDED.fine_cap,
+		    updated_at   = NOW()
+		WHERE exit_checklist_items.status = 'pending'
+	`, employeeID, exitDate)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// loadExitChecklist returns an employee's checklist in display order.
+func loadExitChecklist(ctx context.Context, q querier, employeeID string) ([]models.ExitChecklistItem, error) {
+	rows, err := q.Query(ctx, `
+		SELECT `+exitItemCols+exitItemFrom+`
+		WHERE x.employee_id = $1
+		ORDER BY array_position($2::text[], x.item::text)
+	`, employeeID, models.ExitChecklistItems)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.ExitChecklistItem{}
+	for rows.Next() {
+		var x models.ExitChecklistItem
+		if err := scanExitItem(rows, &x); err != nil {
+			return nil, err
+		}
+		items = append(items, x)
+	}
+	return items, rows.Err()
+}
+
+// GetByEmployee handles GET /api/employees/{id}/exit-checklist
+// Returns the items with their computed status and fine exposure, plus the
+// total exposure of items not waived.
+func (h *ExitChecklistHandler) GetByEmployee(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !checkEmployeeAccess(r.Context(), h.db.GetPool(), id) {
+		JSONError(w, http.StatusForbidden, "Access denied to this employee")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	items, err := loadExitChecklist(ctx, h.db.GetPool(), id)
+	if err != nil {
+		log.Printf("Error fetching exit checklist for %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch exit checklist")
+		return
+	}
+
+	exposure := 0.0
+	for _, x := range items {
+		exposure += x.FineExposure
+	}
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"data":         items,
+		"fineExposure": math.Round(exposure*100) / 100,
+	})
+}
+
+// List handles GET /api/exit-checklist
+// Filters: company_id, employee_id, item, status, overdue=true.
+// Pagination: page, limit (default 20, max 100).
+func (h *ExitChecklistHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	where := "WHERE 1=1"
+	args := []interface{}{}
+	argIdx := 1
+	where, args, argIdx = appendCompanyScope(ctx, where, args, argIdx, "x.company_id")
+
+	filters := []struct{ param, clause string }{
+		{"company_id", "x.company_id::text = $%d"},
+		{"employee_id", "x.employee_id::text = $%d"},
+		{"item", "x.item = $%d"},
+		{"status", "x.status = $%d"},
+	}
+	for _, f := range filters {
+		if v := q.Get(f.param); v != "" {
+			where += " AND " + fmt.Sprintf(f.clause, argIdx)
+			args = append(args, v)
+			argIdx++
+		}
+	}
+	if q.Get("overdue") == "true" {
+		where += " AND x.status = 'pending' AND x.due_date < company_today(x.company_id)"
+	}
+
+	var total int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM exit_checklist_items x `+where, args...).Scan(&total); err != nil {
+		log.Printf("Error counting exit checklist items: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch exit checklist")
+		return
+	}
+
+	args = append(args, limit, (page-1)*limit)
+	rows, err := pool.Query(ctx, fmt.Sprintf(`
+		SELECT %s %s
+		%s
+		ORDER BY x.status = 'pending' DESC, x.due_date ASC, e.name
+		LIMIT $%d OFFSET $%d
+	`, exitItemCols, exitItemFrom, where, argIdx, argIdx+1), args...)
+	if err != nil {
+		log.Printf("Error fetching exit checklist items: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch exit checklist")
+		return
+	}
+	defer rows.Close()
+
+	items := []models.ExitChecklistItem{}
+	for rows.Next() {
+		var x models.ExitChecklistItem
+		if err := scanExitItem(rows, &x); err != nil {
+			log.Printf("Error scanning exit checklist item: %v", err)
+			continue
+		}
+		items = append(items, x)
+	}
+
+	JSON(w, http.StatusOK, PaginatedResponse{
+		Data: items,
+		Pagination: PaginationMeta{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: int(math.Ceil(float64(total) / float64(limit))),
+		},
+	})
+}
+
+// UpdateItem handles PATCH /api/exit-checklist/{id}
+// Marks an item done (completedOn defaults to the company's today) or
+// waived, reopens it as pending, or edits its notes. Completing an item
+// that cancels documents stops their cancellation alerts.
+func (h *ExitChecklistHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !checkExitChecklistAccess(r.Context(), h.db.GetPool(), id) {
+		JSONError(w, http.StatusForbidden, "Access denied to this exit checklist item")
+		return
+	}
+
+	var req models.UpdateExitChecklistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	if errs := req.Validate(); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	// pending clears the completion; done/waived default it to today and
+	// keep an existing date unless a new one is given.
+	var employeeID string
+	err := pool.QueryRow(ctx, `
+		UPDATE exit_checklist_items SET
+			status       = COALESCE($2::text, status),
+			completed_on = CASE
+				WHEN COALESCE($2::text, status) = 'pending' THEN NULL
+				ELSE COALESCE($3::date, completed_on, company_today(company_id)) END,
+			completed_by = CASE
+				WHEN COALESCE($2::text, status) = 'pending' THEN NULL
+				WHEN $2::text IS NOT NULL OR $3::date IS NOT NULL THEN $4::uuid
+				ELSE completed_by END,
+			notes        = COALESCE($5::text, notes),
+			updated_at   = NOW()
+		WHERE id = $1
+		RETURNING employee_id::text
+	`, id, req.Status, req.CompletedOn, nilIfEmpty(userID), req.Notes).Scan(&employeeID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Exit checklist item not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating exit checklist item %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to update exit checklist item")
+		return
+	}
+	refreshEmployeeStatus(ctx, pool, employeeID)
+
+	var x models.ExitChecklistItem
+	if err := scanExitItem(pool.QueryRow(ctx, `SELECT `+exitItemCols+exitItemFrom+` WHERE x.id = $1`, id), &x); err != nil {
+		log.Printf("Error fetching exit checklist item %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch exit checklist item")
+		return
+	}
+
+	go logActivity(pool, userID, "updated", "exit_checklist_item", x.ID, map[string]interface{}{
+		"employeeId": x.EmployeeID, "item": x.Item, "status": x.Status, "completedOn": x.CompletedOn,
+	})
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"data":    x,
+		"message": "Exit checklist item updated",
+	})
+}
+
+// ── Exit Checklist Rules (admin) ─────────────────────────────────
+
+const exitRuleCols = `id, company_id, item, due_days, fine_per_day::float8, fine_type,
+	fine_cap::float8, doc_types, description, created_at::text, updated_at::text`
+
+func scanExitRule(row pgx.Row, rule *models.ExitChecklistRule) error {
+	return row.Scan(
+		&rule.ID, &rule.CompanyID, &rule.Item, &rule.DueDays, &rule.FinePerDay, &rule.FineType,
+		&rule.FineCap, &rule.DocTypes, &rule.Description, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+}
+
+// ListExitChecklistRules handles GET /api/admin/exit-checklist-rules?company_id=
+// With company_id, returns that company's rows plus global rows.
+func (h *AdminHandler) ListExitChecklistRules(w http.ResponseWriter, r *http.Request) {
+	companyID := r.URL.Query().Get("company_id")
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	query := `SELECT ` + exitRuleCols + ` FROM exit_checklist_rules WHERE company_id IS NULL`
+	args := []interface{}{models.ExitChecklistItems}
+	if companyID != "" {
+		args = append(args, companyID)
+		query += ` OR company_id::text = $2`
+	}
+	query += ` ORDER BY array_position($1::text[], item::text), company_id NULLS FIRST`
+
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to list exit checklist rules: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch exit checklist rules")
+		return
+	}
+	defer rows.Close()
+
+	rules := []models.ExitChecklistRule{}
+	for rows.Next() {
+		var rule models.ExitChecklistRule
+		if err := scanExitRule(rows, &rule); err != nil {
+			log.Printf("Failed to scan exit checklist rule: %v", err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{"data": rules})
+}
+
+// SaveExitChecklistRule handles PUT /api/admin/exit-checklist-rules (admin-only).
+// Creates or replaces the rule for the scope (company or global) and item.
+// Existing checklists keep their deadlines.
+func (h *AdminHandler) SaveExitChecklistRule(w http.ResponseWriter, r *http.Request) {
+	var req models.ExitChecklistRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	if errs := req.Validate(); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	if req.CompanyID != nil {
+		var exists bool
+		_ = pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM companies WHERE id::text = $1)`, *req.CompanyID).Scan(&exists)
+		if !exists {
+			JSONError(w, http.StatusUnprocessableEntity, "Company not found")
+			return
+		}
+	}
+
+	var rule models.ExitChecklistRule
+	err := scanExitRule(pool.QueryRow(ctx, `
+		INSERT INTO exit_checklist_rules (company_id, item, due_days, fine_per_day, fine_type, fine_cap, doc_types, description)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (COALESCE(company_id, '00000000-0000-0000-0000-000000000000'::uuid), item) DO UPDATE SET
+			due_days     = EXCLUDED.due_days,
+			fine_per_day = EXCLUDED.fine_per_day,
+			fine_type    = EXCLUDED.fine_type,
+			fine_cap     = EXCLUDED.fine_cap,
+			doc_types    = EXCLUDED.doc_types,
+			description  = EXCLUDED.description,
+			updated_at   = NOW()
+		RETURNING `+exitRuleCols,
+		req.CompanyID, req.Item, req.DueDays, req.FinePerDay, req.FineType, req.FineCap, req.DocTypes, req.Description,
+	), &rule)
+	if err != nil {
+		log.Printf("Failed to save exit checklist rule: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to save exit checklist rule")
+		return
+	}
+
+	go logActivity(pool, userID, "updated", "exit_checklist_rule", rule.ID, map[string]interface{}{
+		"item": rule.Item, "dueDays": rule.DueDays, "finePerDay": rule.FinePerDay, "companyId": rule.CompanyID,
+	})
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"data":    rule,
+		"message": "Exit checklist rule saved",
+	})
+}
+
+// DeleteExitChecklistRule handles DELETE /api/admin/exit-checklist-rules/{id} (admin-only).
+// Only company overrides can be deleted; the company falls back to the global rule.
+func (h *AdminHandler) DeleteExitChecklistRule(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	var companyID *string
+	err := pool.QueryRow(ctx, `SELECT company_id::text FROM exit_checklist_rules WHERE id::text = $1`, id).Scan(&companyID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Exit checklist rule not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to fetch exit checklist rule %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to delete exit checklist rule")
+		return
+	}
+	if companyID == nil {
+		JSONError(w, http.StatusConflict, "Global exit checklist rules cannot be deleted")
+		return
+	}
+
+	if _, err := pool.Exec(ctx, `DELETE FROM exit_checklist_rules WHERE id::text = $1`, id); err != nil {
+		log.Printf("Failed to delete exit checklist rule %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to delete exit checklist rule")
+		return
+	}
+
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+	go logActivity(pool, userID, "deleted", "exit_checklist_rule", id, map[string]interface{}{
+		"companyId": *companyID,
+	})
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Exit checklist rule deleted",
+	})
+}
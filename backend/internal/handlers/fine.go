@@ -0,0 +1,568 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"manpower-backend/internal/compliance"
+	"manpower-backend/internal/ctxkeys"
+	"manpower-backend/internal/database"
+	"manpower-backend/internal/models"
+	"manpower-backend/internal/storage"
+)
+
+// FineHandler handles the fine settlement ledger and its reconciliation.
+// Receipts are stored through the storage.Store interface.
+type FineHandler struct {
+	db    database.Service
+	store storage.Store
+}
+
+// NewFineHandler creates a new FineHandler.
+func NewFineHandler(db database.Service, store storage.Store) *FineHandler {
+	return &FineHandler{db: db, store: store}
+}
+
+// ── Settlement Ledger ────────────────────────────────────────────
+// Each entry settles one document's penalty for [penalty_from, penalty_to]
+// (migration 018). The docstatus refresh treats everything accrued up to the
+// latest penalty_to as paid, so estimated exposure drops once recorded.
+
+const fineSettlementCols = `fs.id, fs.document_id, fs.employee_id, fs.company_id,
+	fs.document_type, fs.penalty_from::text, fs.penalty_to::text,
+	fs.amount::float8, fs.estimated_amount::float8, fs.paid_on::text,
+	fs.reference, fs.notes, fs.receipt_url, fs.receipt_name,
+	fs.created_by, fs.created_at::text`
+
+func scanFineSettlement(scanner interface {
+	Scan(dest ...interface{}) error
+}, s *models.FineSettlement) error {
+	return scanner.Scan(
+		&s.ID, &s.DocumentID, &s.EmployeeID, &s.CompanyID,
+		&s.DocumentType, &s.PenaltyFrom, &s.PenaltyTo,
+		&s.Amount, &s.EstimatedAmount, &s.PaidOn,
+		&s.Reference, &s.Notes, &s.ReceiptURL, &s.ReceiptName,
+		&s.CreatedBy, &s.CreatedAt,
+	)
+}
+
+// ListSettlements handles GET /api/fines/settlements
+// Filters: company_id, employee_id, document_id, from, to (paid_on, YYYY-MM-DD).
+// Pagination: page, limit (default 20, max 100).
+func (h *FineHandler) ListSettlements(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	where := "WHERE 1=1"
+	args := []interface{}{}
+	argIdx := 1
+	where, args, argIdx = appendCompanyScope(ctx, where, args, argIdx, "fs.company_id")
+
+	filters := []struct{ param, clause string }{
+		{"company_id", "fs.company_id::text = $%d"},
+		{"employee_id", "fs.employee_id::text = $%d"},
+		{"document_id", "fs.document_id::text = $%d"},
+		{"from", "fs.paid_on >= $%d::date"},
+		{"to", "fs.paid_on <= $%d::date"},
+	}
+	for _, f := range filters {
+		v := q.Get(f.param)
+		if v == "" {
+			continue
+		}
+		if f.param == "from" || f.param == "to" {
+			if _, err := time.Parse("2006-01-02", v); err != nil {
+				JSONError(w, http.StatusBadRequest, "Dates must be in YYYY-MM-DD format")
+				return
+			}
+		}
+		where += " AND " + fmt.Sprintf(f.clause, argIdx)
+		args = append(args, v)
+		argIdx++
+	}
+
+	var total int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM fine_settlements fs `+where, args...).Scan(&total); err != nil {
+		log.Printf("Error counting fine settlements: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch fine settlements")
+		return
+	}
+
+	args = append(args, limit, (page-1)*limit)
+	rows, err := pool.Query(ctx, fmt.Sprintf(`
+		SELECT %s, e.name, c.name
+		FROM fine_settlements fs
+		JOIN companies c ON c.id = fs.company_id
+		LEFT JOIN employees e ON e.id = fs.employee_id
+		%s
+		ORDER BY fs.paid_on DESC, fs.created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, fineSettlementCols, where, argIdx, argIdx+1), args...)
+	if err != nil {
+		log.Printf("Error fetching fine settlements: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch fine settlements")
+		return
+	}
+	defer rows.Close()
+
+	settlements := []models.FineSettlementWithDetails{}
+	for rows.Next() {
+		var s models.FineSettlementWithDetails
+		if err := rows.Scan(
+			&s.ID, &s.DocumentID, &s.EmployeeID, &s.CompanyID,
+			&s.DocumentType, &s.PenaltyFrom, &s.PenaltyTo,
+			&s.Amount, &s.EstimatedAmount, &s.PaidOn,
+			&s.Reference, &s.Notes, &s.ReceiptURL, &s.ReceiptName,
+			&s.CreatedBy, &s.CreatedAt,
+			&s.EmployeeName, &s.CompanyName,
+		); err != nil {
+			log.Printf("Error scanning fine settlement: %v", err)
+			continue
+		}
+		settlements = append(settlements, s)
+	}
+
+	JSON(w, http.StatusOK, PaginatedResponse{
+		Data: settlements,
+		Pagination: PaginationMeta{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: int(math.Ceil(float64(total) / float64(limit))),
+		},
+	})
+}
+
+// CreateSettlement handles POST /api/fines/settlements
+// Accepts JSON, or multipart/form-data with the same fields plus an optional
+// "receipt" file (PDF, JPG or PNG).
+func (h *FineHandler) CreateSettlement(w http.ResponseWriter, r *http.Request) {
+	var req models.FineSettlementRequest
+	var receipt multipart.File
+	var receiptHeader *multipart.FileHeader
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+		if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+			JSONError(w, http.StatusBadRequest, "File too large. Maximum size is 10MB.")
+			return
+		}
+		req = settlementRequestFromForm(r)
+		file, header, err := r.FormFile("receipt")
+		if err == nil {
+			defer file.Close()
+			receipt, receiptHeader = file, header
+		} else if !errors.Is(err, http.ErrMissingFile) {
+			JSONError(w, http.StatusBadRequest, "Could not read receipt file.")
+			return
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	if errs := req.Validate(); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	// Fine schedule and settlement state as last computed by docstatus
+	var employeeID, companyID, docType string
+	var expiry, settledThrough *time.Time
+	var graceDays int
+	var finePerDay, fineCap float64
+	var fineType string
+	err := pool.QueryRow(ctx, `
+		SELECT d.employee_id::text, e.company_id::text, d.document_type,
+			ds.expiry_date, COALESCE(ds.grace_days, 0),
+			COALESCE(ds.fine_per_day, 0)::float8, COALESCE(ds.fine_type, 'daily'),
+			COALESCE(ds.fine_cap, 0)::float8,
+			(SELECT MAX(penalty_to) FROM fine_settlements WHERE document_id = d.id)
+		FROM documents d
+		JOIN employees e ON e.id = d.employee_id
+		LEFT JOIN document_status ds ON ds.document_id = d.id
+		WHERE d.id::text = $1
+	`, req.DocumentID).Scan(
+		&employeeID, &companyID, &docType,
+		&expiry, &graceDays, &finePerDay, &fineType, &fineCap, &settledThrough,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Document not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error loading document for fine settlement: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to record fine settlement")
+		return
+	}
+	if !checkCompanyAccess(ctx, companyID) {
+		JSONError(w, http.StatusForbidden, "Access denied to this document")
+		return
+	}
+
+	// Default period: the day after the last settlement (or the first
+	// penalty day) through the payment date.
+	paidOn, _ := time.Parse("2006-01-02", req.PaidOn)
+	to := paidOn
+	if req.PenaltyTo != nil && *req.PenaltyTo != "" {
+		to, _ = time.Parse("2006-01-02", *req.PenaltyTo)
+	}
+	var from time.Time
+	switch {
+	case req.PenaltyFrom != nil && *req.PenaltyFrom != "":
+		from, _ = time.Parse("2006-01-02", *req.PenaltyFrom)
+	case settledThrough != nil:
+		from = settledThrough.AddDate(0, 0, 1)
+	case expiry != nil:
+		from = compliance.PenaltyStart(*expiry, graceDays)
+	default:
+		from = to
+	}
+	if to.Before(from) {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error": "Validation failed",
+			"details": map[string]string{
+				"penaltyFrom": "No unsettled penalty period before " + to.Format("2006-01-02"),
+			},
+		})
+		return
+	}
+
+	var overlaps bool
+	err = pool.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM fine_settlements
+			WHERE document_id::text = $1
+			  AND daterange(penalty_from, penalty_to, '[]') && daterange($2::date, $3::date, '[]')
+		)
+	`, req.DocumentID, from.Format("2006-01-02"), to.Format("2006-01-02")).Scan(&overlaps)
+	if err != nil {
+		log.Printf("Error checking fine settlement overlap: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to record fine settlement")
+		return
+	}
+	if overlaps {
+		JSONError(w, http.StatusConflict, "Part of this penalty period is already settled")
+		return
+	}
+
+	// What the engine had accrued for the same period
+	estimated := 0.0
+	if expiry != nil {
+		through := compliance.ComputeFine(*expiry, graceDays, finePerDay, fineType, fineCap, to)
+		before := compliance.ComputeFine(*expiry, graceDays, finePerDay, fineType, fineCap, from.AddDate(0, 0, -1))
+		estimated = math.Max(0, math.Round((through-before)*100)/100)
+	}
+
+	var receiptPath, receiptURL, receiptName *string
+	if receipt != nil {
+		contentType, ok := sniffUpload(w, receipt)
+		if !ok {
+			return
+		}
+		safeName := sanitizeFilename(receiptHeader.Filename)
+		path := fmt.Sprintf("fine-receipts/%s/%d_%s", companyID, time.Now().Unix(), safeName)
+		info, err := h.store.Save(ctx, path, receipt, contentType)
+		if err != nil {
+			log.Printf("Error saving fine receipt: %v", err)
+			JSONError(w, http.StatusInternalServerError, "Failed to save receipt")
+			return
+		}
+		receiptPath, receiptURL, receiptName = &path, &info.URL, &info.FileName
+	}
+
+	var s models.FineSettlement
+	err = scanFineSettlement(pool.QueryRow(ctx, `
+		INSERT INTO fine_settlements AS fs (
+			document_id, employee_id, company_id, document_type,
+			penalty_from, penalty_to, amount, estimated_amount, paid_on,
+			reference, notes, receipt_path, receipt_url, receipt_name, created_by
+		) VALUES ($1, $2, $3, $4, $5::date, $6::date, $7, $8, $9::date, $10, $11, $12, $13, $14, $15)
+		RETURNING `+fineSettlementCols,
+		req.DocumentID, employeeID, companyID, docType,
+		from.Format("2006-01-02"), to.Format("2006-01-02"), req.Amount, estimated, req.PaidOn,
+		strings.TrimSpace(req.Reference), strings.TrimSpace(req.Notes),
+		receiptPath, receiptURL, receiptName, nilIfEmpty(userID),
+	), &s)
+	if err != nil {
+		log.Printf("Error inserting fine settlement: %v", err)
+		if receiptPath != nil {
+			_ = h.store.Delete(context.Background(), *receiptPath)
+		}
+		JSONError(w, http.StatusInternalServerError, "Failed to record fine settlement")
+		return
+	}
+
+	refreshEmployeeStatus(ctx, pool, employeeID)
+
+	go logActivity(pool, userID, "settled_fine", "fine_settlement", s.ID, map[string]interface{}{
+		"documentId": req.DocumentID, "documentType": docType,
+		"amount": s.Amount, "estimatedAmount": s.EstimatedAmount,
+		"penaltyFrom": s.PenaltyFrom, "penaltyTo": s.PenaltyTo,
+	})
+
+	JSON(w, http.StatusCreated, map[string]interface{}{
+		"data":    s,
+		"message": "Fine settlement recorded",
+	})
+}
+
+// settlementRequestFromForm reads the settlement fields of a multipart form.
+func settlementRequestFromForm(r *http.Request) models.FineSettlementRequest {
+	req := models.FineSettlementRequest{
+		DocumentID: r.FormValue("documentId"),
+		PaidOn:     r.FormValue("paidOn"),
+		Reference:  r.FormValue("reference"),
+		Notes:      r.FormValue("notes"),
+	}
+	req.Amount, _ = strconv.ParseFloat(r.FormValue("amount"), 64)
+	if v := r.FormValue("penaltyFrom"); v != "" {
+		req.PenaltyFrom = &v
+	}
+	if v := r.FormValue("penaltyTo"); v != "" {
+		req.PenaltyTo = &v
+	}
+	return req
+}
+
+// DeleteSettlement handles DELETE /api/fines/settlements/{id} (admin-only).
+// The receipt file is removed and the document's exposure recomputed.
+func (h *FineHandler) DeleteSettlement(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	var employeeID, receiptPath *string
+	var companyID string
+	var amount float64
+	err := pool.QueryRow(ctx, `
+		SELECT employee_id::text, company_id::text, receipt_path, amount::float8
+		FROM fine_settlements WHERE id::text = $1
+	`, id).Scan(&employeeID, &companyID, &receiptPath, &amount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Fine settlement not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error loading fine settlement: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to delete fine settlement")
+		return
+	}
+	if !checkCompanyAccess(ctx, companyID) {
+		JSONError(w, http.StatusForbidden, "Access denied to this company")
+		return
+	}
+
+	if _, err := pool.Exec(ctx, `DELETE FROM fine_settlements WHERE id::text = $1`, id); err != nil {
+		log.Printf("Error deleting fine settlement: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to delete fine settlement")
+		return
+	}
+
+	if receiptPath != nil {
+		if err := h.store.Delete(ctx, *receiptPath); err != nil {
+			log.Printf("Error deleting fine receipt %s: %v", *receiptPath, err)
+		}
+	}
+	if employeeID != nil {
+		refreshEmployeeStatus(ctx, pool, *employeeID)
+	}
+
+	go logActivity(pool, userID, "deleted", "fine_settlement", id, map[string]interface{}{
+		"companyId": companyID, "amount": amount,
+	})
+
+	JSON(w, http.StatusOK, map[string]interface{}{"message": "Fine settlement deleted"})
+}
+
+// ── Reconciliation ───────────────────────────────────────────────
+
+// GetReconciliation handles GET /api/fines/reconciliation?from=&to=&company_id=
+// from/to are months (YYYY-MM, default the last 12 months). Compares the
+// engine's estimate against the amount paid per company and payment month,
+// with each company's current outstanding exposure.
+func (h *FineHandler) GetReconciliation(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	companyID := q.Get("company_id")
+	if companyID != "" && !checkCompanyAccess(r.Context(), companyID) {
+		JSONError(w, http.StatusForbidden, "Access denied to this company")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	today := reportToday(ctx, pool, companyID)
+	toMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	fromMonth := toMonth.AddDate(0, -11, 0)
+	for param, dst := range map[string]*time.Time{"from": &fromMonth, "to": &toMonth} {
+		if v := q.Get(param); v != "" {
+			t, err := time.Parse("2006-01", v)
+			if err != nil {
+				JSONError(w, http.StatusBadRequest, "from and to must be months in YYYY-MM format")
+				return
+			}
+			*dst = t
+		}
+	}
+	if toMonth.Before(fromMonth) {
+		JSONError(w, http.StatusBadRequest, "to must not be before from")
+		return
+	}
+
+	where := "WHERE fs.paid_on >= $1 AND fs.paid_on < $2"
+	args := []interface{}{fromMonth, toMonth.AddDate(0, 1, 0)}
+	argIdx := 3
+	where, args, argIdx = appendCompanyScope(ctx, where, args, argIdx, "fs.company_id")
+	if companyID != "" {
+		where += fmt.Sprintf(" AND fs.company_id::text = $%d", argIdx)
+		args = append(args, companyID)
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT c.id::text, c.name, to_char(fs.paid_on, 'YYYY-MM') AS month,
+			COUNT(*), SUM(fs.estimated_amount)::float8, SUM(fs.amount)::float8
+		FROM fine_settlements fs
+		JOIN companies c ON c.id = fs.company_id
+		`+where+`
+		GROUP BY c.id, c.name, month
+		ORDER BY c.name, month
+	`, args...)
+	if err != nil {
+		log.Printf("Error fetching fine reconciliation: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch fine reconciliation")
+		return
+	}
+	defer rows.Close()
+
+	result := models.FineReconciliation{
+		From:      fromMonth.Format("2006-01"),
+		To:        toMonth.Format("2006-01"),
+		Months:    []models.FineReconciliationMonth{},
+		ByCompany: []models.FineReconciliationCompany{},
+	}
+	byCompany := map[string]*models.FineReconciliationCompany{}
+	company := func(id, name string) *models.FineReconciliationCompany {
+		c, ok := byCompany[id]
+		if !ok {
+			c = &models.FineReconciliationCompany{CompanyID: id, CompanyName: name}
+			byCompany[id] = c
+		}
+		return c
+	}
+
+	for rows.Next() {
+		var m models.FineReconciliationMonth
+		if err := rows.Scan(&m.CompanyID, &m.CompanyName, &m.Month, &m.Settlements, &m.Estimated, &m.Paid); err != nil {
+			log.Printf("Error scanning fine reconciliation: %v", err)
+			continue
+		}
+		m.Variance = roundAED(m.Paid - m.Estimated)
+		result.Months = append(result.Months, m)
+
+		c := company(m.CompanyID, m.CompanyName)
+		c.Settlements += m.Settlements
+		c.Estimated += m.Estimated
+		c.Paid += m.Paid
+	}
+	rows.Close()
+
+	// Current outstanding exposure (already net of settlements)
+	outWhere := "WHERE ds.is_required AND ds.estimated_fine > 0 AND e.exit_type IS NULL"
+	outArgs := []interface{}{}
+	outIdx := 1
+	outWhere, outArgs, outIdx = appendCompanyScope(ctx, outWhere, outArgs, outIdx, "ds.company_id")
+	if companyID != "" {
+		outWhere += fmt.Sprintf(" AND ds.company_id::text = $%d", outIdx)
+		outArgs = append(outArgs, companyID)
+	}
+	outRows, err := pool.Query(ctx, `
+		SELECT c.id::text, c.name, SUM(ds.estimated_fine)::float8
+		FROM document_status ds
+		JOIN employees e ON e.id = ds.employee_id
+		JOIN companies c ON c.id = ds.company_id
+		`+outWhere+`
+		GROUP BY c.id, c.name
+	`, outArgs...)
+	if err != nil {
+		log.Printf("Error fetching outstanding fines: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch fine reconciliation")
+		return
+	}
+	defer outRows.Close()
+	for outRows.Next() {
+		var id, name string
+		var outstanding float64
+		if err := outRows.Scan(&id, &name, &outstanding); err != nil {
+			log.Printf("Error scanning outstanding fines: %v", err)
+			continue
+		}
+		company(id, name).Outstanding = outstanding
+	}
+
+	for _, c := range byCompany {
+		c.Estimated, c.Paid = roundAED(c.Estimated), roundAED(c.Paid)
+		c.Variance = roundAED(c.Paid - c.Estimated)
+		c.Outstanding = roundAED(c.Outstanding)
+		result.ByCompany = append(result.ByCompany, *c)
+
+		result.Total.Settlements += c.Settlements
+		result.Total.Estimated += c.Estimated
+		result.Total.Paid += c.Paid
+		result.Total.Outstanding += c.Outstanding
+	}
+	sort.Slice(result.ByCompany, func(i, j int) bool {
+		return result.ByCompany[i].CompanyName < result.ByCompany[j].CompanyName
+	})
+	result.Total.Estimated = roundAED(result.Total.Estimated)
+	result.Total.Paid = roundAED(result.Total.Paid)
+	result.Total.Variance = roundAED(result.Total.Paid - result.Total.Estimated)
+	result.Total.Outstanding = roundAED(result.Total.Outstanding)
+
+	JSON(w, http.StatusOK, map[string]interface{}{"data": result})
+}
+
+// roundAED rounds an amount to fils.
+func roundAED(v float64) float64 {
+	return math.Round(v*100) / 100
+}
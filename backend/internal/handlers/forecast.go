@@ -0,0 +1,379 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"manpower-backend/internal/compliance"
+	"manpower-backend/internal/models"
+)
+
+// ── Fine Forecast ────────────────────────────────────────────────
+// Projects fine exposure at future dates assuming nothing is renewed, and
+// optionally the savings from a set of hypothetical renewals. Inputs (expiry,
+// grace in calendar days, fine schedule) come from document_status, so the
+// forecast starts from exactly what the dashboard shows today.
+
+// GetFineForecast handles GET /api/dashboard/fine-forecast?dates=&company_id=
+// dates is a comma-separated list of YYYY-MM-DD (defaults: today, the 1st of
+// next month and the current quarter end).
+func (h *DashboardHandler) GetFineForecast(w http.ResponseWriter, r *http.Request) {
+	req := models.FineForecastRequest{}
+	if dates := r.URL.Query().Get("dates"); dates != "" {
+		for _, d := range strings.Split(dates, ",") {
+			req.Dates = append(req.Dates, strings.TrimSpace(d))
+		}
+	}
+	if companyID := r.URL.Query().Get("company_id"); companyID != "" {
+		req.CompanyID = &companyID
+	}
+	h.fineForecast(w, r, &req)
+}
+
+// SimulateFineForecast handles POST /api/dashboard/fine-forecast
+// Same as GET, with hypothetical renewals ({documentId, renewBy}) whose
+// savings are reported per date.
+func (h *DashboardHandler) SimulateFineForecast(w http.ResponseWriter, r *http.Request) {
+	var req models.FineForecastRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	h.fineForecast(w, r, &req)
+}
+
+// forecastDoc is one document with fine exposure, as loaded from document_status.
+type forecastDoc struct {
+	DocumentID   string
+	EmployeeID   string
+	EmployeeName string
+	CompanyID    string
+	CompanyName  string
+	DocumentType string
+	ExpiryDate   time.Time
+	GraceDays    int
+	FinePerDay   float64
+	FineType     string
+	FineCap      float64
+
+	SettledThrough *time.Time // last day covered by the fine ledger
+}
+
+func (h *DashboardHandler) fineForecast(w http.ResponseWriter, r *http.Request, req *models.FineForecastRequest) {
+	if errs := req.Validate(); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+	if req.CompanyID != nil && !checkCompanyAccess(r.Context(), *req.CompanyID) {
+		JSONError(w, http.StatusForbidden, "Access denied to this company")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	dates := req.Dates
+	if len(dates) == 0 {
+		companyID := ""
+		if req.CompanyID != nil {
+			companyID = *req.CompanyID
+		}
+		dates = defaultForecastDates(reportToday(ctx, pool, companyID))
+	}
+	sort.Strings(dates)
+	dates = dedupeSorted(dates)
+	forecastDates := make([]time.Time, len(dates))
+	for i, d := range dates {
+		forecastDates[i], _ = time.Parse("2006-01-02", d)
+	}
+
+	where := "WHERE ds.is_required AND e.exit_type IS NULL AND ds.expiry_date IS NOT NULL AND ds.fine_per_day > 0"
+	args := []interface{}{}
+	argIdx := 1
+	where, args, argIdx = appendCompanyScope(ctx, where, args, argIdx, "e.company_id")
+	if req.CompanyID != nil {
+		where += fmt.Sprintf(" AND e.company_id::text = $%d", argIdx)
+		args = append(args, *req.CompanyID)
+		argIdx++
+	}
+
+	// Hypothetical renewals must refer to documents the user can see
+	renewals := map[string]time.Time{}
+	if len(req.Renewals) > 0 {
+		ids := make([]string, len(req.Renewals))
+		for i, rn := range req.Renewals {
+			ids[i] = rn.DocumentID
+		}
+		known, err := visibleDocumentIDs(ctx, pool, ids)
+		if err != nil {
+			log.Printf("Error checking forecast renewals: %v", err)
+			JSONError(w, http.StatusInternalServerError, "Failed to compute fine forecast")
+			return
+		}
+		errs := map[string]string{}
+		for i, rn := range req.Renewals {
+			if !known[rn.DocumentID] {
+				errs[fmt.Sprintf("renewals[%d].documentId", i)] = "Document not found"
+				continue
+			}
+			renewals[rn.DocumentID], _ = time.Parse("2006-01-02", rn.RenewBy)
+		}
+		if len(errs) > 0 {
+			JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+				"error":   "Validation failed",
+				"details": errs,
+			})
+			return
+		}
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT ds.document_id, e.id, e.name, c.id, c.name, ds.document_type,
+			ds.expiry_date, ds.grace_days, ds.fine_per_day::float8, ds.fine_type, ds.fine_cap::float8,
+			ds.settled_through
+		FROM document_status ds
+		JOIN employees e ON ds.employee_id = e.id
+		JOIN companies c ON e.company_id = c.id
+		`+where, args...)
+	if err != nil {
+		log.Printf("Error loading documents for fine forecast: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to compute fine forecast")
+		return
+	}
+	defer rows.Close()
+
+	forecast := newForecastAccumulator(dates)
+	for rows.Next() {
+		var d forecastDoc
+		if err := rows.Scan(
+			&d.DocumentID, &d.EmployeeID, &d.EmployeeName, &d.CompanyID, &d.CompanyName,
+			&d.DocumentType, &d.ExpiryDate, &d.GraceDays, &d.FinePerDay, &d.FineType, &d.FineCap,
+			&d.SettledThrough,
+		); err != nil {
+			log.Printf("Error scanning forecast document: %v", err)
+			continue
+		}
+
+		var renewedOn *time.Time
+		if t, ok := renewals[d.DocumentID]; ok {
+			renewedOn = &t
+		}
+		// Fines already settled are not exposure at any date
+		settled := 0.0
+		if d.SettledThrough != nil {
+			settled = compliance.ComputeFine(d.ExpiryDate, d.GraceDays, d.FinePerDay, d.FineType, d.FineCap, *d.SettledThrough)
+		}
+		for i, at := range forecastDates {
+			base := math.Max(0, compliance.FineAt(d.ExpiryDate, d.GraceDays, d.FinePerDay, d.FineType, d.FineCap, at, nil)-settled)
+			scenario := math.Max(0, compliance.FineAt(d.ExpiryDate, d.GraceDays, d.FinePerDay, d.FineType, d.FineCap, at, renewedOn)-settled)
+			inPenalty := compliance.ComputeStatus(&d.ExpiryDate, d.GraceDays, "", at) == compliance.StatusPenaltyActive
+			forecast.add(&d, i, base, scenario, inPenalty)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error loading documents for fine forecast: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to compute fine forecast")
+		return
+	}
+
+	result := forecast.result()
+	result.Renewals = len(renewals)
+	JSON(w, http.StatusOK, map[string]interface{}{"data": result})
+}
+
+// visibleDocumentIDs returns which of ids exist within the user's company scope.
+func visibleDocumentIDs(ctx context.Context, q querier, ids []string) (map[string]bool, error) {
+	query := `
+		SELECT d.id::text FROM documents d
+		JOIN employees e ON e.id = d.employee_id
+		WHERE d.id::text = ANY($1)`
+	scopeFilter, scopeArg := companyScopeClause(ctx, 2, "e.company_id")
+	args := []interface{}{ids}
+	if scopeArg != nil {
+		query += scopeFilter
+		args = append(args, scopeArg)
+	}
+
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	known := map[string]bool{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		known[id] = true
+	}
+	return known, rows.Err()
+}
+
+// reportToday returns today's date for a report: in the company's timezone
+// when companyID is set, otherwise the latest local date among the caller's
+// companies so that every company's current day is included.
+func reportToday(ctx context.Context, q querier, companyID string) time.Time {
+	where := "WHERE 1=1"
+	args := []interface{}{}
+	argIdx := 1
+	where, args, argIdx = appendCompanyScope(ctx, where, args, argIdx, "c.id")
+	if companyID != "" {
+		where += fmt.Sprintf(" AND c.id::text = $%d", argIdx)
+		args = append(args, companyID)
+	}
+
+	var today *time.Time
+	if err := q.QueryRow(ctx, `SELECT MAX(company_today(c.id)) FROM companies c `+where, args...).Scan(&today); err != nil || today == nil {
+		t := compliance.Today(time.Now(), compliance.DefaultTimezone)
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return *today
+}
+
+// defaultForecastDates returns today, the 1st of next month and the last
+// day of the current quarter.
+func defaultForecastDates(today time.Time) []string {
+	y, m, d := today.Date()
+	quarterEndMonth := ((int(m)-1)/3 + 1) * 3 // 3, 6, 9 or 12
+	return []string{
+		time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Format("2006-01-02"),
+		time.Date(y, m+1, 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02"),
+		time.Date(y, time.Month(quarterEndMonth)+1, 0, 0, 0, 0, 0, time.UTC).Format("2006-01-02"),
+	}
+}
+
+// dedupeSorted removes adjacent duplicates from a sorted slice.
+func dedupeSorted(values []string) []string {
+	out := values[:0]
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// ── Forecast Aggregation ─────────────────────────────────────────
+
+type forecastSeries struct {
+	breakdown models.ForecastBreakdown
+	peak      float64
+}
+
+type forecastAccumulator struct {
+	dates     []string
+	totals    []models.ForecastPoint
+	companies map[string]*forecastSeries
+	docTypes  map[string]*forecastSeries
+	employees map[string]*forecastSeries
+}
+
+func newForecastAccumulator(dates []string) *forecastAccumulator {
+	return &forecastAccumulator{
+		dates:     dates,
+		totals:    newForecastPoints(dates),
+		companies: map[string]*forecastSeries{},
+		docTypes:  map[string]*forecastSeries{},
+		employees: map[string]*forecastSeries{},
+	}
+}
+
+func newForecastPoints(dates []string) []models.ForecastPoint {
+	points := make([]models.ForecastPoint, len(dates))
+	for i, d := range dates {
+		points[i].Date = d
+	}
+	return points
+}
+
+func (f *forecastAccumulator) series(m map[string]*forecastSeries, key, label, companyName string) *forecastSeries {
+	s, ok := m[key]
+	if !ok {
+		s = &forecastSeries{breakdown: models.ForecastBreakdown{
+			Key: key, Label: label, CompanyName: companyName,
+			Points: newForecastPoints(f.dates),
+		}}
+		m[key] = s
+	}
+	return s
+}
+
+// add records one document's baseline and scenario fine at date index i.
+func (f *forecastAccumulator) add(d *forecastDoc, i int, base, scenario float64, inPenalty bool) {
+	targets := []*models.ForecastPoint{
+		&f.totals[i],
+		&f.series(f.companies, d.CompanyID, d.CompanyName, "").breakdown.Points[i],
+		&f.series(f.docTypes, d.DocumentType, compliance.DisplayName(d.DocumentType), "").breakdown.Points[i],
+	}
+	if base > 0 {
+		targets = append(targets, &f.series(f.employees, d.EmployeeID, d.EmployeeName, d.CompanyName).breakdown.Points[i])
+	}
+	for _, p := range targets {
+		p.Exposure += base
+		p.ScenarioExposure += scenario
+		p.Savings += base - scenario
+		if inPenalty {
+			p.DocumentsInPenalty++
+		}
+	}
+}
+
+func (f *forecastAccumulator) result() models.FineForecast {
+	return models.FineForecast{
+		Dates:          f.dates,
+		Totals:         roundForecastPoints(f.totals),
+		ByCompany:      sortedForecastSeries(f.companies),
+		ByDocumentType: sortedForecastSeries(f.docTypes),
+		ByEmployee:     sortedForecastSeries(f.employees),
+	}
+}
+
+// sortedForecastSeries orders series by peak exposure, highest first,
+// dropping those with no exposure at any date.
+func sortedForecastSeries(m map[string]*forecastSeries) []models.ForecastBreakdown {
+	list := make([]*forecastSeries, 0, len(m))
+	for _, s := range m {
+		for _, p := range s.breakdown.Points {
+			s.peak = math.Max(s.peak, p.Exposure)
+		}
+		if s.peak > 0 {
+			list = append(list, s)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].peak != list[j].peak {
+			return list[i].peak > list[j].peak
+		}
+		return list[i].breakdown.Label < list[j].breakdown.Label
+	})
+
+	out := make([]models.ForecastBreakdown, len(list))
+	for i, s := range list {
+		s.breakdown.Points = roundForecastPoints(s.breakdown.Points)
+		out[i] = s.breakdown
+	}
+	return out
+}
+
+func roundForecastPoints(points []models.ForecastPoint) []models.ForecastPoint {
+	for i := range points {
+		points[i].Exposure = math.Round(points[i].Exposure*100) / 100
+		points[i].ScenarioExposure = math.Round(points[i].ScenarioExposure*100) / 100
+		points[i].Savings = math.Round(points[i].Savings*100) / 100
+	}
+	return points
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"manpower-backend/internal/ctxkeys"
+	"manpower-backend/internal/models"
+)
+
+// ── Public Holidays ──────────────────────────────────────────
+// Holidays are skipped (with each company's weekend days) when a compliance
+// rule's grace period is counted in working days — see grace_days_for()
+// in migration 015. Global rows apply to every company.
+
+const publicHolidayCols = `id, company_id, holiday_date::text, name, created_at::text`
+
+// ListPublicHolidays handles GET /api/admin/public-holidays?year=&company_id=
+// With company_id, returns the global holidays plus that company's own.
+func (h *AdminHandler) ListPublicHolidays(w http.ResponseWriter, r *http.Request) {
+	companyID := r.URL.Query().Get("company_id")
+	yearStr := r.URL.Query().Get("year")
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	query := `SELECT ` + publicHolidayCols + ` FROM public_holidays WHERE 1=1`
+	args := []interface{}{}
+	if companyID != "" {
+		args = append(args, companyID)
+		query += ` AND (company_id IS NULL OR company_id = $1)`
+	} else {
+		query += ` AND company_id IS NULL`
+	}
+	if yearStr != "" {
+		year, err := strconv.Atoi(yearStr)
+		if err != nil || year < 2000 || year > 2100 {
+			JSONError(w, http.StatusBadRequest, "Invalid year")
+			return
+		}
+		args = append(args, year)
+		query += ` AND EXTRACT(YEAR FROM holiday_date) = $` + strconv.Itoa(len(args))
+	}
+	query += ` ORDER BY holiday_date, company_id NULLS FIRST`
+
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to list public holidays: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch public holidays")
+		return
+	}
+	defer rows.Close()
+
+	holidays := []models.PublicHoliday{}
+	for rows.Next() {
+		var ph models.PublicHoliday
+		if err := rows.Scan(&ph.ID, &ph.CompanyID, &ph.Date, &ph.Name, &ph.CreatedAt); err != nil {
+			log.Printf("Failed to scan public holiday: %v", err)
+			continue
+		}
+		holidays = append(holidays, ph)
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{"data": holidays})
+}
+
+// CreatePublicHoliday handles POST /api/admin/public-holidays (admin-only).
+func (h *AdminHandler) CreatePublicHoliday(w http.ResponseWriter, r *http.Request) {
+	var req models.PublicHolidayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	if errs := req.Validate(); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	if req.CompanyID != nil {
+		var exists bool
+		_ = pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM companies WHERE id::text = $1)`, *req.CompanyID).Scan(&exists)
+		if !exists {
+			JSONError(w, http.StatusUnprocessableEntity, "Company not found")
+			return
+		}
+	}
+
+	var ph models.PublicHoliday
+	err := pool.QueryRow(ctx, `
+		INSERT INTO public_holidays (company_id, holiday_date, name)
+		VALUES ($1, $2, $3)
+		RETURNING `+publicHolidayCols,
+		req.CompanyID, req.Date, req.Name,
+	).Scan(&ph.ID, &ph.CompanyID, &ph.Date, &ph.Name, &ph.CreatedAt)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			JSONError(w, http.StatusConflict, "A holiday already exists on this date")
+			return
+		}
+		log.Printf("Failed to create public holiday: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to create public holiday")
+		return
+	}
+
+	go logActivity(pool, userID, "created", "public_holiday", ph.ID, map[string]interface{}{
+		"date": ph.Date, "name": ph.Name, "companyId": ph.CompanyID,
+	})
+	refreshStatusAsync(pool, nilStringDefault(ph.CompanyID, ""))
+
+	JSON(w, http.StatusCreated, map[string]interface{}{
+		"data":    ph,
+		"message": "Public holiday added",
+	})
+}
+
+// DeletePublicHoliday handles DELETE /api/admin/public-holidays/{id} (admin-only).
+func (h *AdminHandler) DeletePublicHoliday(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	var companyID *string
+	err := pool.QueryRow(ctx, `DELETE FROM public_holidays WHERE id = $1 RETURNING company_id`, id).Scan(&companyID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Public holiday not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to delete public holiday: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to delete public holiday")
+		return
+	}
+
+	go logActivity(pool, userID, "deleted", "public_holiday", id, nil)
+	refreshStatusAsync(pool, nilStringDefault(companyID, ""))
+
+	JSON(w, http.StatusOK, map[string]interface{}{"message": "Public holiday deleted"})
+}
@@ -0,0 +1,584 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"manpower-backend/internal/compliance"
+	"manpower-backend/internal/ctxkeys"
+	"manpower-backend/internal/database"
+	"manpower-backend/internal/docstatus"
+	"manpower-backend/internal/models"
+)
+
+// LeaveHandler handles leave types, leave requests and balances.
+type LeaveHandler struct {
+	db database.Service
+}
+
+// NewLeaveHandler creates a new LeaveHandler.
+func NewLeaveHandler(db database.Service) *LeaveHandler {
+	return &LeaveHandler{db: db}
+}
+
+// ── Leave ────────────────────────────────────────────────────────
+// Leave requests (migration 030) are created pending and approved, rejected
+// or cancelled by a writer. Approved leave puts the employee on_leave for
+// its dates (docstatus.SyncLeaveStatuses, here on every decision and daily
+// from the notifier). Balances are per calendar year; a request counts in
+// the year it starts. Annual leave accrues per compliance.AnnualLeaveAccrued
+// and carries over up to the type's cap; other types have a fixed yearly
+// (or once-per-employment) allowance or none.
+
+const leaveRequestCols = `lr.id, lr.employee_id, lr.company_id, lr.leave_type,
+	lr.start_date::text, lr.end_date::text, lr.days, lr.status, lr.reason, lr.decision_notes,
+	lr.on_leave_applied, lr.requested_by, lr.decided_by, lr.decided_at::text,
+	lr.created_at::text, lr.updated_at::text, lt.name, lt.is_paid, e.name, co.name`
+
+const leaveRequestFrom = `
+	FROM leave_requests lr
+	JOIN leave_types lt ON lt.code = lr.leave_type
+	JOIN employees e ON e.id = lr.employee_id
+	JOIN companies co ON co.id = lr.company_id`
+
+// scanLeaveRequest scans leaveRequestCols.
+func scanLeaveRequest(row pgx.Row, l *models.LeaveRequest) error {
+	return row.Scan(
+		&l.ID, &l.EmployeeID, &l.CompanyID, &l.LeaveType,
+		&l.StartDate, &l.EndDate, &l.Days, &l.Status, &l.Reason, &l.DecisionNotes,
+		&l.OnLeaveApplied, &l.RequestedBy, &l.DecidedBy, &l.DecidedAt,
+		&l.CreatedAt, &l.UpdatedAt, &l.LeaveTypeName, &l.IsPaid, &l.EmployeeName, &l.CompanyName,
+	)
+}
+
+func loadLeaveRequest(ctx context.Context, q querier, id string) (models.LeaveRequest, error) {
+	var l models.LeaveRequest
+	err := scanLeaveRequest(q.QueryRow(ctx, `SELECT `+leaveRequestCols+leaveRequestFrom+` WHERE lr.id = $1`, id), &l)
+	return l, err
+}
+
+func loadLeaveTypes(ctx context.Context, q querier) ([]models.LeaveType, error) {
+	rows, err := q.Query(ctx, `
+		SELECT code, name, is_paid, accrues, annual_days::float8, once_per_employment,
+			max_carry_over::float8, sort_order
+		FROM leave_types ORDER BY sort_order, name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types := []models.LeaveType{}
+	for rows.Next() {
+		var t models.LeaveType
+		if err := rows.Scan(&t.Code, &t.Name, &t.IsPaid, &t.Accrues, &t.AnnualDays,
+			&t.OncePerEmployment, &t.MaxCarryOver, &t.SortOrder); err != nil {
+			return nil, err
+		}
+		types = append(types, t)
+	}
+	return types, rows.Err()
+}
+
+// leaveBalances computes the employee's balance for every leave type as of
+// asOf. Approved leave starting after asOf's year counts in that year.
+func leaveBalances(ctx context.Context, q querier, employeeID string, joining, asOf time.Time, types []models.LeaveType) ([]models.LeaveBalance, error) {
+	rows, err := q.Query(ctx, `
+		SELECT leave_type, status, EXTRACT(YEAR FROM start_date)::int, SUM(days)::float8
+		FROM leave_requests
+		WHERE employee_id = $1 AND status IN ('approved', 'pending')
+		GROUP BY 1, 2, 3
+	`, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type key struct {
+		leaveType, status string
+	}
+	byYear := map[key]map[int]float64{}
+	for rows.Next() {
+		var k key
+		var year int
+		var days float64
+		if err := rows.Scan(&k.leaveType, &k.status, &year, &days); err != nil {
+			return nil, err
+		}
+		if year > asOf.Year() {
+			year = asOf.Year()
+		}
+		if byYear[k] == nil {
+			byYear[k] = map[int]float64{}
+		}
+		byYear[k][year] += days
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sum := func(years map[int]float64, onlyYear int) float64 {
+		total := 0.0
+		for y, d := range years {
+			if onlyYear == 0 || y == onlyYear {
+				total += d
+			}
+		}
+		return total
+	}
+
+	balances := []models.LeaveBalance{}
+	for _, t := range types {
+		approved := byYear[key{t.Code, "approved"}]
+		pending := byYear[key{t.Code, "pending"}]
+		b := models.LeaveBalance{LeaveType: t.Code, Name: t.Name, IsPaid: t.IsPaid}
+
+		switch {
+		case t.Accrues:
+			years := compliance.AnnualLeaveYears(joining, asOf, approved, t.MaxCarryOver)
+			for _, y := range years {
+				b.Years = append(b.Years, models.LeaveYear{
+					Year: y.Year, CarriedIn: y.CarriedIn, Accrued: y.Accrued, Taken: y.Taken,
+					Balance: y.Balance, CarriedOut: y.CarriedOut, Forfeited: y.Forfeited,
+				})
+			}
+			b.Pending = sum(pending, 0)
+			if len(years) > 0 {
+				cur := years[len(years)-1]
+				entitlement := cur.CarriedIn + cur.Accrued
+				available := cur.Balance - b.Pending
+				b.Entitlement, b.Taken, b.Available = &entitlement, cur.Taken, &available
+			} else {
+				zero := 0.0
+				available := -b.Pending
+				b.Entitlement, b.Available = &zero, &available
+			}
+		case t.OncePerEmployment:
+			b.Taken, b.Pending = sum(approved, 0), sum(pending, 0)
+		default:
+			b.Taken, b.Pending = sum(approved, asOf.Year()), sum(pending, asOf.Year())
+		}
+		if !t.Accrues && t.AnnualDays != nil {
+			available := *t.AnnualDays - b.Taken - b.Pending
+			b.Entitlement, b.Available = t.AnnualDays, &available
+		}
+		balances = append(balances, b)
+	}
+	return balances, nil
+}
+
+// syncEmployeeLeave applies approved leave to the employee's status and
+// reconciles their document slots if it changed.
+func syncEmployeeLeave(ctx context.Context, q querier, employeeID string) {
+	previous, err := requiredSlots(ctx, q, "e.id = $1", employeeID)
+	if err != nil {
+		log.Printf("Error loading document slots for employee %s: %v", employeeID, err)
+		return
+	}
+	changed, err := docstatus.SyncLeaveStatuses(ctx, q, employeeID)
+	if err != nil {
+		log.Printf("Error applying leave status for employee %s: %v", employeeID, err)
+		return
+	}
+	if len(changed) == 0 {
+		return
+	}
+	var emp models.Employee
+	if err := q.QueryRow(ctx, `
+		SELECT id, company_id, trade, gender, nationality, COALESCE(status, 'active')
+		FROM employees WHERE id = $1
+	`, employeeID).Scan(&emp.ID, &emp.CompanyID, &emp.Trade, &emp.Gender, &emp.Nationality, &emp.Status); err != nil {
+		log.Printf("Error loading employee %s: %v", employeeID, err)
+		return
+	}
+	if _, err := reconcileDocumentSlots(ctx, q, &emp, previous[employeeID]); err != nil {
+		log.Printf("Error reconciling document slots for employee %s: %v", employeeID, err)
+	}
+}
+
+// ListTypes handles GET /api/leave-types
+func (h *LeaveHandler) ListTypes(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	types, err := loadLeaveTypes(ctx, h.db.GetPool())
+	if err != nil {
+		log.Printf("Error fetching leave types: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch leave types")
+		return
+	}
+	JSON(w, http.StatusOK, map[string]interface{}{"data": types})
+}
+
+// List handles GET /api/leave-requests
+// Filters: status, company_id, employee_id, leave_type, from / to (leave
+// overlapping the range). Pagination: page, limit (default 20, max 100).
+func (h *LeaveHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	where := "WHERE 1=1"
+	args := []interface{}{}
+	argIdx := 1
+	where, args, argIdx = appendCompanyScope(ctx, where, args, argIdx, "lr.company_id")
+
+	filters := []struct{ param, clause string }{
+		{"status", "lr.status = $%d"},
+		{"company_id", "lr.company_id::text = $%d"},
+		{"employee_id", "lr.employee_id::text = $%d"},
+		{"leave_type", "lr.leave_type = $%d"},
+		{"from", "lr.end_date >= $%d::date"},
+		{"to", "lr.start_date <= $%d::date"},
+	}
+	for _, f := range filters {
+		if v := q.Get(f.param); v != "" {
+			where += " AND " + fmt.Sprintf(f.clause, argIdx)
+			args = append(args, v)
+			argIdx++
+		}
+	}
+
+	var total int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM leave_requests lr `+where, args...).Scan(&total); err != nil {
+		log.Printf("Error counting leave requests: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch leave requests")
+		return
+	}
+
+	args = append(args, limit, (page-1)*limit)
+	rows, err := pool.Query(ctx, fmt.Sprintf(`
+		SELECT %s %s
+		%s
+		ORDER BY lr.status = 'pending' DESC, lr.start_date DESC, lr.created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, leaveRequestCols, leaveRequestFrom, where, argIdx, argIdx+1), args...)
+	if err != nil {
+		log.Printf("Error fetching leave requests: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch leave requests")
+		return
+	}
+	defer rows.Close()
+
+	requests := []models.LeaveRequest{}
+	for rows.Next() {
+		var l models.LeaveRequest
+		if err := scanLeaveRequest(rows, &l); err != nil {
+			log.Printf("Error scanning leave request: %v", err)
+			continue
+		}
+		requests = append(requests, l)
+	}
+
+	JSON(w, http.StatusOK, PaginatedResponse{
+		Data: requests,
+		Pagination: PaginationMeta{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: int(math.Ceil(float64(total) / float64(limit))),
+		},
+	})
+}
+
+// Balances handles GET /api/employees/{id}/leave-balances?as_of=YYYY-MM-DD
+// as_of defaults to today in the company's time zone.
+func (h *LeaveHandler) Balances(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !checkEmployeeAccess(r.Context(), h.db.GetPool(), id) {
+		JSONError(w, http.StatusForbidden, "Access denied to this employee")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	var joining, today time.Time
+	err := pool.QueryRow(ctx,
+		`SELECT joining_date, company_today(company_id) FROM employees WHERE id = $1`, id,
+	).Scan(&joining, &today)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Employee not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching employee %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch leave balances")
+		return
+	}
+	asOf := today
+	if v := r.URL.Query().Get("as_of"); v != "" {
+		if asOf, err = time.Parse("2006-01-02", v); err != nil {
+			JSONError(w, http.StatusBadRequest, "as_of must be in YYYY-MM-DD format")
+			return
+		}
+	}
+
+	types, err := loadLeaveTypes(ctx, pool)
+	var balances []models.LeaveBalance
+	if err == nil {
+		balances, err = leaveBalances(ctx, pool, id, joining, asOf, types)
+	}
+	if err != nil {
+		log.Printf("Error computing leave balances for employee %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch leave balances")
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"data": balances,
+		"asOf": asOf.Format("2006-01-02"),
+	})
+}
+
+// Create handles POST /api/leave-requests
+// The request is created pending. It must start on or after the joining
+// date, not overlap the employee's other pending or approved leave, and fit
+// the balance of its type as of the start date.
+func (h *LeaveHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateLeaveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	days, errs := req.Validate()
+	if len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	if !checkEmployeeAccess(r.Context(), h.db.GetPool(), req.EmployeeID) {
+		JSONError(w, http.StatusForbidden, "Access denied to this employee")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	var joining time.Time
+	var exited bool
+	err := pool.QueryRow(ctx,
+		`SELECT joining_date, exit_type IS NOT NULL FROM employees WHERE id::text = $1`, req.EmployeeID,
+	).Scan(&joining, &exited)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Employee not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching employee %s: %v", req.EmployeeID, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to create leave request")
+		return
+	}
+	if exited {
+		JSONError(w, http.StatusConflict, "Cannot request leave for an exited employee")
+		return
+	}
+
+	start, _ := time.Parse("2006-01-02", req.StartDate)
+	if start.Before(joining) {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": map[string]string{"startDate": "Leave cannot start before the joining date"},
+		})
+		return
+	}
+
+	types, err := loadLeaveTypes(ctx, pool)
+	if err != nil {
+		log.Printf("Error fetching leave types: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to create leave request")
+		return
+	}
+	known := false
+	for _, t := range types {
+		known = known || t.Code == req.LeaveType
+	}
+	if !known {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": map[string]string{"leaveType": "Unknown leave type"},
+		})
+		return
+	}
+
+	var overlapping bool
+	if err := pool.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM leave_requests
+			WHERE employee_id::text = $1 AND status IN ('pending', 'approved')
+			  AND start_date <= $3::date AND end_date >= $2::date
+		)
+	`, req.EmployeeID, req.StartDate, req.EndDate).Scan(&overlapping); err != nil {
+		log.Printf("Error checking overlapping leave: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to create leave request")
+		return
+	}
+	if overlapping {
+		JSONError(w, http.StatusConflict, "The employee already has leave requested or approved for these dates")
+		return
+	}
+
+	balances, err := leaveBalances(ctx, pool, req.EmployeeID, joining, start, types)
+	if err != nil {
+		log.Printf("Error computing leave balances for employee %s: %v", req.EmployeeID, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to create leave request")
+		return
+	}
+	for _, b := range balances {
+		if b.LeaveType == req.LeaveType && b.Available != nil && *b.Available < float64(days) {
+			JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+				"error": "Validation failed",
+				"details": map[string]string{
+					"endDate": fmt.Sprintf("%d days requested but only %g days of %s are available", days, math.Max(*b.Available, 0), b.Name),
+				},
+			})
+			return
+		}
+	}
+
+	var id string
+	err = pool.QueryRow(ctx, `
+		INSERT INTO leave_requests (employee_id, company_id, leave_type, start_date, end_date,
+		    days, reason, requested_by)
+		SELECT e.id, e.company_id, $2, $3, $4, $5, $6, $7
+		FROM employees e WHERE e.id::text = $1
+		RETURNING id
+	`, req.EmployeeID, req.LeaveType, req.StartDate, req.EndDate, days, req.Reason, nilIfEmpty(userID)).Scan(&id)
+	if err != nil {
+		log.Printf("Error creating leave request: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to create leave request")
+		return
+	}
+
+	l, err := loadLeaveRequest(ctx, pool, id)
+	if err != nil {
+		log.Printf("Error fetching leave request %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch leave request")
+		return
+	}
+
+	go logActivity(pool, userID, "requested", "leave_request", id, map[string]interface{}{
+		"employeeId": req.EmployeeID, "leaveType": req.LeaveType,
+		"startDate": req.StartDate, "endDate": req.EndDate, "days": days,
+	})
+
+	JSON(w, http.StatusCreated, map[string]interface{}{
+		"data":    l,
+		"message": "Leave requested",
+	})
+}
+
+// Approve handles POST /api/leave-requests/{id}/approve (pending only).
+func (h *LeaveHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, "approved", `status = 'pending'`, "Only pending leave requests can be approved")
+}
+
+// Reject handles POST /api/leave-requests/{id}/reject (pending only).
+func (h *LeaveHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, "rejected", `status = 'pending'`, "Only pending leave requests can be rejected")
+}
+
+// Cancel handles POST /api/leave-requests/{id}/cancel
+// Pending or approved leave that has not ended can be cancelled; an employee
+// already on this leave returns to active.
+func (h *LeaveHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, "cancelled",
+		`status IN ('pending', 'approved') AND end_date >= company_today(company_id)`,
+		"Only pending or approved leave that has not ended can be cancelled")
+}
+
+// decide moves a leave request to status if it matches the allowed
+// condition, then applies the result to the employee's status.
+func (h *LeaveHandler) decide(w http.ResponseWriter, r *http.Request, status, allowed, conflictMsg string) {
+	id := chi.URLParam(r, "id")
+	if !checkLeaveRequestAccess(r.Context(), h.db.GetPool(), id) {
+		JSONError(w, http.StatusForbidden, "Access denied to this leave request")
+		return
+	}
+
+	var req models.LeaveDecisionRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	var employeeID string
+	err := pool.QueryRow(ctx, `
+		UPDATE leave_requests SET
+			status = $2, decision_notes = $3, decided_by = $4, decided_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND `+allowed+`
+		RETURNING employee_id::text
+	`, id, status, req.Notes, nilIfEmpty(userID)).Scan(&employeeID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		var exists bool
+		_ = pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM leave_requests WHERE id = $1)`, id).Scan(&exists)
+		if !exists {
+			JSONError(w, http.StatusNotFound, "Leave request not found")
+			return
+		}
+		JSONError(w, http.StatusConflict, conflictMsg)
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating leave request %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to update leave request")
+		return
+	}
+
+	syncEmployeeLeave(ctx, pool, employeeID)
+
+	l, err := loadLeaveRequest(ctx, pool, id)
+	if err != nil {
+		log.Printf("Error fetching leave request %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch leave request")
+		return
+	}
+
+	go logActivity(pool, userID, status, "leave_request", id, map[string]interface{}{
+		"employeeId": employeeID, "leaveType": l.LeaveType,
+		"startDate": l.StartDate, "endDate": l.EndDate, "notes": req.Notes,
+	})
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"data":    l,
+		"message": "Leave request " + status,
+	})
+}
@@ -0,0 +1,656 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"manpower-backend/internal/compliance"
+	"manpower-backend/internal/ctxkeys"
+	"manpower-backend/internal/database"
+	"manpower-backend/internal/models"
+)
+
+// RenewalCaseHandler handles the renewal case workflow.
+type RenewalCaseHandler struct {
+	db database.Service
+}
+
+// NewRenewalCaseHandler creates a new RenewalCaseHandler.
+func NewRenewalCaseHandler(db database.Service) *RenewalCaseHandler {
+	return &RenewalCaseHandler{db: db}
+}
+
+// ── Renewal Cases ────────────────────────────────────────────────
+// A case follows one document through requested → … → approved (migration
+// 023); DocumentHandler.Renew completes it. Due dates come from
+// compliance.RenewalStageDue; the notifier escalates a stage past its due
+// date once and skips "expiring soon" alerts while a case is open.
+
+const renewalCaseCols = `rc.id, rc.document_id, rc.employee_id, rc.company_id, rc.document_type,
+	rc.status, rc.stage, rc.assignee_id, rc.expiry_date::text, rc.grace_days, rc.deadline::text,
+	rc.stage_entered_on::text, rc.stage_due::text,
+	(rc.status = 'open' AND rc.stage_due < company_today(rc.company_id)),
+	rc.escalated_at::text, rc.renewed_document_id, rc.created_by,
+	rc.created_at::text, rc.updated_at::text, rc.closed_at::text`
+
+// scanRenewalCase scans renewalCaseCols followed by extra destinations.
+func scanRenewalCase(row pgx.Row, c *models.RenewalCase, extra ...interface{}) error {
+	dest := []interface{}{
+		&c.ID, &c.DocumentID, &c.EmployeeID, &c.CompanyID, &c.DocumentType,
+		&c.Status, &c.Stage, &c.AssigneeID, &c.ExpiryDate, &c.GraceDays, &c.Deadline,
+		&c.StageEnteredOn, &c.StageDue, &c.Overdue,
+		&c.EscalatedAt, &c.RenewedDocumentID, &c.CreatedBy,
+		&c.CreatedAt, &c.UpdatedAt, &c.ClosedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	if next := compliance.NextRenewalStage(c.Stage); next != "" && c.Status == "open" {
+		c.NextStage = &next
+	}
+	return nil
+}
+
+// loadRenewalCase fetches a case with its display names.
+func loadRenewalCase(ctx context.Context, q querier, id string) (models.RenewalCaseWithDetails, error) {
+	var c models.RenewalCaseWithDetails
+	err := scanRenewalCase(q.QueryRow(ctx, `
+		SELECT `+renewalCaseCols+`, e.name, co.name, u.name
+		FROM renewal_cases rc
+		JOIN employees e ON e.id = rc.employee_id
+		JOIN companies co ON co.id = rc.company_id
+		LEFT JOIN users u ON u.id = rc.assignee_id
+		WHERE rc.id = $1
+	`, id), &c.RenewalCase, &c.EmployeeName, &c.CompanyName, &c.AssigneeName)
+	return c, err
+}
+
+// addRenewalCaseEvent appends a comment or history entry to a case.
+func addRenewalCaseEvent(ctx context.Context, q querier, caseID, kind string, stage *string, body, userID string) error {
+	_, err := q.Exec(ctx, `
+		INSERT INTO renewal_case_events (case_id, kind, stage, body, user_id)
+		VALUES ($1, $2, $3, $4, $5)
+	`, caseID, kind, stage, body, nilIfEmpty(userID))
+	return err
+}
+
+// validCaseAssignee reports whether the user exists and can see the company:
+// admins see every company, other roles need a user_companies row.
+func validCaseAssignee(ctx context.Context, q querier, userID, companyID string) bool {
+	var ok bool
+	_ = q.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM users u
+			WHERE u.id::text = $1
+			  AND (u.role IN ('admin', 'super_admin')
+			       OR EXISTS(SELECT 1 FROM user_companies uc WHERE uc.user_id = u.id AND uc.company_id::text = $2))
+		)
+	`, userID, companyID).Scan(&ok)
+	return ok
+}
+
+// notifyCaseAssignee tells the new assignee about a case.
+func notifyCaseAssignee(ctx context.Context, q querier, c *models.RenewalCaseWithDetails) {
+	if c.AssigneeID == nil {
+		return
+	}
+	_, err := q.Exec(ctx, `
+		INSERT INTO notifications (user_id, title, message, type, entity_type, entity_id)
+		VALUES ($1, $2, $3, 'renewal_assigned', 'renewal_case', $4)
+	`, *c.AssigneeID,
+		fmt.Sprintf("📝 %s renewal assigned to you", c.DocumentType),
+		fmt.Sprintf("%s (%s): %s renewal is at stage %s. Deadline: %s.",
+			c.EmployeeName, c.CompanyName, c.DocumentType, c.Stage, c.Deadline),
+		c.ID)
+	if err != nil {
+		log.Printf("Error notifying renewal case assignee: %v", err)
+	}
+}
+
+// closeRenewalCase completes the open case of a renewed document, if any.
+// Run in the renewal's transaction. Returns the closed case ID or "".
+func closeRenewalCase(ctx context.Context, q querier, documentID, renewedID, userID string) (string, error) {
+	var caseID string
+	err := q.QueryRow(ctx, `
+		UPDATE renewal_cases
+		SET status = 'completed', stage = 'completed', renewed_document_id = $2,
+		    stage_entered_on = company_today(company_id), stage_due = NULL,
+		    closed_at = NOW(), updated_at = NOW()
+		WHERE document_id = $1 AND status = 'open'
+		RETURNING id
+	`, documentID, renewedID).Scan(&caseID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	stage := compliance.StageCompleted
+	if err := addRenewalCaseEvent(ctx, q, caseID, "closed", &stage, "Document renewed", userID); err != nil {
+		return "", err
+	}
+	return caseID, nil
+}
+
+// ListCases handles GET /api/renewal-cases
+// Filters: status, stage, company_id, employee_id, document_id, assignee_id
+// ("me" = current user), overdue=true. Pagination: page, limit (default 20, max 100).
+func (h *RenewalCaseHandler) ListCases(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	where := "WHERE 1=1"
+	args := []interface{}{}
+	argIdx := 1
+	where, args, argIdx = appendCompanyScope(ctx, where, args, argIdx, "rc.company_id")
+
+	if q.Get("assignee_id") == "me" {
+		userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+		q.Set("assignee_id", userID)
+	}
+	filters := []struct{ param, clause string }{
+		{"status", "rc.status = $%d"},
+		{"stage", "rc.stage = $%d"},
+		{"company_id", "rc.company_id::text = $%d"},
+		{"employee_id", "rc.employee_id::text = $%d"},
+		{"document_id", "rc.document_id::text = $%d"},
+		{"assignee_id", "rc.assignee_id::text = $%d"},
+	}
+	for _, f := range filters {
+		if v := q.Get(f.param); v != "" {
+			where += " AND " + fmt.Sprintf(f.clause, argIdx)
+			args = append(args, v)
+			argIdx++
+		}
+	}
+	if q.Get("overdue") == "true" {
+		where += " AND rc.status = 'open' AND rc.stage_due < company_today(rc.company_id)"
+	}
+
+	var total int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM renewal_cases rc `+where, args...).Scan(&total); err != nil {
+		log.Printf("Error counting renewal cases: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch renewal cases")
+		return
+	}
+
+	args = append(args, limit, (page-1)*limit)
+	rows, err := pool.Query(ctx, fmt.Sprintf(`
+		SELECT %s, e.name, co.name, u.name
+		FROM renewal_cases rc
+		JOIN employees e ON e.id = rc.employee_id
+		JOIN companies co ON co.id = rc.company_id
+		LEFT JOIN users u ON u.id = rc.assignee_id
+		%s
+		ORDER BY rc.status = 'open' DESC, rc.stage_due ASC NULLS LAST, rc.created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, renewalCaseCols, where, argIdx, argIdx+1), args...)
+	if err != nil {
+		log.Printf("Error fetching renewal cases: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch renewal cases")
+		return
+	}
+	defer rows.Close()
+
+	cases := []models.RenewalCaseWithDetails{}
+	for rows.Next() {
+		var c models.RenewalCaseWithDetails
+		if err := scanRenewalCase(rows, &c.RenewalCase, &c.EmployeeName, &c.CompanyName, &c.AssigneeName); err != nil {
+			log.Printf("Error scanning renewal case: %v", err)
+			continue
+		}
+		cases = append(cases, c)
+	}
+
+	JSON(w, http.StatusOK, PaginatedResponse{
+		Data: cases,
+		Pagination: PaginationMeta{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: int(math.Ceil(float64(total) / float64(limit))),
+		},
+	})
+}
+
+// GetCase handles GET /api/renewal-cases/{id}
+// Returns the case with its comments and history, oldest first.
+func (h *RenewalCaseHandler) GetCase(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !checkRenewalCaseAccess(r.Context(), h.db.GetPool(), id) {
+		JSONError(w, http.StatusForbidden, "Access denied to this renewal case")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	c, err := loadRenewalCase(ctx, pool, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Renewal case not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching renewal case %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch renewal case")
+		return
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT ev.id, ev.case_id, ev.kind, ev.stage, ev.body, ev.user_id, u.name, ev.created_at::text
+		FROM renewal_case_events ev
+		LEFT JOIN users u ON u.id = ev.user_id
+		WHERE ev.case_id = $1
+		ORDER BY ev.created_at, ev.id
+	`, id)
+	if err != nil {
+		log.Printf("Error fetching renewal case events: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch renewal case")
+		return
+	}
+	defer rows.Close()
+
+	events := []models.RenewalCaseEvent{}
+	for rows.Next() {
+		var ev models.RenewalCaseEvent
+		if err := rows.Scan(&ev.ID, &ev.CaseID, &ev.Kind, &ev.Stage, &ev.Body, &ev.UserID, &ev.UserName, &ev.CreatedAt); err != nil {
+			log.Printf("Error scanning renewal case event: %v", err)
+			continue
+		}
+		events = append(events, ev)
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"data":   c,
+		"events": events,
+	})
+}
+
+// OpenCase handles POST /api/documents/{id}/renewal-case
+// Opens a case at stage requested. The deadline is the last day of the
+// document's grace period; 409 if the document already has an open case.
+func (h *RenewalCaseHandler) OpenCase(w http.ResponseWriter, r *http.Request) {
+	docID := chi.URLParam(r, "id")
+	if !checkDocumentAccess(r.Context(), h.db.GetPool(), docID) {
+		JSONError(w, http.StatusForbidden, "Access denied to this document")
+		return
+	}
+
+	var req models.OpenRenewalCaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	req.Validate()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	var doc models.Document
+	row := pool.QueryRow(ctx, fmt.Sprintf(`SELECT %s FROM documents d WHERE d.id = $1`, docCols), docID)
+	if err := scanDocument(row, &doc); err != nil {
+		JSONError(w, http.StatusNotFound, "Document not found")
+		return
+	}
+	var companyID string
+	if err := pool.QueryRow(ctx, `SELECT company_id::text FROM employees WHERE id = $1`, doc.EmployeeID).Scan(&companyID); err != nil {
+		JSONError(w, http.StatusNotFound, "Employee not found")
+		return
+	}
+	if req.AssigneeID != nil && !validCaseAssignee(ctx, pool, *req.AssigneeID, companyID) {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": map[string]string{"assigneeId": "Assignee must be a user with access to this company"},
+		})
+		return
+	}
+
+	// Due dates from the expiry and grace period, in the company's timezone
+	rulePtr, timezone := loadDocumentRule(ctx, pool, &doc)
+	graceDays := 0
+	if rulePtr != nil {
+		graceDays = rulePtr.GracePeriodDays
+	}
+	today := compliance.Today(time.Now(), timezone)
+	var expiry *time.Time
+	if doc.ExpiryDate != nil {
+		if t, err := time.Parse("2006-01-02", *doc.ExpiryDate); err == nil {
+			expiry = &t
+		}
+	}
+	deadline := compliance.RenewalDeadline(expiry, graceDays, today)
+	stageDue := compliance.RenewalStageDue(compliance.StageMedicalDone, today, deadline)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var caseID string
+	err = tx.QueryRow(ctx, `
+		INSERT INTO renewal_cases (document_id, employee_id, company_id, document_type, assignee_id,
+		    expiry_date, grace_days, deadline, stage_entered_on, stage_due, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`, doc.ID, doc.EmployeeID, companyID, doc.DocumentType, req.AssigneeID,
+		doc.ExpiryDate, graceDays, deadline, today, stageDue, nilIfEmpty(userID)).Scan(&caseID)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			JSONError(w, http.StatusConflict, "This document already has an open renewal case")
+			return
+		}
+		log.Printf("Error opening renewal case: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to open renewal case")
+		return
+	}
+	stage := compliance.StageRequested
+	body := req.Comment
+	if body == "" {
+		body = "Renewal requested"
+	}
+	if err := addRenewalCaseEvent(ctx, tx, caseID, "stage", &stage, body, userID); err != nil {
+		log.Printf("Error recording renewal case event: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to open renewal case")
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to open renewal case")
+		return
+	}
+
+	c, err := loadRenewalCase(ctx, pool, caseID)
+	if err != nil {
+		log.Printf("Error fetching renewal case %s: %v", caseID, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch renewal case")
+		return
+	}
+	notifyCaseAssignee(ctx, pool, &c)
+
+	go logActivity(pool, userID, "opened", "renewal_case", caseID, map[string]interface{}{
+		"documentId": doc.ID, "type": doc.DocumentType, "assigneeId": req.AssigneeID, "deadline": c.Deadline,
+	})
+
+	JSON(w, http.StatusCreated, map[string]interface{}{
+		"data":    c,
+		"message": "Renewal case opened",
+	})
+}
+
+// UpdateCase handles PATCH /api/renewal-cases/{id}
+// Moves an open case forward (stages may be skipped, e.g. no medical for
+// insurance) and/or reassigns it. A stage change restarts the SLA clock
+// and clears the escalation.
+func (h *RenewalCaseHandler) UpdateCase(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !checkRenewalCaseAccess(r.Context(), h.db.GetPool(), id) {
+		JSONError(w, http.StatusForbidden, "Access denied to this renewal case")
+		return
+	}
+
+	var req models.UpdateRenewalCaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	if errs := req.Validate(); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var status, stage, companyID, deadline, timezone string
+	var assigneeID *string
+	err = tx.QueryRow(ctx, `
+		SELECT status, stage, company_id::text, deadline::text, assignee_id::text, company_timezone(company_id)
+		FROM renewal_cases WHERE id = $1 FOR UPDATE
+	`, id).Scan(&status, &stage, &companyID, &deadline, &assigneeID, &timezone)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Renewal case not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error loading renewal case %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to update renewal case")
+		return
+	}
+	if status != "open" {
+		JSONError(w, http.StatusConflict, "Renewal case is "+status)
+		return
+	}
+
+	errs := map[string]string{}
+	if req.Stage != nil && compliance.RenewalStageIndex(*req.Stage) <= compliance.RenewalStageIndex(stage) {
+		errs["stage"] = "Stage can only move forward from " + stage
+	}
+	if req.AssigneeID != nil && *req.AssigneeID != "" && !validCaseAssignee(ctx, tx, *req.AssigneeID, companyID) {
+		errs["assigneeId"] = "Assignee must be a user with access to this company"
+	}
+	if len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	if req.Stage != nil {
+		today := compliance.Today(time.Now(), timezone)
+		deadlineDate, _ := time.Parse("2006-01-02", deadline)
+		stageDue := compliance.RenewalStageDue(compliance.NextRenewalStage(*req.Stage), today, deadlineDate)
+		if _, err := tx.Exec(ctx, `
+			UPDATE renewal_cases
+			SET stage = $2, stage_entered_on = $3, stage_due = $4, escalated_at = NULL, updated_at = NOW()
+			WHERE id = $1
+		`, id, *req.Stage, today, stageDue); err != nil {
+			log.Printf("Error updating renewal case stage: %v", err)
+			JSONError(w, http.StatusInternalServerError, "Failed to update renewal case")
+			return
+		}
+		if err := addRenewalCaseEvent(ctx, tx, id, "stage", req.Stage, req.Comment, userID); err != nil {
+			log.Printf("Error recording renewal case event: %v", err)
+			JSONError(w, http.StatusInternalServerError, "Failed to update renewal case")
+			return
+		}
+	}
+
+	reassigned := req.AssigneeID != nil && *req.AssigneeID != nilStringDefault(assigneeID, "")
+	if reassigned {
+		newAssignee := nilIfEmpty(*req.AssigneeID)
+		if _, err := tx.Exec(ctx, `UPDATE renewal_cases SET assignee_id = $2, updated_at = NOW() WHERE id = $1`, id, newAssignee); err != nil {
+			log.Printf("Error reassigning renewal case: %v", err)
+			JSONError(w, http.StatusInternalServerError, "Failed to update renewal case")
+			return
+		}
+		body := "Unassigned"
+		if newAssignee != nil {
+			body = "Assigned"
+		}
+		if req.Stage == nil && req.Comment != "" {
+			body = req.Comment
+		}
+		if err := addRenewalCaseEvent(ctx, tx, id, "assignment", nil, body, userID); err != nil {
+			log.Printf("Error recording renewal case event: %v", err)
+			JSONError(w, http.StatusInternalServerError, "Failed to update renewal case")
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to update renewal case")
+		return
+	}
+
+	c, err := loadRenewalCase(ctx, pool, id)
+	if err != nil {
+		log.Printf("Error fetching renewal case %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch renewal case")
+		return
+	}
+	if reassigned {
+		notifyCaseAssignee(ctx, pool, &c)
+	}
+
+	go logActivity(pool, userID, "updated", "renewal_case", id, map[string]interface{}{
+		"stage": req.Stage, "previousStage": stage, "assigneeId": req.AssigneeID,
+	})
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"data":    c,
+		"message": "Renewal case updated",
+	})
+}
+
+// AddComment handles POST /api/renewal-cases/{id}/comments
+func (h *RenewalCaseHandler) AddComment(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !checkRenewalCaseAccess(r.Context(), h.db.GetPool(), id) {
+		JSONError(w, http.StatusForbidden, "Access denied to this renewal case")
+		return
+	}
+
+	var req models.RenewalCaseCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	if errs := req.Validate(); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	var ev models.RenewalCaseEvent
+	err := pool.QueryRow(ctx, `
+		INSERT INTO renewal_case_events (case_id, kind, body, user_id)
+		VALUES ($1, 'comment', $2, $3)
+		RETURNING id, case_id, kind, stage, body, user_id, created_at::text
+	`, id, req.Body, nilIfEmpty(userID)).Scan(&ev.ID, &ev.CaseID, &ev.Kind, &ev.Stage, &ev.Body, &ev.UserID, &ev.CreatedAt)
+	if err != nil {
+		log.Printf("Error adding renewal case comment: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to add comment")
+		return
+	}
+
+	JSON(w, http.StatusCreated, map[string]interface{}{
+		"data":    ev,
+		"message": "Comment added",
+	})
+}
+
+// CancelCase handles POST /api/renewal-cases/{id}/cancel
+// Body: {"body": "<reason>"}. Cancelled cases no longer suppress alerts.
+func (h *RenewalCaseHandler) CancelCase(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !checkRenewalCaseAccess(r.Context(), h.db.GetPool(), id) {
+		JSONError(w, http.StatusForbidden, "Access denied to this renewal case")
+		return
+	}
+
+	var req models.RenewalCaseCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	if errs := req.Validate(); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": map[string]string{"body": "A reason is required to cancel a case"},
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE renewal_cases
+		SET status = 'cancelled', stage_due = NULL, closed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'open'
+	`, id)
+	if err != nil {
+		log.Printf("Error cancelling renewal case: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to cancel renewal case")
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		JSONError(w, http.StatusConflict, "Only open renewal cases can be cancelled")
+		return
+	}
+	if err := addRenewalCaseEvent(ctx, tx, id, "closed", nil, req.Body, userID); err != nil {
+		log.Printf("Error recording renewal case event: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to cancel renewal case")
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to cancel renewal case")
+		return
+	}
+
+	go logActivity(pool, userID, "cancelled", "renewal_case", id, map[string]interface{}{
+		"reason": req.Body,
+	})
+
+	JSON(w, http.StatusOK, map[string]interface{}{"message": "Renewal case cancelled"})
+}
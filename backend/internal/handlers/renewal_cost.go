@@ -0,0 +1,444 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"manpower-backend/internal/ctxkeys"
+	"manpower-backend/internal/models"
+)
+
+// ── Renewal Cost Catalog ─────────────────────────────────────
+// Cost lines per doc type and component (migration 017). Company rows replace
+// global rows for the same doc type and component; within a scope the row
+// effective on the renewal date wins.
+
+const renewalCostCols = `id, company_id, doc_type, component, amount::float8,
+	effective_from::text, effective_to::text, description,
+	created_at::text, updated_at::text`
+
+func scanRenewalCost(scanner interface {
+	Scan(dest ...interface{}) error
+}, c *models.RenewalCost) error {
+	return scanner.Scan(
+		&c.ID, &c.CompanyID, &c.DocType, &c.Component, &c.Amount,
+		&c.EffectiveFrom, &c.EffectiveTo, &c.Description,
+		&c.CreatedAt, &c.UpdatedAt,
+	)
+}
+
+// ListRenewalCosts handles GET /api/admin/renewal-costs?company_id=&doc_type=
+// With company_id, returns that company's rows plus global rows.
+func (h *AdminHandler) ListRenewalCosts(w http.ResponseWriter, r *http.Request) {
+	companyID := r.URL.Query().Get("company_id")
+	docType := r.URL.Query().Get("doc_type")
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	query := `SELECT ` + renewalCostCols + ` FROM renewal_costs WHERE 1=1`
+	args := []interface{}{}
+	if companyID != "" {
+		args = append(args, companyID)
+		query += fmt.Sprintf(` AND (company_id IS NULL OR company_id = $%d)`, len(args))
+	}
+	if docType != "" {
+		args = append(args, docType)
+		query += fmt.Sprintf(` AND doc_type = $%d`, len(args))
+	}
+	query += ` ORDER BY doc_type, component, company_id NULLS FIRST, effective_from`
+
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to list renewal costs: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch renewal costs")
+		return
+	}
+	defer rows.Close()
+
+	costs := []models.RenewalCost{}
+	for rows.Next() {
+		var c models.RenewalCost
+		if err := scanRenewalCost(rows, &c); err != nil {
+			log.Printf("Failed to scan renewal cost: %v", err)
+			continue
+		}
+		costs = append(costs, c)
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{"data": costs})
+}
+
+// CreateRenewalCost handles POST /api/admin/renewal-costs (admin-only).
+func (h *AdminHandler) CreateRenewalCost(w http.ResponseWriter, r *http.Request) {
+	h.saveRenewalCost(w, r, "")
+}
+
+// UpdateRenewalCost handles PUT /api/admin/renewal-costs/{id} (admin-only).
+func (h *AdminHandler) UpdateRenewalCost(w http.ResponseWriter, r *http.Request) {
+	h.saveRenewalCost(w, r, chi.URLParam(r, "id"))
+}
+
+// saveRenewalCost creates (id == "") or replaces a cost line. Effective
+// ranges may not overlap for the same scope, doc type and component.
+func (h *AdminHandler) saveRenewalCost(w http.ResponseWriter, r *http.Request, id string) {
+	var req models.RenewalCostRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	if errs := req.Validate(); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	if req.CompanyID != nil {
+		var exists bool
+		_ = pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM companies WHERE id::text = $1)`, *req.CompanyID).Scan(&exists)
+		if !exists {
+			JSONError(w, http.StatusUnprocessableEntity, "Company not found")
+			return
+		}
+	}
+
+	var overlaps bool
+	err := pool.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM renewal_costs
+			WHERE company_id IS NOT DISTINCT FROM $1::uuid
+			  AND doc_type = $2 AND component = $3
+			  AND id::text <> $4
+			  AND daterange(effective_from, effective_to, '[]') && daterange($5::date, $6::date, '[]')
+		)
+	`, req.CompanyID, req.DocType, req.Component, id, req.EffectiveFrom, req.EffectiveTo).Scan(&overlaps)
+	if err != nil {
+		log.Printf("Failed to check renewal cost overlap: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to save renewal cost")
+		return
+	}
+	if overlaps {
+		JSONError(w, http.StatusConflict, "Another "+req.Component+" cost for this document type is effective in the same period")
+		return
+	}
+
+	var c models.RenewalCost
+	action := "created"
+	if id == "" {
+		err = scanRenewalCost(pool.QueryRow(ctx, `
+			INSERT INTO renewal_costs (company_id, doc_type, component, amount, effective_from, effective_to, description)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING `+renewalCostCols,
+			req.CompanyID, req.DocType, req.Component, req.Amount,
+			req.EffectiveFrom, req.EffectiveTo, req.Description,
+		), &c)
+	} else {
+		action = "updated"
+		err = scanRenewalCost(pool.QueryRow(ctx, `
+			UPDATE renewal_costs SET
+				company_id = $1, doc_type = $2, component = $3, amount = $4,
+				effective_from = $5, effective_to = $6, description = $7,
+				updated_at = NOW()
+			WHERE id = $8
+			RETURNING `+renewalCostCols,
+			req.CompanyID, req.DocType, req.Component, req.Amount,
+			req.EffectiveFrom, req.EffectiveTo, req.Description, id,
+		), &c)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Renewal cost not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to save renewal cost: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to save renewal cost")
+		return
+	}
+
+	go logActivity(pool, userID, action, "renewal_cost", c.ID, map[string]interface{}{
+		"docType": c.DocType, "component": c.Component, "amount": c.Amount,
+		"effectiveFrom": c.EffectiveFrom, "companyId": c.CompanyID,
+	})
+
+	status := http.StatusOK
+	if id == "" {
+		status = http.StatusCreated
+	}
+	JSON(w, status, map[string]interface{}{
+		"data":    c,
+		"message": "Renewal cost " + action,
+	})
+}
+
+// DeleteRenewalCost handles DELETE /api/admin/renewal-costs/{id} (admin-only).
+func (h *AdminHandler) DeleteRenewalCost(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	tag, err := pool.Exec(ctx, `DELETE FROM renewal_costs WHERE id = $1`, id)
+	if err != nil {
+		log.Printf("Failed to delete renewal cost: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to delete renewal cost")
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		JSONError(w, http.StatusNotFound, "Renewal cost not found")
+		return
+	}
+
+	go logActivity(pool, userID, "deleted", "renewal_cost", id, nil)
+
+	JSON(w, http.StatusOK, map[string]interface{}{"message": "Renewal cost deleted"})
+}
+
+// ── Cost Resolution ──────────────────────────────────────────
+
+type costLine struct {
+	CompanyID *string
+	DocType   string
+	Component string
+	Amount    float64
+	From      time.Time
+	To        *time.Time
+}
+
+// costCatalog resolves the cost of renewing a doc type on a given date.
+type costCatalog struct {
+	lines map[string][]costLine // doc type → lines
+}
+
+// loadCostCatalog reads every cost line (global and company rows).
+func loadCostCatalog(ctx context.Context, q querier) (*costCatalog, error) {
+	rows, err := q.Query(ctx, `
+		SELECT company_id::text, doc_type, component, amount::float8, effective_from, effective_to
+		FROM renewal_costs
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cat := &costCatalog{lines: map[string][]costLine{}}
+	for rows.Next() {
+		var l costLine
+		if err := rows.Scan(&l.CompanyID, &l.DocType, &l.Component, &l.Amount, &l.From, &l.To); err != nil {
+			return nil, err
+		}
+		cat.lines[l.DocType] = append(cat.lines[l.DocType], l)
+	}
+	return cat, rows.Err()
+}
+
+// resolve returns component → amount for renewing docType at companyID on
+// date `on`. Per component, a company row effective on that date replaces the
+// global row; the latest effective_from wins within a scope.
+func (c *costCatalog) resolve(companyID, docType string, on time.Time) map[string]float64 {
+	type pick struct {
+		line    costLine
+		company bool
+	}
+	best := map[string]pick{}
+	for _, l := range c.lines[docType] {
+		if l.CompanyID != nil && *l.CompanyID != companyID {
+			continue
+		}
+		if on.Before(l.From) || (l.To != nil && on.After(*l.To)) {
+			continue
+		}
+		isCompany := l.CompanyID != nil
+		cur, ok := best[l.Component]
+		if !ok || (isCompany && !cur.company) || (isCompany == cur.company && l.From.After(cur.line.From)) {
+			best[l.Component] = pick{line: l, company: isCompany}
+		}
+	}
+
+	costs := make(map[string]float64, len(best))
+	for component, p := range best {
+		costs[component] = p.line.Amount
+	}
+	return costs
+}
+
+// ── Renewal Budget ───────────────────────────────────────────
+
+// GetRenewalBudget handles GET /api/dashboard/renewal-budget?company_id=&months=12
+// Projects every required document falling due in the next N months (by
+// current expiry date) at catalog prices on its due date, grouped by month
+// and company. Already-expired documents are budgeted in the first month.
+func (h *DashboardHandler) GetRenewalBudget(w http.ResponseWriter, r *http.Request) {
+	companyID := r.URL.Query().Get("company_id")
+	months := 12
+	if v := r.URL.Query().Get("months"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 36 {
+			JSONError(w, http.StatusBadRequest, "months must be between 1 and 36")
+			return
+		}
+		months = n
+	}
+	if companyID != "" && !checkCompanyAccess(r.Context(), companyID) {
+		JSONError(w, http.StatusForbidden, "Access denied to this company")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	today := reportToday(ctx, pool, companyID)
+	start := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, months, -1)
+
+	catalog, err := loadCostCatalog(ctx, pool)
+	if err != nil {
+		log.Printf("Error loading renewal cost catalog: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to compute renewal budget")
+		return
+	}
+
+	where := "WHERE ds.is_required AND e.exit_type IS NULL AND ds.expiry_date IS NOT NULL AND ds.expiry_date <= $1"
+	args := []interface{}{end}
+	argIdx := 2
+	where, args, argIdx = appendCompanyScope(ctx, where, args, argIdx, "e.company_id")
+	if companyID != "" {
+		where += fmt.Sprintf(" AND e.company_id::text = $%d", argIdx)
+		args = append(args, companyID)
+		argIdx++
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT c.id, c.name, ds.document_type, ds.expiry_date, COALESCE(ds.days_remaining, 0),
+			company_today(c.id)
+		FROM document_status ds
+		JOIN employees e ON ds.employee_id = e.id
+		JOIN companies c ON e.company_id = c.id
+		`+where, args...)
+	if err != nil {
+		log.Printf("Error loading renewals for budget: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to compute renewal budget")
+		return
+	}
+	defer rows.Close()
+
+	monthKeys := make([]string, months)
+	for i := range monthKeys {
+		monthKeys[i] = start.AddDate(0, i, 0).Format("2006-01")
+	}
+	newMonths := func() []models.BudgetMonth {
+		ms := make([]models.BudgetMonth, months)
+		for i, k := range monthKeys {
+			ms[i] = models.BudgetMonth{Month: k, ByComponent: map[string]float64{}}
+		}
+		return ms
+	}
+
+	budget := models.RenewalBudget{
+		From: monthKeys[0], Months: newMonths(),
+		ByCompany: []models.CompanyBudget{}, Unpriced: []models.UnpricedRenewals{},
+	}
+	companies := map[string]*models.CompanyBudget{}
+	unpriced := map[string]int{}
+
+	for rows.Next() {
+		var cID, cName, docType string
+		var expiry, companyToday time.Time
+		var daysRemaining int
+		if err := rows.Scan(&cID, &cName, &docType, &expiry, &daysRemaining, &companyToday); err != nil {
+			log.Printf("Error scanning budget renewal: %v", err)
+			continue
+		}
+
+		// Overdue renewals are due now, at today's prices
+		dueOn := expiry
+		if daysRemaining < 0 {
+			budget.Overdue++
+			dueOn = companyToday
+		}
+		idx := 0
+		if dueOn.After(start) {
+			idx = (dueOn.Year()-start.Year())*12 + int(dueOn.Month()) - int(start.Month())
+		}
+
+		costs := catalog.resolve(cID, docType, dueOn)
+		if len(costs) == 0 {
+			unpriced[docType]++
+		}
+
+		cb, ok := companies[cID]
+		if !ok {
+			cb = &models.CompanyBudget{CompanyID: cID, CompanyName: cName, Months: newMonths()}
+			companies[cID] = cb
+		}
+		for _, m := range []*models.BudgetMonth{&budget.Months[idx], &cb.Months[idx]} {
+			m.Renewals++
+			for component, amount := range costs {
+				m.Amount += amount
+				m.ByComponent[component] += amount
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error loading renewals for budget: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to compute renewal budget")
+		return
+	}
+
+	budget.Total = roundBudgetMonths(budget.Months)
+	for _, cb := range companies {
+		cb.Total = roundBudgetMonths(cb.Months)
+		budget.ByCompany = append(budget.ByCompany, *cb)
+	}
+	sort.Slice(budget.ByCompany, func(i, j int) bool {
+		if budget.ByCompany[i].Total != budget.ByCompany[j].Total {
+			return budget.ByCompany[i].Total > budget.ByCompany[j].Total
+		}
+		return budget.ByCompany[i].CompanyName < budget.ByCompany[j].CompanyName
+	})
+	for docType, n := range unpriced {
+		budget.Unpriced = append(budget.Unpriced, models.UnpricedRenewals{DocType: docType, Renewals: n})
+	}
+	sort.Slice(budget.Unpriced, func(i, j int) bool { return budget.Unpriced[i].DocType < budget.Unpriced[j].DocType })
+
+	JSON(w, http.StatusOK, map[string]interface{}{"data": budget})
+}
+
+// roundBudgetMonths rounds each month to 2 decimals and returns the total.
+func roundBudgetMonths(months []models.BudgetMonth) float64 {
+	total := 0.0
+	for i := range months {
+		months[i].Amount = math.Round(months[i].Amount*100) / 100
+		for k, v := range months[i].ByComponent {
+			months[i].ByComponent[k] = math.Round(v*100) / 100
+		}
+		total += months[i].Amount
+	}
+	return math.Round(total*100) / 100
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
 
 	"manpower-backend/internal/ctxkeys"
 	"manpower-backend/internal/database"
@@ -28,7 +30,10 @@ func NewSalaryHandler(db database.Service) *SalaryHandler {
 }
 
 // Generate handles POST /api/salary/generate
-// Creates salary records for all active employees for a given month/year.
+// Creates salary records for all active and on-leave employees for a given
+// month/year, then puts pending records of employees with an open absconding
+// case on hold. Approved unpaid leave in the month (leave types with
+// is_paid = false, migration 030) is deducted at salary / 30 per day.
 func (h *SalaryHandler) Generate(w http.ResponseWriter, r *http.Request) {
 	var req models.GenerateSalaryRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -46,19 +51,38 @@ func (h *SalaryHandler) Generate(w http.ResponseWriter, r *http.Request) {
 
 	pool := h.db.GetPool()
 
-	// Insert salary records for all active employees that have a salary set.
+	// Insert salary records for all working employees that have a salary set.
 	// ON CONFLICT DO NOTHING — skip if record already exists for that month.
-	genScopeFilter, genScopeArg := companyScopeClause(ctx, 3, "company_id")
+	genScopeFilter, genScopeArg := companyScopeClause(ctx, 3, "e.company_id")
 	genArgs := []interface{}{req.Month, req.Year}
 	if genScopeArg != nil {
 		genArgs = append(genArgs, genScopeArg)
 	}
 
-	tag, err := pool.Exec(ctx, fmt.Sprintf(`
-		INSERT INTO salary_records (employee_id, month, year, amount, status)
-		SELECT id, $1, $2, salary, 'pending'
-		FROM employees
-		WHERE status = 'active' AND salary IS NOT NULL AND salary > 0%s
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, fmt.Sprintf(`
+		INSERT INTO salary_records (employee_id, company_id, month, year, amount, unpaid_leave_days, status)
+		SELECT e.id, e.company_id, $1, $2,
+			GREATEST(ROUND(e.salary - e.salary / 30 * ul.days, 2), 0), ul.days, 'pending'
+		FROM employees e
+		CROSS JOIN LATERAL (
+			SELECT COALESCE(SUM(
+				LEAST(lr.end_date, (make_date($2::int, $1::int, 1) + INTERVAL '1 month - 1 day')::date)
+				- GREATEST(lr.start_date, make_date($2::int, $1::int, 1)) + 1
+			), 0)::int AS days
+			FROM leave_requests lr
+			JOIN leave_types lt ON lt.code = lr.leave_type
+			WHERE lr.employee_id = e.id AND lr.status = 'approved' AND NOT lt.is_paid
+			  AND lr.start_date <= (make_date($2::int, $1::int, 1) + INTERVAL '1 month - 1 day')::date
+			  AND lr.end_date >= make_date($2::int, $1::int, 1)
+		) ul
+		WHERE e.status IN ('active', 'on_leave') AND e.salary IS NOT NULL AND e.salary > 0%s
 		ON CONFLICT (employee_id, month, year) DO NOTHING
 	`, genScopeFilter), genArgs...)
 	if err != nil {
@@ -66,16 +90,29 @@ func (h *SalaryHandler) Generate(w http.ResponseWriter, r *http.Request) {
 		JSONError(w, http.StatusInternalServerError, "Failed to generate salary records")
 		return
 	}
+	// Nothing generated may be payable while an absconding case is open
+	held, err := holdAbscondingSalaries(ctx, tx, "", ctxkeys.GetCompanyScope(ctx))
+	if err != nil {
+		log.Printf("Error holding absconded employees' salary: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to generate salary records")
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error committing salary records: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to generate salary records")
+		return
+	}
 
 	// Audit trail
 	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
 	logActivity(pool, userID, "generated_salary", "salary", "bulk", map[string]interface{}{
-		"month": req.Month, "year": req.Year, "count": tag.RowsAffected(),
+		"month": req.Month, "year": req.Year, "count": tag.RowsAffected(), "onHold": held,
 	})
 
 	JSON(w, http.StatusCreated, map[string]interface{}{
 		"message":  "Salary records generated",
 		"inserted": tag.RowsAffected(),
+		"onHold":   held,
 	})
 }
 
@@ -105,7 +142,7 @@ func (h *SalaryHandler) List(w http.ResponseWriter, r *http.Request) {
 	args := []interface{}{month, year}
 	argIdx := 3
 
-	where, args, argIdx = appendCompanyScope(ctx, where, args, argIdx, "e.company_id")
+	where, args, argIdx = appendCompanyScope(ctx, where, args, argIdx, "s.company_id")
 
 	if statusFilter != "" && statusFilter != "all" {
 		where += fmt.Sprintf(" AND s.status = $%d", argIdx)
@@ -113,19 +150,19 @@ func (h *SalaryHandler) List(w http.ResponseWriter, r *http.Request) {
 		argIdx++
 	}
 	if companyFilter != "" {
-		where += fmt.Sprintf(" AND e.company_id = $%d", argIdx)
+		where += fmt.Sprintf(" AND s.company_id = $%d", argIdx)
 		args = append(args, companyFilter)
 		argIdx++
 	}
 
 	rows, err := pool.Query(ctx, fmt.Sprintf(`
 		SELECT s.id, s.employee_id, s.month, s.year, s.amount, s.status,
-			s.paid_date::text, s.notes,
+			s.paid_date::text, s.notes, s.unpaid_leave_days,
 			s.created_at::text, s.updated_at::text,
 			e.name, c.name, COALESCE(c.currency, 'AED')
 		FROM salary_records s
 		JOIN employees e ON s.employee_id = e.id
-		JOIN companies c ON e.company_id = c.id
+		JOIN companies c ON s.company_id = c.id
 		%s
 		ORDER BY e.name ASC
 	`, where), args...)
@@ -141,7 +178,7 @@ func (h *SalaryHandler) List(w http.ResponseWriter, r *http.Request) {
 		var rec models.SalaryRecordWithEmployee
 		if err := rows.Scan(
 			&rec.ID, &rec.EmployeeID, &rec.Month, &rec.Year, &rec.Amount, &rec.Status,
-			&rec.PaidDate, &rec.Notes,
+			&rec.PaidDate, &rec.Notes, &rec.UnpaidLeaveDays,
 			&rec.CreatedAt, &rec.UpdatedAt,
 			&rec.EmployeeName, &rec.CompanyName, &rec.Currency,
 		); err != nil {
@@ -196,13 +233,22 @@ func (h *SalaryHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 	err := pool.QueryRow(ctx, fmt.Sprintf(`
 		UPDATE salary_records 
 		SET status = $1, paid_date = %s, updated_at = NOW()
-		WHERE id = $2
+		WHERE id = $2 AND status <> 'on_hold'
 		RETURNING id, employee_id, month, year, amount, status,
-			paid_date::text, notes, created_at::text, updated_at::text
+			paid_date::text, notes, unpaid_leave_days, created_at::text, updated_at::text
 	`, paidDateExpr), req.Status, id).Scan(
 		&rec.ID, &rec.EmployeeID, &rec.Month, &rec.Year, &rec.Amount, &rec.Status,
-		&rec.PaidDate, &rec.Notes, &rec.CreatedAt, &rec.UpdatedAt,
+		&rec.PaidDate, &rec.Notes, &rec.UnpaidLeaveDays, &rec.CreatedAt, &rec.UpdatedAt,
 	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Held records are released only by withdrawing the absconding case
+		var held bool
+		_ = pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM salary_records WHERE id = $1 AND status = 'on_hold')`, id).Scan(&held)
+		if held {
+			JSONError(w, http.StatusConflict, "salary is on hold by an open absconding case")
+			return
+		}
+	}
 	if err != nil {
 		log.Printf("Error updating salary status %s: %v", id, err)
 		JSONError(w, http.StatusNotFound, "Salary record not found")
@@ -261,14 +307,14 @@ func (h *SalaryHandler) BulkUpdateStatus(w http.ResponseWriter, r *http.Request)
 	scope := ctxkeys.GetCompanyScope(r.Context())
 	scopeJoin := ""
 	if scope != nil {
-		scopeJoin = fmt.Sprintf(` AND employee_id IN (SELECT id FROM employees WHERE company_id = ANY($%d))`, len(args)+1)
+		scopeJoin = fmt.Sprintf(` AND company_id = ANY($%d)`, len(args)+1)
 		args = append(args, scope)
 	}
 
 	query := fmt.Sprintf(`
 		UPDATE salary_records 
 		SET status = $1, paid_date = %s, updated_at = NOW()
-		WHERE id IN (%s)%s
+		WHERE id IN (%s) AND status <> 'on_hold'%s
 	`, paidDateExpr, strings.Join(placeholders, ", "), scopeJoin)
 
 	tag, err := pool.Exec(ctx, query, args...)
@@ -313,7 +359,7 @@ func (h *SalaryHandler) Summary(w http.ResponseWriter, r *http.Request) {
 	// Calculate summary
 	// Note: Summing amounts of different currencies is conceptually wrong,
 	// but for this MVP we just sum raw values. Ideally frontend should warn if "Mixed".
-	sumScopeFilter, sumScopeArg := companyScopeClause(ctx, 3, "e.company_id")
+	sumScopeFilter, sumScopeArg := companyScopeClause(ctx, 3, "s.company_id")
 	sumArgs := []interface{}{month, year}
 	if sumScopeArg != nil {
 		sumArgs = append(sumArgs, sumScopeArg)
@@ -328,20 +374,21 @@ func (h *SalaryHandler) Summary(w http.ResponseWriter, r *http.Request) {
 				count(CASE WHEN s.status = 'pending' THEN 1 END) as pending_count,
 				count(CASE WHEN s.status = 'paid' THEN 1 END) as paid_count,
 				count(CASE WHEN s.status = 'partial' THEN 1 END) as partial_count,
+				count(CASE WHEN s.status = 'on_hold' THEN 1 END) as on_hold_count,
 				count(*) as total_count,
 				count(DISTINCT c.currency) as currency_count,
 				MAX(c.currency) as single_currency
 			FROM salary_records s
 			JOIN employees e ON s.employee_id = e.id
-			JOIN companies c ON e.company_id = c.id
+			JOIN companies c ON s.company_id = c.id
 			WHERE s.month = $1 AND s.year = $2%s
 		)
-		SELECT total_amount, paid_amount, pending_count, paid_count, partial_count, total_count,
+		SELECT total_amount, paid_amount, pending_count, paid_count, partial_count, on_hold_count, total_count,
 			CASE WHEN currency_count > 1 THEN 'Mixed' ELSE COALESCE(single_currency, 'AED') END as currency
 		FROM stats
 	`, sumScopeFilter), sumArgs...).Scan(
 		&summary.TotalAmount, &summary.PaidAmount,
-		&summary.PendingCount, &summary.PaidCount, &summary.PartialCount, &summary.TotalCount,
+		&summary.PendingCount, &summary.PaidCount, &summary.PartialCount, &summary.OnHoldCount, &summary.TotalCount,
 		&summary.Currency,
 	)
 	if err != nil {
@@ -375,7 +422,7 @@ func (h *SalaryHandler) Export(w http.ResponseWriter, r *http.Request) {
 
 	pool := h.db.GetPool()
 
-	expScopeFilter, expScopeArg := companyScopeClause(ctx, 3, "e.company_id")
+	expScopeFilter, expScopeArg := companyScopeClause(ctx, 3, "s.company_id")
 	expArgs := []interface{}{month, year}
 	if expScopeArg != nil {
 		expArgs = append(expArgs, expScopeArg)
@@ -386,7 +433,7 @@ func (h *SalaryHandler) Export(w http.ResponseWriter, r *http.Request) {
 			COALESCE(s.paid_date::text, ''), COALESCE(s.notes, '')
 		FROM salary_records s
 		JOIN employees e ON s.employee_id = e.id
-		JOIN companies c ON e.company_id = c.id
+		JOIN companies c ON s.company_id = c.id
 		WHERE s.month = $1 AND s.year = $2%s
 		ORDER BY e.name ASC
 	`, expScopeFilter), expArgs...)
@@ -435,12 +482,12 @@ func (h *SalaryHandler) ListByEmployee(w http.ResponseWriter, r *http.Request) {
 
 	rows, err := pool.Query(ctx, `
 		SELECT s.id, s.employee_id, s.month, s.year, s.amount, s.status,
-			COALESCE(s.paid_date::text, ''), COALESCE(s.notes, ''),
+			COALESCE(s.paid_date::text, ''), COALESCE(s.notes, ''), s.unpaid_leave_days,
 			s.created_at::text, s.updated_at::text,
 			c.name, COALESCE(c.currency, 'AED')
 		FROM salary_records s
 		JOIN employees e ON s.employee_id = e.id
-		JOIN companies c ON e.company_id = c.id
+		JOIN companies c ON s.company_id = c.id
 		WHERE s.employee_id = $1
 		ORDER BY s.year DESC, s.month DESC
 	`, employeeID)
@@ -456,7 +503,7 @@ func (h *SalaryHandler) ListByEmployee(w http.ResponseWriter, r *http.Request) {
 		var rec models.SalaryRecordWithEmployee
 		if err := rows.Scan(
 			&rec.ID, &rec.EmployeeID, &rec.Month, &rec.Year, &rec.Amount, &rec.Status,
-			&rec.PaidDate, &rec.Notes,
+			&rec.PaidDate, &rec.Notes, &rec.UnpaidLeaveDays,
 			&rec.CreatedAt, &rec.UpdatedAt,
 			&rec.CompanyName, &rec.Currency,
 		); err != nil {
@@ -64,14 +64,14 @@ func checkDocumentAccess(ctx context.Context, pool *pgxpool.Pool, documentID str
 	return checkCompanyAccess(ctx, companyID)
 }
 
-// checkSalaryAccess looks up the salary record's employee → company and checks scope.
+// checkSalaryAccess looks up the salary record's paying company and checks scope.
 func checkSalaryAccess(ctx context.Context, pool *pgxpool.Pool, salaryID string) bool {
 	if ctxkeys.IsGlobalScope(ctx) {
 		return true
 	}
 	var companyID string
 	err := pool.QueryRow(ctx,
-		"SELECT e.company_id::text FROM salary_records s JOIN employees e ON e.id = s.employee_id WHERE s.id = $1",
+		"SELECT company_id::text FROM salary_records WHERE id = $1",
 		salaryID,
 	).Scan(&companyID)
 	if err != nil {
@@ -92,3 +92,86 @@ func checkEmployeeAccess(ctx context.Context, pool *pgxpool.Pool, employeeID str
 	}
 	return checkCompanyAccess(ctx, companyID)
 }
+
+// checkRenewalCaseAccess looks up the renewal case's company and checks scope.
+func checkRenewalCaseAccess(ctx context.Context, pool *pgxpool.Pool, caseID string) bool {
+	if ctxkeys.IsGlobalScope(ctx) {
+		return true
+	}
+	var companyID string
+	err := pool.QueryRow(ctx, "SELECT company_id::text FROM renewal_cases WHERE id = $1", caseID).Scan(&companyID)
+	if err != nil {
+		return false
+	}
+	return checkCompanyAccess(ctx, companyID)
+}
+
+// checkExitChecklistAccess verifies the caller can access the company of an
+// exit checklist item.
+func checkExitChecklistAccess(ctx context.Context, pool *pgxpool.Pool, itemID string) bool {
+	if ctxkeys.IsGlobalScope(ctx) {
+		return true
+	}
+	var companyID string
+	err := pool.QueryRow(ctx, "SELECT company_id::text FROM exit_checklist_items WHERE id = $1", itemID).Scan(&companyID)
+	if err != nil {
+		return false
+	}
+	return checkCompanyAccess(ctx, companyID)
+}
+
+// checkAbscondingCaseAccess looks up the absconding case's company and checks scope.
+func checkAbscondingCaseAccess(ctx context.Context, pool *pgxpool.Pool, caseID string) bool {
+	if ctxkeys.IsGlobalScope(ctx) {
+		return true
+	}
+	var companyID string
+	err := pool.QueryRow(ctx, "SELECT company_id::text FROM absconding_cases WHERE id = $1", caseID).Scan(&companyID)
+	if err != nil {
+		return false
+	}
+	return checkCompanyAccess(ctx, companyID)
+}
+
+// checkLeaveRequestAccess looks up the leave request's company and checks scope.
+func checkLeaveRequestAccess(ctx context.Context, pool *pgxpool.Pool, requestID string) bool {
+	if ctxkeys.IsGlobalScope(ctx) {
+		return true
+	}
+	var companyID string
+	err := pool.QueryRow(ctx, "SELECT company_id::text FROM leave_requests WHERE id = $1", requestID).Scan(&companyID)
+	if err != nil {
+		return false
+	}
+	return checkCompanyAccess(ctx, companyID)
+}
+
+// checkDependentAccess looks up the sponsoring employee's company and checks scope.
+func checkDependentAccess(ctx context.Context, pool *pgxpool.Pool, dependentID string) bool {
+	if ctxkeys.IsGlobalScope(ctx) {
+		return true
+	}
+	var companyID string
+	err := pool.QueryRow(ctx, `
+		SELECT e.company_id::text FROM dependents dp
+		JOIN employees e ON e.id = dp.employee_id
+		WHERE dp.id = $1
+	`, dependentID).Scan(&companyID)
+	if err != nil {
+		return false
+	}
+	return checkCompanyAccess(ctx, companyID)
+}
+
+// checkContractAccess looks up the contract's company and checks scope.
+func checkContractAccess(ctx context.Context, pool *pgxpool.Pool, contractID string) bool {
+	if ctxkeys.IsGlobalScope(ctx) {
+		return true
+	}
+	var companyID string
+	err := pool.QueryRow(ctx, "SELECT company_id::text FROM employee_contracts WHERE id = $1", contractID).Scan(&companyID)
+	if err != nil {
+		return false
+	}
+	return checkCompanyAccess(ctx, companyID)
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"manpower-backend/internal/compliance"
+	"manpower-backend/internal/ctxkeys"
+	"manpower-backend/internal/models"
+)
+
+// ── Compliance Score Weights ─────────────────────────────────────
+// Single-row admin setting (migration 020). Changing the weights rescores
+// every employee in the background.
+
+// GetScoreWeights handles GET /api/admin/score-weights (admin-only).
+func (h *AdminHandler) GetScoreWeights(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	var sw models.ScoreWeights
+	err := h.db.GetPool().QueryRow(ctx, `
+		SELECT penalty_weight::float8, grace_weight::float8, expiring_weight::float8,
+			incomplete_weight::float8, fine_rate_weight::float8, optional_weight::float8,
+			updated_at::text
+		FROM compliance_score_weights LIMIT 1
+	`).Scan(&sw.Penalty, &sw.Grace, &sw.Expiring, &sw.Incomplete, &sw.FineRate, &sw.Optional, &sw.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		d := compliance.DefaultScoreWeights
+		sw = models.ScoreWeights{
+			Penalty: d.Penalty, Grace: d.Grace, Expiring: d.Expiring,
+			Incomplete: d.Incomplete, FineRate: d.FineRate, Optional: d.Optional,
+		}
+	} else if err != nil {
+		log.Printf("Failed to fetch score weights: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch score weights")
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{"data": sw})
+}
+
+// UpdateScoreWeights handles PUT /api/admin/score-weights (admin-only).
+func (h *AdminHandler) UpdateScoreWeights(w http.ResponseWriter, r *http.Request) {
+	var req models.ScoreWeights
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	if errs := req.Validate(); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	err := pool.QueryRow(ctx, `
+		INSERT INTO compliance_score_weights (
+			id, penalty_weight, grace_weight, expiring_weight,
+			incomplete_weight, fine_rate_weight, optional_weight, updated_at
+		) VALUES (TRUE, $1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (id) DO UPDATE SET
+			penalty_weight    = EXCLUDED.penalty_weight,
+			grace_weight      = EXCLUDED.grace_weight,
+			expiring_weight   = EXCLUDED.expiring_weight,
+			incomplete_weight = EXCLUDED.incomplete_weight,
+			fine_rate_weight  = EXCLUDED.fine_rate_weight,
+			optional_weight   = EXCLUDED.optional_weight,
+			updated_at        = NOW()
+		RETURNING updated_at::text
+	`, req.Penalty, req.Grace, req.Expiring, req.Incomplete, req.FineRate, req.Optional).Scan(&req.UpdatedAt)
+	if err != nil {
+		log.Printf("Failed to update score weights: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to update score weights")
+		return
+	}
+
+	refreshStatusAsync(pool, "")
+
+	go logActivity(pool, userID, "updated", "score_weights", "global", map[string]interface{}{
+		"penalty": req.Penalty, "grace": req.Grace, "expiring": req.Expiring,
+		"incomplete": req.Incomplete, "fineRate": req.FineRate, "optional": req.Optional,
+	})
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"data":    req,
+		"message": "Score weights updated; scores are being recalculated",
+	})
+}
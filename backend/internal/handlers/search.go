@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"manpower-backend/internal/database"
+	"manpower-backend/internal/models"
+)
+
+// SearchHandler handles the global employee / document search.
+type SearchHandler struct {
+	db database.Service
+}
+
+// NewSearchHandler creates a new SearchHandler.
+func NewSearchHandler(db database.Service) *SearchHandler {
+	return &SearchHandler{db: db}
+}
+
+// ── Search ───────────────────────────────────────────────────────
+// Backed by the indexes from migration 028. Names, trades and nationalities
+// match through the employees.search_vector full-text column and trigram
+// similarity on the name; numbers match as substrings of their
+// search_norm() form (lowercase, separators stripped). Every match gets a
+// rank in 0–1 — exact 1.0, prefix 0.9, substring 0.7, otherwise the
+// trigram similarity / ts_rank — and each employee is returned once, with
+// its best-ranked match. $1 is the query.
+
+// searchNumberFields are the numbers searched as normalized substrings.
+var searchNumberFields = []struct {
+	field, expr, from, docID, docType string
+}{
+	{"mobile", "e.mobile", "employees e", "NULL::uuid", "NULL::text"},
+	{"passport_number", "e.passport_number", "employees e", "NULL::uuid", "NULL::text"},
+	{"document_number", "d.document_number", "documents d JOIN employees e ON e.id = d.employee_id", "d.id", "d.document_type"},
+	{"mohre_file_number", "d.metadata->>'mohre_file_number'", "documents d JOIN employees e ON e.id = d.employee_id", "d.id", "d.document_type"},
+}
+
+// searchMatchesQuery builds the m CTE: one row per (employee, matched field).
+func searchMatchesQuery() string {
+	branches := []string{`
+		SELECT e.id AS employee_id, f.field, f.value,
+			NULL::uuid AS document_id, NULL::text AS document_type,
+			GREATEST(
+				CASE WHEN lower(f.value) = lower($1) THEN 1.0
+				     WHEN f.value ILIKE $1 || '%' THEN 0.9
+				     WHEN f.value ILIKE '%' || $1 || '%' THEN 0.7
+				     ELSE 0 END::float8,
+				similarity(e.name, $1)::float8,
+				ts_rank(e.search_vector, plainto_tsquery('simple', $1))::float8
+			) AS rank
+		FROM employees e
+		CROSS JOIN LATERAL (
+			SELECT CASE
+				WHEN e.name ILIKE '%' || $1 || '%' OR e.name % $1
+				  OR to_tsvector('simple', e.name) @@ plainto_tsquery('simple', $1) THEN 'name'
+				WHEN to_tsvector('simple', COALESCE(e.trade, '')) @@ plainto_tsquery('simple', $1) THEN 'trade'
+				ELSE 'nationality' END AS field
+		) k
+		CROSS JOIN LATERAL (
+			SELECT k.field, CASE k.field
+				WHEN 'name' THEN e.name
+				WHEN 'trade' THEN COALESCE(e.trade, '')
+				ELSE COALESCE(e.nationality, '') END AS value
+		) f
+		WHERE e.search_vector @@ plainto_tsquery('simple', $1)
+		   OR e.name ILIKE '%' || $1 || '%'
+		   OR e.name % $1`}
+
+	for _, f := range searchNumberFields {
+		norm := "search_norm(" + f.expr + ")"
+		branches = append(branches, `
+		SELECT e.id, '`+f.field+`', COALESCE(`+f.expr+`, ''), `+f.docID+`, `+f.docType+`,
+			CASE WHEN `+norm+` = search_norm($1) THEN 1.0
+			     WHEN `+norm+` LIKE search_norm($1) || '%' THEN 0.9
+			     ELSE 0.7 END::float8
+		FROM `+f.from+`
+		WHERE length(search_norm($1)) >= 3
+		  AND `+norm+` LIKE '%' || search_norm($1) || '%'`)
+	}
+	return "WITH m AS (" + strings.Join(branches, "\n\t\tUNION ALL") + "\n\t)"
+}
+
+// Search handles GET /api/search?q=...
+// Filters: company_id. limit defaults to 20 (max 50). Results are ordered
+// by rank, then name, and respect the caller's company scope.
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	term := strings.TrimSpace(q.Get("q"))
+	if utf8.RuneCountInString(term) < 2 {
+		JSONError(w, http.StatusBadRequest, "Search query must be at least 2 characters")
+		return
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit < 1 || limit > 50 {
+		limit = 20
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	where := "WHERE 1=1"
+	args := []interface{}{term}
+	argIdx := 2
+	where, args, argIdx = appendCompanyScope(ctx, where, args, argIdx, "e.company_id")
+	if v := q.Get("company_id"); v != "" {
+		where += fmt.Sprintf(" AND e.company_id::text = $%d", argIdx)
+		args = append(args, v)
+		argIdx++
+	}
+	args = append(args, limit)
+
+	rows, err := pool.Query(ctx, searchMatchesQuery()+fmt.Sprintf(`
+		SELECT e.id, e.name, e.company_id, c.name, COALESCE(e.trade, ''), COALESCE(e.status, ''),
+			best.field, best.value, best.document_id::text, best.document_type, best.rank
+		FROM (
+			SELECT DISTINCT ON (m.employee_id) m.*
+			FROM m
+			ORDER BY m.employee_id, m.rank DESC, m.field = 'name' DESC
+		) best
+		JOIN employees e ON e.id = best.employee_id
+		JOIN companies c ON c.id = e.company_id
+		%s
+		ORDER BY best.rank DESC, e.name
+		LIMIT $%d
+	`, where, argIdx), args...)
+	if err != nil {
+		log.Printf("Error searching for %q: %v", term, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to search")
+		return
+	}
+	defer rows.Close()
+
+	results := []models.SearchResult{}
+	for rows.Next() {
+		var res models.SearchResult
+		if err := rows.Scan(
+			&res.EmployeeID, &res.EmployeeName, &res.CompanyID, &res.CompanyName,
+			&res.Trade, &res.Status, &res.MatchedField, &res.MatchedValue,
+			&res.DocumentID, &res.DocumentType, &res.Rank,
+		); err != nil {
+			log.Printf("Error scanning search result: %v", err)
+			continue
+		}
+		res.Highlight = highlightMatch(res.MatchedValue, term)
+		results = append(results, res)
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"data":  results,
+		"query": term,
+	})
+}
+
+// highlightMatch HTML-escapes value and wraps the parts matching the query
+// in <mark>. Matching ignores case and separators the same way
+// search_norm() does, so "7841990" marks "784-1990" in an Emirates ID. The
+// whole query and each of its words are marked; words shorter than two
+// characters are ignored.
+func highlightMatch(value, query string) string {
+	runes := []rune(value)
+	var norm []rune
+	var pos []int // pos[i] = index in runes of norm[i]
+	for i, r := range runes {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			norm = append(norm, unicode.ToLower(r))
+			pos = append(pos, i)
+		}
+	}
+
+	marked := make([]bool, len(runes))
+	tokens := append([]string{query}, strings.Fields(query)...)
+	for _, token := range tokens {
+		var t []rune
+		for _, r := range token {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				t = append(t, unicode.ToLower(r))
+			}
+		}
+		if len(t) < 2 {
+			continue
+		}
+		for start := 0; start+len(t) <= len(norm); start++ {
+			if string(norm[start:start+len(t)]) != string(t) {
+				continue
+			}
+			for i := pos[start]; i <= pos[start+len(t)-1]; i++ {
+				marked[i] = true
+			}
+		}
+	}
+
+	var b strings.Builder
+	for i, r := range runes {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			b.WriteString("<mark>")
+		}
+		b.WriteString(html.EscapeString(string(r)))
+		if marked[i] && (i == len(runes)-1 || !marked[i+1]) {
+			b.WriteString("</mark>")
+		}
+	}
+	return b.String()
+}
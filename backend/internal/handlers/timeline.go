@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"manpower-backend/internal/ctxkeys"
+	"manpower-backend/internal/models"
+)
+
+// ── Employee Timeline ────────────────────────────────────────────
+// The timeline merges everything that happened to one employee into a
+// single feed. Each source below yields rows of
+// (occurred_at, type, title, entity_type, entity_id, details):
+//   - joining date, transfers, approved leave, labour contracts, exit and
+//     absconding dates from their tables
+//   - status changes and renewals from activity_log
+//   - document uploads and expiries (a document counts as expired only if
+//     no newer document of the same type was added before it lapsed)
+//   - salary generation and payment, limited to the caller's company scope
+//     because records stay with the company that paid them
+//   - notifications about the employee or their documents, checklist items,
+//     cases and contracts, de-duplicated across recipients
+// $1 = employee id, $2 = company scope (NULL = all companies).
+
+const timelineEvents = `
+WITH doc_names AS (
+	SELECT d.*, COALESCE(dt.display_name, initcap(replace(d.document_type, '_', ' '))) AS type_name
+	FROM documents d
+	LEFT JOIN document_types dt ON dt.doc_type = d.document_type
+	WHERE d.employee_id = $1
+), ev AS (
+	SELECT e.joining_date::timestamptz AS occurred_at, 'joined' AS type,
+		'Joined ' || COALESCE(co.name, '') AS title, 'employee' AS entity_type, e.id::text AS entity_id,
+		jsonb_build_object('trade', e.trade, 'companyId', co.id) AS details
+	FROM employees e
+	LEFT JOIN LATERAL (
+		SELECT s.company_id FROM employee_sponsorships s
+		WHERE s.employee_id = e.id ORDER BY s.start_date LIMIT 1
+	) sp ON TRUE
+	LEFT JOIN companies co ON co.id = COALESCE(sp.company_id, e.company_id)
+	WHERE e.id = $1
+
+	UNION ALL
+	SELECT a.created_at::timestamptz, 'status_changed',
+		'Status changed from ' || (a.details->>'statusFrom') || ' to ' || (a.details->>'status'),
+		'employee', a.entity_id::text,
+		jsonb_build_object('from', a.details->'statusFrom', 'to', a.details->'status', 'userId', a.user_id)
+	FROM activity_log a
+	WHERE a.entity_type = 'employee' AND a.entity_id = $1 AND a.action = 'updated'
+	  AND a.details ? 'status'
+
+	UNION ALL
+	SELECT t.effective_date::timestamptz, 'transferred',
+		'Transferred from ' || COALESCE(fc.name, 'deleted company') || ' to ' || COALESCE(tc.name, 'deleted company'),
+		'employee_transfer', t.id::text,
+		jsonb_build_object('fromCompanyId', t.from_company_id, 'toCompanyId', t.to_company_id,
+			'reason', t.reason, 'slotsAdded', t.slots_added, 'slotsRemoved', t.slots_removed,
+			'salaryMoved', t.salary_moved)
+	FROM employee_transfers t
+	LEFT JOIN companies fc ON fc.id = t.from_company_id
+	LEFT JOIN companies tc ON tc.id = t.to_company_id
+	WHERE t.employee_id = $1 AND t.status = 'applied'
+
+	UNION ALL
+	SELECT d.created_at::timestamptz, 'document_added', d.type_name || ' added', 'document', d.id::text,
+		jsonb_build_object('documentType', d.document_type, 'documentNumber', d.document_number,
+			'expiryDate', d.expiry_date, 'hasFile', d.file_url <> '')
+	FROM doc_names d
+	WHERE NOT EXISTS (
+		SELECT 1 FROM activity_log a
+		WHERE a.entity_type = 'document' AND a.entity_id = d.id
+		  AND a.action IN ('renewed', 'linked_renewal')
+	)
+
+	UNION ALL
+	SELECT a.created_at::timestamptz, 'document_renewed', d.type_name || ' renewed', 'document', d.id::text,
+		jsonb_build_object('documentType', d.document_type, 'previousDocId', a.details->'previousDocId',
+			'expiryDate', d.expiry_date, 'linked', a.action = 'linked_renewal')
+	FROM activity_log a
+	JOIN doc_names d ON d.id = a.entity_id
+	WHERE a.entity_type = 'document' AND a.action IN ('renewed', 'linked_renewal')
+
+	UNION ALL
+	SELECT (d.expiry_date + 1)::timestamptz, 'document_expired', d.type_name || ' expired', 'document', d.id::text,
+		jsonb_build_object('documentType', d.document_type, 'expiryDate', d.expiry_date)
+	FROM doc_names d
+	JOIN employees e ON e.id = d.employee_id
+	WHERE d.expiry_date < company_today(e.company_id)
+	  AND NOT EXISTS (
+		SELECT 1 FROM documents n
+		WHERE n.employee_id = d.employee_id AND n.document_type = d.document_type
+		  AND n.id <> d.id AND n.created_at > d.created_at
+		  AND n.created_at::date <= d.expiry_date
+	  )
+
+	UNION ALL
+	SELECT s.created_at, 'salary_generated',
+		'Salary generated for ' || to_char(make_date(s.year, s.month, 1), 'Mon YYYY'),
+		'salary_record', s.id::text,
+		jsonb_build_object('month', s.month, 'year', s.year, 'amount', s.amount,
+			'status', s.status, 'companyId', s.company_id)
+	FROM salary_records s
+	WHERE s.employee_id = $1 AND ($2::text[] IS NULL OR s.company_id::text = ANY($2))
+
+	UNION ALL
+	SELECT s.paid_date::timestamptz, 'salary_paid',
+		'Salary paid for ' || to_char(make_date(s.year, s.month, 1), 'Mon YYYY'),
+		'salary_record', s.id::text,
+		jsonb_build_object('month', s.month, 'year', s.year, 'amount', s.amount, 'companyId', s.company_id)
+	FROM salary_records s
+	WHERE s.employee_id = $1 AND s.paid_date IS NOT NULL
+	  AND ($2::text[] IS NULL OR s.company_id::text = ANY($2))
+
+	UNION ALL
+	SELECT e.exit_date::timestamptz, 'exited', 'Exited (' || e.exit_type || ')', 'employee', e.id::text,
+		jsonb_build_object('exitType', e.exit_type, 'notes', e.exit_notes)
+	FROM employees e
+	WHERE e.id = $1 AND e.exit_date IS NOT NULL
+
+	UNION ALL
+	SELECT x.completed_on::timestamptz, 'exit_item_completed',
+		initcap(replace(x.item, '_', ' ')) || CASE WHEN x.status = 'waived' THEN ' waived' ELSE ' done' END,
+		'exit_checklist_item', x.id::text,
+		jsonb_build_object('item', x.item, 'status', x.status, 'dueDate', x.due_date, 'notes', x.notes)
+	FROM exit_checklist_items x
+	WHERE x.employee_id = $1 AND x.completed_on IS NOT NULL
+
+	UNION ALL
+	SELECT ac.reported_on::timestamptz, 'absconding_reported', 'Absconding reported', 'absconding_case', ac.id::text,
+		jsonb_build_object('lastWorkingDay', ac.last_working_day, 'filingDeadline', ac.filing_deadline)
+	FROM absconding_cases ac WHERE ac.employee_id = $1
+
+	UNION ALL
+	SELECT ac.filed_on::timestamptz, 'absconding_filed', 'Absconding report filed with ' || upper(ac.authority),
+		'absconding_case', ac.id::text,
+		jsonb_build_object('authority', ac.authority, 'reference', ac.filing_reference)
+	FROM absconding_cases ac WHERE ac.employee_id = $1 AND ac.filed_on IS NOT NULL
+
+	UNION ALL
+	SELECT ac.withdrawn_on::timestamptz, 'absconding_withdrawn', 'Absconding case withdrawn',
+		'absconding_case', ac.id::text,
+		jsonb_build_object('reason', ac.withdrawal_reason)
+	FROM absconding_cases ac WHERE ac.employee_id = $1 AND ac.withdrawn_on IS NOT NULL
+
+	UNION ALL
+	SELECT lr.start_date::timestamptz, 'leave',
+		lt.name || ' (' || lr.days || CASE WHEN lr.days = 1 THEN ' day)' ELSE ' days)' END,
+		'leave_request', lr.id::text,
+		jsonb_build_object('leaveType', lr.leave_type, 'startDate', lr.start_date,
+			'endDate', lr.end_date, 'days', lr.days, 'isPaid', lt.is_paid)
+	FROM leave_requests lr
+	JOIN leave_types lt ON lt.code = lr.leave_type
+	WHERE lr.employee_id = $1 AND lr.status = 'approved'
+
+	UNION ALL
+	SELECT ec.start_date::timestamptz, 'contract_started',
+		initcap(ec.contract_type) || ' contract with ' || co.name ||
+			COALESCE(' until ' || ec.end_date::text, ''),
+		'contract', ec.id::text,
+		jsonb_build_object('contractType', ec.contract_type, 'startDate', ec.start_date,
+			'endDate', ec.end_date, 'probationEndDate', ec.probation_end_date,
+			'noticePeriodDays', ec.notice_period_days, 'status', ec.status)
+	FROM employee_contracts ec
+	JOIN companies co ON co.id = ec.company_id
+	WHERE ec.employee_id = $1
+
+	UNION ALL
+	SELECT n.created_at, 'notification', n.title, n.entity_type, n.entity_id::text,
+		jsonb_build_object('notificationType', n.type, 'message', n.message)
+	FROM (
+		SELECT DISTINCT ON (n.type, n.title, n.message, n.created_at::date) n.*
+		FROM notifications n
+		WHERE (n.entity_type = 'employee' AND n.entity_id = $1)
+		   OR (n.entity_type = 'document' AND n.entity_id IN (SELECT id FROM doc_names))
+		   OR (n.entity_type = 'exit_checklist_item' AND n.entity_id IN (SELECT id FROM exit_checklist_items WHERE employee_id = $1))
+		   OR (n.entity_type = 'absconding_case' AND n.entity_id IN (SELECT id FROM absconding_cases WHERE employee_id = $1))
+		   OR (n.entity_type = 'renewal_case' AND n.entity_id IN (SELECT id FROM renewal_cases WHERE employee_id = $1))
+		   OR (n.entity_type = 'contract' AND n.entity_id IN (SELECT id FROM employee_contracts WHERE employee_id = $1))
+		ORDER BY n.type, n.title, n.message, n.created_at::date, n.created_at
+	) n
+)`
+
+// Timeline handles GET /api/employees/{id}/timeline
+// Filters: type (comma-separated, see models.TimelineEventTypes), order=asc
+// for oldest first (default newest first). Pagination: page, limit
+// (default 50, max 200).
+func (h *EmployeeHandler) Timeline(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		JSONError(w, http.StatusBadRequest, "Employee ID is required")
+		return
+	}
+
+	if !checkEmployeeAccess(r.Context(), h.db.GetPool(), id) {
+		JSONError(w, http.StatusForbidden, "Access denied to this employee")
+		return
+	}
+
+	q := r.URL.Query()
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+	order := "DESC"
+	if q.Get("order") == "asc" {
+		order = "ASC"
+	}
+
+	var types []string
+	if v := q.Get("type"); v != "" {
+		valid := map[string]bool{}
+		for _, t := range models.TimelineEventTypes {
+			valid[t] = true
+		}
+		for _, t := range strings.Split(v, ",") {
+			t = strings.TrimSpace(t)
+			if !valid[t] {
+				JSONError(w, http.StatusBadRequest, "Unknown event type: "+t)
+				return
+			}
+			types = append(types, t)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+	scope := ctxkeys.GetCompanyScope(ctx)
+
+	var exists bool
+	if err := pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM employees WHERE id = $1)`, id).Scan(&exists); err != nil || !exists {
+		JSONError(w, http.StatusNotFound, "Employee not found")
+		return
+	}
+
+	where := "WHERE occurred_at IS NOT NULL AND ($3::text[] IS NULL OR type = ANY($3))"
+
+	var total int
+	if err := pool.QueryRow(ctx, timelineEvents+` SELECT COUNT(*) FROM ev `+where,
+		id, scope, types,
+	).Scan(&total); err != nil {
+		log.Printf("Error counting timeline events for employee %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch timeline")
+		return
+	}
+
+	rows, err := pool.Query(ctx, fmt.Sprintf(`%s
+		SELECT occurred_at::text, type, title, entity_type, entity_id, details
+		FROM ev %s
+		ORDER BY occurred_at %s, type
+		LIMIT $4 OFFSET $5
+	`, timelineEvents, where, order), id, scope, types, limit, (page-1)*limit)
+	if err != nil {
+		log.Printf("Error fetching timeline for employee %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch timeline")
+		return
+	}
+	defer rows.Close()
+
+	events := []models.TimelineEvent{}
+	for rows.Next() {
+		var ev models.TimelineEvent
+		if err := rows.Scan(&ev.OccurredAt, &ev.Type, &ev.Title, &ev.EntityType, &ev.EntityID, &ev.Details); err != nil {
+			log.Printf("Error scanning timeline event: %v", err)
+			continue
+		}
+		events = append(events, ev)
+	}
+
+	JSON(w, http.StatusOK, PaginatedResponse{
+		Data: events,
+		Pagination: PaginationMeta{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: int(math.Ceil(float64(total) / float64(limit))),
+		},
+	})
+}
@@ -0,0 +1,612 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"manpower-backend/internal/ctxkeys"
+	"manpower-backend/internal/models"
+)
+
+// ── Transfers & Sponsorship ──────────────────────────────────────
+// A transfer moves an employee to another group company effective from a
+// date (migration 026). The employee keeps their documents and salary
+// history: the current sponsorship period is closed, a new one is opened,
+// mandatory slots are reconciled for the new company, and open salary
+// records from the effective month onwards move to the new company.
+// Company-specific compliance_rules apply through employees.company_id, so
+// refreshing the employee's status re-evaluates their documents.
+// A transfer dated after the current company's local date is stored as
+// pending (migration 034) and applied by ApplyDueTransfers once the date
+// arrives.
+
+// openSponsorship starts the employee's first sponsorship period on their
+// joining date.
+func openSponsorship(ctx context.Context, q querier, employeeID string) error {
+	_, err := q.Exec(ctx, `
+		INSERT INTO employee_sponsorships (employee_id, company_id, start_date)
+		SELECT id, company_id, joining_date FROM employees WHERE id = $1
+		ON CONFLICT (employee_id) WHERE end_date IS NULL DO NOTHING
+	`, employeeID)
+	return err
+}
+
+// transferState is what a company change is validated against, read with
+// the employee row locked.
+type transferState struct {
+	CompanyID      string
+	Today          string // local date of the current company
+	ExitType       *string
+	SponsoredSince *string // start of the open sponsorship period
+	CustomFields   map[string]interface{}
+	Scheduled      bool // a pending transfer exists
+}
+
+// loadTransferState locks the employee and reads their transfer state.
+// Returns pgx.ErrNoRows when the employee does not exist.
+func loadTransferState(ctx context.Context, q querier, employeeID string) (*transferState, error) {
+	var st transferState
+	err := q.QueryRow(ctx, `
+		SELECT e.company_id::text, company_today(e.company_id)::text, e.exit_type,
+			(SELECT s.start_date::text FROM employee_sponsorships s
+			 WHERE s.employee_id = e.id AND s.end_date IS NULL),
+			e.custom_fields,
+			EXISTS(SELECT 1 FROM employee_transfers t
+			       WHERE t.employee_id = e.id AND t.status = 'pending')
+		FROM employees e WHERE e.id = $1
+		FOR UPDATE OF e
+	`, employeeID).Scan(&st.CompanyID, &st.Today, &st.ExitType, &st.SponsoredSince, &st.CustomFields, &st.Scheduled)
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// conflict returns why the employee cannot change company at all, or "".
+func (st *transferState) conflict() string {
+	if st.ExitType != nil {
+		return "Exited employees cannot be transferred"
+	}
+	if st.Scheduled {
+		return "Employee already has a scheduled transfer"
+	}
+	return ""
+}
+
+// validate checks a move to toCompanyID effective on effectiveDate. Both
+// the transfer endpoint and company changes through the employee update
+// use it.
+func (st *transferState) validate(toCompanyID, effectiveDate string) map[string]string {
+	errs := map[string]string{}
+	if toCompanyID == st.CompanyID {
+		errs["companyId"] = "Employee already belongs to this company"
+	}
+	if st.SponsoredSince != nil && effectiveDate <= *st.SponsoredSince {
+		errs["effectiveDate"] = "Effective date must be after the current sponsorship started (" + *st.SponsoredSince + ")"
+	}
+	return errs
+}
+
+// recordTransfer writes the transfer, rolls the sponsorship over and moves
+// open salary records and renewal cases to the new company. The caller has
+// already updated employees.company_id and reconciled document slots.
+// pendingID, when set, is the scheduled transfer being applied; its row is
+// marked applied instead of inserting a new one.
+func recordTransfer(ctx context.Context, q querier, employeeID, fromCompanyID, toCompanyID, effectiveDate, reason, userID, pendingID string, slots slotReconciliation) (string, int64, error) {
+	var transferID string
+	var err error
+	if pendingID != "" {
+		err = q.QueryRow(ctx, `
+			UPDATE employee_transfers
+			SET status = 'applied', applied_at = NOW(), slots_added = $2, slots_removed = $3
+			WHERE id = $1 AND status = 'pending'
+			RETURNING id
+		`, pendingID, slots.Added, slots.Removed).Scan(&transferID)
+	} else {
+		err = q.QueryRow(ctx, `
+			INSERT INTO employee_transfers (
+				employee_id, from_company_id, to_company_id, effective_date,
+				reason, slots_added, slots_removed, created_by, applied_at
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+			RETURNING id
+		`, employeeID, fromCompanyID, toCompanyID, effectiveDate, reason,
+			slots.Added, slots.Removed, nilIfEmpty(userID),
+		).Scan(&transferID)
+	}
+	if err != nil {
+		return "", 0, err
+	}
+
+	if _, err := q.Exec(ctx, `
+		UPDATE employee_sponsorships
+		SET end_date = GREATEST(start_date, $2::date - 1)
+		WHERE employee_id = $1 AND end_date IS NULL
+	`, employeeID, effectiveDate); err != nil {
+		return "", 0, err
+	}
+	if _, err := q.Exec(ctx, `
+		INSERT INTO employee_sponsorships (employee_id, company_id, start_date, transfer_id)
+		VALUES ($1, $2, $3, $4)
+	`, employeeID, toCompanyID, effectiveDate, transferID); err != nil {
+		return "", 0, err
+	}
+
+	// Paid records stay with the company that paid them.
+	tag, err := q.Exec(ctx, `
+		UPDATE salary_records SET company_id = $3, updated_at = NOW()
+		WHERE employee_id = $1 AND company_id = $2 AND status <> 'paid'
+		  AND make_date(year, month, 1) >= date_trunc('month', $4::date)::date
+	`, employeeID, fromCompanyID, toCompanyID, effectiveDate)
+	if err != nil {
+		return "", 0, err
+	}
+	moved := tag.RowsAffected()
+
+	if _, err := q.Exec(ctx, `
+		UPDATE renewal_cases SET company_id = $2, updated_at = NOW()
+		WHERE employee_id = $1 AND status = 'open'
+	`, employeeID, toCompanyID); err != nil {
+		return "", 0, err
+	}
+
+	if _, err := q.Exec(ctx,
+		`UPDATE employee_transfers SET salary_moved = $2 WHERE id = $1`,
+		transferID, moved,
+	); err != nil {
+		return "", 0, err
+	}
+	return transferID, moved, nil
+}
+
+// appliedTransfer is the outcome of applyTransfer.
+type appliedTransfer struct {
+	Employee    models.Employee
+	TransferID  string
+	Slots       slotReconciliation
+	SalaryMoved int64
+}
+
+// applyTransfer moves the employee to toCompanyID with the already
+// validated custom fields, reconciles their slots and records the transfer.
+func applyTransfer(ctx context.Context, tx pgx.Tx, st *transferState, employeeID, toCompanyID, effectiveDate, reason, userID, pendingID string, customFields map[string]interface{}) (*appliedTransfer, error) {
+	previousSlots, err := requiredSlots(ctx, tx, "e.id = $1", employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var res appliedTransfer
+	err = scanEmployee(tx.QueryRow(ctx, `
+		UPDATE employees SET company_id = $1, custom_fields = $3, updated_at = NOW()
+		WHERE id = $2
+		RETURNING `+employeeRetCols,
+		toCompanyID, employeeID, customFields,
+	), &res.Employee)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Slots, err = reconcileDocumentSlots(ctx, tx, &res.Employee, previousSlots[employeeID]); err != nil {
+		return nil, err
+	}
+
+	res.TransferID, res.SalaryMoved, err = recordTransfer(ctx, tx, employeeID, st.CompanyID, toCompanyID,
+		effectiveDate, reason, userID, pendingID, res.Slots)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Transfer handles POST /api/employees/{id}/transfer
+func (h *EmployeeHandler) Transfer(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		JSONError(w, http.StatusBadRequest, "Employee ID is required")
+		return
+	}
+
+	if !checkEmployeeAccess(r.Context(), h.db.GetPool(), id) {
+		JSONError(w, http.StatusForbidden, "Access denied to this employee")
+		return
+	}
+
+	var req models.TransferEmployeeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	if errs := req.Validate(); len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	if !checkCompanyAccess(r.Context(), req.CompanyID) {
+		JSONError(w, http.StatusForbidden, "Access denied to this company")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to transfer employee")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	st, err := loadTransferState(ctx, tx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "Employee not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching employee %s for transfer: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to transfer employee")
+		return
+	}
+
+	if msg := st.conflict(); msg != "" {
+		JSONError(w, http.StatusConflict, msg)
+		return
+	}
+	var companyExists bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM companies WHERE id = $1)`, req.CompanyID).Scan(&companyExists); err != nil || !companyExists {
+		JSONError(w, http.StatusNotFound, "Company not found")
+		return
+	}
+
+	errs := st.validate(req.CompanyID, req.EffectiveDate)
+
+	// Custom fields follow the new company's definitions
+	defs, err := loadCustomFieldDefs(ctx, tx, req.CompanyID)
+	if err != nil {
+		log.Printf("Error loading custom fields for company %s: %v", req.CompanyID, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to transfer employee")
+		return
+	}
+	customFields, cfErrs := models.MoveCustomFields(defs, st.CustomFields, req.CustomFields)
+	for k, v := range cfErrs {
+		errs[k] = v
+	}
+	if len(errs) > 0 {
+		JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+
+	// Future-dated: keep the submitted values and apply on the date
+	if req.EffectiveDate > st.Today {
+		transfer := models.EmployeeTransfer{SlotsAdded: []string{}, SlotsRemoved: []string{}}
+		err := tx.QueryRow(ctx, `
+			INSERT INTO employee_transfers (
+				employee_id, from_company_id, to_company_id, effective_date,
+				reason, custom_fields, status, created_by
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, 'pending', $7)
+			RETURNING id, employee_id, from_company_id::text, to_company_id::text,
+				effective_date::text, reason, status, created_by::text, created_at::text
+		`, id, st.CompanyID, req.CompanyID, req.EffectiveDate, req.Reason,
+			req.CustomFields, nilIfEmpty(userID),
+		).Scan(&transfer.ID, &transfer.EmployeeID, &transfer.FromCompanyID, &transfer.ToCompanyID,
+			&transfer.EffectiveDate, &transfer.Reason, &transfer.Status, &transfer.CreatedBy, &transfer.CreatedAt)
+		if err != nil {
+			log.Printf("Error scheduling transfer for employee %s: %v", id, err)
+			JSONError(w, http.StatusInternalServerError, "Failed to transfer employee")
+			return
+		}
+		if err := tx.Commit(ctx); err != nil {
+			log.Printf("Error committing scheduled transfer: %v", err)
+			JSONError(w, http.StatusInternalServerError, "Failed to transfer employee")
+			return
+		}
+
+		logActivity(pool, userID, "transfer_scheduled", "employee", id, map[string]interface{}{
+			"transferId":    transfer.ID,
+			"fromCompanyId": st.CompanyID,
+			"toCompanyId":   req.CompanyID,
+			"effectiveDate": req.EffectiveDate,
+		})
+
+		JSON(w, http.StatusAccepted, map[string]interface{}{
+			"data":    transfer,
+			"message": "Transfer scheduled for " + req.EffectiveDate,
+		})
+		return
+	}
+
+	res, err := applyTransfer(ctx, tx, st, id, req.CompanyID, req.EffectiveDate, req.Reason, userID, "", customFields)
+	if err != nil {
+		log.Printf("Error transferring employee %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to transfer employee")
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error committing employee transfer: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to transfer employee")
+		return
+	}
+	refreshEmployeeStatus(ctx, pool, res.Employee.ID)
+
+	// Audit trail
+	logActivity(pool, userID, "transferred", "employee", res.Employee.ID, map[string]interface{}{
+		"name":          res.Employee.Name,
+		"fromCompanyId": st.CompanyID,
+		"toCompanyId":   req.CompanyID,
+		"effectiveDate": req.EffectiveDate,
+		"slotsAdded":    res.Slots.Added,
+		"slotsRemoved":  res.Slots.Removed,
+		"salaryMoved":   res.SalaryMoved,
+	})
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"data":          res.Employee,
+		"transferId":    res.TransferID,
+		"documentSlots": res.Slots,
+		"salaryMoved":   res.SalaryMoved,
+		"message":       "Employee transferred successfully",
+	})
+}
+
+// CancelTransfer handles DELETE /api/employees/{id}/transfer
+// Cancels the employee's scheduled transfer.
+func (h *EmployeeHandler) CancelTransfer(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		JSONError(w, http.StatusBadRequest, "Employee ID is required")
+		return
+	}
+
+	if !checkEmployeeAccess(r.Context(), h.db.GetPool(), id) {
+		JSONError(w, http.StatusForbidden, "Access denied to this employee")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	var transferID, toCompanyID, effectiveDate string
+	err := pool.QueryRow(ctx, `
+		UPDATE employee_transfers SET status = 'cancelled'
+		WHERE employee_id = $1 AND status = 'pending'
+		RETURNING id, to_company_id::text, effective_date::text
+	`, id).Scan(&transferID, &toCompanyID, &effectiveDate)
+	if errors.Is(err, pgx.ErrNoRows) {
+		JSONError(w, http.StatusNotFound, "No scheduled transfer for this employee")
+		return
+	}
+	if err != nil {
+		log.Printf("Error cancelling transfer for employee %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to cancel transfer")
+		return
+	}
+
+	userID, _ := r.Context().Value(ctxkeys.UserID).(string)
+	logActivity(pool, userID, "transfer_cancelled", "employee", id, map[string]interface{}{
+		"transferId":    transferID,
+		"toCompanyId":   toCompanyID,
+		"effectiveDate": effectiveDate,
+	})
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Scheduled transfer cancelled",
+	})
+}
+
+// ApplyDueTransfers applies scheduled transfers whose effective date has
+// arrived in the employee's current company. A transfer whose employee has
+// since exited or changed company is cancelled instead. Custom field values
+// stored with the transfer are revalidated against the new company's
+// definitions; values that no longer fit are dropped rather than blocking
+// the move. Returns how many transfers were applied.
+func ApplyDueTransfers(ctx context.Context, pool *pgxpool.Pool) (int, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT t.id, t.employee_id::text, t.from_company_id::text, t.to_company_id::text,
+			t.effective_date::text, t.reason, t.custom_fields, t.created_by::text
+		FROM employee_transfers t
+		WHERE t.status = 'pending'
+		  AND t.effective_date <= company_today(t.from_company_id)
+		ORDER BY t.effective_date, t.created_at
+	`)
+	if err != nil {
+		return 0, err
+	}
+	type dueTransfer struct {
+		ID, EmployeeID, FromCompanyID, ToCompanyID, EffectiveDate, Reason string
+		CustomFields                                                      map[string]interface{}
+		CreatedBy                                                         *string
+	}
+	due := []dueTransfer{}
+	for rows.Next() {
+		var t dueTransfer
+		if err := rows.Scan(&t.ID, &t.EmployeeID, &t.FromCompanyID, &t.ToCompanyID,
+			&t.EffectiveDate, &t.Reason, &t.CustomFields, &t.CreatedBy); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		due = append(due, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, t := range due {
+		res, err := applyDueTransfer(ctx, pool, t.ID, t.EmployeeID, t.FromCompanyID, t.ToCompanyID,
+			t.EffectiveDate, t.Reason, t.CustomFields)
+		if err != nil {
+			log.Printf("Error applying scheduled transfer %s: %v", t.ID, err)
+			continue
+		}
+		if res == nil {
+			continue
+		}
+		applied++
+		refreshEmployeeStatus(ctx, pool, t.EmployeeID)
+
+		userID := ""
+		if t.CreatedBy != nil {
+			userID = *t.CreatedBy
+		}
+		logActivity(pool, userID, "transferred", "employee", t.EmployeeID, map[string]interface{}{
+			"name":          res.Employee.Name,
+			"fromCompanyId": t.FromCompanyID,
+			"toCompanyId":   t.ToCompanyID,
+			"effectiveDate": t.EffectiveDate,
+			"scheduled":     true,
+			"slotsAdded":    res.Slots.Added,
+			"slotsRemoved":  res.Slots.Removed,
+			"salaryMoved":   res.SalaryMoved,
+		})
+	}
+	return applied, nil
+}
+
+// applyDueTransfer applies one scheduled transfer in its own transaction.
+// Returns nil without error when the transfer was cancelled instead.
+func applyDueTransfer(ctx context.Context, pool *pgxpool.Pool, transferID, employeeID, fromCompanyID, toCompanyID, effectiveDate, reason string, values map[string]interface{}) (*appliedTransfer, error) {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	st, err := loadTransferState(ctx, tx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if st.ExitType != nil || st.CompanyID != fromCompanyID {
+		if _, err := tx.Exec(ctx,
+			`UPDATE employee_transfers SET status = 'cancelled' WHERE id = $1 AND status = 'pending'`, transferID,
+		); err != nil {
+			return nil, err
+		}
+		return nil, tx.Commit(ctx)
+	}
+
+	defs, err := loadCustomFieldDefs(ctx, tx, toCompanyID)
+	if err != nil {
+		return nil, err
+	}
+	customFields, _ := models.MoveCustomFields(defs, st.CustomFields, values)
+
+	res, err := applyTransfer(ctx, tx, st, employeeID, toCompanyID, effectiveDate, reason, "", transferID, customFields)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Sponsorships handles GET /api/employees/{id}/sponsorships
+// Returns the sponsorship timeline (oldest first) and the transfers behind it.
+func (h *EmployeeHandler) Sponsorships(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		JSONError(w, http.StatusBadRequest, "Employee ID is required")
+		return
+	}
+
+	if !checkEmployeeAccess(r.Context(), h.db.GetPool(), id) {
+		JSONError(w, http.StatusForbidden, "Access denied to this employee")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	rows, err := pool.Query(ctx, `
+		SELECT s.id, s.employee_id, s.company_id::text, COALESCE(c.name, ''),
+			s.start_date::text, s.end_date::text, s.transfer_id::text, s.created_at::text
+		FROM employee_sponsorships s
+		LEFT JOIN companies c ON c.id = s.company_id
+		WHERE s.employee_id = $1
+		ORDER BY s.start_date, s.created_at
+	`, id)
+	if err != nil {
+		log.Printf("Error listing sponsorships for employee %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch sponsorship history")
+		return
+	}
+	defer rows.Close()
+
+	periods := []models.EmployeeSponsorship{}
+	for rows.Next() {
+		var s models.EmployeeSponsorship
+		if err := rows.Scan(
+			&s.ID, &s.EmployeeID, &s.CompanyID, &s.CompanyName,
+			&s.StartDate, &s.EndDate, &s.TransferID, &s.CreatedAt,
+		); err != nil {
+			log.Printf("Error scanning sponsorship row: %v", err)
+			JSONError(w, http.StatusInternalServerError, "Failed to fetch sponsorship history")
+			return
+		}
+		periods = append(periods, s)
+	}
+
+	rows, err = pool.Query(ctx, `
+		SELECT t.id, t.employee_id, t.from_company_id::text, COALESCE(fc.name, ''),
+			t.to_company_id::text, COALESCE(tc.name, ''), t.effective_date::text,
+			t.reason, t.status, t.slots_added, t.slots_removed, t.salary_moved,
+			t.created_by::text, t.created_at::text
+		FROM employee_transfers t
+		LEFT JOIN companies fc ON fc.id = t.from_company_id
+		LEFT JOIN companies tc ON tc.id = t.to_company_id
+		WHERE t.employee_id = $1 AND t.status <> 'cancelled'
+		ORDER BY t.effective_date, t.created_at
+	`, id)
+	if err != nil {
+		log.Printf("Error listing transfers for employee %s: %v", id, err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch sponsorship history")
+		return
+	}
+	defer rows.Close()
+
+	transfers := []models.EmployeeTransfer{}
+	for rows.Next() {
+		var t models.EmployeeTransfer
+		if err := rows.Scan(
+			&t.ID, &t.EmployeeID, &t.FromCompanyID, &t.FromCompanyName,
+			&t.ToCompanyID, &t.ToCompanyName, &t.EffectiveDate,
+			&t.Reason, &t.Status, &t.SlotsAdded, &t.SlotsRemoved, &t.SalaryMoved,
+			&t.CreatedBy, &t.CreatedAt,
+		); err != nil {
+			log.Printf("Error scanning transfer row: %v", err)
+			JSONError(w, http.StatusInternalServerError, "Failed to fetch sponsorship history")
+			return
+		}
+		transfers = append(transfers, t)
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"data":      periods,
+		"transfers": transfers,
+	})
+}
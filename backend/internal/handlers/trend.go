@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"manpower-backend/internal/compliance"
+	"manpower-backend/internal/models"
+)
+
+// ── Compliance Trends ────────────────────────────────────────────
+// Time series built from compliance_snapshots (migration 019, recorded by
+// the status refresher). Counts are states, not flows, so a weekly or
+// monthly point takes each company's last snapshot in the period.
+
+// trendInterval describes how one interval buckets dates.
+type trendInterval struct {
+	trunc       string // date_trunc unit
+	defaultSpan func(to time.Time) time.Time
+	start       func(d time.Time) time.Time
+	next        func(d time.Time) time.Time
+}
+
+var trendIntervals = map[string]trendInterval{
+	models.TrendDaily: {
+		trunc:       "day",
+		defaultSpan: func(to time.Time) time.Time { return to.AddDate(0, 0, -29) },
+		start:       func(d time.Time) time.Time { return d },
+		next:        func(d time.Time) time.Time { return d.AddDate(0, 0, 1) },
+	},
+	models.TrendWeekly: {
+		trunc:       "week", // ISO weeks start on Monday
+		defaultSpan: func(to time.Time) time.Time { return to.AddDate(0, 0, -7*11) },
+		start: func(d time.Time) time.Time {
+			return d.AddDate(0, 0, -((int(d.Weekday()) + 6) % 7))
+		},
+		next: func(d time.Time) time.Time { return d.AddDate(0, 0, 7) },
+	},
+	models.TrendMonthly: {
+		trunc:       "month",
+		defaultSpan: func(to time.Time) time.Time { return to.AddDate(0, -11, 0) },
+		start: func(d time.Time) time.Time {
+			return time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, time.UTC)
+		},
+		next: func(d time.Time) time.Time { return d.AddDate(0, 1, 0) },
+	},
+}
+
+// GetComplianceTrend handles GET /api/dashboard/trends?interval=&from=&to=&company_id=
+// interval is daily (default, last 30 days), weekly (last 12 weeks) or
+// monthly (last 12 months); from/to are YYYY-MM-DD and are widened to whole
+// periods.
+func (h *DashboardHandler) GetComplianceTrend(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	intervalName := q.Get("interval")
+	if intervalName == "" {
+		intervalName = models.TrendDaily
+	}
+	interval, ok := trendIntervals[intervalName]
+	if !ok {
+		JSONError(w, http.StatusBadRequest, "interval must be daily, weekly or monthly")
+		return
+	}
+
+	companyID := q.Get("company_id")
+	if companyID != "" && !checkCompanyAccess(r.Context(), companyID) {
+		JSONError(w, http.StatusForbidden, "Access denied to this company")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	pool := h.db.GetPool()
+
+	// Snapshots are recorded per company local date
+	to := reportToday(ctx, pool, companyID)
+	if v := q.Get("to"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			JSONError(w, http.StatusBadRequest, "to must be in YYYY-MM-DD format")
+			return
+		}
+		to = t
+	}
+	from := interval.defaultSpan(to)
+	if v := q.Get("from"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			JSONError(w, http.StatusBadRequest, "from must be in YYYY-MM-DD format")
+			return
+		}
+		from = t
+	}
+	if to.Before(from) {
+		JSONError(w, http.StatusBadRequest, "to must not be before from")
+		return
+	}
+
+	// Whole periods: [start of from's period, end of to's period]
+	periods := []time.Time{}
+	for p := interval.start(from); !p.After(to); p = interval.next(p) {
+		periods = append(periods, p)
+		if len(periods) > models.MaxTrendPoints {
+			JSONError(w, http.StatusBadRequest, fmt.Sprintf(
+				"Range too long: at most %d %s points", models.MaxTrendPoints, intervalName))
+			return
+		}
+	}
+	rangeEnd := interval.next(periods[len(periods)-1]).AddDate(0, 0, -1)
+
+	where := "WHERE cs.snapshot_date BETWEEN $1 AND $2"
+	args := []interface{}{periods[0], rangeEnd, interval.trunc}
+	argIdx := 4
+	where, args, argIdx = appendCompanyScope(ctx, where, args, argIdx, "cs.company_id")
+	if companyID != "" {
+		where += fmt.Sprintf(" AND cs.company_id::text = $%d", argIdx)
+		args = append(args, companyID)
+	}
+
+	rows, err := pool.Query(ctx, `
+		WITH ranked AS (
+			SELECT cs.*,
+				date_trunc($3, cs.snapshot_date::timestamp)::date AS period,
+				ROW_NUMBER() OVER (
+					PARTITION BY cs.company_id, date_trunc($3, cs.snapshot_date::timestamp)
+					ORDER BY cs.snapshot_date DESC
+				) AS rn
+			FROM compliance_snapshots cs
+			`+where+`
+		)
+		SELECT s.period, c.id::text, c.name,
+			s.docs_valid, s.docs_expiring_soon, s.docs_in_grace, s.docs_penalty_active,
+			s.docs_incomplete, s.employees_total, s.employees_compliant,
+			s.employees_incomplete, s.employees_penalty, s.fine_exposure::float8,
+			s.score_lost::float8, s.score_max::float8
+		FROM ranked s
+		JOIN companies c ON c.id = s.company_id
+		WHERE s.rn = 1
+		ORDER BY c.name, s.period
+	`, args...)
+	if err != nil {
+		log.Printf("Error fetching compliance trend: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch compliance trend")
+		return
+	}
+	defer rows.Close()
+
+	index := make(map[string]int, len(periods))
+	for i, p := range periods {
+		index[p.Format("2006-01-02")] = i
+	}
+	newPoints := func() []models.TrendPoint {
+		points := make([]models.TrendPoint, len(periods))
+		for i, p := range periods {
+			points[i].Period = p.Format("2006-01-02")
+			points[i].PeriodEnd = interval.next(p).AddDate(0, 0, -1).Format("2006-01-02")
+		}
+		return points
+	}
+
+	result := models.ComplianceTrend{
+		Interval:  intervalName,
+		From:      periods[0].Format("2006-01-02"),
+		To:        rangeEnd.Format("2006-01-02"),
+		Totals:    newPoints(),
+		ByCompany: []models.CompanyTrend{},
+	}
+	byCompany := map[string]*models.CompanyTrend{}
+	order := []string{} // company name order, from the query
+
+	for rows.Next() {
+		var period time.Time
+		var id, name string
+		var s models.TrendPoint
+		if err := rows.Scan(&period, &id, &name,
+			&s.DocsValid, &s.DocsExpiringSoon, &s.DocsInGrace, &s.DocsPenaltyActive,
+			&s.DocsIncomplete, &s.EmployeesTotal, &s.EmployeesCompliant,
+			&s.EmployeesIncomplete, &s.EmployeesPenalty, &s.FineExposure,
+			&s.ScoreLost, &s.ScoreMax,
+		); err != nil {
+			log.Printf("Error scanning compliance trend: %v", err)
+			continue
+		}
+		i, ok := index[period.Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+
+		ct, ok := byCompany[id]
+		if !ok {
+			ct = &models.CompanyTrend{CompanyID: id, CompanyName: name, Points: newPoints()}
+			byCompany[id] = ct
+			order = append(order, id)
+		}
+		addTrendPoint(&ct.Points[i], &s)
+		addTrendPoint(&result.Totals[i], &s)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error fetching compliance trend: %v", err)
+		JSONError(w, http.StatusInternalServerError, "Failed to fetch compliance trend")
+		return
+	}
+
+	for _, id := range order {
+		setTrendScores(byCompany[id].Points)
+		result.ByCompany = append(result.ByCompany, *byCompany[id])
+	}
+	setTrendScores(result.Totals)
+	for i := range result.Totals {
+		result.Totals[i].FineExposure = math.Round(result.Totals[i].FineExposure*100) / 100
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{"data": result})
+}
+
+// addTrendPoint adds one company snapshot s into point p.
+func addTrendPoint(p, s *models.TrendPoint) {
+	p.Companies++
+	p.DocsValid += s.DocsValid
+	p.DocsExpiringSoon += s.DocsExpiringSoon
+	p.DocsInGrace += s.DocsInGrace
+	p.DocsPenaltyActive += s.DocsPenaltyActive
+	p.DocsIncomplete += s.DocsIncomplete
+	p.EmployeesTotal += s.EmployeesTotal
+	p.EmployeesCompliant += s.EmployeesCompliant
+	p.EmployeesIncomplete += s.EmployeesIncomplete
+	p.EmployeesPenalty += s.EmployeesPenalty
+	p.FineExposure += s.FineExposure
+	p.ScoreLost += s.ScoreLost
+	p.ScoreMax += s.ScoreMax
+}
+
+// setTrendScores derives each point's score from its summed weights.
+func setTrendScores(points []models.TrendPoint) {
+	for i := range points {
+		if points[i].Companies == 0 {
+			continue
+		}
+		score := compliance.ScoreValue(points[i].ScoreLost, points[i].ScoreMax)
+		points[i].ComplianceScore = &score
+	}
+}
@@ -52,25 +52,8 @@ func (h *UploadHandler) Upload(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
-	// Validate file type by reading the first 512 bytes (MIME sniffing)
-	buffer := make([]byte, 512)
-	n, err := file.Read(buffer)
-	if err != nil && err != io.EOF {
-		JSONError(w, http.StatusBadRequest, "Could not read file.")
-		return
-	}
-	contentType := http.DetectContentType(buffer[:n])
-
-	if !allowedTypes[contentType] {
-		JSONError(w, http.StatusBadRequest, fmt.Sprintf(
-			"File type '%s' not allowed. Accepted: PDF, JPG, PNG.", contentType,
-		))
-		return
-	}
-
-	// Reset file reader to beginning after MIME sniffing
-	if _, err := file.Seek(0, io.SeekStart); err != nil {
-		JSONError(w, http.StatusInternalServerError, "Failed to process file.")
+	contentType, ok := sniffUpload(w, file)
+	if !ok {
 		return
 	}
 
@@ -117,6 +100,33 @@ func (h *UploadHandler) ServeFile(w http.ResponseWriter, r *http.Request) {
 	http.ServeFile(w, r, filepath.Join("uploads", filepath.Clean(filePath)))
 }
 
+// sniffUpload validates the file type by reading the first 512 bytes (MIME
+// sniffing) and rewinds the file. On failure it writes the error response
+// and returns ok == false.
+func sniffUpload(w http.ResponseWriter, file io.ReadSeeker) (contentType string, ok bool) {
+	buffer := make([]byte, 512)
+	n, err := file.Read(buffer)
+	if err != nil && err != io.EOF {
+		JSONError(w, http.StatusBadRequest, "Could not read file.")
+		return "", false
+	}
+	contentType = http.DetectContentType(buffer[:n])
+
+	if !allowedTypes[contentType] {
+		JSONError(w, http.StatusBadRequest, fmt.Sprintf(
+			"File type '%s' not allowed. Accepted: PDF, JPG, PNG.", contentType,
+		))
+		return "", false
+	}
+
+	// Reset file reader to beginning after MIME sniffing
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		JSONError(w, http.StatusInternalServerError, "Failed to process file.")
+		return "", false
+	}
+	return contentType, true
+}
+
 // sanitizeFilename removes path separators and unsafe characters.
 func sanitizeFilename(name string) string {
 	// Keep only the base name (no directory components)
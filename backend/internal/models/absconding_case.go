@@ -0,0 +1,112 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ── Absconding Cases ─────────────────────────────────────────
+
+// AbscondingCase tracks the legal process for an absconded employee
+// (migration 025).
+type AbscondingCase struct {
+	ID               string  `json:"id"`
+	EmployeeID       string  `json:"employeeId"`
+	CompanyID        string  `json:"companyId"`
+	Status           string  `json:"status"` // "reported" | "filed" | "withdrawn"
+	ReportedOn       string  `json:"reportedOn"`
+	LastWorkingDay   string  `json:"lastWorkingDay"`
+	Authority        string  `json:"authority"` // "mohre" | "gdrfa"
+	EarliestFiling   string  `json:"earliestFiling"`
+	FilingDeadline   string  `json:"filingDeadline"`
+	FilingOverdue    bool    `json:"filingOverdue"` // computed: reported and past the deadline
+	FiledOn          *string `json:"filedOn"`
+	FilingReference  string  `json:"filingReference"`
+	WithdrawnOn      *string `json:"withdrawnOn"`
+	WithdrawalReason string  `json:"withdrawalReason"`
+	Notes            string  `json:"notes"`
+	SalaryOnHold     int     `json:"salaryOnHold"` // salary records held by this case
+	CreatedBy        *string `json:"createdBy"`
+	CreatedAt        string  `json:"createdAt"`
+	UpdatedAt        string  `json:"updatedAt"`
+	EmployeeName     string  `json:"employeeName"`
+	CompanyName      string  `json:"companyName"`
+}
+
+// AbscondingCaseFile is a supporting document attached to a case.
+type AbscondingCaseFile struct {
+	ID          string  `json:"id"`
+	CaseID      string  `json:"caseId"`
+	FileURL     string  `json:"fileUrl"`
+	FileName    string  `json:"fileName"`
+	FileSize    int64   `json:"fileSize"`
+	FileType    string  `json:"fileType"`
+	Description string  `json:"description"`
+	UploadedBy  *string `json:"uploadedBy"`
+	CreatedAt   string  `json:"createdAt"`
+}
+
+// ValidAbscondingAuthorities are the authorities a case can be filed with.
+var ValidAbscondingAuthorities = []string{"mohre", "gdrfa"}
+
+// UpdateAbscondingCaseRequest records the filing or edits the case.
+// Setting FiledOn moves a reported case to filed.
+type UpdateAbscondingCaseRequest struct {
+	Authority       *string `json:"authority"`
+	FiledOn         *string `json:"filedOn"`
+	FilingReference *string `json:"filingReference"`
+	Notes           *string `json:"notes"`
+}
+
+// Validate checks the authority and filing date.
+func (r *UpdateAbscondingCaseRequest) Validate() map[string]string {
+	errors := map[string]string{}
+	if r.Authority == nil && r.FiledOn == nil && r.FilingReference == nil && r.Notes == nil {
+		errors["filedOn"] = "Nothing to update"
+	}
+	if r.Authority != nil {
+		a := strings.ToLower(strings.TrimSpace(*r.Authority))
+		r.Authority = &a
+		if a != "mohre" && a != "gdrfa" {
+			errors["authority"] = "Authority must be one of: " + strings.Join(ValidAbscondingAuthorities, ", ")
+		}
+	}
+	if r.FiledOn != nil {
+		if _, err := time.Parse("2006-01-02", *r.FiledOn); err != nil {
+			errors["filedOn"] = "Filing date must be in YYYY-MM-DD format"
+		}
+	}
+	if r.FilingReference != nil {
+		ref := strings.TrimSpace(*r.FilingReference)
+		r.FilingReference = &ref
+		if len(ref) > 100 {
+			errors["filingReference"] = "Reference must be at most 100 characters"
+		}
+	}
+	if r.Notes != nil {
+		notes := strings.TrimSpace(*r.Notes)
+		r.Notes = &notes
+	}
+	return errors
+}
+
+// WithdrawAbscondingCaseRequest closes a case because the worker returned.
+type WithdrawAbscondingCaseRequest struct {
+	ReturnedOn string `json:"returnedOn"`
+	Reason     string `json:"reason"`
+}
+
+// Validate checks the return date and reason.
+func (r *WithdrawAbscondingCaseRequest) Validate() map[string]string {
+	errors := map[string]string{}
+	r.Reason = strings.TrimSpace(r.Reason)
+	if _, err := time.Parse("2006-01-02", r.ReturnedOn); err != nil {
+		errors["returnedOn"] = "Return date must be in YYYY-MM-DD format"
+	}
+	if r.Reason == "" {
+		errors["reason"] = "Reason is required"
+	} else if len(r.Reason) > 4000 {
+		errors["reason"] = "Reason must be at most 4000 characters"
+	}
+	return errors
+}
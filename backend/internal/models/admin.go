@@ -1,6 +1,9 @@
 package models
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"strings"
+)
 
 // ── Document Types ───────────────────────────────────────────
 
@@ -18,6 +21,7 @@ type DocumentType struct {
 	MetadataFields    json.RawMessage `json:"metadataFields"`
 	IsSystem          bool            `json:"isSystem"`
 	IsActive          bool            `json:"isActive"`
+	AppliesTo         string          `json:"appliesTo"` // "employee" | "dependent" (migration 031)
 
 	// Per-field visibility and required flags (migration 008)
 	ShowDocumentNumber    bool `json:"showDocumentNumber"`
@@ -29,6 +33,10 @@ type DocumentType struct {
 	ShowFile              bool `json:"showFile"`
 	RequireFile           bool `json:"requireFile"`
 
+	// Standard validity (migration 022); 0 = no standard term
+	ValidityPeriod int    `json:"validityPeriod"`
+	ValidityUnit   string `json:"validityUnit"` // "months" | "days"
+
 	CreatedAt string `json:"createdAt"`
 	UpdatedAt string `json:"updatedAt"`
 }
@@ -43,6 +51,7 @@ type CreateDocumentTypeRequest struct {
 	ExpiryLabel       string          `json:"expiryLabel"`
 	SortOrder         int             `json:"sortOrder"`
 	MetadataFields    json.RawMessage `json:"metadataFields"`
+	AppliesTo         string          `json:"appliesTo"` // default "employee"
 
 	ShowDocumentNumber    *bool `json:"showDocumentNumber,omitempty"`
 	RequireDocumentNumber *bool `json:"requireDocumentNumber,omitempty"`
@@ -52,6 +61,9 @@ type CreateDocumentTypeRequest struct {
 	RequireExpiryDate     *bool `json:"requireExpiryDate,omitempty"`
 	ShowFile              *bool `json:"showFile,omitempty"`
 	RequireFile           *bool `json:"requireFile,omitempty"`
+
+	ValidityPeriod int    `json:"validityPeriod"`
+	ValidityUnit   string `json:"validityUnit"` // default "months"
 }
 
 // Validate checks required fields for a new document type.
@@ -63,6 +75,16 @@ func (r *CreateDocumentTypeRequest) Validate() map[string]string {
 	if len(r.DisplayName) < 2 {
 		errors["displayName"] = "Display name is required (min 2 characters)"
 	}
+	if r.AppliesTo == "" {
+		r.AppliesTo = "employee"
+	}
+	if r.AppliesTo != "employee" && r.AppliesTo != "dependent" {
+		errors["appliesTo"] = "Applies to must be employee or dependent"
+	}
+	if r.ValidityUnit == "" {
+		r.ValidityUnit = "months"
+	}
+	validateValidity(errors, &r.ValidityPeriod, &r.ValidityUnit)
 	return errors
 }
 
@@ -83,6 +105,39 @@ type UpdateDocumentTypeRequest struct {
 	RequireExpiryDate     *bool `json:"requireExpiryDate,omitempty"`
 	ShowFile              *bool `json:"showFile,omitempty"`
 	RequireFile           *bool `json:"requireFile,omitempty"`
+
+	ValidityPeriod *int    `json:"validityPeriod,omitempty"` // 0 = no standard term
+	ValidityUnit   *string `json:"validityUnit,omitempty"`
+}
+
+// Validate checks the optional validity fields.
+func (r *UpdateDocumentTypeRequest) Validate() map[string]string {
+	errors := map[string]string{}
+	validateValidity(errors, r.ValidityPeriod, r.ValidityUnit)
+	return errors
+}
+
+// ValidValidityUnit reports whether unit is "months" or "days".
+func ValidValidityUnit(unit string) bool {
+	return unit == "months" || unit == "days"
+}
+
+// validateValidity checks a standard validity period (up to 10 years) and unit.
+// Either may be nil when not being changed.
+func validateValidity(errors map[string]string, period *int, unit *string) {
+	if unit != nil && !ValidValidityUnit(*unit) {
+		errors["validityUnit"] = "Validity unit must be months or days"
+	}
+	if period == nil {
+		return
+	}
+	max := 120
+	if unit != nil && *unit == "days" {
+		max = 3650
+	}
+	if *period < 0 || *period > max {
+		errors["validityPeriod"] = "Validity must be between 0 and 10 years"
+	}
 }
 
 // ── Compliance Rules ─────────────────────────────────────────
@@ -93,10 +148,13 @@ type ComplianceRule struct {
 	CompanyID       *string  `json:"companyId"`
 	DocType         string   `json:"docType"`
 	GracePeriodDays int      `json:"gracePeriodDays"`
+	GracePeriodUnit string   `json:"gracePeriodUnit"` // "calendar" | "working"
 	FinePerDay      float64  `json:"finePerDay"`
 	FineType        string   `json:"fineType"`
 	FineCap         float64  `json:"fineCap"`
 	IsMandatory     *bool    `json:"isMandatory"`
+	ValidityPeriod  *int     `json:"validityPeriod"` // nil = document type's standard validity
+	ValidityUnit    *string  `json:"validityUnit"`
 	CreatedAt       string   `json:"createdAt"`
 	UpdatedAt       string   `json:"updatedAt"`
 }
@@ -105,10 +163,29 @@ type ComplianceRule struct {
 type ComplianceRuleInput struct {
 	DocType         string  `json:"docType"`
 	GracePeriodDays int     `json:"gracePeriodDays"`
+	GracePeriodUnit string  `json:"gracePeriodUnit,omitempty"` // "calendar" (default) | "working"
 	FinePerDay      float64 `json:"finePerDay"`
 	FineType        string  `json:"fineType"`
 	FineCap         float64 `json:"fineCap"`
 	IsMandatory     *bool   `json:"isMandatory"`
+
+	// Company override of the standard validity: nil keeps the current
+	// override, 0 removes it (the document type's applies again)
+	ValidityPeriod *int   `json:"validityPeriod,omitempty"`
+	ValidityUnit   string `json:"validityUnit,omitempty"` // default "months"
+}
+
+// GraceUnit returns the grace period unit, defaulting to calendar days.
+func (r ComplianceRuleInput) GraceUnit() string {
+	if r.GracePeriodUnit == "" {
+		return "calendar"
+	}
+	return r.GracePeriodUnit
+}
+
+// ValidGraceUnit reports whether unit is empty, "calendar" or "working".
+func ValidGraceUnit(unit string) bool {
+	return unit == "" || unit == "calendar" || unit == "working"
 }
 
 // UpsertComplianceRulesRequest is the request body for bulk-upserting rules.
@@ -132,6 +209,128 @@ func (r *UpsertComplianceRulesRequest) Validate() map[string]string {
 			errors["rules"] = "Rule " + rule.DocType + " has invalid fineType"
 			break
 		}
+		if !ValidGraceUnit(rule.GracePeriodUnit) {
+			errors["rules"] = "Rule " + rule.DocType + " has invalid gracePeriodUnit (calendar or working)"
+			break
+		}
+		if rule.ValidityPeriod != nil {
+			if rule.ValidityUnit == "" {
+				r.Rules[i].ValidityUnit = "months"
+			}
+			unit := r.Rules[i].ValidityUnit
+			verrs := map[string]string{}
+			validateValidity(verrs, rule.ValidityPeriod, &unit)
+			if len(verrs) > 0 {
+				errors["rules"] = "Rule " + rule.DocType + " has invalid validity"
+				break
+			}
+		}
+	}
+	return errors
+}
+
+// ── Requirement Conditions ───────────────────────────────────
+
+// RequirementCondition makes a document type mandatory ("require") or not
+// ("exempt") for employees whose attributes match. Empty lists match any value.
+type RequirementCondition struct {
+	ID            string   `json:"id"`
+	CompanyID     *string  `json:"companyId"`
+	DocType       string   `json:"docType"`
+	Effect        string   `json:"effect"` // "require" | "exempt"
+	Nationalities []string `json:"nationalities"`
+	Trades        []string `json:"trades"`
+	Genders       []string `json:"genders"`
+	Statuses      []string `json:"statuses"`
+	Description   string   `json:"description"`
+	CreatedAt     string   `json:"createdAt"`
+	UpdatedAt     string   `json:"updatedAt"`
+}
+
+// RequirementConditionRequest is used to create or replace a condition.
+type RequirementConditionRequest struct {
+	CompanyID     *string  `json:"companyId"`
+	DocType       string   `json:"docType"`
+	Effect        string   `json:"effect"`
+	Nationalities []string `json:"nationalities"`
+	Trades        []string `json:"trades"`
+	Genders       []string `json:"genders"`
+	Statuses      []string `json:"statuses"`
+	Description   string   `json:"description"`
+}
+
+// Validate checks the condition targets a doc type with a known effect
+// and normalises match values to the lower-cased form stored in the DB.
+func (r *RequirementConditionRequest) Validate() map[string]string {
+	errors := map[string]string{}
+	if r.DocType == "" {
+		errors["docType"] = "Document type is required"
+	}
+	if r.Effect != "require" && r.Effect != "exempt" {
+		errors["effect"] = "Effect must be 'require' or 'exempt'"
+	}
+	r.Nationalities = normalizeMatchValues(r.Nationalities)
+	r.Trades = normalizeMatchValues(r.Trades)
+	r.Genders = normalizeMatchValues(r.Genders)
+	r.Statuses = normalizeMatchValues(r.Statuses)
+	if len(r.Nationalities)+len(r.Trades)+len(r.Genders)+len(r.Statuses) == 0 {
+		errors["conditions"] = "At least one of nationalities, trades, genders or statuses is required"
+	}
+	return errors
+}
+
+// normalizeMatchValues trims, lower-cases and de-duplicates match values.
+func normalizeMatchValues(values []string) []string {
+	out := []string{}
+	seen := map[string]bool{}
+	for _, v := range values {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
+
+// ── Compliance Score Weights ─────────────────────────────────────
+
+// ScoreWeights are the admin settings of the compliance score (see
+// compliance.ScoreWeights for how they are applied).
+type ScoreWeights struct {
+	Penalty    float64 `json:"penalty"`
+	Grace      float64 `json:"grace"`
+	Expiring   float64 `json:"expiring"`
+	Incomplete float64 `json:"incomplete"`
+	FineRate   float64 `json:"fineRate"`
+	Optional   float64 `json:"optional"`
+	UpdatedAt  string  `json:"updatedAt,omitempty"`
+}
+
+// Validate checks weight ranges.
+func (r *ScoreWeights) Validate() map[string]string {
+	errors := map[string]string{}
+	statusWeights := map[string]float64{
+		"penalty": r.Penalty, "grace": r.Grace, "expiring": r.Expiring, "incomplete": r.Incomplete,
+	}
+	anyPositive := false
+	for field, v := range statusWeights {
+		if v < 0 || v > 10 {
+			errors[field] = "Weight must be between 0 and 10"
+		}
+		if v > 0 {
+			anyPositive = true
+		}
+	}
+	if !anyPositive {
+		errors["penalty"] = "At least one status weight must be greater than 0"
+	}
+	if r.FineRate < 0 || r.FineRate > 10 {
+		errors["fineRate"] = "Weight must be between 0 and 10"
+	}
+	if r.Optional < 0 || r.Optional > 1 {
+		errors["optional"] = "Weight must be between 0 and 1"
 	}
 	return errors
 }
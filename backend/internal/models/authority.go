@@ -0,0 +1,147 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ── Authority Profiles ───────────────────────────────────────
+
+// AuthorityProfile bundles the document rules and dependency chain of a
+// regulatory authority (MOHRE, JAFZA, DMCC, ...). Code matches
+// companies.regulatory_authority.
+type AuthorityProfile struct {
+	ID           string                       `json:"id"`
+	Code         string                       `json:"code"`
+	Name         string                       `json:"name"`
+	Description  string                       `json:"description"`
+	Version      int                          `json:"version"`
+	Rules        []ComplianceRuleInput        `json:"rules"`
+	Dependencies []AuthorityProfileDependency `json:"dependencies"`
+	CreatedAt    string                       `json:"createdAt"`
+	UpdatedAt    string                       `json:"updatedAt"`
+}
+
+// AuthorityProfileDependency is a dependency rule carried by a profile.
+type AuthorityProfileDependency struct {
+	BlockingDocType string `json:"blockingDocType"`
+	BlockedDocType  string `json:"blockedDocType"`
+	Description     string `json:"description"`
+	MinValidityDays int    `json:"minValidityDays"`
+}
+
+// AuthorityProfileVersion is a snapshot of a profile at a given version.
+type AuthorityProfileVersion struct {
+	Version      int                          `json:"version"`
+	Name         string                       `json:"name"`
+	Description  string                       `json:"description"`
+	Rules        []ComplianceRuleInput        `json:"rules"`
+	Dependencies []AuthorityProfileDependency `json:"dependencies"`
+	ChangedBy    *string                      `json:"changedBy"`
+	CreatedAt    string                       `json:"createdAt"`
+}
+
+// AuthorityProfileRequest is the body for creating/updating a profile.
+// Code is only read on create; updates address the profile by URL.
+type AuthorityProfileRequest struct {
+	Code         string                       `json:"code"`
+	Name         string                       `json:"name"`
+	Description  string                       `json:"description"`
+	Rules        []ComplianceRuleInput        `json:"rules"`
+	Dependencies []AuthorityProfileDependency `json:"dependencies"`
+}
+
+var authorityCodePattern = regexp.MustCompile(`^[A-Z0-9_]{2,50}$`)
+
+// NormalizeAuthorityCode trims and upper-cases an authority code.
+func NormalizeAuthorityCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Validate checks required fields, fine types and duplicate entries.
+// Doc types are checked against document_types by the handler.
+func (r *AuthorityProfileRequest) Validate(requireCode bool) map[string]string {
+	errors := map[string]string{}
+	r.Code = NormalizeAuthorityCode(r.Code)
+	r.Name = strings.TrimSpace(r.Name)
+
+	if requireCode && !authorityCodePattern.MatchString(r.Code) {
+		errors["code"] = "Code must be 2-50 characters: A-Z, 0-9 or underscore"
+	}
+	if r.Name == "" {
+		errors["name"] = "Name is required"
+	}
+	if len(r.Rules) == 0 {
+		errors["rules"] = "At least one rule is required"
+	}
+
+	seen := map[string]bool{}
+	for _, rule := range r.Rules {
+		if rule.DocType == "" {
+			errors["rules"] = "Every rule needs a docType"
+			break
+		}
+		if seen[rule.DocType] {
+			errors["rules"] = fmt.Sprintf("Duplicate rule for %s", rule.DocType)
+			break
+		}
+		seen[rule.DocType] = true
+		if rule.FineType != "daily" && rule.FineType != "monthly" && rule.FineType != "one_time" {
+			errors["rules"] = "Rule " + rule.DocType + " has invalid fineType"
+			break
+		}
+		if rule.GracePeriodDays < 0 || rule.FinePerDay < 0 || rule.FineCap < 0 {
+			errors["rules"] = "Rule " + rule.DocType + " has negative values"
+			break
+		}
+		if !ValidGraceUnit(rule.GracePeriodUnit) {
+			errors["rules"] = "Rule " + rule.DocType + " has invalid gracePeriodUnit"
+			break
+		}
+	}
+
+	seenDep := map[string]bool{}
+	for _, d := range r.Dependencies {
+		if d.BlockingDocType == "" || d.BlockedDocType == "" || strings.TrimSpace(d.Description) == "" {
+			errors["dependencies"] = "Every dependency needs blockingDocType, blockedDocType and description"
+			break
+		}
+		if d.BlockingDocType == d.BlockedDocType {
+			errors["dependencies"] = "A document type cannot block itself"
+			break
+		}
+		key := d.BlockingDocType + "→" + d.BlockedDocType
+		if seenDep[key] {
+			errors["dependencies"] = "Duplicate dependency " + key
+			break
+		}
+		seenDep[key] = true
+		if d.MinValidityDays < 0 || d.MinValidityDays > 3650 {
+			errors["dependencies"] = "minValidityDays must be between 0 and 3650"
+			break
+		}
+	}
+	return errors
+}
+
+// ProfileRuleDiff compares a company's current rule for a doc type with what
+// applying a profile would set.
+type ProfileRuleDiff struct {
+	DocType   string               `json:"docType"`
+	Change    string               `json:"change"`    // "add" | "update" | "unchanged"
+	Current   *ComplianceRuleInput `json:"current"`   // company-level override, nil if none
+	Effective ComplianceRuleInput  `json:"effective"` // what applies today (company or global)
+	Proposed  ComplianceRuleInput  `json:"proposed"`
+}
+
+// ProfilePreview is the result of previewing a profile against a company.
+type ProfilePreview struct {
+	CompanyID           string                       `json:"companyId"`
+	Code                string                       `json:"code"`
+	Version             int                          `json:"version"`
+	AppliedVersion      *int                         `json:"appliedVersion"`
+	Rules               []ProfileRuleDiff            `json:"rules"`
+	DependenciesAdded   []AuthorityProfileDependency `json:"dependenciesAdded"`
+	DependenciesRemoved []AuthorityProfileDependency `json:"dependenciesRemoved"`
+}
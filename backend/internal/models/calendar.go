@@ -0,0 +1,41 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ── Public Holidays ──────────────────────────────────────────
+
+// PublicHoliday is a non-working day used to count working-day grace
+// periods. CompanyID nil = applies to every company.
+type PublicHoliday struct {
+	ID        string  `json:"id"`
+	CompanyID *string `json:"companyId"`
+	Date      string  `json:"date"` // YYYY-MM-DD
+	Name      string  `json:"name"`
+	CreatedAt string  `json:"createdAt"`
+}
+
+// PublicHolidayRequest is the body for adding a holiday.
+type PublicHolidayRequest struct {
+	CompanyID *string `json:"companyId"`
+	Date      string  `json:"date"`
+	Name      string  `json:"name"`
+}
+
+// Validate checks the date format and name.
+func (r *PublicHolidayRequest) Validate() map[string]string {
+	errors := map[string]string{}
+	r.Name = strings.TrimSpace(r.Name)
+	if r.CompanyID != nil && strings.TrimSpace(*r.CompanyID) == "" {
+		r.CompanyID = nil
+	}
+	if _, err := time.Parse("2006-01-02", r.Date); err != nil {
+		errors["date"] = "Date must be in YYYY-MM-DD format"
+	}
+	if r.Name == "" {
+		errors["name"] = "Name is required"
+	}
+	return errors
+}
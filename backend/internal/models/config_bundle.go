@@ -0,0 +1,225 @@
+package models
+
+// ── Admin Configuration Bundle ───────────────────────────────
+// Serialised form of every admin setting so environments can be kept in sync.
+// Companies are referenced by name (IDs differ between environments).
+// The same JSON field names are used for the YAML form.
+
+// ConfigBundleFormat identifies an admin configuration bundle.
+const ConfigBundleFormat = "manpower-admin-config"
+
+// ConfigBundleSchemaVersion is the bundle layout version written by export.
+// Import accepts bundles up to this version; sections a bundle predates are
+// left untouched. Each version adds:
+//   - 2: standard validity on document types and compliance rules
+//   - 3: appliesTo (employee or dependent document types)
+//   - 4: document links
+//   - 5: public holidays and company calendars
+//   - 6: renewal costs
+//   - 7: score weights
+//   - 8: exit checklist rules
+//   - 9: custom fields
+//   - 10: leave types
+//   - 11: Emiratisation targets
+const ConfigBundleSchemaVersion = 11
+
+// ConfigBundle holds all admin settings, global and per-company.
+type ConfigBundle struct {
+	Format                string                       `json:"format"`
+	SchemaVersion         int                          `json:"schemaVersion"`
+	ExportedAt            string                       `json:"exportedAt"`
+	Checksum              string                       `json:"checksum"` // sha256 of the settings; equal checksums = identical config
+	DocumentTypes         []BundleDocumentType         `json:"documentTypes"`
+	ComplianceRules       []BundleComplianceRule       `json:"complianceRules"`
+	Dependencies          []BundleDependency           `json:"dependencies"`
+	RequirementConditions []BundleRequirementCondition `json:"requirementConditions"`
+	AuthorityProfiles     []BundleAuthorityProfile     `json:"authorityProfiles"`
+	DocumentLinks         []BundleDocumentLink         `json:"documentLinks"`
+	PublicHolidays        []BundlePublicHoliday        `json:"publicHolidays"`
+	CompanyCalendars      []BundleCompanyCalendar      `json:"companyCalendars"`
+	RenewalCosts          []BundleRenewalCost          `json:"renewalCosts"`
+	ScoreWeights          *ScoreWeights                `json:"scoreWeights"` // nil = leave unchanged
+	ExitChecklistRules    []BundleExitChecklistRule    `json:"exitChecklistRules"`
+	CustomFields          []BundleCustomField          `json:"customFields"`
+	LeaveTypes            []LeaveType                  `json:"leaveTypes"`
+	EmiratisationTargets  []BundleEmiratisationTarget  `json:"emiratisationTargets"`
+}
+
+// BundleDocumentType is a document type as exported. IsSystem is informational.
+type BundleDocumentType struct {
+	DocType               string      `json:"docType"`
+	DisplayName           string      `json:"displayName"`
+	IsMandatory           bool        `json:"isMandatory"`
+	HasExpiry             bool        `json:"hasExpiry"`
+	NumberLabel           string      `json:"numberLabel"`
+	NumberPlaceholder     string      `json:"numberPlaceholder"`
+	ExpiryLabel           string      `json:"expiryLabel"`
+	SortOrder             int         `json:"sortOrder"`
+	MetadataFields        interface{} `json:"metadataFields"`
+	IsSystem              bool        `json:"isSystem"`
+	IsActive              bool        `json:"isActive"`
+	ShowDocumentNumber    bool        `json:"showDocumentNumber"`
+	RequireDocumentNumber bool        `json:"requireDocumentNumber"`
+	ShowIssueDate         bool        `json:"showIssueDate"`
+	RequireIssueDate      bool        `json:"requireIssueDate"`
+	ShowExpiryDate        bool        `json:"showExpiryDate"`
+	RequireExpiryDate     bool        `json:"requireExpiryDate"`
+	ShowFile              bool        `json:"showFile"`
+	RequireFile           bool        `json:"requireFile"`
+	ValidityPeriod        int         `json:"validityPeriod"`
+	ValidityUnit          string      `json:"validityUnit"`
+	AppliesTo             string      `json:"appliesTo"`
+}
+
+// BundleComplianceRule is a grace/fine rule. Company empty = global.
+type BundleComplianceRule struct {
+	ID              string  `json:"-"`
+	Company         string  `json:"company,omitempty"`
+	DocType         string  `json:"docType"`
+	GracePeriodDays int     `json:"gracePeriodDays"`
+	GracePeriodUnit string  `json:"gracePeriodUnit"`
+	FinePerDay      float64 `json:"finePerDay"`
+	FineType        string  `json:"fineType"`
+	FineCap         float64 `json:"fineCap"`
+	IsMandatory     *bool   `json:"isMandatory"`
+	ValidityPeriod  *int    `json:"validityPeriod,omitempty"` // nil = document type's
+	ValidityUnit    *string `json:"validityUnit,omitempty"`
+}
+
+// BundleDependency is a dependency rule scoped to a company, an authority or global.
+type BundleDependency struct {
+	ID                  string `json:"-"`
+	Company             string `json:"company,omitempty"`
+	RegulatoryAuthority string `json:"regulatoryAuthority,omitempty"`
+	BlockingDocType     string `json:"blockingDocType"`
+	BlockedDocType      string `json:"blockedDocType"`
+	Description         string `json:"description"`
+	MinValidityDays     int    `json:"minValidityDays"`
+}
+
+// BundleRequirementCondition is a conditional mandatory rule. Company empty = global.
+type BundleRequirementCondition struct {
+	ID            string   `json:"-"`
+	Company       string   `json:"company,omitempty"`
+	DocType       string   `json:"docType"`
+	Effect        string   `json:"effect"`
+	Nationalities []string `json:"nationalities"`
+	Trades        []string `json:"trades"`
+	Genders       []string `json:"genders"`
+	Statuses      []string `json:"statuses"`
+	Description   string   `json:"description"`
+}
+
+// BundleAuthorityProfile is the current version of an authority profile.
+type BundleAuthorityProfile struct {
+	Code         string                       `json:"code"`
+	Name         string                       `json:"name"`
+	Description  string                       `json:"description"`
+	Rules        []ComplianceRuleInput        `json:"rules"`
+	Dependencies []AuthorityProfileDependency `json:"dependencies"`
+}
+
+// BundleDocumentLink is a linked-expiry rule. Company empty = global.
+type BundleDocumentLink struct {
+	ID             string `json:"-"`
+	Company        string `json:"company,omitempty"`
+	SourceDocType  string `json:"sourceDocType"`
+	TargetDocType  string `json:"targetDocType"`
+	Mode           string `json:"mode"`
+	OffsetDays     int    `json:"offsetDays"`
+	ValidityMonths int    `json:"validityMonths"`
+	ValidityDays   int    `json:"validityDays"`
+	Action         string `json:"action"`
+	IsActive       bool   `json:"isActive"`
+	Description    string `json:"description"`
+}
+
+// BundlePublicHoliday is a non-working day. Company empty = every company.
+type BundlePublicHoliday struct {
+	ID      string `json:"-"`
+	Company string `json:"company,omitempty"`
+	Date    string `json:"date"`
+	Name    string `json:"name"`
+}
+
+// BundleCompanyCalendar is a company's timezone and weekend. Only calendars
+// that differ from the default (Asia/Dubai, Saturday/Sunday) are exported;
+// a company left out of the bundle is reset to the default.
+type BundleCompanyCalendar struct {
+	Company     string `json:"company"`
+	Timezone    string `json:"timezone"`
+	WeekendDays []int  `json:"weekendDays"` // ISO weekdays, 1 = Monday … 7 = Sunday
+}
+
+// BundleRenewalCost is one renewal cost line. Company empty = global.
+type BundleRenewalCost struct {
+	ID            string  `json:"-"`
+	Company       string  `json:"company,omitempty"`
+	DocType       string  `json:"docType"`
+	Component     string  `json:"component"`
+	Amount        float64 `json:"amount"`
+	EffectiveFrom string  `json:"effectiveFrom"`
+	EffectiveTo   *string `json:"effectiveTo"` // nil = open-ended
+	Description   string  `json:"description"`
+}
+
+// BundleExitChecklistRule is the deadline and fine of one exit checklist
+// item. Company empty = global.
+type BundleExitChecklistRule struct {
+	ID          string   `json:"-"`
+	Company     string   `json:"company,omitempty"`
+	Item        string   `json:"item"`
+	DueDays     int      `json:"dueDays"`
+	FinePerDay  float64  `json:"finePerDay"`
+	FineType    string   `json:"fineType"`
+	FineCap     float64  `json:"fineCap"`
+	DocTypes    []string `json:"docTypes"`
+	Description string   `json:"description"`
+}
+
+// BundleCustomField is a custom employee field of one company.
+type BundleCustomField struct {
+	ID        string              `json:"-"`
+	Company   string              `json:"company"`
+	Key       string              `json:"key"`
+	Label     string              `json:"label"`
+	Type      string              `json:"type"`
+	Required  bool                `json:"required"`
+	Options   []CustomFieldOption `json:"options"`
+	Pattern   string              `json:"pattern"`
+	MinValue  *float64            `json:"minValue"`
+	MaxValue  *float64            `json:"maxValue"`
+	HelpText  string              `json:"helpText"`
+	SortOrder int                 `json:"sortOrder"`
+	IsActive  bool                `json:"isActive"`
+}
+
+// BundleEmiratisationTarget is the quota at one checkpoint. Company empty = global.
+type BundleEmiratisationTarget struct {
+	ID             string  `json:"-"`
+	Company        string  `json:"company,omitempty"`
+	CheckpointDate string  `json:"checkpointDate"`
+	TargetPercent  float64 `json:"targetPercent"`
+	MinHeadcount   int     `json:"minHeadcount"`
+	MinWorkforce   int     `json:"minWorkforce"`
+	FinePerMissing float64 `json:"finePerMissing"`
+	Description    string  `json:"description"`
+}
+
+// ConfigChange is one difference between the current settings and a bundle.
+type ConfigChange struct {
+	Section string      `json:"section"` // documentTypes | complianceRules | dependencies | requirementConditions | authorityProfiles | documentLinks | publicHolidays | companyCalendars | renewalCosts | scoreWeights | exitChecklistRules | customFields | leaveTypes | emiratisationTargets
+	Key     string      `json:"key"`
+	Action  string      `json:"action"`           // create | update | delete | retain
+	Reason  string      `json:"reason,omitempty"` // why a delete was retained
+	Before  interface{} `json:"before,omitempty"`
+	After   interface{} `json:"after,omitempty"`
+}
+
+// ConfigImportResult is returned by the import endpoint.
+type ConfigImportResult struct {
+	Applied  bool           `json:"applied"` // false for dry runs
+	Checksum string         `json:"checksum"`
+	Summary  map[string]int `json:"summary"` // action → count
+	Changes  []ConfigChange `json:"changes"`
+}
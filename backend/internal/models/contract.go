@@ -0,0 +1,124 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ── Labour Contracts ─────────────────────────────────────────
+
+// Probation and notice limits of the UAE Labour Law (Decree-Law 33/2021,
+// Articles 9 and 43).
+const (
+	MaxProbationMonths    = 6
+	MinNoticePeriodDays   = 30
+	MaxNoticePeriodDays   = 90
+	DefaultNoticePeriod   = 30
+	ContractTypeLimited   = "limited"
+	ContractTypeUnlimited = "unlimited"
+)
+
+// Contract is an employee's labour contract (migration 032). At most one
+// contract per employee is current; earlier ones are kept as superseded.
+type Contract struct {
+	ID               string  `json:"id"`
+	EmployeeID       string  `json:"employeeId"`
+	CompanyID        string  `json:"companyId"`
+	ContractType     string  `json:"contractType"` // "limited" | "unlimited"
+	ContractNumber   *string `json:"contractNumber"`
+	StartDate        string  `json:"startDate"`
+	EndDate          *string `json:"endDate"` // limited contracts only
+	ProbationEndDate *string `json:"probationEndDate"`
+	NoticePeriodDays int     `json:"noticePeriodDays"`
+	Status           string  `json:"status"` // "current" | "superseded"
+	SupersededBy     *string `json:"supersededBy"`
+	FileURL          string  `json:"fileUrl"`
+	FileName         string  `json:"fileName"`
+	FileSize         int64   `json:"fileSize"`
+	FileType         string  `json:"fileType"`
+	Notes            string  `json:"notes"`
+	CreatedBy        *string `json:"createdBy"`
+	CreatedAt        string  `json:"createdAt"`
+	UpdatedAt        string  `json:"updatedAt"`
+	CompanyName      string  `json:"companyName"`
+
+	// Relative to the company's local date; nil when not applicable
+	DaysUntilEnd       *int `json:"daysUntilEnd"`
+	DaysUntilProbation *int `json:"daysUntilProbationEnd"`
+	OnProbation        bool `json:"onProbation"`
+}
+
+// ContractRequest creates a contract, or corrects one (PUT) with the same
+// fields.
+type ContractRequest struct {
+	ContractType     string `json:"contractType"`
+	ContractNumber   string `json:"contractNumber"`
+	StartDate        string `json:"startDate"`
+	EndDate          string `json:"endDate"`
+	ProbationEndDate string `json:"probationEndDate"`
+	NoticePeriodDays *int   `json:"noticePeriodDays"` // default 30
+	FileURL          string `json:"fileUrl"`
+	FileName         string `json:"fileName"`
+	FileSize         int64  `json:"fileSize"`
+	FileType         string `json:"fileType"`
+	Notes            string `json:"notes"`
+}
+
+// Validate checks the contract type, dates and notice period. A limited
+// contract needs an end date after the start; an unlimited one has none.
+// Probation may not exceed six months from the start.
+func (r *ContractRequest) Validate() map[string]string {
+	errors := map[string]string{}
+	r.ContractType = strings.ToLower(strings.TrimSpace(r.ContractType))
+	r.ContractNumber = strings.TrimSpace(r.ContractNumber)
+	r.EndDate = strings.TrimSpace(r.EndDate)
+	r.ProbationEndDate = strings.TrimSpace(r.ProbationEndDate)
+	r.Notes = strings.TrimSpace(r.Notes)
+
+	if r.ContractType != ContractTypeLimited && r.ContractType != ContractTypeUnlimited {
+		errors["contractType"] = "Contract type must be limited or unlimited"
+	}
+	if len(r.ContractNumber) > 100 {
+		errors["contractNumber"] = "Contract number must be at most 100 characters"
+	}
+
+	start, err := time.Parse("2006-01-02", r.StartDate)
+	if err != nil {
+		errors["startDate"] = "Start date must be in YYYY-MM-DD format"
+	}
+
+	switch {
+	case r.ContractType == ContractTypeUnlimited && r.EndDate != "":
+		errors["endDate"] = "Unlimited contracts have no end date"
+	case r.ContractType == ContractTypeLimited && r.EndDate == "":
+		errors["endDate"] = "End date is required for a limited contract"
+	case r.EndDate != "":
+		if end, err2 := time.Parse("2006-01-02", r.EndDate); err2 != nil {
+			errors["endDate"] = "End date must be in YYYY-MM-DD format"
+		} else if err == nil && !end.After(start) {
+			errors["endDate"] = "End date must be after the start date"
+		}
+	}
+
+	if r.ProbationEndDate != "" {
+		if p, err2 := time.Parse("2006-01-02", r.ProbationEndDate); err2 != nil {
+			errors["probationEndDate"] = "Probation end date must be in YYYY-MM-DD format"
+		} else if err == nil && p.Before(start) {
+			errors["probationEndDate"] = "Probation cannot end before the contract starts"
+		} else if err == nil && p.After(start.AddDate(0, MaxProbationMonths, 0)) {
+			errors["probationEndDate"] = "Probation cannot exceed 6 months"
+		}
+	}
+
+	if r.NoticePeriodDays == nil {
+		n := DefaultNoticePeriod
+		r.NoticePeriodDays = &n
+	} else if *r.NoticePeriodDays < MinNoticePeriodDays || *r.NoticePeriodDays > MaxNoticePeriodDays {
+		errors["noticePeriodDays"] = "Notice period must be between 30 and 90 days"
+	}
+
+	if len(r.Notes) > 4000 {
+		errors["notes"] = "Notes must be at most 4000 characters"
+	}
+	return errors
+}
@@ -0,0 +1,346 @@
+package models
+
+import (
+	"fmt"
+	"math/big"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ── Custom Employee Fields ───────────────────────────────────
+
+// CustomFieldDefinition is an admin-defined employee field for one company
+// (migration 029). Values are stored in employees.custom_fields by Key.
+type CustomFieldDefinition struct {
+	ID        string              `json:"id"`
+	CompanyID string              `json:"companyId"`
+	Key       string              `json:"key"`
+	Label     string              `json:"label"`
+	Type      string              `json:"type"` // see ValidCustomFieldTypes
+	Required  bool                `json:"required"`
+	Options   []CustomFieldOption `json:"options"`  // select only
+	Pattern   string              `json:"pattern"`  // optional regex for text-like values
+	MinValue  *float64            `json:"minValue"` // number: value, text: length
+	MaxValue  *float64            `json:"maxValue"`
+	HelpText  string              `json:"helpText"`
+	SortOrder int                 `json:"sortOrder"`
+	IsActive  bool                `json:"isActive"`
+	CreatedAt string              `json:"createdAt"`
+	UpdatedAt string              `json:"updatedAt"`
+}
+
+// CustomFieldOption is one choice of a select field.
+type CustomFieldOption struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+}
+
+// ValidCustomFieldTypes are the supported custom field types.
+var ValidCustomFieldTypes = []string{"text", "number", "date", "select", "boolean", "email", "phone", "iban"}
+
+var (
+	customFieldKeyRe = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+	phoneRe          = regexp.MustCompile(`^\+?[0-9 ()\-]+$`)
+	ibanRe           = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+)
+
+// CustomFieldDefinitionRequest creates or updates a definition. Key and
+// CompanyID are fixed once created.
+type CustomFieldDefinitionRequest struct {
+	CompanyID string              `json:"companyId"`
+	Key       string              `json:"key"`
+	Label     string              `json:"label"`
+	Type      string              `json:"type"`
+	Required  bool                `json:"required"`
+	Options   []CustomFieldOption `json:"options"`
+	Pattern   string              `json:"pattern"`
+	MinValue  *float64            `json:"minValue"`
+	MaxValue  *float64            `json:"maxValue"`
+	HelpText  string              `json:"helpText"`
+	SortOrder *int                `json:"sortOrder"`
+	IsActive  *bool               `json:"isActive"`
+}
+
+// Validate checks the key, type, options, pattern and bounds.
+func (r *CustomFieldDefinitionRequest) Validate() map[string]string {
+	errors := map[string]string{}
+	r.Key = strings.TrimSpace(r.Key)
+	r.Label = strings.TrimSpace(r.Label)
+	r.Type = strings.ToLower(strings.TrimSpace(r.Type))
+	r.HelpText = strings.TrimSpace(r.HelpText)
+
+	if r.CompanyID == "" {
+		errors["companyId"] = "Company is required"
+	}
+	if !customFieldKeyRe.MatchString(r.Key) {
+		errors["key"] = "Key must be 2-50 characters: lowercase letters, digits and underscores, starting with a letter"
+	}
+	if r.Label == "" || len(r.Label) > 100 {
+		errors["label"] = "Label must be between 1 and 100 characters"
+	}
+
+	validType := false
+	for _, t := range ValidCustomFieldTypes {
+		if r.Type == t {
+			validType = true
+		}
+	}
+	if !validType {
+		errors["type"] = "Type must be one of: " + strings.Join(ValidCustomFieldTypes, ", ")
+	}
+
+	if r.Type == "select" {
+		if len(r.Options) == 0 {
+			errors["options"] = "Select fields need at least one option"
+		}
+		seen := map[string]bool{}
+		for i, o := range r.Options {
+			o.Value = strings.TrimSpace(o.Value)
+			o.Label = strings.TrimSpace(o.Label)
+			if o.Label == "" {
+				o.Label = o.Value
+			}
+			r.Options[i] = o
+			if o.Value == "" || seen[o.Value] {
+				errors["options"] = "Option values must be non-empty and unique"
+			}
+			seen[o.Value] = true
+		}
+	} else {
+		r.Options = []CustomFieldOption{}
+	}
+
+	if r.Pattern != "" {
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			errors["pattern"] = "Pattern is not a valid regular expression"
+		}
+	}
+	if r.MinValue != nil && r.MaxValue != nil && *r.MinValue > *r.MaxValue {
+		errors["maxValue"] = "Maximum must be greater than or equal to minimum"
+	}
+	return errors
+}
+
+// NormalizeValue validates a submitted value against the definition and
+// returns it in its stored form: string for text-like types and dates,
+// float64 for numbers, bool for booleans. An empty value returns nil.
+func (d *CustomFieldDefinition) NormalizeValue(v interface{}) (interface{}, string) {
+	if v == nil {
+		return nil, ""
+	}
+
+	switch d.Type {
+	case "number":
+		var n float64
+		switch x := v.(type) {
+		case float64:
+			n = x
+		case string:
+			if strings.TrimSpace(x) == "" {
+				return nil, ""
+			}
+			f, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+			if err != nil {
+				return nil, d.Label + " must be a number"
+			}
+			n = f
+		default:
+			return nil, d.Label + " must be a number"
+		}
+		if d.MinValue != nil && n < *d.MinValue {
+			return nil, fmt.Sprintf("%s must be at least %g", d.Label, *d.MinValue)
+		}
+		if d.MaxValue != nil && n > *d.MaxValue {
+			return nil, fmt.Sprintf("%s must be at most %g", d.Label, *d.MaxValue)
+		}
+		return n, ""
+
+	case "boolean":
+		switch x := v.(type) {
+		case bool:
+			return x, ""
+		case string:
+			if strings.TrimSpace(x) == "" {
+				return nil, ""
+			}
+			b, err := strconv.ParseBool(strings.TrimSpace(x))
+			if err != nil {
+				return nil, d.Label + " must be true or false"
+			}
+			return b, ""
+		}
+		return nil, d.Label + " must be true or false"
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return nil, d.Label + " must be text"
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, ""
+	}
+
+	switch d.Type {
+	case "date":
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			return nil, d.Label + " must be a date in YYYY-MM-DD format"
+		}
+		return s, ""
+	case "select":
+		for _, o := range d.Options {
+			if o.Value == s {
+				return s, ""
+			}
+		}
+		return nil, d.Label + " must be one of the listed options"
+	case "email":
+		addr, err := mail.ParseAddress(s)
+		if err != nil || addr.Address != s {
+			return nil, d.Label + " must be a valid email address"
+		}
+	case "phone":
+		digits := 0
+		for _, c := range s {
+			if c >= '0' && c <= '9' {
+				digits++
+			}
+		}
+		if !phoneRe.MatchString(s) || digits < 7 || digits > 15 {
+			return nil, d.Label + " must be a valid phone number"
+		}
+	case "iban":
+		s = strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+		if !ibanRe.MatchString(s) || !ibanChecksumValid(s) {
+			return nil, d.Label + " must be a valid IBAN"
+		}
+	}
+
+	if d.Type == "text" {
+		n := float64(utf8.RuneCountInString(s))
+		if d.MinValue != nil && n < *d.MinValue {
+			return nil, fmt.Sprintf("%s must be at least %g characters", d.Label, *d.MinValue)
+		}
+		if d.MaxValue != nil && n > *d.MaxValue {
+			return nil, fmt.Sprintf("%s must be at most %g characters", d.Label, *d.MaxValue)
+		}
+	}
+	if d.Pattern != "" {
+		if re, err := regexp.Compile(d.Pattern); err == nil && !re.MatchString(s) {
+			return nil, d.Label + " has an invalid format"
+		}
+	}
+	return s, ""
+}
+
+// ibanChecksumValid applies the ISO 13616 mod-97 check.
+func ibanChecksumValid(iban string) bool {
+	rearranged := iban[4:] + iban[:4]
+	var digits strings.Builder
+	for _, c := range rearranged {
+		if c >= 'A' && c <= 'Z' {
+			digits.WriteString(strconv.Itoa(int(c-'A') + 10))
+		} else {
+			digits.WriteRune(c)
+		}
+	}
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	return ok && new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+// ValidateCustomFields applies submitted values on top of current ones
+// using the company's active definitions. A nil or empty value clears the
+// field. With requireAll, every required field must end up with a value;
+// otherwise only fields being cleared are checked. Returns the merged values
+// and errors keyed "customFields.<key>".
+func ValidateCustomFields(defs []CustomFieldDefinition, values, current map[string]interface{}, requireAll bool) (map[string]interface{}, map[string]string) {
+	errors := map[string]string{}
+	merged := map[string]interface{}{}
+	for k, v := range current {
+		merged[k] = v
+	}
+
+	byKey := map[string]*CustomFieldDefinition{}
+	for i := range defs {
+		byKey[defs[i].Key] = &defs[i]
+	}
+
+	for k, v := range values {
+		def, ok := byKey[k]
+		if !ok {
+			errors["customFields."+k] = "Unknown field for this company"
+			continue
+		}
+		norm, msg := def.NormalizeValue(v)
+		if msg != "" {
+			errors["customFields."+k] = msg
+			continue
+		}
+		if norm == nil {
+			if def.Required {
+				errors["customFields."+k] = def.Label + " is required"
+			}
+			delete(merged, k)
+			continue
+		}
+		merged[k] = norm
+	}
+
+	if requireAll {
+		for _, def := range defs {
+			if _, ok := merged[def.Key]; def.Required && !ok {
+				if _, reported := errors["customFields."+def.Key]; !reported {
+					errors["customFields."+def.Key] = def.Label + " is required"
+				}
+			}
+		}
+	}
+	return merged, errors
+}
+
+// ValidateCustomFields checks CustomFields against the company's active
+// definitions and replaces them with their normalized form.
+func (r *CreateEmployeeRequest) ValidateCustomFields(defs []CustomFieldDefinition) map[string]string {
+	merged, errors := ValidateCustomFields(defs, r.CustomFields, nil, true)
+	r.CustomFields = merged
+	return errors
+}
+
+// ValidateCustomFields merges CustomFields into the employee's current values
+// and checks them against the (possibly new) company's active definitions;
+// a company change goes through MoveCustomFields. CustomFields is replaced
+// with the merged, normalized values.
+func (r *UpdateEmployeeRequest) ValidateCustomFields(defs []CustomFieldDefinition, current map[string]interface{}, companyChanged bool) map[string]string {
+	if companyChanged {
+		merged, errors := MoveCustomFields(defs, current, r.CustomFields)
+		r.CustomFields = merged
+		return errors
+	}
+	if r.CustomFields == nil {
+		return nil
+	}
+	merged, errors := ValidateCustomFields(defs, r.CustomFields, current, false)
+	r.CustomFields = merged
+	return errors
+}
+
+// MoveCustomFields revalidates an employee's values for the company they
+// move to: keys its definitions do not cover are dropped, the rest (with any
+// submitted values on top) are normalized again and its required fields must
+// be set.
+func MoveCustomFields(defs []CustomFieldDefinition, current, values map[string]interface{}) (map[string]interface{}, map[string]string) {
+	carried := map[string]interface{}{}
+	for _, def := range defs {
+		if v, ok := current[def.Key]; ok {
+			carried[def.Key] = v
+		}
+	}
+	for k, v := range values {
+		carried[k] = v
+	}
+	return ValidateCustomFields(defs, carried, nil, true)
+}
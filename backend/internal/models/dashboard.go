@@ -21,34 +21,42 @@ type Company struct {
 	EstablishmentCardNumber *string `json:"establishmentCardNumber,omitempty"`
 	MohreCategory           *string `json:"mohreCategory,omitempty"`       // "1", "2", "3"
 	RegulatoryAuthority     *string `json:"regulatoryAuthority,omitempty"` // "MOHRE", "JAFZA", etc.
+	Timezone                string  `json:"timezone"`                      // IANA name, e.g. "Asia/Dubai"
+	WeekendDays             []int   `json:"weekendDays"`                   // ISO weekdays, 1 = Monday … 7 = Sunday
 	CreatedAt               string  `json:"createdAt"`
 	UpdatedAt               string  `json:"updatedAt"`
 }
 
 // CompanySummary includes employee count per company.
 type CompanySummary struct {
-	ID            string `json:"id"`
-	Name          string `json:"name"`
-	Currency      string `json:"currency"`
-	EmployeeCount int    `json:"employeeCount"`
+	ID              string  `json:"id"`
+	Name            string  `json:"name"`
+	Currency        string  `json:"currency"`
+	EmployeeCount   int     `json:"employeeCount"`
+	ComplianceScore float64 `json:"complianceScore"` // 0–100 over active employees, see compliance.Score
 }
 
 // ── Expiry Alerts ────────────────────────────────────────────────
 
-// ExpiryAlert represents a document nearing/past expiry.
+// ExpiryAlert represents a document nearing/past expiry, or the current
+// labour contract (migration 032) nearing its end or probation end.
 type ExpiryAlert struct {
-	DocumentID         string  `json:"documentId"`
+	Kind               string  `json:"kind"`                 // "document" | "contract_end" | "probation_end"
+	DocumentID         string  `json:"documentId"`           // empty for contract alerts
+	ContractID         *string `json:"contractId"`
 	EmployeeID         string  `json:"employeeId"`
 	EmployeeName       string  `json:"employeeName"`
 	CompanyName        string  `json:"companyName"`
 	DocumentType       string  `json:"documentType"`
 	ExpiryDate         string  `json:"expiryDate"`
 	DaysLeft           int     `json:"daysLeft"`
-	Status             string  `json:"status"`               // "expiring_soon", "in_grace", "penalty_active"
+	Status             string  `json:"status"`               // "expiring_soon", "in_grace", "penalty_active"; "expired" for a contract past its end
 	EstimatedFine      float64 `json:"estimatedFine"`        // current fine for this document
 	FinePerDay         float64 `json:"finePerDay"`           // daily rate
 	GraceDaysRemaining *int    `json:"graceDaysRemaining"`   // only set when status == "in_grace"
 	DaysInPenalty      *int    `json:"daysInPenalty"`         // only set when status == "penalty_active"
+	DependentID        *string `json:"dependentId"`           // set for a sponsored dependent's document
+	DependentName      *string `json:"dependentName"`
 }
 
 // ── Compliance Stats (new dashboard) ─────────────────────────────
@@ -74,6 +82,7 @@ type CompanyCompliance struct {
 	IncompleteCount  int     `json:"incompleteCount"`
 	DailyExposure    float64 `json:"dailyExposure"` // sum of fine_per_day for active penalties
 	AccumulatedFines float64 `json:"accumulatedFines"`
+	ComplianceScore  float64 `json:"complianceScore"` // 0–100, see compliance.Score
 }
 
 // ── Dependency Alerts ────────────────────────────────────────────
@@ -81,10 +90,11 @@ type CompanyCompliance struct {
 // DependencyAlert warns when a blocking document's expiry threatens
 // the renewal of a dependent document.
 type DependencyAlert struct {
-	Severity       string `json:"severity"`    // "critical" | "warning"
-	BlockingDoc    string `json:"blockingDoc"` // e.g. "passport"
-	BlockedDoc     string `json:"blockedDoc"`  // e.g. "visa"
-	Message        string `json:"message"`
-	BlockingExpiry string `json:"blockingExpiry"`
-	BlockedExpiry  string `json:"blockedExpiry"`
+	Severity        string `json:"severity"`    // "critical" | "warning"
+	BlockingDoc     string `json:"blockingDoc"` // e.g. "passport"
+	BlockedDoc      string `json:"blockedDoc"`  // e.g. "visa"
+	Message         string `json:"message"`
+	BlockingExpiry  string `json:"blockingExpiry"`
+	BlockedExpiry   string `json:"blockedExpiry"`
+	MinValidityDays int    `json:"minValidityDays"`
 }
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ── Dependents ───────────────────────────────────────────────
+
+// Dependent is a family member sponsored by an employee (migration 031).
+// Its documents are stored with the sponsor's employee ID and count toward
+// the sponsor's compliance.
+type Dependent struct {
+	ID             string  `json:"id"`
+	EmployeeID     string  `json:"employeeId"`
+	Name           string  `json:"name"`
+	Relationship   string  `json:"relationship"` // see ValidDependentRelationships
+	Gender         *string `json:"gender"`
+	Nationality    *string `json:"nationality"`
+	DateOfBirth    *string `json:"dateOfBirth"`
+	PassportNumber *string `json:"passportNumber"`
+	Status         string  `json:"status"` // "active" | "cancelled"
+	CancelledOn    *string `json:"cancelledOn"`
+	Notes          string  `json:"notes"`
+	CreatedBy      *string `json:"createdBy"`
+	CreatedAt      string  `json:"createdAt"`
+	UpdatedAt      string  `json:"updatedAt"`
+
+	// Rollup of the dependent's required documents (document_status)
+	ComplianceStatus string  `json:"complianceStatus"` // worst status: "penalty_active" | "in_grace" | "expiring_soon" | "incomplete" | "valid" | "none"
+	DocsComplete     int     `json:"docsComplete"`
+	DocsTotal        int     `json:"docsTotal"`
+	EstimatedFine    float64 `json:"estimatedFine"`
+}
+
+// ValidDependentRelationships are the supported relationships to the sponsor.
+var ValidDependentRelationships = []string{"spouse", "child", "parent", "other"}
+
+// DependentRequest creates a dependent, or updates one when fields are nil.
+// Setting Status to "cancelled" records CancelledOn (default today).
+type DependentRequest struct {
+	Name           *string `json:"name"`
+	Relationship   *string `json:"relationship"`
+	Gender         *string `json:"gender"`
+	Nationality    *string `json:"nationality"`
+	DateOfBirth    *string `json:"dateOfBirth"`
+	PassportNumber *string `json:"passportNumber"`
+	Status         *string `json:"status"`
+	CancelledOn    *string `json:"cancelledOn"`
+	Notes          *string `json:"notes"`
+}
+
+// Validate checks the fields; create requires name and relationship.
+func (r *DependentRequest) Validate(create bool) map[string]string {
+	errors := map[string]string{}
+	trim := func(p *string) {
+		if p != nil {
+			*p = strings.TrimSpace(*p)
+		}
+	}
+	trim(r.Name)
+	trim(r.Gender)
+	trim(r.Nationality)
+	trim(r.PassportNumber)
+	trim(r.Notes)
+
+	if r.Name != nil {
+		if *r.Name == "" || len(*r.Name) > 255 {
+			errors["name"] = "Name must be between 1 and 255 characters"
+		}
+	} else if create {
+		errors["name"] = "Name is required"
+	}
+
+	if r.Relationship != nil {
+		rel := strings.ToLower(strings.TrimSpace(*r.Relationship))
+		r.Relationship = &rel
+		valid := false
+		for _, v := range ValidDependentRelationships {
+			valid = valid || rel == v
+		}
+		if !valid {
+			errors["relationship"] = "Relationship must be one of: " + strings.Join(ValidDependentRelationships, ", ")
+		}
+	} else if create {
+		errors["relationship"] = "Relationship is required"
+	}
+
+	if r.Gender != nil && *r.Gender != "" {
+		g := strings.ToLower(*r.Gender)
+		r.Gender = &g
+		if g != "male" && g != "female" {
+			errors["gender"] = "Gender must be male or female"
+		}
+	}
+	if r.DateOfBirth != nil && *r.DateOfBirth != "" {
+		if dob, err := time.Parse("2006-01-02", *r.DateOfBirth); err != nil {
+			errors["dateOfBirth"] = "Date of birth must be in YYYY-MM-DD format"
+		} else if dob.After(time.Now()) {
+			errors["dateOfBirth"] = "Date of birth cannot be in the future"
+		}
+	}
+	if r.PassportNumber != nil && len(*r.PassportNumber) > 50 {
+		errors["passportNumber"] = "Passport number must be at most 50 characters"
+	}
+	if r.Status != nil {
+		s := strings.ToLower(strings.TrimSpace(*r.Status))
+		r.Status = &s
+		if s != "active" && s != "cancelled" {
+			errors["status"] = "Status must be active or cancelled"
+		}
+	}
+	if r.CancelledOn != nil && *r.CancelledOn != "" {
+		if _, err := time.Parse("2006-01-02", *r.CancelledOn); err != nil {
+			errors["cancelledOn"] = "Cancellation date must be in YYYY-MM-DD format"
+		}
+	}
+	return errors
+}
@@ -11,6 +11,7 @@ import (
 type Document struct {
 	ID             string          `json:"id"`
 	EmployeeID     string          `json:"employeeId"`
+	DependentID    *string         `json:"dependentId"` // set for a sponsored dependent's document (migration 031)
 	DocumentType   string          `json:"documentType"`
 	DocumentNumber *string         `json:"documentNumber"` // e.g. visa UID, EID number
 	IssueDate      *string         `json:"issueDate"`      // when the document was issued
@@ -55,6 +56,7 @@ type DocumentWithEmployee struct {
 
 // CreateDocumentRequest holds the fields for creating a new document.
 type CreateDocumentRequest struct {
+	DependentID    *string         `json:"dependentId,omitempty"` // the employee's dependent this document belongs to
 	DocumentType   string          `json:"documentType"`
 	DocumentNumber *string         `json:"documentNumber,omitempty"`
 	IssueDate      *string         `json:"issueDate,omitempty"`
@@ -92,3 +94,26 @@ func (r *CreateDocumentRequest) Validate() map[string]string {
 
 	return errors
 }
+
+// ValidityCheck compares a document's dates with the standard validity of
+// its type. Returned by create/update/renew when a standard term applies.
+type ValidityCheck struct {
+	ValidityPeriod int    `json:"validityPeriod"`
+	ValidityUnit   string `json:"validityUnit"`
+	ExpectedExpiry string `json:"expectedExpiry"` // issue date + standard term
+	Derived        bool   `json:"derived"`        // expiry was missing and set to ExpectedExpiry
+	DeviationDays  int    `json:"deviationDays"`  // entered expiry minus expected
+	Warning        string `json:"warning,omitempty"`
+}
+
+// DependencyViolation describes a blocking document that does not meet the
+// minimum validity required to renew or update a dependent document.
+type DependencyViolation struct {
+	BlockingDocType string  `json:"blockingDocType"`
+	BlockedDocType  string  `json:"blockedDocType"`
+	BlockingDocID   string  `json:"blockingDocId"`
+	BlockingExpiry  *string `json:"blockingExpiry"`
+	DaysRemaining   *int    `json:"daysRemaining"`
+	MinValidityDays int     `json:"minValidityDays"`
+	Message         string  `json:"message"`
+}
@@ -0,0 +1,93 @@
+package models
+
+import "strings"
+
+// ── Linked Expiry ────────────────────────────────────────────
+
+// DocumentLink ties a target document type's dates to the renewal of a
+// source type (migration 021). Company rows replace global rows (CompanyID
+// nil) for the same source/target pair.
+type DocumentLink struct {
+	ID             string  `json:"id"`
+	CompanyID      *string `json:"companyId"`
+	SourceDocType  string  `json:"sourceDocType"`
+	TargetDocType  string  `json:"targetDocType"`
+	Mode           string  `json:"mode"`           // "source_expiry" | "from_issue"
+	OffsetDays     int     `json:"offsetDays"`     // source_expiry only
+	ValidityMonths int     `json:"validityMonths"` // from_issue only
+	ValidityDays   int     `json:"validityDays"`   // from_issue only
+	Action         string  `json:"action"`         // "propose" | "auto"
+	IsActive       bool    `json:"isActive"`
+	Description    string  `json:"description"`
+	CreatedAt      string  `json:"createdAt"`
+	UpdatedAt      string  `json:"updatedAt"`
+}
+
+// DocumentLinkRequest is used to create or replace a link.
+type DocumentLinkRequest struct {
+	CompanyID      *string `json:"companyId"`
+	SourceDocType  string  `json:"sourceDocType"`
+	TargetDocType  string  `json:"targetDocType"`
+	Mode           string  `json:"mode"`
+	OffsetDays     int     `json:"offsetDays"`
+	ValidityMonths int     `json:"validityMonths"`
+	ValidityDays   int     `json:"validityDays"`
+	Action         string  `json:"action"`
+	IsActive       *bool   `json:"isActive"` // default true
+	Description    string  `json:"description"`
+}
+
+// Validate checks doc types, mode parameters and action.
+func (r *DocumentLinkRequest) Validate() map[string]string {
+	errors := map[string]string{}
+	r.SourceDocType = strings.TrimSpace(r.SourceDocType)
+	r.TargetDocType = strings.TrimSpace(r.TargetDocType)
+	r.Mode = strings.ToLower(strings.TrimSpace(r.Mode))
+	r.Action = strings.ToLower(strings.TrimSpace(r.Action))
+	r.Description = strings.TrimSpace(r.Description)
+	if r.CompanyID != nil && strings.TrimSpace(*r.CompanyID) == "" {
+		r.CompanyID = nil
+	}
+	if r.Action == "" {
+		r.Action = "propose"
+	}
+
+	if r.SourceDocType == "" {
+		errors["sourceDocType"] = "Source document type is required"
+	}
+	if r.TargetDocType == "" {
+		errors["targetDocType"] = "Target document type is required"
+	} else if r.TargetDocType == r.SourceDocType {
+		errors["targetDocType"] = "A document type cannot be linked to itself"
+	}
+	switch r.Mode {
+	case "source_expiry":
+		if r.OffsetDays < -365 || r.OffsetDays > 365 {
+			errors["offsetDays"] = "Offset must be between -365 and 365 days"
+		}
+	case "from_issue":
+		if r.ValidityMonths < 0 || r.ValidityDays < 0 {
+			errors["validityMonths"] = "Validity cannot be negative"
+		} else if r.ValidityMonths == 0 && r.ValidityDays == 0 {
+			errors["validityMonths"] = "Validity is required for links from issue date"
+		}
+	default:
+		errors["mode"] = "Mode must be source_expiry or from_issue"
+	}
+	if r.Action != "propose" && r.Action != "auto" {
+		errors["action"] = "Action must be propose or auto"
+	}
+	return errors
+}
+
+// LinkedRenewal reports what a renewal did to one linked document.
+type LinkedRenewal struct {
+	LinkID        string  `json:"linkId"`
+	DocumentID    string  `json:"documentId"` // the linked document before renewal
+	DocumentType  string  `json:"documentType"`
+	CurrentExpiry *string `json:"currentExpiry"`
+	IssueDate     string  `json:"issueDate"`  // proposed/applied
+	ExpiryDate    string  `json:"expiryDate"` // proposed/applied
+	Result        string  `json:"result"`     // "proposed" | "renewed" | "up_to_date"
+	NewDocumentID *string `json:"newDocumentId,omitempty"`
+}
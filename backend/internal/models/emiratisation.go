@@ -0,0 +1,112 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ── Emiratisation Quota ──────────────────────────────────────
+
+// EmiratisationTarget is the UAE-national share required at one checkpoint
+// (migration 033). Company rows replace the global row (CompanyID nil) for
+// the same checkpoint date.
+type EmiratisationTarget struct {
+	ID             string  `json:"id"`
+	CompanyID      *string `json:"companyId"`
+	CheckpointDate string  `json:"checkpointDate"`
+	TargetPercent  float64 `json:"targetPercent"`  // of the workforce, rounded down
+	MinHeadcount   int     `json:"minHeadcount"`   // required regardless of the percentage
+	MinWorkforce   int     `json:"minWorkforce"`   // smaller companies are exempt
+	FinePerMissing float64 `json:"finePerMissing"` // AED per missing UAE national
+	Description    string  `json:"description"`
+	CreatedAt      string  `json:"createdAt"`
+	UpdatedAt      string  `json:"updatedAt"`
+}
+
+// EmiratisationTargetRequest is used to create or replace a target.
+type EmiratisationTargetRequest struct {
+	CompanyID      *string `json:"companyId"`
+	CheckpointDate string  `json:"checkpointDate"`
+	TargetPercent  float64 `json:"targetPercent"`
+	MinHeadcount   int     `json:"minHeadcount"`
+	MinWorkforce   *int    `json:"minWorkforce"` // default 50
+	FinePerMissing float64 `json:"finePerMissing"`
+	Description    string  `json:"description"`
+}
+
+// Validate checks the checkpoint date, percentage and counts.
+func (r *EmiratisationTargetRequest) Validate() map[string]string {
+	errors := map[string]string{}
+	r.Description = strings.TrimSpace(r.Description)
+	if r.CompanyID != nil && strings.TrimSpace(*r.CompanyID) == "" {
+		r.CompanyID = nil
+	}
+
+	if _, err := time.Parse("2006-01-02", r.CheckpointDate); err != nil {
+		errors["checkpointDate"] = "Checkpoint date must be in YYYY-MM-DD format"
+	}
+	if r.TargetPercent < 0 || r.TargetPercent >= 100 {
+		errors["targetPercent"] = "Target percent must be at least 0 and below 100"
+	}
+	if r.MinHeadcount < 0 {
+		errors["minHeadcount"] = "Minimum headcount cannot be negative"
+	}
+	if r.TargetPercent == 0 && r.MinHeadcount == 0 {
+		errors["targetPercent"] = "Set a target percent or a minimum headcount"
+	}
+	if r.MinWorkforce == nil {
+		n := 50
+		r.MinWorkforce = &n
+	} else if *r.MinWorkforce < 1 {
+		errors["minWorkforce"] = "Minimum workforce must be at least 1"
+	}
+	if r.FinePerMissing < 0 {
+		errors["finePerMissing"] = "Fine cannot be negative"
+	}
+	return errors
+}
+
+// EmiratisationCheckpoint is a company's projected position at one upcoming
+// checkpoint, assuming today's headcount.
+type EmiratisationCheckpoint struct {
+	TargetID        string  `json:"targetId"`
+	CheckpointDate  string  `json:"checkpointDate"`
+	Description     string  `json:"description"`
+	CompanySpecific bool    `json:"companySpecific"`
+	TargetPercent   float64 `json:"targetPercent"`
+	MinHeadcount    int     `json:"minHeadcount"`
+	MinWorkforce    int     `json:"minWorkforce"`
+	FinePerMissing  float64 `json:"finePerMissing"`
+	Status          string  `json:"status"` // "exempt" | "met" | "shortfall"
+	Required        int     `json:"required"`
+	Missing         int     `json:"missing"`
+	HiresNeeded     int     `json:"hiresNeeded"` // each hire also grows the workforce
+	ProjectedFine   float64 `json:"projectedFine"`
+	DaysUntil       int     `json:"daysUntil"`
+}
+
+// EmiratisationCompany is one company's UAE-national headcount against its
+// targets.
+type EmiratisationCompany struct {
+	CompanyID           string                    `json:"companyId"`
+	CompanyName         string                    `json:"companyName"`
+	RegulatoryAuthority string                    `json:"regulatoryAuthority"`
+	Subject             bool                      `json:"subject"` // MOHRE company
+	Workforce           int                       `json:"workforce"`
+	Nationals           int                       `json:"nationals"`
+	NationalsPercent    float64                   `json:"nationalsPercent"`
+	Status              string                    `json:"status"` // of the next checkpoint; "none" when none is configured
+	NextCheckpoint      *EmiratisationCheckpoint  `json:"nextCheckpoint"`
+	Checkpoints         []EmiratisationCheckpoint `json:"checkpoints"`
+}
+
+// EmiratisationSummary totals the next checkpoint across companies.
+type EmiratisationSummary struct {
+	Companies      int     `json:"companies"`
+	Subject        int     `json:"subject"`   // not exempt at their next checkpoint
+	Shortfall      int     `json:"shortfall"` // companies short of their next target
+	Missing        int     `json:"missing"`
+	HiresNeeded    int     `json:"hiresNeeded"`
+	ProjectedFine  float64 `json:"projectedFine"`
+	NextCheckpoint *string `json:"nextCheckpoint"`
+}
@@ -4,26 +4,27 @@ import "time"
 
 // Employee represents an employee record in the database.
 type Employee struct {
-	ID              string    `json:"id"`
-	CompanyID       string    `json:"companyId"`
-	Name            string    `json:"name"`
-	Trade           string    `json:"trade"`
-	Mobile          string    `json:"mobile"`
-	JoiningDate     string    `json:"joiningDate"`
-	PhotoURL        *string   `json:"photoUrl"`
-	Gender          *string   `json:"gender,omitempty"`
-	DateOfBirth     *string   `json:"dateOfBirth,omitempty"`
-	Nationality     *string   `json:"nationality,omitempty"`
-	PassportNumber  *string   `json:"passportNumber,omitempty"`
-	NativeLocation  *string   `json:"nativeLocation,omitempty"`
-	CurrentLocation *string   `json:"currentLocation,omitempty"`
-	Salary          *float64  `json:"salary,omitempty"`
-	Status          string    `json:"status"`             // active, inactive, on_leave, terminated, resigned
-	ExitType        *string   `json:"exitType,omitempty"` // resigned, terminated, absconded
-	ExitDate        *string   `json:"exitDate,omitempty"`
-	ExitNotes       *string   `json:"exitNotes,omitempty"`
-	CreatedAt       time.Time `json:"createdAt"`
-	UpdatedAt       time.Time `json:"updatedAt"`
+	ID              string                 `json:"id"`
+	CompanyID       string                 `json:"companyId"`
+	Name            string                 `json:"name"`
+	Trade           string                 `json:"trade"`
+	Mobile          string                 `json:"mobile"`
+	JoiningDate     string                 `json:"joiningDate"`
+	PhotoURL        *string                `json:"photoUrl"`
+	Gender          *string                `json:"gender,omitempty"`
+	DateOfBirth     *string                `json:"dateOfBirth,omitempty"`
+	Nationality     *string                `json:"nationality,omitempty"`
+	PassportNumber  *string                `json:"passportNumber,omitempty"`
+	NativeLocation  *string                `json:"nativeLocation,omitempty"`
+	CurrentLocation *string                `json:"currentLocation,omitempty"`
+	Salary          *float64               `json:"salary,omitempty"`
+	Status          string                 `json:"status"`             // active, inactive, on_leave, terminated, resigned
+	ExitType        *string                `json:"exitType,omitempty"` // resigned, terminated, absconded
+	ExitDate        *string                `json:"exitDate,omitempty"`
+	ExitNotes       *string                `json:"exitNotes,omitempty"`
+	CustomFields    map[string]interface{} `json:"customFields"` // values of the company's custom fields, by key
+	CreatedAt       time.Time              `json:"createdAt"`
+	UpdatedAt       time.Time              `json:"updatedAt"`
 }
 
 // EmployeeWithCompany includes the company name alongside employee data.
@@ -39,24 +40,26 @@ type EmployeeWithCompany struct {
 	UrgentDocType     *string `json:"urgentDocType,omitempty"`     // type of the most urgent doc
 	ExpiredCount      int     `json:"expiredCount"`                // how many mandatory docs are expired
 	ExpiringCount     int     `json:"expiringCount"`               // how many mandatory docs are expiring soon (<=30d)
+	ComplianceScore   float64 `json:"complianceScore"`             // 0–100, see compliance.Score
 }
 
 // CreateEmployeeRequest holds the fields needed to create an employee.
 type CreateEmployeeRequest struct {
-	CompanyID       string   `json:"companyId"`
-	Name            string   `json:"name"`
-	Trade           string   `json:"trade"`
-	Mobile          string   `json:"mobile"`
-	JoiningDate     string   `json:"joiningDate"`
-	PhotoURL        string   `json:"photoUrl,omitempty"`
-	Gender          *string  `json:"gender,omitempty"`
-	DateOfBirth     *string  `json:"dateOfBirth,omitempty"`
-	Nationality     *string  `json:"nationality,omitempty"`
-	PassportNumber  *string  `json:"passportNumber,omitempty"`
-	NativeLocation  *string  `json:"nativeLocation,omitempty"`
-	CurrentLocation *string  `json:"currentLocation,omitempty"`
-	Salary          *float64 `json:"salary,omitempty"`
-	Status          string   `json:"status,omitempty"`
+	CompanyID       string                 `json:"companyId"`
+	Name            string                 `json:"name"`
+	Trade           string                 `json:"trade"`
+	Mobile          string                 `json:"mobile"`
+	JoiningDate     string                 `json:"joiningDate"`
+	PhotoURL        string                 `json:"photoUrl,omitempty"`
+	Gender          *string                `json:"gender,omitempty"`
+	DateOfBirth     *string                `json:"dateOfBirth,omitempty"`
+	Nationality     *string                `json:"nationality,omitempty"`
+	PassportNumber  *string                `json:"passportNumber,omitempty"`
+	NativeLocation  *string                `json:"nativeLocation,omitempty"`
+	CurrentLocation *string                `json:"currentLocation,omitempty"`
+	Salary          *float64               `json:"salary,omitempty"`
+	Status          string                 `json:"status,omitempty"`
+	CustomFields    map[string]interface{} `json:"customFields,omitempty"`
 }
 
 // UpdateEmployeeRequest holds the fields that can be updated.
@@ -75,6 +78,8 @@ type UpdateEmployeeRequest struct {
 	CurrentLocation *string  `json:"currentLocation,omitempty"`
 	Salary          *float64 `json:"salary,omitempty"`
 	Status          *string  `json:"status,omitempty"`
+	// CustomFields are merged into the current values; null clears a field.
+	CustomFields map[string]interface{} `json:"customFields,omitempty"`
 }
 
 // ExitEmployeeRequest is used when recording an employee exit.
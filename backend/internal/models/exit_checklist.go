@@ -0,0 +1,154 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ── Exit Checklist ───────────────────────────────────────────
+
+// ExitChecklistItems lists the checklist items in display order.
+var ExitChecklistItems = []string{"cancel_work_permit", "cancel_visa", "final_salary", "gratuity", "company_assets"}
+
+// ExitChecklistRule sets the deadline and fine for one checklist item.
+// Company rows replace the global row (CompanyID nil) for the same item.
+type ExitChecklistRule struct {
+	ID          string   `json:"id"`
+	CompanyID   *string  `json:"companyId"`
+	Item        string   `json:"item"`
+	DueDays     int      `json:"dueDays"` // days after the exit date
+	FinePerDay  float64  `json:"finePerDay"`
+	FineType    string   `json:"fineType"` // "daily" | "monthly" | "one_time"
+	FineCap     float64  `json:"fineCap"`
+	DocTypes    []string `json:"docTypes"` // documents the item cancels
+	Description string   `json:"description"`
+	CreatedAt   string   `json:"createdAt"`
+	UpdatedAt   string   `json:"updatedAt"`
+}
+
+// ExitChecklistRuleRequest creates or replaces the rule for a scope and item.
+type ExitChecklistRuleRequest struct {
+	CompanyID   *string  `json:"companyId"`
+	Item        string   `json:"item"`
+	DueDays     int      `json:"dueDays"`
+	FinePerDay  float64  `json:"finePerDay"`
+	FineType    string   `json:"fineType"`
+	FineCap     float64  `json:"fineCap"`
+	DocTypes    []string `json:"docTypes"`
+	Description string   `json:"description"`
+}
+
+// Validate checks the item, deadline and fine settings.
+func (r *ExitChecklistRuleRequest) Validate() map[string]string {
+	errors := map[string]string{}
+	r.Item = strings.ToLower(strings.TrimSpace(r.Item))
+	r.FineType = strings.ToLower(strings.TrimSpace(r.FineType))
+	r.Description = strings.TrimSpace(r.Description)
+	if r.CompanyID != nil && strings.TrimSpace(*r.CompanyID) == "" {
+		r.CompanyID = nil
+	}
+	if r.FineType == "" {
+		r.FineType = "daily"
+	}
+	docTypes := []string{}
+	for _, t := range r.DocTypes {
+		if t = strings.TrimSpace(t); t != "" {
+			docTypes = append(docTypes, t)
+		}
+	}
+	r.DocTypes = docTypes
+
+	if !validExitChecklistItem(r.Item) {
+		errors["item"] = "Item must be one of: " + strings.Join(ExitChecklistItems, ", ")
+	}
+	if r.DueDays < 0 || r.DueDays > 365 {
+		errors["dueDays"] = "Due days must be between 0 and 365"
+	}
+	if r.FinePerDay < 0 {
+		errors["finePerDay"] = "Fine cannot be negative"
+	}
+	if r.FineCap < 0 {
+		errors["fineCap"] = "Fine cap cannot be negative"
+	}
+	switch r.FineType {
+	case "daily", "monthly", "one_time":
+	default:
+		errors["fineType"] = "Fine type must be daily, monthly or one_time"
+	}
+	return errors
+}
+
+// ExitChecklistItem is one task an exited employee's company must complete.
+type ExitChecklistItem struct {
+	ID           string   `json:"id"`
+	EmployeeID   string   `json:"employeeId"`
+	CompanyID    string   `json:"companyId"`
+	Item         string   `json:"item"`
+	DocTypes     []string `json:"docTypes"`
+	DueDate      string   `json:"dueDate"` // last day without a fine
+	FinePerDay   float64  `json:"finePerDay"`
+	FineType     string   `json:"fineType"`
+	FineCap      float64  `json:"fineCap"`
+	Status       string   `json:"status"` // "pending" | "done" | "waived"
+	CompletedOn  *string  `json:"completedOn"`
+	CompletedBy  *string  `json:"completedBy"`
+	Notes        string   `json:"notes"`
+	CreatedAt    string   `json:"createdAt"`
+	UpdatedAt    string   `json:"updatedAt"`
+	EmployeeName string   `json:"employeeName"`
+	CompanyName  string   `json:"companyName"`
+
+	// Computed
+	Compliance    string  `json:"compliance"` // valid | expiring_soon | penalty_active
+	DaysRemaining int     `json:"daysRemaining"`
+	Overdue       bool    `json:"overdue"`
+	FineExposure  float64 `json:"fineExposure"` // fine accrued so far, or until completion
+}
+
+// UpdateExitChecklistItemRequest marks an item done or waived, or reopens it.
+type UpdateExitChecklistItemRequest struct {
+	Status      *string `json:"status"`
+	CompletedOn *string `json:"completedOn"` // defaults to today when marking done
+	Notes       *string `json:"notes"`
+}
+
+// Validate checks the status and completion date.
+func (r *UpdateExitChecklistItemRequest) Validate() map[string]string {
+	errors := map[string]string{}
+	if r.Status == nil && r.CompletedOn == nil && r.Notes == nil {
+		errors["status"] = "Status, completion date or notes is required"
+	}
+	if r.Status != nil {
+		status := strings.ToLower(strings.TrimSpace(*r.Status))
+		r.Status = &status
+		switch status {
+		case "pending", "done", "waived":
+		default:
+			errors["status"] = "Status must be pending, done or waived"
+		}
+	}
+	if r.CompletedOn != nil {
+		if strings.TrimSpace(*r.CompletedOn) == "" {
+			r.CompletedOn = nil
+		} else if _, err := time.Parse("2006-01-02", *r.CompletedOn); err != nil {
+			errors["completedOn"] = "Completion date must be in YYYY-MM-DD format"
+		}
+	}
+	if r.Notes != nil {
+		notes := strings.TrimSpace(*r.Notes)
+		r.Notes = &notes
+		if len(notes) > 4000 {
+			errors["notes"] = "Notes must be at most 4000 characters"
+		}
+	}
+	return errors
+}
+
+func validExitChecklistItem(item string) bool {
+	for _, i := range ExitChecklistItems {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ── Fine Settlement Ledger ───────────────────────────────────────
+
+// FineSettlement is a fine actually paid for one document's penalty period.
+// EstimatedAmount is what the compliance engine had accrued for the same
+// period when the payment was recorded.
+type FineSettlement struct {
+	ID              string  `json:"id"`
+	DocumentID      *string `json:"documentId"` // nil once the document is deleted
+	EmployeeID      *string `json:"employeeId"`
+	CompanyID       string  `json:"companyId"`
+	DocumentType    string  `json:"documentType"`
+	PenaltyFrom     string  `json:"penaltyFrom"`
+	PenaltyTo       string  `json:"penaltyTo"`
+	Amount          float64 `json:"amount"`
+	EstimatedAmount float64 `json:"estimatedAmount"`
+	PaidOn          string  `json:"paidOn"`
+	Reference       string  `json:"reference"`
+	Notes           string  `json:"notes"`
+	ReceiptURL      *string `json:"receiptUrl,omitempty"`
+	ReceiptName     *string `json:"receiptName,omitempty"`
+	CreatedBy       *string `json:"createdBy,omitempty"`
+	CreatedAt       string  `json:"createdAt"`
+}
+
+// FineSettlementWithDetails adds display names for the ledger list.
+type FineSettlementWithDetails struct {
+	FineSettlement
+	EmployeeName *string `json:"employeeName"`
+	CompanyName  string  `json:"companyName"`
+}
+
+// FineSettlementRequest is the form for recording a paid fine. The penalty
+// period defaults to the day after the last settlement (or the first penalty
+// day) through PaidOn.
+type FineSettlementRequest struct {
+	DocumentID  string  `json:"documentId"`
+	Amount      float64 `json:"amount"`
+	PaidOn      string  `json:"paidOn"`
+	PenaltyFrom *string `json:"penaltyFrom"`
+	PenaltyTo   *string `json:"penaltyTo"`
+	Reference   string  `json:"reference"`
+	Notes       string  `json:"notes"`
+}
+
+// Validate checks required fields and the penalty period.
+func (r *FineSettlementRequest) Validate() map[string]string {
+	errors := map[string]string{}
+	if strings.TrimSpace(r.DocumentID) == "" {
+		errors["documentId"] = "Document ID is required"
+	}
+	if r.Amount <= 0 {
+		errors["amount"] = "Amount must be greater than 0"
+	}
+	if len(r.Reference) > 100 {
+		errors["reference"] = "Reference must be at most 100 characters"
+	}
+
+	paidOn, err := time.Parse("2006-01-02", r.PaidOn)
+	if err != nil {
+		errors["paidOn"] = "Payment date must be in YYYY-MM-DD format"
+	}
+	var from, to time.Time
+	if r.PenaltyFrom != nil && *r.PenaltyFrom != "" {
+		if from, err = time.Parse("2006-01-02", *r.PenaltyFrom); err != nil {
+			errors["penaltyFrom"] = "Date must be in YYYY-MM-DD format"
+		}
+	}
+	if r.PenaltyTo != nil && *r.PenaltyTo != "" {
+		if to, err = time.Parse("2006-01-02", *r.PenaltyTo); err != nil {
+			errors["penaltyTo"] = "Date must be in YYYY-MM-DD format"
+		} else if _, ok := errors["paidOn"]; !ok && to.After(paidOn) {
+			errors["penaltyTo"] = "A fine cannot be settled beyond the payment date"
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		errors["penaltyTo"] = "Penalty end must be on or after penalty start"
+	}
+	return errors
+}
+
+// ── Reconciliation ───────────────────────────────────────────────
+
+// FineReconciliationMonth compares estimated and paid fines for one company
+// in one month (by payment date). Variance is paid minus estimated.
+type FineReconciliationMonth struct {
+	CompanyID   string  `json:"companyId"`
+	CompanyName string  `json:"companyName"`
+	Month       string  `json:"month"` // YYYY-MM
+	Settlements int     `json:"settlements"`
+	Estimated   float64 `json:"estimated"`
+	Paid        float64 `json:"paid"`
+	Variance    float64 `json:"variance"`
+}
+
+// FineReconciliationCompany totals one company over the report range.
+// Outstanding is the current unsettled exposure, regardless of range.
+type FineReconciliationCompany struct {
+	CompanyID   string  `json:"companyId"`
+	CompanyName string  `json:"companyName"`
+	Settlements int     `json:"settlements"`
+	Estimated   float64 `json:"estimated"`
+	Paid        float64 `json:"paid"`
+	Variance    float64 `json:"variance"`
+	Outstanding float64 `json:"outstanding"`
+}
+
+// FineReconciliation is the response of the reconciliation report.
+type FineReconciliation struct {
+	From      string                      `json:"from"` // YYYY-MM
+	To        string                      `json:"to"`
+	Months    []FineReconciliationMonth   `json:"months"`
+	ByCompany []FineReconciliationCompany `json:"byCompany"`
+	Total     FineReconciliationCompany   `json:"total"`
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ── Fine Forecast ────────────────────────────────────────────────
+
+// MaxForecastDates caps how many dates a single forecast evaluates.
+const MaxForecastDates = 12
+
+// FineForecastRequest is the body for POST /api/dashboard/fine-forecast.
+// Dates default to today, the 1st of next month and the current quarter end.
+type FineForecastRequest struct {
+	Dates     []string              `json:"dates"`
+	CompanyID *string               `json:"companyId"`
+	Renewals  []HypotheticalRenewal `json:"renewals"`
+}
+
+// HypotheticalRenewal assumes a document is renewed on RenewBy; its fine
+// stops accruing from that date.
+type HypotheticalRenewal struct {
+	DocumentID string `json:"documentId"`
+	RenewBy    string `json:"renewBy"` // YYYY-MM-DD
+}
+
+// Validate checks date formats, limits and renewal entries.
+func (r *FineForecastRequest) Validate() map[string]string {
+	errors := map[string]string{}
+	if r.CompanyID != nil && strings.TrimSpace(*r.CompanyID) == "" {
+		r.CompanyID = nil
+	}
+	if len(r.Dates) > MaxForecastDates {
+		errors["dates"] = fmt.Sprintf("At most %d dates can be forecast at once", MaxForecastDates)
+	}
+	for i, d := range r.Dates {
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			errors[fmt.Sprintf("dates[%d]", i)] = "Date must be in YYYY-MM-DD format"
+		}
+	}
+	seen := map[string]bool{}
+	for i, rn := range r.Renewals {
+		key := fmt.Sprintf("renewals[%d]", i)
+		if strings.TrimSpace(rn.DocumentID) == "" {
+			errors[key+".documentId"] = "Document ID is required"
+		} else if seen[rn.DocumentID] {
+			errors[key+".documentId"] = "Document is listed more than once"
+		}
+		seen[rn.DocumentID] = true
+		if _, err := time.Parse("2006-01-02", rn.RenewBy); err != nil {
+			errors[key+".renewBy"] = "Renewal date must be in YYYY-MM-DD format"
+		}
+	}
+	return errors
+}
+
+// ForecastPoint is the fine exposure at one forecast date. Exposure assumes
+// nothing is renewed; ScenarioExposure applies the hypothetical renewals.
+type ForecastPoint struct {
+	Date               string  `json:"date"`
+	Exposure           float64 `json:"exposure"`
+	ScenarioExposure   float64 `json:"scenarioExposure"`
+	Savings            float64 `json:"savings"`
+	DocumentsInPenalty int     `json:"documentsInPenalty"`
+}
+
+// ForecastBreakdown is a forecast series for one company, document type or employee.
+type ForecastBreakdown struct {
+	Key         string          `json:"key"` // company ID, doc type or employee ID
+	Label       string          `json:"label"`
+	CompanyName string          `json:"companyName,omitempty"` // employee breakdown only
+	Points      []ForecastPoint `json:"points"`
+}
+
+// FineForecast is the response of the fine forecast endpoint.
+type FineForecast struct {
+	Dates          []string            `json:"dates"`
+	Totals         []ForecastPoint     `json:"totals"`
+	ByCompany      []ForecastBreakdown `json:"byCompany"`
+	ByDocumentType []ForecastBreakdown `json:"byDocumentType"`
+	ByEmployee     []ForecastBreakdown `json:"byEmployee"` // only employees with exposure at some date
+	Renewals       int                 `json:"renewals"`   // hypothetical renewals applied
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ── Leave ────────────────────────────────────────────────────
+
+// LeaveType is a kind of leave with its yearly allowance (migration 030).
+type LeaveType struct {
+	Code              string   `json:"code"`
+	Name              string   `json:"name"`
+	IsPaid            bool     `json:"isPaid"`
+	Accrues           bool     `json:"accrues"`    // annual leave: earned monthly
+	AnnualDays        *float64 `json:"annualDays"` // nil = no limit (or accruing)
+	OncePerEmployment bool     `json:"oncePerEmployment"`
+	MaxCarryOver      float64  `json:"maxCarryOver"`
+	SortOrder         int      `json:"sortOrder"`
+}
+
+// LeaveRequest is a request for leave and its approval.
+type LeaveRequest struct {
+	ID             string  `json:"id"`
+	EmployeeID     string  `json:"employeeId"`
+	CompanyID      string  `json:"companyId"`
+	LeaveType      string  `json:"leaveType"`
+	StartDate      string  `json:"startDate"`
+	EndDate        string  `json:"endDate"`
+	Days           int     `json:"days"`
+	Status         string  `json:"status"` // "pending" | "approved" | "rejected" | "cancelled"
+	Reason         string  `json:"reason"`
+	DecisionNotes  string  `json:"decisionNotes"`
+	OnLeaveApplied bool    `json:"onLeaveApplied"` // the employee is on_leave because of this request
+	RequestedBy    *string `json:"requestedBy"`
+	DecidedBy      *string `json:"decidedBy"`
+	DecidedAt      *string `json:"decidedAt"`
+	CreatedAt      string  `json:"createdAt"`
+	UpdatedAt      string  `json:"updatedAt"`
+	LeaveTypeName  string  `json:"leaveTypeName"`
+	IsPaid         bool    `json:"isPaid"`
+	EmployeeName   string  `json:"employeeName"`
+	CompanyName    string  `json:"companyName"`
+}
+
+// LeaveBalance is an employee's position for one leave type. For annual
+// leave, Entitlement is the carried-in plus accrued days of the current
+// year and Years holds the account per calendar year.
+type LeaveBalance struct {
+	LeaveType   string      `json:"leaveType"`
+	Name        string      `json:"name"`
+	IsPaid      bool        `json:"isPaid"`
+	Entitlement *float64    `json:"entitlement"` // nil = no limit
+	Taken       float64     `json:"taken"`       // approved, this year (whole employment for once-only types)
+	Pending     float64     `json:"pending"`     // awaiting approval
+	Available   *float64    `json:"available"`   // Entitlement - Taken - Pending; nil = no limit
+	Years       []LeaveYear `json:"years,omitempty"`
+}
+
+// LeaveYear is the annual leave account for one calendar year.
+type LeaveYear struct {
+	Year       int     `json:"year"`
+	CarriedIn  float64 `json:"carriedIn"`
+	Accrued    float64 `json:"accrued"`
+	Taken      float64 `json:"taken"`
+	Balance    float64 `json:"balance"`
+	CarriedOut float64 `json:"carriedOut"` // closed years only
+	Forfeited  float64 `json:"forfeited"`  // closed years only
+}
+
+// CreateLeaveRequest asks for leave for one employee.
+type CreateLeaveRequest struct {
+	EmployeeID string `json:"employeeId"`
+	LeaveType  string `json:"leaveType"`
+	StartDate  string `json:"startDate"`
+	EndDate    string `json:"endDate"`
+	Reason     string `json:"reason"`
+}
+
+// Validate checks the required fields and the date range, and returns the
+// number of calendar days requested.
+func (r *CreateLeaveRequest) Validate() (int, map[string]string) {
+	errors := map[string]string{}
+	r.LeaveType = strings.ToLower(strings.TrimSpace(r.LeaveType))
+	r.Reason = strings.TrimSpace(r.Reason)
+
+	if r.EmployeeID == "" {
+		errors["employeeId"] = "Employee is required"
+	}
+	if r.LeaveType == "" {
+		errors["leaveType"] = "Leave type is required"
+	}
+	start, err := time.Parse("2006-01-02", r.StartDate)
+	if err != nil {
+		errors["startDate"] = "Start date must be in YYYY-MM-DD format"
+	}
+	end, err2 := time.Parse("2006-01-02", r.EndDate)
+	if err2 != nil {
+		errors["endDate"] = "End date must be in YYYY-MM-DD format"
+	}
+	days := 0
+	if err == nil && err2 == nil {
+		if end.Before(start) {
+			errors["endDate"] = "End date must be on or after the start date"
+		} else if days = int(end.Sub(start).Hours()/24) + 1; days > 366 {
+			errors["endDate"] = "Leave cannot be longer than a year"
+		}
+	}
+	if len(r.Reason) > 4000 {
+		errors["reason"] = "Reason must be at most 4000 characters"
+	}
+	return days, errors
+}
+
+// LeaveDecisionRequest approves, rejects or cancels a leave request.
+type LeaveDecisionRequest struct {
+	Notes string `json:"notes"`
+}
@@ -0,0 +1,123 @@
+package models
+
+import "strings"
+
+// ── Renewal Cases ────────────────────────────────────────────
+
+// RenewalCase tracks one document's renewal in progress (migration 023).
+type RenewalCase struct {
+	ID                string  `json:"id"`
+	DocumentID        string  `json:"documentId"`
+	EmployeeID        string  `json:"employeeId"`
+	CompanyID         string  `json:"companyId"`
+	DocumentType      string  `json:"documentType"`
+	Status            string  `json:"status"` // "open" | "completed" | "cancelled"
+	Stage             string  `json:"stage"`  // requested → medical_done → biometrics_done → submitted → approved → completed
+	NextStage         *string `json:"nextStage"`
+	AssigneeID        *string `json:"assigneeId"`
+	ExpiryDate        *string `json:"expiryDate"` // document expiry when the case was opened
+	GraceDays         int     `json:"graceDays"`
+	Deadline          string  `json:"deadline"` // last day without a fine
+	StageEnteredOn    string  `json:"stageEnteredOn"`
+	StageDue          *string `json:"stageDue"` // due date of NextStage
+	Overdue           bool    `json:"overdue"`  // computed: open and past StageDue
+	EscalatedAt       *string `json:"escalatedAt"`
+	RenewedDocumentID *string `json:"renewedDocumentId"`
+	CreatedBy         *string `json:"createdBy"`
+	CreatedAt         string  `json:"createdAt"`
+	UpdatedAt         string  `json:"updatedAt"`
+	ClosedAt          *string `json:"closedAt"`
+}
+
+// RenewalCaseWithDetails adds display names for lists.
+type RenewalCaseWithDetails struct {
+	RenewalCase
+	EmployeeName string  `json:"employeeName"`
+	CompanyName  string  `json:"companyName"`
+	AssigneeName *string `json:"assigneeName"`
+}
+
+// RenewalCaseEvent is a comment or a change in a case's history.
+type RenewalCaseEvent struct {
+	ID        string  `json:"id"`
+	CaseID    string  `json:"caseId"`
+	Kind      string  `json:"kind"` // "comment" | "stage" | "assignment" | "escalation" | "closed"
+	Stage     *string `json:"stage"`
+	Body      string  `json:"body"`
+	UserID    *string `json:"userId"`
+	UserName  *string `json:"userName"`
+	CreatedAt string  `json:"createdAt"`
+}
+
+// RenewalCaseStages lists the stages in order.
+var RenewalCaseStages = []string{"requested", "medical_done", "biometrics_done", "submitted", "approved", "completed"}
+
+// OpenRenewalCaseRequest opens a case for a document.
+type OpenRenewalCaseRequest struct {
+	AssigneeID *string `json:"assigneeId"`
+	Comment    string  `json:"comment"`
+}
+
+// Validate normalises the optional fields.
+func (r *OpenRenewalCaseRequest) Validate() map[string]string {
+	r.Comment = strings.TrimSpace(r.Comment)
+	if r.AssigneeID != nil && strings.TrimSpace(*r.AssigneeID) == "" {
+		r.AssigneeID = nil
+	}
+	return map[string]string{}
+}
+
+// UpdateRenewalCaseRequest moves a case to a later stage and/or reassigns
+// it. AssigneeID "" unassigns. The completed stage is only reached through
+// the document renewal.
+type UpdateRenewalCaseRequest struct {
+	Stage      *string `json:"stage"`
+	AssigneeID *string `json:"assigneeId"`
+	Comment    string  `json:"comment"`
+}
+
+// Validate checks the stage and that something changes.
+func (r *UpdateRenewalCaseRequest) Validate() map[string]string {
+	errors := map[string]string{}
+	r.Comment = strings.TrimSpace(r.Comment)
+	if r.AssigneeID != nil {
+		id := strings.TrimSpace(*r.AssigneeID)
+		r.AssigneeID = &id
+	}
+	if r.Stage == nil && r.AssigneeID == nil {
+		errors["stage"] = "Stage or assignee is required"
+	}
+	if r.Stage != nil {
+		stage := strings.ToLower(strings.TrimSpace(*r.Stage))
+		r.Stage = &stage
+		valid := false
+		for _, s := range RenewalCaseStages {
+			valid = valid || s == stage
+		}
+		switch {
+		case !valid:
+			errors["stage"] = "Stage must be one of " + strings.Join(RenewalCaseStages, ", ")
+		case stage == "completed":
+			errors["stage"] = "Cases are completed by renewing the document"
+		}
+	}
+	return errors
+}
+
+// RenewalCaseCommentRequest adds a comment to a case; also used to cancel
+// a case with a reason.
+type RenewalCaseCommentRequest struct {
+	Body string `json:"body"`
+}
+
+// Validate checks the body is present.
+func (r *RenewalCaseCommentRequest) Validate() map[string]string {
+	errors := map[string]string{}
+	r.Body = strings.TrimSpace(r.Body)
+	if r.Body == "" {
+		errors["body"] = "Comment is required"
+	} else if len(r.Body) > 4000 {
+		errors["body"] = "Comment must be at most 4000 characters"
+	}
+	return errors
+}
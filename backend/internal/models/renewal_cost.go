@@ -0,0 +1,114 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ── Renewal Cost Catalog ─────────────────────────────────────
+
+// RenewalCostComponents are the cost lines a renewal can carry.
+var RenewalCostComponents = []string{"typing", "government", "medical", "insurance", "other"}
+
+// RenewalCost is one cost line for renewing a document type. Company rows
+// replace global rows (CompanyID nil) for the same doc type and component.
+type RenewalCost struct {
+	ID            string  `json:"id"`
+	CompanyID     *string `json:"companyId"`
+	DocType       string  `json:"docType"`
+	Component     string  `json:"component"` // "typing" | "government" | "medical" | "insurance" | "other"
+	Amount        float64 `json:"amount"`    // AED
+	EffectiveFrom string  `json:"effectiveFrom"`
+	EffectiveTo   *string `json:"effectiveTo"` // inclusive; nil = open-ended
+	Description   string  `json:"description"`
+	CreatedAt     string  `json:"createdAt"`
+	UpdatedAt     string  `json:"updatedAt"`
+}
+
+// RenewalCostRequest is used to create or replace a cost line.
+type RenewalCostRequest struct {
+	CompanyID     *string `json:"companyId"`
+	DocType       string  `json:"docType"`
+	Component     string  `json:"component"`
+	Amount        float64 `json:"amount"`
+	EffectiveFrom string  `json:"effectiveFrom"`
+	EffectiveTo   *string `json:"effectiveTo"`
+	Description   string  `json:"description"`
+}
+
+// Validate checks the component, amount and effective date range.
+func (r *RenewalCostRequest) Validate() map[string]string {
+	errors := map[string]string{}
+	r.DocType = strings.TrimSpace(r.DocType)
+	r.Component = strings.ToLower(strings.TrimSpace(r.Component))
+	r.Description = strings.TrimSpace(r.Description)
+	if r.CompanyID != nil && strings.TrimSpace(*r.CompanyID) == "" {
+		r.CompanyID = nil
+	}
+	if r.EffectiveTo != nil && strings.TrimSpace(*r.EffectiveTo) == "" {
+		r.EffectiveTo = nil
+	}
+
+	if r.DocType == "" {
+		errors["docType"] = "Document type is required"
+	}
+	validComponent := false
+	for _, c := range RenewalCostComponents {
+		if r.Component == c {
+			validComponent = true
+		}
+	}
+	if !validComponent {
+		errors["component"] = "Component must be one of: " + strings.Join(RenewalCostComponents, ", ")
+	}
+	if r.Amount < 0 {
+		errors["amount"] = "Amount cannot be negative"
+	}
+	from, err := time.Parse("2006-01-02", r.EffectiveFrom)
+	if err != nil {
+		errors["effectiveFrom"] = "Effective from must be in YYYY-MM-DD format"
+	}
+	if r.EffectiveTo != nil {
+		to, err := time.Parse("2006-01-02", *r.EffectiveTo)
+		if err != nil {
+			errors["effectiveTo"] = "Effective to must be in YYYY-MM-DD format"
+		} else if errors["effectiveFrom"] == "" && to.Before(from) {
+			errors["effectiveTo"] = "Effective to cannot be before effective from"
+		}
+	}
+	return errors
+}
+
+// ── Renewal Budget ───────────────────────────────────────────
+
+// BudgetMonth is the projected renewal spend for one month.
+type BudgetMonth struct {
+	Month       string             `json:"month"` // YYYY-MM
+	Renewals    int                `json:"renewals"`
+	Amount      float64            `json:"amount"`
+	ByComponent map[string]float64 `json:"byComponent,omitempty"`
+}
+
+// CompanyBudget is the month-by-month projection for one company.
+type CompanyBudget struct {
+	CompanyID   string        `json:"companyId"`
+	CompanyName string        `json:"companyName"`
+	Months      []BudgetMonth `json:"months"`
+	Total       float64       `json:"total"`
+}
+
+// UnpricedRenewals counts renewals with no cost line in the catalog.
+type UnpricedRenewals struct {
+	DocType  string `json:"docType"`
+	Renewals int    `json:"renewals"`
+}
+
+// RenewalBudget is the 12-month renewal projection.
+type RenewalBudget struct {
+	From      string             `json:"from"` // first month (YYYY-MM)
+	Months    []BudgetMonth      `json:"months"`
+	ByCompany []CompanyBudget    `json:"byCompany"`
+	Total     float64            `json:"total"`
+	Overdue   int                `json:"overdue"` // already-expired docs, budgeted in the first month
+	Unpriced  []UnpricedRenewals `json:"unpriced"`
+}
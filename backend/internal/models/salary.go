@@ -2,16 +2,17 @@ package models
 
 // SalaryRecord represents a single month's salary entry for an employee.
 type SalaryRecord struct {
-	ID         string  `json:"id"`
-	EmployeeID string  `json:"employeeId"`
-	Month      int     `json:"month"`
-	Year       int     `json:"year"`
-	Amount     float64 `json:"amount"`
-	Status     string  `json:"status"` // pending, paid, partial
-	PaidDate   *string `json:"paidDate,omitempty"`
-	Notes      *string `json:"notes,omitempty"`
-	CreatedAt  string  `json:"createdAt"`
-	UpdatedAt  string  `json:"updatedAt"`
+	ID              string  `json:"id"`
+	EmployeeID      string  `json:"employeeId"`
+	Month           int     `json:"month"`
+	Year            int     `json:"year"`
+	Amount          float64 `json:"amount"`
+	Status          string  `json:"status"` // pending, paid, partial, on_hold (absconding case)
+	PaidDate        *string `json:"paidDate,omitempty"`
+	Notes           *string `json:"notes,omitempty"`
+	UnpaidLeaveDays int     `json:"unpaidLeaveDays"` // deducted at salary / 30 per day
+	CreatedAt       string  `json:"createdAt"`
+	UpdatedAt       string  `json:"updatedAt"`
 }
 
 // SalaryRecordWithEmployee extends SalaryRecord with display fields.
@@ -29,6 +30,7 @@ type SalarySummary struct {
 	PendingCount int     `json:"pendingCount"`
 	PaidCount    int     `json:"paidCount"`
 	PartialCount int     `json:"partialCount"`
+	OnHoldCount  int     `json:"onHoldCount"`
 	TotalCount   int     `json:"totalCount"`
 	Currency     string  `json:"currency"` // "AED", "USD", or "Mixed"
 }
@@ -0,0 +1,21 @@
+package models
+
+// ── Search ───────────────────────────────────────────────────
+
+// SearchResult is one employee found by GET /api/search, with the field
+// that matched best. DocumentID/DocumentType are set when the match was on
+// a document number.
+type SearchResult struct {
+	EmployeeID   string  `json:"employeeId"`
+	EmployeeName string  `json:"employeeName"`
+	CompanyID    string  `json:"companyId"`
+	CompanyName  string  `json:"companyName"`
+	Trade        string  `json:"trade"`
+	Status       string  `json:"status"`
+	MatchedField string  `json:"matchedField"` // "name" | "trade" | "nationality" | "mobile" | "passport_number" | "document_number" | "mohre_file_number"
+	MatchedValue string  `json:"matchedValue"`
+	Highlight    string  `json:"highlight"` // MatchedValue (HTML-escaped) with matches wrapped in <mark>
+	DocumentID   *string `json:"documentId"`
+	DocumentType *string `json:"documentType"`
+	Rank         float64 `json:"rank"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// ── Employee Timeline ────────────────────────────────────────
+
+// TimelineEventTypes are the event types returned by the employee timeline.
+var TimelineEventTypes = []string{
+	"joined", "status_changed", "transferred",
+	"document_added", "document_renewed", "document_expired",
+	"salary_generated", "salary_paid",
+	"exited", "exit_item_completed",
+	"absconding_reported", "absconding_filed", "absconding_withdrawn",
+	"leave", "contract_started",
+	"notification",
+}
+
+// TimelineEvent is one entry in an employee's lifecycle feed.
+type TimelineEvent struct {
+	Type       string                 `json:"type"`
+	OccurredAt string                 `json:"occurredAt"`
+	Title      string                 `json:"title"`
+	EntityType string                 `json:"entityType"`
+	EntityID   *string                `json:"entityId"`
+	Details    map[string]interface{} `json:"details"`
+}
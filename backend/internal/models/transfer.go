@@ -0,0 +1,67 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ── Transfers & Sponsorship ──────────────────────────────────
+
+// EmployeeSponsorship is one period during which a company sponsored the
+// employee (migration 026). EndDate is nil for the current sponsor.
+type EmployeeSponsorship struct {
+	ID          string  `json:"id"`
+	EmployeeID  string  `json:"employeeId"`
+	CompanyID   *string `json:"companyId"`
+	CompanyName string  `json:"companyName"`
+	StartDate   string  `json:"startDate"`
+	EndDate     *string `json:"endDate"`
+	TransferID  *string `json:"transferId"`
+	CreatedAt   string  `json:"createdAt"`
+}
+
+// EmployeeTransfer records a move between group companies. A future-dated
+// transfer is "pending" until its effective date (migration 034).
+type EmployeeTransfer struct {
+	ID              string   `json:"id"`
+	EmployeeID      string   `json:"employeeId"`
+	FromCompanyID   *string  `json:"fromCompanyId"`
+	FromCompanyName string   `json:"fromCompanyName"`
+	ToCompanyID     *string  `json:"toCompanyId"`
+	ToCompanyName   string   `json:"toCompanyName"`
+	EffectiveDate   string   `json:"effectiveDate"`
+	Reason          string   `json:"reason"`
+	Status          string   `json:"status"` // pending, applied, cancelled
+	SlotsAdded      []string `json:"slotsAdded"`
+	SlotsRemoved    []string `json:"slotsRemoved"`
+	SalaryMoved     int      `json:"salaryMoved"` // open salary records moved to the new company
+	CreatedBy       *string  `json:"createdBy"`
+	CreatedAt       string   `json:"createdAt"`
+}
+
+// TransferEmployeeRequest moves an employee to another company.
+type TransferEmployeeRequest struct {
+	CompanyID     string `json:"companyId"`
+	EffectiveDate string `json:"effectiveDate"`
+	Reason        string `json:"reason"`
+	// CustomFields supplies values for the new company's custom fields;
+	// current values it does not define are dropped.
+	CustomFields map[string]interface{} `json:"customFields,omitempty"`
+}
+
+// Validate checks the target company and effective date.
+func (r *TransferEmployeeRequest) Validate() map[string]string {
+	errors := map[string]string{}
+	r.CompanyID = strings.TrimSpace(r.CompanyID)
+	r.Reason = strings.TrimSpace(r.Reason)
+	if r.CompanyID == "" {
+		errors["companyId"] = "Company is required"
+	}
+	if _, err := time.Parse("2006-01-02", r.EffectiveDate); err != nil {
+		errors["effectiveDate"] = "Effective date must be in YYYY-MM-DD format"
+	}
+	if len(r.Reason) > 4000 {
+		errors["reason"] = "Reason must be at most 4000 characters"
+	}
+	return errors
+}
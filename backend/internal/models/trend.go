@@ -0,0 +1,54 @@
+package models
+
+// ── Compliance Trends ────────────────────────────────────────────
+
+// Trend intervals accepted by the trend endpoint.
+const (
+	TrendDaily   = "daily"
+	TrendWeekly  = "weekly"
+	TrendMonthly = "monthly"
+)
+
+// MaxTrendPoints caps the number of periods in one trend response.
+const MaxTrendPoints = 366
+
+// TrendPoint is the compliance state at the end of one period: for each
+// company, the last snapshot recorded in the period. Companies is the number
+// of companies with a snapshot in the period (0 = no data yet).
+type TrendPoint struct {
+	Period              string   `json:"period"`    // first day, YYYY-MM-DD
+	PeriodEnd           string   `json:"periodEnd"` // last day, YYYY-MM-DD
+	Companies           int      `json:"companies"`
+	DocsValid           int      `json:"docsValid"`
+	DocsExpiringSoon    int      `json:"docsExpiringSoon"`
+	DocsInGrace         int      `json:"docsInGrace"`
+	DocsPenaltyActive   int      `json:"docsPenaltyActive"`
+	DocsIncomplete      int      `json:"docsIncomplete"`
+	EmployeesTotal      int      `json:"employeesTotal"`
+	EmployeesCompliant  int      `json:"employeesCompliant"`
+	EmployeesIncomplete int      `json:"employeesIncomplete"`
+	EmployeesPenalty    int      `json:"employeesPenalty"`
+	FineExposure        float64  `json:"fineExposure"`
+	ComplianceScore     *float64 `json:"complianceScore"` // nil when no company has data
+
+	// Summed score weights (compliance.Score) the score is derived from
+	ScoreLost float64 `json:"-"`
+	ScoreMax  float64 `json:"-"`
+}
+
+// CompanyTrend is the trend series for one company.
+type CompanyTrend struct {
+	CompanyID   string       `json:"companyId"`
+	CompanyName string       `json:"companyName"`
+	Points      []TrendPoint `json:"points"`
+}
+
+// ComplianceTrend is the response of the trend endpoint. Every series has
+// one point per period from From to To, in order.
+type ComplianceTrend struct {
+	Interval  string         `json:"interval"`
+	From      string         `json:"from"`
+	To        string         `json:"to"`
+	Totals    []TrendPoint   `json:"totals"`
+	ByCompany []CompanyTrend `json:"byCompany"`
+}
@@ -0,0 +1,78 @@
+-- Migration 011: Conditional mandatory documents
+-- Mandatory slots used to come only from document_types.is_mandatory (optionally
+-- overridden per company in compliance_rules). Real requirements depend on the
+-- worker, so conditions can now require or exempt a document type based on the
+-- employee's nationality, trade, gender and status.
+-- All changes are additive.
+
+-- ── 1. Conditions Table ──────────────────────────────────────────
+-- effect 'require' makes the doc type mandatory when the condition matches,
+-- effect 'exempt' removes it. Empty arrays match any value.
+-- Match values are stored lower-cased and trimmed.
+-- Company rows replace global rows (company_id IS NULL) for the same doc type,
+-- mirroring the compliance_rules override pattern.
+
+CREATE TABLE IF NOT EXISTS document_requirement_conditions (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    company_id    UUID REFERENCES companies(id) ON DELETE CASCADE,
+    doc_type      VARCHAR(100) NOT NULL,
+    effect        VARCHAR(10) NOT NULL CHECK (effect IN ('require', 'exempt')),
+    nationalities TEXT[] NOT NULL DEFAULT '{}',
+    trades        TEXT[] NOT NULL DEFAULT '{}',
+    genders       TEXT[] NOT NULL DEFAULT '{}',
+    statuses      TEXT[] NOT NULL DEFAULT '{}',
+    description   TEXT NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_doc_req_conditions_type
+    ON document_requirement_conditions(doc_type, company_id);
+
+-- ── 2. Resolver Function ─────────────────────────────────────────
+-- Single definition of "is this doc type mandatory for this employee",
+-- shared by slot seeding (Go) and the employee list/detail queries (SQL).
+-- Precedence: matching exempt > matching require > company rule > document type.
+
+CREATE OR REPLACE FUNCTION doc_required_for(
+    p_company_id  UUID,
+    p_doc_type    TEXT,
+    p_nationality TEXT,
+    p_trade       TEXT,
+    p_gender      TEXT,
+    p_status      TEXT
+) RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    WITH conds AS (
+        SELECT c.effect, c.nationalities, c.trades, c.genders, c.statuses
+        FROM document_requirement_conditions c
+        WHERE c.doc_type = p_doc_type
+          AND (c.company_id = p_company_id
+               OR (c.company_id IS NULL AND NOT EXISTS (
+                   SELECT 1 FROM document_requirement_conditions cc
+                   WHERE cc.doc_type = p_doc_type AND cc.company_id = p_company_id)))
+    ), matched AS (
+        SELECT effect FROM conds
+        WHERE (cardinality(nationalities) = 0 OR lower(btrim(COALESCE(p_nationality, ''))) = ANY(nationalities))
+          AND (cardinality(trades)        = 0 OR lower(btrim(COALESCE(p_trade, '')))       = ANY(trades))
+          AND (cardinality(genders)       = 0 OR lower(btrim(COALESCE(p_gender, '')))      = ANY(genders))
+          AND (cardinality(statuses)      = 0 OR lower(btrim(COALESCE(p_status, '')))      = ANY(statuses))
+    )
+    SELECT CASE
+        WHEN NOT EXISTS (SELECT 1 FROM document_types dt WHERE dt.doc_type = p_doc_type AND dt.is_active = TRUE) THEN FALSE
+        WHEN EXISTS (SELECT 1 FROM matched WHERE effect = 'exempt') THEN FALSE
+        WHEN EXISTS (SELECT 1 FROM matched WHERE effect = 'require') THEN TRUE
+        ELSE COALESCE(
+            (SELECT cr.is_mandatory FROM compliance_rules cr WHERE cr.doc_type = p_doc_type AND cr.company_id = p_company_id),
+            (SELECT dt.is_mandatory FROM document_types dt WHERE dt.doc_type = p_doc_type AND dt.is_active = TRUE),
+            FALSE)
+    END
+$$;
+
+-- ── 3. Seed Global Conditions (only if table is empty) ──────────
+
+INSERT INTO document_requirement_conditions (company_id, doc_type, effect, nationalities, description)
+SELECT NULL, a, b, c::text[], d FROM (VALUES
+    ('visa', 'exempt', '{emirati,uae,united arab emirates,saudi,saudi arabian,kuwaiti,bahraini,qatari,omani}', 'GCC nationals do not need a residence visa')
+) AS seed(a, b, c, d)
+WHERE NOT EXISTS (SELECT 1 FROM document_requirement_conditions LIMIT 1);
@@ -0,0 +1,30 @@
+-- Migration 012: Enforce document dependencies on renewal
+-- 1. Adds a minimum-validity threshold per dependency (e.g. passport needs 180 days
+--    left before a visa can be renewed).
+-- 2. Records admin overrides when a renewal/update goes ahead despite a
+--    blocking document failing its threshold.
+-- All changes are additive.
+
+-- ── 1. Minimum validity threshold ───────────────────────────────
+-- 0 = blocking document only needs to be unexpired.
+
+ALTER TABLE document_dependencies ADD COLUMN IF NOT EXISTS min_validity_days INT NOT NULL DEFAULT 0;
+
+UPDATE document_dependencies SET min_validity_days = 180
+WHERE blocking_doc_type = 'passport' AND blocked_doc_type = 'visa' AND min_validity_days = 0;
+
+-- ── 2. Override audit table ─────────────────────────────────────
+
+CREATE TABLE IF NOT EXISTS dependency_overrides (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    action      VARCHAR(20) NOT NULL, -- 'renew' | 'update'
+    violations  JSONB NOT NULL DEFAULT '[]',
+    reason      TEXT NOT NULL,
+    user_id     UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dependency_overrides_document ON dependency_overrides(document_id);
+CREATE INDEX IF NOT EXISTS idx_dependency_overrides_employee ON dependency_overrides(employee_id);
@@ -0,0 +1,65 @@
+-- Migration 013: Company- and authority-scoped document dependencies
+-- Free-zone companies (JAFZA, DMCC, DIFC, ...) have different prerequisite chains
+-- from mainland MOHRE companies. A dependency row can now belong to a company
+-- or to a regulatory authority; rows with neither stay global.
+-- All changes are additive.
+
+-- ── 1. Scope Columns ─────────────────────────────────────────────
+
+ALTER TABLE document_dependencies ADD COLUMN IF NOT EXISTS company_id UUID REFERENCES companies(id) ON DELETE CASCADE;
+ALTER TABLE document_dependencies ADD COLUMN IF NOT EXISTS regulatory_authority VARCHAR(50);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'document_dependencies_single_scope') THEN
+        ALTER TABLE document_dependencies ADD CONSTRAINT document_dependencies_single_scope
+            CHECK (company_id IS NULL OR regulatory_authority IS NULL);
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_document_dependencies_blocked
+    ON document_dependencies(blocked_doc_type, company_id, regulatory_authority);
+
+-- ── 2. Resolver Function ─────────────────────────────────────────
+-- Returns the dependencies in force for a company (or, with p_company_id NULL,
+-- for an authority or the global defaults).
+-- Like compliance_rules, the most specific scope wins, decided per blocked
+-- doc type: if the company has any rows for a blocked type they replace the
+-- authority's rows, which in turn replace the global rows.
+-- The authority comes from p_authority, else from the company.
+
+CREATE OR REPLACE FUNCTION effective_dependencies(p_company_id UUID, p_authority TEXT)
+RETURNS TABLE (
+    id                   UUID,
+    blocking_doc_type    VARCHAR,
+    blocked_doc_type     VARCHAR,
+    description          TEXT,
+    min_validity_days    INT,
+    company_id           UUID,
+    regulatory_authority VARCHAR,
+    scope                TEXT
+)
+LANGUAGE sql STABLE AS $$
+    WITH auth AS (
+        SELECT upper(COALESCE(
+            NULLIF(btrim(p_authority), ''),
+            (SELECT c.regulatory_authority FROM companies c WHERE c.id = p_company_id),
+            '')) AS code
+    ), ranked AS (
+        SELECT d.*,
+               CASE
+                   WHEN d.company_id IS NOT NULL THEN 1
+                   WHEN d.regulatory_authority IS NOT NULL THEN 2
+                   ELSE 3
+               END AS lvl
+        FROM document_dependencies d, auth
+        WHERE d.company_id = p_company_id
+           OR (d.company_id IS NULL AND upper(d.regulatory_authority) = auth.code)
+           OR (d.company_id IS NULL AND d.regulatory_authority IS NULL)
+    )
+    SELECT r.id, r.blocking_doc_type, r.blocked_doc_type, r.description,
+           r.min_validity_days, r.company_id, r.regulatory_authority,
+           CASE r.lvl WHEN 1 THEN 'company' WHEN 2 THEN 'authority' ELSE 'global' END
+    FROM ranked r
+    WHERE r.lvl = (SELECT min(r2.lvl) FROM ranked r2 WHERE r2.blocked_doc_type = r.blocked_doc_type)
+$$;
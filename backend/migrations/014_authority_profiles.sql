@@ -0,0 +1,126 @@
+-- Migration 014: Regulatory authority profiles
+-- companies.regulatory_authority used to be a label only. A profile bundles the
+-- document types an authority expects (mandatory flag, grace period, fine
+-- schedule) and its dependency chain. Applying a profile to a company writes
+-- company-level compliance_rules; profile dependencies are kept as
+-- authority-scoped document_dependencies rows (migration 013).
+-- Every edit bumps the version and keeps a snapshot in authority_profile_versions.
+-- All changes are additive.
+
+-- ── 1. Profiles ──────────────────────────────────────────────────
+-- rules:        [{"docType","isMandatory","gracePeriodDays","finePerDay","fineType","fineCap"}]
+-- dependencies: [{"blockingDocType","blockedDocType","description","minValidityDays"}]
+
+CREATE TABLE IF NOT EXISTS authority_profiles (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code         VARCHAR(50) NOT NULL UNIQUE, -- upper-case, matches companies.regulatory_authority
+    name         VARCHAR(200) NOT NULL,
+    description  TEXT NOT NULL DEFAULT '',
+    version      INT NOT NULL DEFAULT 1,
+    rules        JSONB NOT NULL DEFAULT '[]',
+    dependencies JSONB NOT NULL DEFAULT '[]',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS authority_profile_versions (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    profile_id   UUID NOT NULL REFERENCES authority_profiles(id) ON DELETE CASCADE,
+    version      INT NOT NULL,
+    name         VARCHAR(200) NOT NULL,
+    description  TEXT NOT NULL DEFAULT '',
+    rules        JSONB NOT NULL DEFAULT '[]',
+    dependencies JSONB NOT NULL DEFAULT '[]',
+    changed_by   UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (profile_id, version)
+);
+
+-- Which profile version was last applied to the company (NULL = never)
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS authority_profile_version INT;
+
+-- ── 2. Seed Profiles (only if table is empty) ───────────────────
+-- Defaults mirror the global rules; free zones issue their own employment
+-- cards, so the MOHRE work permit is not mandatory there and health
+-- insurance is a visa prerequisite instead.
+
+INSERT INTO authority_profiles (code, name, description, rules, dependencies)
+SELECT a, b, c, d::jsonb, e::jsonb FROM (VALUES
+    ('MOHRE', 'Ministry of Human Resources & Emiratisation', 'Mainland companies',
+     '[{"docType":"passport","isMandatory":true,"gracePeriodDays":0,"finePerDay":0,"fineType":"daily","fineCap":0},
+       {"docType":"visa","isMandatory":true,"gracePeriodDays":0,"finePerDay":50,"fineType":"daily","fineCap":0},
+       {"docType":"emirates_id","isMandatory":true,"gracePeriodDays":30,"finePerDay":20,"fineType":"daily","fineCap":1000},
+       {"docType":"work_permit","isMandatory":true,"gracePeriodDays":50,"finePerDay":500,"fineType":"one_time","fineCap":500},
+       {"docType":"health_insurance","isMandatory":true,"gracePeriodDays":0,"finePerDay":500,"fineType":"monthly","fineCap":150000},
+       {"docType":"iloe_insurance","isMandatory":true,"gracePeriodDays":0,"finePerDay":400,"fineType":"one_time","fineCap":400},
+       {"docType":"medical_fitness","isMandatory":true,"gracePeriodDays":0,"finePerDay":0,"fineType":"daily","fineCap":0}]',
+     '[{"blockingDocType":"passport","blockedDocType":"visa","description":"Passport must have 6+ months validity to renew Residence Visa","minValidityDays":180},
+       {"blockingDocType":"medical_fitness","blockedDocType":"visa","description":"Medical fitness certificate required for visa issuance/renewal","minValidityDays":0},
+       {"blockingDocType":"health_insurance","blockedDocType":"work_permit","description":"Valid health insurance required to issue/renew Work Permit","minValidityDays":0},
+       {"blockingDocType":"visa","blockedDocType":"emirates_id","description":"Valid residence visa required to renew Emirates ID","minValidityDays":0}]'),
+    ('JAFZA', 'Jebel Ali Free Zone Authority', 'Jebel Ali free zone companies',
+     '[{"docType":"passport","isMandatory":true,"gracePeriodDays":0,"finePerDay":0,"fineType":"daily","fineCap":0},
+       {"docType":"visa","isMandatory":true,"gracePeriodDays":0,"finePerDay":50,"fineType":"daily","fineCap":0},
+       {"docType":"emirates_id","isMandatory":true,"gracePeriodDays":30,"finePerDay":20,"fineType":"daily","fineCap":1000},
+       {"docType":"work_permit","isMandatory":false,"gracePeriodDays":0,"finePerDay":0,"fineType":"daily","fineCap":0},
+       {"docType":"health_insurance","isMandatory":true,"gracePeriodDays":0,"finePerDay":500,"fineType":"monthly","fineCap":150000},
+       {"docType":"iloe_insurance","isMandatory":true,"gracePeriodDays":0,"finePerDay":400,"fineType":"one_time","fineCap":400},
+       {"docType":"medical_fitness","isMandatory":true,"gracePeriodDays":0,"finePerDay":0,"fineType":"daily","fineCap":0}]',
+     '[{"blockingDocType":"passport","blockedDocType":"visa","description":"Passport must have 6+ months validity to renew Residence Visa","minValidityDays":180},
+       {"blockingDocType":"medical_fitness","blockedDocType":"visa","description":"Medical fitness certificate required for visa issuance/renewal","minValidityDays":0},
+       {"blockingDocType":"health_insurance","blockedDocType":"visa","description":"Free zone visa requires valid health insurance","minValidityDays":0},
+       {"blockingDocType":"visa","blockedDocType":"emirates_id","description":"Valid residence visa required to renew Emirates ID","minValidityDays":0}]'),
+    ('DMCC', 'Dubai Multi Commodities Centre', 'DMCC free zone companies',
+     '[{"docType":"passport","isMandatory":true,"gracePeriodDays":0,"finePerDay":0,"fineType":"daily","fineCap":0},
+       {"docType":"visa","isMandatory":true,"gracePeriodDays":0,"finePerDay":50,"fineType":"daily","fineCap":0},
+       {"docType":"emirates_id","isMandatory":true,"gracePeriodDays":30,"finePerDay":20,"fineType":"daily","fineCap":1000},
+       {"docType":"work_permit","isMandatory":false,"gracePeriodDays":0,"finePerDay":0,"fineType":"daily","fineCap":0},
+       {"docType":"health_insurance","isMandatory":true,"gracePeriodDays":0,"finePerDay":500,"fineType":"monthly","fineCap":150000},
+       {"docType":"iloe_insurance","isMandatory":true,"gracePeriodDays":0,"finePerDay":400,"fineType":"one_time","fineCap":400},
+       {"docType":"medical_fitness","isMandatory":true,"gracePeriodDays":0,"finePerDay":0,"fineType":"daily","fineCap":0}]',
+     '[{"blockingDocType":"passport","blockedDocType":"visa","description":"Passport must have 6+ months validity to renew Residence Visa","minValidityDays":180},
+       {"blockingDocType":"medical_fitness","blockedDocType":"visa","description":"Medical fitness certificate required for visa issuance/renewal","minValidityDays":0},
+       {"blockingDocType":"health_insurance","blockedDocType":"visa","description":"Free zone visa requires valid health insurance","minValidityDays":0},
+       {"blockingDocType":"visa","blockedDocType":"emirates_id","description":"Valid residence visa required to renew Emirates ID","minValidityDays":0}]'),
+    ('DAFZA', 'Dubai Airport Freezone Authority', 'DAFZA free zone companies',
+     '[{"docType":"passport","isMandatory":true,"gracePeriodDays":0,"finePerDay":0,"fineType":"daily","fineCap":0},
+       {"docType":"visa","isMandatory":true,"gracePeriodDays":0,"finePerDay":50,"fineType":"daily","fineCap":0},
+       {"docType":"emirates_id","isMandatory":true,"gracePeriodDays":30,"finePerDay":20,"fineType":"daily","fineCap":1000},
+       {"docType":"work_permit","isMandatory":false,"gracePeriodDays":0,"finePerDay":0,"fineType":"daily","fineCap":0},
+       {"docType":"health_insurance","isMandatory":true,"gracePeriodDays":0,"finePerDay":500,"fineType":"monthly","fineCap":150000},
+       {"docType":"iloe_insurance","isMandatory":true,"gracePeriodDays":0,"finePerDay":400,"fineType":"one_time","fineCap":400},
+       {"docType":"medical_fitness","isMandatory":true,"gracePeriodDays":0,"finePerDay":0,"fineType":"daily","fineCap":0}]',
+     '[{"blockingDocType":"passport","blockedDocType":"visa","description":"Passport must have 6+ months validity to renew Residence Visa","minValidityDays":180},
+       {"blockingDocType":"medical_fitness","blockedDocType":"visa","description":"Medical fitness certificate required for visa issuance/renewal","minValidityDays":0},
+       {"blockingDocType":"health_insurance","blockedDocType":"visa","description":"Free zone visa requires valid health insurance","minValidityDays":0},
+       {"blockingDocType":"visa","blockedDocType":"emirates_id","description":"Valid residence visa required to renew Emirates ID","minValidityDays":0}]'),
+    ('DIFC', 'Dubai International Financial Centre', 'DIFC companies (DIFC employment law)',
+     '[{"docType":"passport","isMandatory":true,"gracePeriodDays":0,"finePerDay":0,"fineType":"daily","fineCap":0},
+       {"docType":"visa","isMandatory":true,"gracePeriodDays":0,"finePerDay":50,"fineType":"daily","fineCap":0},
+       {"docType":"emirates_id","isMandatory":true,"gracePeriodDays":30,"finePerDay":20,"fineType":"daily","fineCap":1000},
+       {"docType":"work_permit","isMandatory":false,"gracePeriodDays":0,"finePerDay":0,"fineType":"daily","fineCap":0},
+       {"docType":"health_insurance","isMandatory":true,"gracePeriodDays":0,"finePerDay":500,"fineType":"monthly","fineCap":150000},
+       {"docType":"iloe_insurance","isMandatory":true,"gracePeriodDays":0,"finePerDay":400,"fineType":"one_time","fineCap":400},
+       {"docType":"medical_fitness","isMandatory":true,"gracePeriodDays":0,"finePerDay":0,"fineType":"daily","fineCap":0}]',
+     '[{"blockingDocType":"passport","blockedDocType":"visa","description":"Passport must have 6+ months validity to renew Residence Visa","minValidityDays":180},
+       {"blockingDocType":"medical_fitness","blockedDocType":"visa","description":"Medical fitness certificate required for visa issuance/renewal","minValidityDays":0},
+       {"blockingDocType":"health_insurance","blockedDocType":"visa","description":"DIFC visa requires valid health insurance","minValidityDays":0},
+       {"blockingDocType":"visa","blockedDocType":"emirates_id","description":"Valid residence visa required to renew Emirates ID","minValidityDays":0}]')
+) AS seed(a, b, c, d, e)
+WHERE NOT EXISTS (SELECT 1 FROM authority_profiles LIMIT 1);
+
+-- Version 1 snapshot for each seeded profile
+INSERT INTO authority_profile_versions (profile_id, version, name, description, rules, dependencies)
+SELECT p.id, p.version, p.name, p.description, p.rules, p.dependencies
+FROM authority_profiles p
+WHERE NOT EXISTS (SELECT 1 FROM authority_profile_versions v WHERE v.profile_id = p.id);
+
+-- Profile dependencies become authority-scoped rows (skipped if the authority already has rows)
+INSERT INTO document_dependencies (blocking_doc_type, blocked_doc_type, description, min_validity_days, regulatory_authority)
+SELECT dep->>'blockingDocType', dep->>'blockedDocType', dep->>'description',
+       COALESCE((dep->>'minValidityDays')::int, 0), p.code
+FROM authority_profiles p, jsonb_array_elements(p.dependencies) dep
+WHERE NOT EXISTS (
+    SELECT 1 FROM document_dependencies d
+    WHERE d.company_id IS NULL AND upper(d.regulatory_authority) = p.code
+);
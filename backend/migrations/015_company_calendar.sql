@@ -0,0 +1,118 @@
+-- Migration 015: Company timezone and working calendar
+-- Compliance dates ("today", days remaining, grace end) were computed with the
+-- server clock (time.Now() / CURRENT_DATE). On a UTC host documents flipped
+-- status at 4am Dubai time and Go and SQL could disagree around midnight.
+-- Each company now has a timezone (default Asia/Dubai) and weekend days, and
+-- grace periods can be counted in working days using a public-holiday table.
+-- All changes are additive.
+
+-- ── 1. Company Calendar Columns ──────────────────────────────────
+-- weekend_days uses ISO day numbers (1 = Monday … 7 = Sunday).
+-- UAE federal weekend is Saturday/Sunday since 2022.
+
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Dubai';
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS weekend_days SMALLINT[] NOT NULL DEFAULT '{6,7}';
+
+-- ── 2. Grace Period Unit ─────────────────────────────────────────
+-- 'calendar' = grace_period_days are calendar days (previous behaviour)
+-- 'working'  = weekends and public holidays are skipped
+
+ALTER TABLE compliance_rules ADD COLUMN IF NOT EXISTS grace_period_unit VARCHAR(10) NOT NULL DEFAULT 'calendar';
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'compliance_rules_grace_period_unit_check') THEN
+        ALTER TABLE compliance_rules ADD CONSTRAINT compliance_rules_grace_period_unit_check
+            CHECK (grace_period_unit IN ('calendar', 'working'));
+    END IF;
+END $$;
+
+-- ── 3. Public Holidays ───────────────────────────────────────────
+-- company_id NULL = applies to every company.
+
+CREATE TABLE IF NOT EXISTS public_holidays (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    company_id   UUID REFERENCES companies(id) ON DELETE CASCADE,
+    holiday_date DATE NOT NULL,
+    name         VARCHAR(200) NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_public_holidays_global_date
+    ON public_holidays(holiday_date) WHERE company_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_public_holidays_company_date
+    ON public_holidays(company_id, holiday_date) WHERE company_id IS NOT NULL;
+
+-- Fixed-date UAE federal holidays. Islamic holidays move with the lunar
+-- calendar and are announced each year — admins add them via the API.
+INSERT INTO public_holidays (holiday_date, name)
+SELECT v.d, v.n
+FROM (VALUES
+    ('2026-01-01'::date, 'New Year''s Day'),
+    ('2026-12-01'::date, 'Commemoration Day'),
+    ('2026-12-02'::date, 'National Day'),
+    ('2026-12-03'::date, 'National Day'),
+    ('2027-01-01'::date, 'New Year''s Day'),
+    ('2027-12-01'::date, 'Commemoration Day'),
+    ('2027-12-02'::date, 'National Day'),
+    ('2027-12-03'::date, 'National Day')
+) AS v(d, n)
+WHERE NOT EXISTS (
+    SELECT 1 FROM public_holidays h WHERE h.holiday_date = v.d AND h.company_id IS NULL
+);
+
+-- ── 4. Calendar Functions ────────────────────────────────────────
+-- Every compliance query uses these instead of CURRENT_DATE so SQL agrees
+-- with the Go engine (compliance.Today with the company's timezone).
+
+-- company_timezone returns the company's timezone (Asia/Dubai if unknown).
+CREATE OR REPLACE FUNCTION company_timezone(p_company_id UUID)
+RETURNS TEXT
+LANGUAGE sql STABLE AS $$
+    SELECT COALESCE((SELECT c.timezone FROM companies c WHERE c.id = p_company_id), 'Asia/Dubai')
+$$;
+
+-- company_today returns the current date in the company's timezone.
+CREATE OR REPLACE FUNCTION company_today(p_company_id UUID)
+RETURNS DATE
+LANGUAGE sql STABLE AS $$
+    SELECT (NOW() AT TIME ZONE company_timezone(p_company_id))::date
+$$;
+
+-- grace_days_for converts a grace period into calendar days after p_expiry.
+-- Calendar-unit grace is returned unchanged; working-unit grace skips the
+-- company's weekend days and public holidays (global or company-specific).
+-- Go receives the result as the grace period, so both sides share one rule.
+CREATE OR REPLACE FUNCTION grace_days_for(p_company_id UUID, p_expiry DATE, p_grace INT, p_unit TEXT)
+RETURNS INT
+LANGUAGE plpgsql STABLE AS $$
+DECLARE
+    v_weekend SMALLINT[];
+    v_day     DATE := p_expiry;
+    v_left    INT  := p_grace;
+BEGIN
+    IF p_expiry IS NULL OR p_grace IS NULL OR p_grace <= 0 OR p_unit IS DISTINCT FROM 'working' THEN
+        RETURN GREATEST(COALESCE(p_grace, 0), 0);
+    END IF;
+
+    SELECT c.weekend_days INTO v_weekend FROM companies c WHERE c.id = p_company_id;
+    v_weekend := COALESCE(v_weekend, '{6,7}');
+    IF cardinality(v_weekend) >= 7 THEN
+        RETURN p_grace; -- no working days at all; fall back to calendar days
+    END IF;
+
+    WHILE v_left > 0 LOOP
+        v_day := v_day + 1;
+        IF NOT (EXTRACT(ISODOW FROM v_day)::smallint = ANY (v_weekend))
+           AND NOT EXISTS (
+               SELECT 1 FROM public_holidays h
+               WHERE h.holiday_date = v_day
+                 AND (h.company_id IS NULL OR h.company_id = p_company_id)
+           ) THEN
+            v_left := v_left - 1;
+        END IF;
+    END LOOP;
+
+    RETURN v_day - p_expiry;
+END;
+$$;
@@ -0,0 +1,56 @@
+-- Migration 016: Persisted document compliance status
+-- Status rules used to be written three times (compliance.ComputeStatus, the
+-- LATERAL CASE in the employee list, and the dashboard queries) and they
+-- disagreed on edge cases (the expiry day itself, the 30-day window).
+-- The Go engine now computes every document's status once and stores it here;
+-- the employee list, dashboard and notifier read these tables instead of
+-- re-deriving. Rows are refreshed on document/employee writes, on rule
+-- changes, and by a background job whenever a company's local date rolls over.
+-- All changes are additive.
+
+-- ── 1. Per-document Status ───────────────────────────────────────
+-- as_of is the company-local date the row was computed for.
+
+CREATE TABLE IF NOT EXISTS document_status (
+    document_id          UUID PRIMARY KEY REFERENCES documents(id) ON DELETE CASCADE,
+    employee_id          UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    company_id           UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    document_type        VARCHAR(100) NOT NULL,
+    is_required          BOOLEAN NOT NULL DEFAULT FALSE,
+    status               VARCHAR(20) NOT NULL,
+    expiry_date          DATE,
+    days_remaining       INT,
+    grace_days           INT NOT NULL DEFAULT 0,
+    grace_days_remaining INT,
+    days_in_penalty      INT,
+    fine_per_day         NUMERIC(10,2) NOT NULL DEFAULT 0,
+    fine_type            VARCHAR(20) NOT NULL DEFAULT 'daily',
+    fine_cap             NUMERIC(10,2) NOT NULL DEFAULT 0,
+    estimated_fine       NUMERIC(12,2) NOT NULL DEFAULT 0,
+    as_of                DATE NOT NULL,
+    computed_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_status_employee ON document_status(employee_id);
+CREATE INDEX IF NOT EXISTS idx_document_status_company_status ON document_status(company_id, status) WHERE is_required;
+
+-- ── 2. Per-employee Rollup ───────────────────────────────────────
+-- Aggregated over the employee's required documents (doc_required_for).
+-- compliance_status is 'none' when no documents are required.
+
+CREATE TABLE IF NOT EXISTS employee_compliance (
+    employee_id         UUID PRIMARY KEY REFERENCES employees(id) ON DELETE CASCADE,
+    company_id          UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    compliance_status   VARCHAR(20) NOT NULL,
+    nearest_expiry_days INT,
+    docs_complete       INT NOT NULL DEFAULT 0,
+    docs_total          INT NOT NULL DEFAULT 0,
+    urgent_doc_type     VARCHAR(100),
+    expired_count       INT NOT NULL DEFAULT 0,
+    expiring_count      INT NOT NULL DEFAULT 0,
+    as_of               DATE NOT NULL,
+    computed_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_employee_compliance_company_status ON employee_compliance(company_id, compliance_status);
+CREATE INDEX IF NOT EXISTS idx_employee_compliance_status ON employee_compliance(compliance_status);
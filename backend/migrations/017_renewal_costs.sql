@@ -0,0 +1,23 @@
+-- Migration 017: Renewal cost catalog
+-- What it costs to renew each document type (typing fees, government fees,
+-- medical, insurance premium), per company or globally, with effective dates
+-- so fee changes can be entered ahead of time. Used by the renewal budget.
+-- Amounts are AED, like the fine schedule. All changes are additive.
+
+CREATE TABLE IF NOT EXISTS renewal_costs (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    company_id     UUID REFERENCES companies(id) ON DELETE CASCADE, -- NULL = all companies
+    doc_type       VARCHAR(100) NOT NULL,
+    component      VARCHAR(20) NOT NULL
+                   CHECK (component IN ('typing', 'government', 'medical', 'insurance', 'other')),
+    amount         NUMERIC(12,2) NOT NULL CHECK (amount >= 0),
+    effective_from DATE NOT NULL,
+    effective_to   DATE, -- inclusive; NULL = open-ended
+    description    TEXT NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (effective_to IS NULL OR effective_to >= effective_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_renewal_costs_type
+    ON renewal_costs(doc_type, company_id, component, effective_from);
@@ -0,0 +1,42 @@
+-- Migration 018: Fine settlement ledger
+-- Fines actually paid (e.g. at the typing centre) against the engine's
+-- estimate. Each entry settles one document's penalty for a date range;
+-- accrual up to the latest settled date no longer counts as exposure.
+-- estimated_amount is what the engine had accrued for the same range when
+-- the entry was recorded, so estimated and paid can be reconciled later.
+-- Amounts are AED. All changes are additive.
+
+-- ── 1. Ledger ────────────────────────────────────────────────────
+-- document_id / employee_id are kept nullable so paid fines stay on the
+-- books after the document or employee is deleted; document_type is a
+-- snapshot for reporting.
+
+CREATE TABLE IF NOT EXISTS fine_settlements (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    document_id      UUID REFERENCES documents(id) ON DELETE SET NULL,
+    employee_id      UUID REFERENCES employees(id) ON DELETE SET NULL,
+    company_id       UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    document_type    VARCHAR(100) NOT NULL,
+    penalty_from     DATE NOT NULL,
+    penalty_to       DATE NOT NULL, -- inclusive
+    amount           NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+    estimated_amount NUMERIC(12,2) NOT NULL DEFAULT 0,
+    paid_on          DATE NOT NULL,
+    reference        VARCHAR(100) NOT NULL DEFAULT '',
+    notes            TEXT NOT NULL DEFAULT '',
+    receipt_path     TEXT,
+    receipt_url      TEXT,
+    receipt_name     TEXT,
+    created_by       UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (penalty_to >= penalty_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_fine_settlements_document ON fine_settlements(document_id, penalty_to);
+CREATE INDEX IF NOT EXISTS idx_fine_settlements_company ON fine_settlements(company_id, paid_on);
+
+-- ── 2. Settlement in Persisted Status ────────────────────────────
+-- estimated_fine becomes the outstanding fine: accrual after settled_through.
+
+ALTER TABLE document_status ADD COLUMN IF NOT EXISTS settled_through DATE;
+ALTER TABLE document_status ADD COLUMN IF NOT EXISTS fine_paid NUMERIC(12,2) NOT NULL DEFAULT 0;
@@ -0,0 +1,27 @@
+-- Migration 019: Daily compliance snapshots
+-- One row per company per company-local day, copied from the persisted
+-- status tables (migration 016) so compliance can be charted over time.
+-- The row for the current day is overwritten by each refresher run until
+-- the company's date rolls over, so past rows hold the end-of-day state.
+-- Exited employees are excluded, as on the dashboard. All changes are additive.
+
+CREATE TABLE IF NOT EXISTS compliance_snapshots (
+    company_id           UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    snapshot_date        DATE NOT NULL,
+    -- Required documents by status
+    docs_valid           INT NOT NULL DEFAULT 0,
+    docs_expiring_soon   INT NOT NULL DEFAULT 0,
+    docs_in_grace        INT NOT NULL DEFAULT 0,
+    docs_penalty_active  INT NOT NULL DEFAULT 0,
+    docs_incomplete      INT NOT NULL DEFAULT 0,
+    -- Employees by rollup status
+    employees_total      INT NOT NULL DEFAULT 0,
+    employees_compliant  INT NOT NULL DEFAULT 0, -- rollup valid or none
+    employees_incomplete INT NOT NULL DEFAULT 0,
+    employees_penalty    INT NOT NULL DEFAULT 0,
+    fine_exposure        NUMERIC(14,2) NOT NULL DEFAULT 0, -- unsettled, AED
+    recorded_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (company_id, snapshot_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_compliance_snapshots_date ON compliance_snapshots(snapshot_date);
@@ -0,0 +1,36 @@
+-- Migration 020: Compliance score
+-- Scoring weights (admin settings, single row) and the persisted score sums.
+-- Each document's weight comes from its fine rate and mandatory flag; its
+-- status decides how much of that weight is lost (compliance.Score).
+-- score_lost / score_max are stored next to the score so company and
+-- multi-company scores can be summed exactly. All changes are additive.
+
+-- ── 1. Weights ───────────────────────────────────────────────────
+
+CREATE TABLE IF NOT EXISTS compliance_score_weights (
+    id                BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id), -- single row
+    penalty_weight    NUMERIC(6,3) NOT NULL DEFAULT 1,
+    grace_weight      NUMERIC(6,3) NOT NULL DEFAULT 0.6,
+    expiring_weight   NUMERIC(6,3) NOT NULL DEFAULT 0.3,
+    incomplete_weight NUMERIC(6,3) NOT NULL DEFAULT 0.5,
+    fine_rate_weight  NUMERIC(6,3) NOT NULL DEFAULT 0.25,
+    optional_weight   NUMERIC(6,3) NOT NULL DEFAULT 0.25,
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO compliance_score_weights (id)
+SELECT TRUE
+WHERE NOT EXISTS (SELECT 1 FROM compliance_score_weights);
+
+-- ── 2. Persisted Scores ──────────────────────────────────────────
+-- employee_compliance.score is stored for sorting and filtering; snapshot
+-- scores are derived from the sums when read (compliance.ScoreValue).
+
+ALTER TABLE employee_compliance ADD COLUMN IF NOT EXISTS score NUMERIC(5,1) NOT NULL DEFAULT 100;
+ALTER TABLE employee_compliance ADD COLUMN IF NOT EXISTS score_lost NUMERIC(12,4) NOT NULL DEFAULT 0;
+ALTER TABLE employee_compliance ADD COLUMN IF NOT EXISTS score_max NUMERIC(12,4) NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_employee_compliance_score ON employee_compliance(score);
+
+ALTER TABLE compliance_snapshots ADD COLUMN IF NOT EXISTS score_lost NUMERIC(14,4) NOT NULL DEFAULT 0;
+ALTER TABLE compliance_snapshots ADD COLUMN IF NOT EXISTS score_max NUMERIC(14,4) NOT NULL DEFAULT 0;
@@ -0,0 +1,42 @@
+-- Migration 021: Linked expiry between document types
+-- Renewing one document often fixes the dates of another: the Emirates ID
+-- and work permit expire with the residence visa, ILOE insurance runs one
+-- year from issue. A link tells DocumentHandler.Renew to propose (or, with
+-- action 'auto', create) the renewal of the linked document.
+-- A company row replaces the global row for the same source/target pair,
+-- so an inactive company row switches a global link off. All changes are
+-- additive.
+
+CREATE TABLE IF NOT EXISTS document_links (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    company_id      UUID REFERENCES companies(id) ON DELETE CASCADE, -- NULL = all companies
+    source_doc_type VARCHAR(100) NOT NULL, -- the document being renewed
+    target_doc_type VARCHAR(100) NOT NULL, -- the document whose dates follow
+    mode            VARCHAR(20) NOT NULL CHECK (mode IN ('source_expiry', 'from_issue')),
+    offset_days     INT NOT NULL DEFAULT 0,  -- source_expiry: target expiry = source expiry + offset
+    validity_months INT NOT NULL DEFAULT 0 CHECK (validity_months >= 0), -- from_issue
+    validity_days   INT NOT NULL DEFAULT 0 CHECK (validity_days >= 0),   -- from_issue
+    action          VARCHAR(10) NOT NULL DEFAULT 'propose' CHECK (action IN ('propose', 'auto')),
+    is_active       BOOLEAN NOT NULL DEFAULT TRUE,
+    description     TEXT NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (source_doc_type <> target_doc_type),
+    CHECK (mode <> 'from_issue' OR validity_months > 0 OR validity_days > 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_document_links_pair
+    ON document_links(COALESCE(company_id, '00000000-0000-0000-0000-000000000000'::uuid), source_doc_type, target_doc_type);
+
+-- Seed the common UAE links (proposals only)
+INSERT INTO document_links (source_doc_type, target_doc_type, mode, description)
+SELECT 'visa', 'emirates_id', 'source_expiry', 'Emirates ID expires with the residence visa'
+WHERE NOT EXISTS (SELECT 1 FROM document_links WHERE company_id IS NULL AND source_doc_type = 'visa' AND target_doc_type = 'emirates_id');
+
+INSERT INTO document_links (source_doc_type, target_doc_type, mode, description)
+SELECT 'visa', 'work_permit', 'source_expiry', 'Work permit is renewed with the residence visa'
+WHERE NOT EXISTS (SELECT 1 FROM document_links WHERE company_id IS NULL AND source_doc_type = 'visa' AND target_doc_type = 'work_permit');
+
+INSERT INTO document_links (source_doc_type, target_doc_type, mode, validity_months, description)
+SELECT 'visa', 'iloe_insurance', 'from_issue', 12, 'ILOE insurance runs one year from issue'
+WHERE NOT EXISTS (SELECT 1 FROM document_links WHERE company_id IS NULL AND source_doc_type = 'visa' AND target_doc_type = 'iloe_insurance');
@@ -0,0 +1,30 @@
+-- Migration 022: Standard validity per document type
+-- Most documents are issued for a fixed term (2-year visa, 1-year insurance).
+-- Document create/update/renew derive a missing expiry_date from issue_date
+-- with it, and warn when an entered expiry is far off the standard term.
+-- A company compliance rule can override the term (NULL = use the document
+-- type's). All changes are additive.
+
+ALTER TABLE document_types
+    ADD COLUMN IF NOT EXISTS validity_period INT NOT NULL DEFAULT 0 CHECK (validity_period >= 0), -- 0 = no standard term
+    ADD COLUMN IF NOT EXISTS validity_unit VARCHAR(10) NOT NULL DEFAULT 'months' CHECK (validity_unit IN ('months', 'days'));
+
+ALTER TABLE compliance_rules
+    ADD COLUMN IF NOT EXISTS validity_period INT CHECK (validity_period > 0),
+    ADD COLUMN IF NOT EXISTS validity_unit VARCHAR(10) CHECK (validity_unit IN ('months', 'days'));
+
+-- Period and unit are overridden together
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'compliance_rules_validity_pair') THEN
+        ALTER TABLE compliance_rules ADD CONSTRAINT compliance_rules_validity_pair
+            CHECK ((validity_period IS NULL) = (validity_unit IS NULL));
+    END IF;
+END $$;
+
+-- Seed the common UAE terms where no term is set yet
+UPDATE document_types SET validity_period = 24, validity_unit = 'months'
+WHERE doc_type IN ('visa', 'work_permit', 'emirates_id') AND validity_period = 0;
+
+UPDATE document_types SET validity_period = 12, validity_unit = 'months'
+WHERE doc_type IN ('health_insurance', 'iloe_insurance', 'trade_license') AND validity_period = 0;
@@ -0,0 +1,55 @@
+-- Migration 023: Renewal case workflow
+-- A renewal used to be a single POST /api/documents/{id}/renew after the
+-- fact. A case tracks a document's renewal while it is in progress:
+-- requested → medical_done → biometrics_done → submitted → approved →
+-- completed, with an assignee and per-stage due dates (compliance.RenewalStageDue)
+-- derived from the expiry and grace period. Renew closes the open case.
+-- While a case is open, "expiring soon" notifications for the document are
+-- suppressed; a stage past its due date is escalated once by the notifier.
+-- All changes are additive.
+
+-- ── 1. Cases ─────────────────────────────────────────────────────
+
+CREATE TABLE IF NOT EXISTS renewal_cases (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    document_id         UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    employee_id         UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    company_id          UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    document_type       VARCHAR(100) NOT NULL,
+    status              VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'completed', 'cancelled')),
+    stage               VARCHAR(20) NOT NULL DEFAULT 'requested'
+                        CHECK (stage IN ('requested', 'medical_done', 'biometrics_done', 'submitted', 'approved', 'completed')),
+    assignee_id         UUID REFERENCES users(id) ON DELETE SET NULL,
+    expiry_date         DATE,          -- document expiry when the case was opened
+    grace_days          INT NOT NULL DEFAULT 0,
+    deadline            DATE NOT NULL, -- last day without a fine (expiry + grace)
+    stage_entered_on    DATE NOT NULL,
+    stage_due           DATE,          -- due date of the next stage; NULL once closed
+    escalated_at        TIMESTAMPTZ,   -- set when the current stage missed its due date
+    renewed_document_id UUID REFERENCES documents(id) ON DELETE SET NULL,
+    created_by          UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    closed_at           TIMESTAMPTZ
+);
+
+-- One open case per document
+CREATE UNIQUE INDEX IF NOT EXISTS idx_renewal_cases_open_document ON renewal_cases(document_id) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_renewal_cases_company ON renewal_cases(company_id, status);
+CREATE INDEX IF NOT EXISTS idx_renewal_cases_assignee ON renewal_cases(assignee_id) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_renewal_cases_due ON renewal_cases(stage_due) WHERE status = 'open';
+
+-- ── 2. Comments and History ──────────────────────────────────────
+-- Comments, stage changes, reassignments, escalations and closing, in order.
+
+CREATE TABLE IF NOT EXISTS renewal_case_events (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    case_id    UUID NOT NULL REFERENCES renewal_cases(id) ON DELETE CASCADE,
+    kind       VARCHAR(20) NOT NULL CHECK (kind IN ('comment', 'stage', 'assignment', 'escalation', 'closed')),
+    stage      VARCHAR(20), -- stage after the event
+    body       TEXT NOT NULL DEFAULT '',
+    user_id    UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_renewal_case_events_case ON renewal_case_events(case_id, created_at);
@@ -0,0 +1,105 @@
+-- Migration 024: Exit checklist and cancellation tracking
+-- Exiting an employee (resigned/terminated) used to leave their visa, EID,
+-- work permit and insurance documents on renewal tracking, so they kept
+-- producing expiry alerts while nothing tracked the legal cancellation
+-- deadlines. Exit now generates a checklist from exit_checklist_rules:
+-- cancel the work permit, cancel the visa, settle the final salary, pay the
+-- gratuity and collect company assets, each due a number of days after the
+-- exit date with its own fine if missed (compliance.ExitItemFine).
+-- Documents of exited employees are tracked against the item covering their
+-- type (document_status.tracking = 'cancellation'); other documents of
+-- exited employees are no longer tracked ('none').
+-- All changes are additive.
+
+-- ── 1. Checklist Rules ───────────────────────────────────────────
+-- company_id NULL = global default; a company row replaces the global row
+-- for the same item. doc_types are the documents the item cancels.
+
+CREATE TABLE IF NOT EXISTS exit_checklist_rules (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    company_id   UUID REFERENCES companies(id) ON DELETE CASCADE,
+    item         VARCHAR(30) NOT NULL
+                 CHECK (item IN ('cancel_work_permit', 'cancel_visa', 'final_salary', 'gratuity', 'company_assets')),
+    due_days     INT NOT NULL CHECK (due_days >= 0),
+    fine_per_day NUMERIC(10,2) NOT NULL DEFAULT 0 CHECK (fine_per_day >= 0),
+    fine_type    VARCHAR(20) NOT NULL DEFAULT 'daily' CHECK (fine_type IN ('daily', 'monthly', 'one_time')),
+    fine_cap     NUMERIC(10,2) NOT NULL DEFAULT 0 CHECK (fine_cap >= 0),
+    doc_types    TEXT[] NOT NULL DEFAULT '{}',
+    description  TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_exit_checklist_rules_scope
+    ON exit_checklist_rules(COALESCE(company_id, '00000000-0000-0000-0000-000000000000'::uuid), item);
+
+-- Global defaults (only if no global rules exist)
+INSERT INTO exit_checklist_rules (company_id, item, due_days, fine_per_day, fine_type, fine_cap, doc_types, description)
+SELECT NULL, a, b, c, d, e, f::text[], g FROM (VALUES
+    ('cancel_work_permit', 14, 100.00, 'daily',    5000.00, '{work_permit}',
+        'Cancel the labour card / work permit with MOHRE'),
+    ('cancel_visa',        30,  50.00, 'daily',       0.00, '{visa,emirates_id,health_insurance,iloe_insurance}',
+        'Cancel the residence visa; the Emirates ID and insurance lapse with it'),
+    ('final_salary',       14, 1000.00, 'one_time',   0.00, '{}',
+        'Pay the final salary and outstanding dues'),
+    ('gratuity',           14, 1000.00, 'one_time',   0.00, '{}',
+        'Pay the end-of-service gratuity'),
+    ('company_assets',      7,   0.00, 'daily',       0.00, '{}',
+        'Collect laptops, access cards, uniforms and other company assets')
+) AS seed(a, b, c, d, e, f, g)
+WHERE NOT EXISTS (SELECT 1 FROM exit_checklist_rules WHERE company_id IS NULL);
+
+-- ── 2. Checklist Items ───────────────────────────────────────────
+-- One row per item per exited employee. Rule values are copied when the
+-- item is generated so later rule changes do not move existing deadlines.
+
+CREATE TABLE IF NOT EXISTS exit_checklist_items (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id  UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    company_id   UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    item         VARCHAR(30) NOT NULL
+                 CHECK (item IN ('cancel_work_permit', 'cancel_visa', 'final_salary', 'gratuity', 'company_assets')),
+    doc_types    TEXT[] NOT NULL DEFAULT '{}',
+    due_date     DATE NOT NULL, -- last day without a fine
+    fine_per_day NUMERIC(10,2) NOT NULL DEFAULT 0,
+    fine_type    VARCHAR(20) NOT NULL DEFAULT 'daily',
+    fine_cap     NUMERIC(10,2) NOT NULL DEFAULT 0,
+    status       VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'done', 'waived')),
+    completed_on DATE,
+    completed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    notes        TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (employee_id, item)
+);
+
+CREATE INDEX IF NOT EXISTS idx_exit_checklist_items_company ON exit_checklist_items(company_id, status);
+CREATE INDEX IF NOT EXISTS idx_exit_checklist_items_due ON exit_checklist_items(due_date) WHERE status = 'pending';
+
+-- Backfill employees who already exited
+INSERT INTO exit_checklist_items (employee_id, company_id, item, doc_types, due_date, fine_per_day, fine_type, fine_cap)
+SELECT e.id, e.company_id, r.item, r.doc_types, e.exit_date + r.due_days, r.fine_per_day, r.fine_type, r.fine_cap
+FROM employees e
+CROSS JOIN LATERAL (
+    SELECT DISTINCT ON (item) * FROM exit_checklist_rules
+    WHERE company_id = e.company_id OR company_id IS NULL
+    ORDER BY item, company_id NULLS LAST
+) r
+WHERE e.status IN ('resigned', 'terminated') AND e.exit_date IS NOT NULL
+ON CONFLICT (employee_id, item) DO NOTHING;
+
+-- ── 3. Document Tracking Mode ────────────────────────────────────
+-- renewal: status from the expiry (active employees)
+-- cancellation: status from the exit checklist item covering the type
+-- none: exited employee, no item covers the type; not tracked
+
+ALTER TABLE document_status ADD COLUMN IF NOT EXISTS tracking VARCHAR(20) NOT NULL DEFAULT 'renewal';
+ALTER TABLE document_status ADD COLUMN IF NOT EXISTS cancellation_due DATE;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'document_status_tracking_check') THEN
+        ALTER TABLE document_status ADD CONSTRAINT document_status_tracking_check
+            CHECK (tracking IN ('renewal', 'cancellation', 'none'));
+    END IF;
+END $$;
@@ -0,0 +1,74 @@
+-- Migration 025: Absconding cases
+-- An "absconded" exit used to be recorded as a plain termination, losing the
+-- legal process. Exit with exit_type 'absconded' now opens a case tracking
+-- the report date, the last working day, the MOHRE/GDRFA filing deadline
+-- (compliance.AbscondingFilingDeadline) and filing status, with supporting
+-- documents. If the worker returns, the case is withdrawn and the employee
+-- reinstated. Pending salary records of an employee with an open case are
+-- put on hold (salary_records.status = 'on_hold') and released on withdrawal.
+-- All changes are additive.
+
+-- ── 1. Cases ─────────────────────────────────────────────────────
+-- reported: absence reported internally, not yet filed
+-- filed:    filed with the authority (filed_on, filing_reference)
+-- withdrawn: worker returned; withdrawn_on, withdrawal_reason
+
+CREATE TABLE IF NOT EXISTS absconding_cases (
+    id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id       UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    company_id        UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    status            VARCHAR(20) NOT NULL DEFAULT 'reported' CHECK (status IN ('reported', 'filed', 'withdrawn')),
+    reported_on       DATE NOT NULL,
+    last_working_day  DATE NOT NULL,
+    authority         VARCHAR(10) NOT NULL DEFAULT 'mohre' CHECK (authority IN ('mohre', 'gdrfa')),
+    filing_deadline   DATE NOT NULL, -- last day to file with the authority
+    filed_on          DATE,
+    filing_reference  VARCHAR(100) NOT NULL DEFAULT '',
+    withdrawn_on      DATE,
+    withdrawal_reason TEXT NOT NULL DEFAULT '',
+    notes             TEXT NOT NULL DEFAULT '',
+    created_by        UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One open (reported or filed) case per employee
+CREATE UNIQUE INDEX IF NOT EXISTS idx_absconding_cases_open_employee
+    ON absconding_cases(employee_id) WHERE status IN ('reported', 'filed');
+CREATE INDEX IF NOT EXISTS idx_absconding_cases_company ON absconding_cases(company_id, status);
+CREATE INDEX IF NOT EXISTS idx_absconding_cases_deadline ON absconding_cases(filing_deadline) WHERE status = 'reported';
+
+-- ── 2. Supporting Documents ──────────────────────────────────────
+-- Files saved through storage.Store; file_path is the store key.
+
+CREATE TABLE IF NOT EXISTS absconding_case_files (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    case_id     UUID NOT NULL REFERENCES absconding_cases(id) ON DELETE CASCADE,
+    file_path   TEXT NOT NULL,
+    file_url    TEXT NOT NULL,
+    file_name   VARCHAR(255) NOT NULL,
+    file_size   BIGINT NOT NULL DEFAULT 0,
+    file_type   VARCHAR(100) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_absconding_case_files_case ON absconding_case_files(case_id, created_at);
+
+-- ── 3. Salary Holds ──────────────────────────────────────────────
+-- held_by_case_id marks records put on hold by a case, so withdrawal only
+-- releases those.
+
+ALTER TABLE salary_records ADD COLUMN IF NOT EXISTS held_by_case_id UUID REFERENCES absconding_cases(id) ON DELETE SET NULL;
+
+-- ── 4. Backfill ──────────────────────────────────────────────────
+-- Employees already exited as absconded get a case (reported on the exit
+-- date; the deadline follows compliance.AbscondingFilingDays = 15).
+
+INSERT INTO absconding_cases (employee_id, company_id, reported_on, last_working_day, filing_deadline)
+SELECT e.id, e.company_id, e.exit_date, e.exit_date, e.exit_date + 15
+FROM employees e
+WHERE e.exit_type = 'absconded' AND e.exit_date IS NOT NULL
+  AND e.status IN ('resigned', 'terminated')
+  AND NOT EXISTS (SELECT 1 FROM absconding_cases ac WHERE ac.employee_id = e.id);
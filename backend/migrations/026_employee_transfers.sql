@@ -0,0 +1,64 @@
+-- Migration 026: Employee transfers and sponsorship history
+-- Moving a worker between group companies used to mean deleting and
+-- recreating them, losing their documents and salary history. A transfer
+-- now changes employees.company_id effective from a date and records:
+--   employee_sponsorships — one period per sponsoring company
+--   employee_transfers    — the transfer itself, with the slot changes
+-- Salary records now carry the paying company (salary_records.company_id),
+-- so paid history stays with the old company while open records from the
+-- effective month move to the new one.
+-- All changes are additive.
+
+-- ── 1. Transfers ─────────────────────────────────────────────────
+
+CREATE TABLE IF NOT EXISTS employee_transfers (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id     UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    from_company_id UUID REFERENCES companies(id) ON DELETE SET NULL,
+    to_company_id   UUID REFERENCES companies(id) ON DELETE SET NULL,
+    effective_date  DATE NOT NULL,
+    reason          TEXT NOT NULL DEFAULT '',
+    slots_added     TEXT[] NOT NULL DEFAULT '{}',
+    slots_removed   TEXT[] NOT NULL DEFAULT '{}',
+    salary_moved    INT NOT NULL DEFAULT 0,
+    created_by      UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_employee_transfers_employee ON employee_transfers(employee_id, effective_date);
+
+-- ── 2. Sponsorship Periods ───────────────────────────────────────
+-- end_date NULL = current sponsor. transfer_id is the transfer that started
+-- the period (NULL for the initial period).
+
+CREATE TABLE IF NOT EXISTS employee_sponsorships (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    company_id  UUID REFERENCES companies(id) ON DELETE SET NULL,
+    start_date  DATE NOT NULL,
+    end_date    DATE,
+    transfer_id UUID REFERENCES employee_transfers(id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_employee_sponsorships_current
+    ON employee_sponsorships(employee_id) WHERE end_date IS NULL;
+CREATE INDEX IF NOT EXISTS idx_employee_sponsorships_employee ON employee_sponsorships(employee_id, start_date);
+
+-- Initial period for existing employees
+INSERT INTO employee_sponsorships (employee_id, company_id, start_date)
+SELECT e.id, e.company_id, COALESCE(e.joining_date, e.created_at::date)
+FROM employees e
+WHERE NOT EXISTS (SELECT 1 FROM employee_sponsorships s WHERE s.employee_id = e.id);
+
+-- ── 3. Paying Company on Salary Records ──────────────────────────
+
+ALTER TABLE salary_records ADD COLUMN IF NOT EXISTS company_id UUID REFERENCES companies(id) ON DELETE CASCADE;
+
+UPDATE salary_records s SET company_id = e.company_id
+FROM employees e
+WHERE e.id = s.employee_id AND s.company_id IS NULL;
+
+ALTER TABLE salary_records ALTER COLUMN company_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_salary_company ON salary_records(company_id, year, month);
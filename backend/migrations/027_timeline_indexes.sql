@@ -0,0 +1,7 @@
+-- Migration 027: Indexes for the employee timeline
+-- GET /api/employees/{id}/timeline looks up notifications and activity
+-- entries by the entity they concern.
+-- All changes are additive.
+
+CREATE INDEX IF NOT EXISTS idx_notifications_entity ON notifications(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_activity_log_entity_action ON activity_log(entity_id, action);
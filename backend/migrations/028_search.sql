@@ -0,0 +1,36 @@
+-- Migration 028: Full-text and trigram search
+-- GET /api/search finds employees by partial Arabic/English name, trade or
+-- nationality (full-text + trigram on the name) and by mobile, passport,
+-- Emirates ID, visa / MoHRE file and other document numbers (trigram on the
+-- number with separators stripped, so "784199012345671" finds
+-- "784-1990-1234567-1").
+-- All changes are additive.
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- search_norm lowercases and drops everything but letters and digits.
+CREATE OR REPLACE FUNCTION search_norm(t TEXT) RETURNS TEXT
+LANGUAGE sql IMMUTABLE PARALLEL SAFE AS $$
+    SELECT lower(regexp_replace(COALESCE(t, ''), '[^[:alnum:]]', '', 'g'))
+$$;
+
+-- ── 1. Employees ─────────────────────────────────────────────────
+-- 'simple' config: no stemming or stop words, so Arabic and transliterated
+-- names tokenize the same way as English ones.
+
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS search_vector TSVECTOR
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', COALESCE(name, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE(trade, '')), 'B') ||
+        setweight(to_tsvector('simple', COALESCE(nationality, '')), 'C')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_employees_search ON employees USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_employees_name_trgm ON employees USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_employees_mobile_trgm ON employees USING GIN (search_norm(mobile) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_employees_passport_trgm ON employees USING GIN (search_norm(passport_number) gin_trgm_ops);
+
+-- ── 2. Documents ─────────────────────────────────────────────────
+
+CREATE INDEX IF NOT EXISTS idx_documents_number_trgm ON documents USING GIN (search_norm(document_number) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_documents_mohre_file_trgm ON documents USING GIN (search_norm(metadata->>'mohre_file_number') gin_trgm_ops);
@@ -0,0 +1,40 @@
+-- Migration 029: Custom employee fields per company
+-- Admins define extra employee fields per company (camp room, labour
+-- contract number, bank IBAN, emergency contact ...). Values live on the
+-- employee in employees.custom_fields, keyed by field_key, and are
+-- validated by the API against the employee's company definitions.
+-- All changes are additive.
+
+-- ── 1. Definitions ───────────────────────────────────────────────
+-- field_type: text | number | date | select | boolean | email | phone | iban
+-- options (select only): [{"value": "...", "label": "..."}]
+-- pattern: optional regex for text-like values.
+-- min_value / max_value: bounds for numbers, length bounds for text.
+
+CREATE TABLE IF NOT EXISTS custom_field_definitions (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    company_id  UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    field_key   VARCHAR(50) NOT NULL,
+    label       VARCHAR(100) NOT NULL,
+    field_type  VARCHAR(20) NOT NULL DEFAULT 'text'
+        CHECK (field_type IN ('text', 'number', 'date', 'select', 'boolean', 'email', 'phone', 'iban')),
+    required    BOOLEAN NOT NULL DEFAULT FALSE,
+    options     JSONB NOT NULL DEFAULT '[]',
+    pattern     TEXT NOT NULL DEFAULT '',
+    min_value   NUMERIC(14,2),
+    max_value   NUMERIC(14,2),
+    help_text   TEXT NOT NULL DEFAULT '',
+    sort_order  INT NOT NULL DEFAULT 100,
+    is_active   BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (company_id, field_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_custom_field_definitions_company ON custom_field_definitions(company_id, sort_order);
+
+-- ── 2. Values ────────────────────────────────────────────────────
+
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS custom_fields JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_employees_custom_fields ON employees USING GIN (custom_fields jsonb_path_ops);
@@ -0,0 +1,70 @@
+-- Migration 030: Leave management
+-- Employees could be marked on_leave by hand, but there were no leave
+-- records. Leave requests of a configurable type (annual, sick, unpaid,
+-- emergency, Hajj) are now requested and approved; approved leave switches
+-- the employee to on_leave from the start date and back to active after the
+-- end date (docstatus.SyncLeaveStatuses). Annual leave accrues per UAE
+-- Labour Law (compliance.AnnualLeaveAccrued) with a yearly carry-over cap.
+-- Unpaid leave days are deducted when salary records are generated.
+-- All changes are additive.
+
+-- ── 1. Leave types ───────────────────────────────────────────────
+-- accrues:             balance earned monthly (annual leave); annual_days unused
+-- annual_days:         days allowed per calendar year (NULL = no limit)
+-- once_per_employment: annual_days is allowed once over the whole employment (Hajj)
+-- max_carry_over:      accruing types only; days moved into the next year
+
+CREATE TABLE IF NOT EXISTS leave_types (
+    code                VARCHAR(20) PRIMARY KEY,
+    name                VARCHAR(100) NOT NULL,
+    is_paid             BOOLEAN NOT NULL DEFAULT TRUE,
+    accrues             BOOLEAN NOT NULL DEFAULT FALSE,
+    annual_days         NUMERIC(5,1),
+    once_per_employment BOOLEAN NOT NULL DEFAULT FALSE,
+    max_carry_over      NUMERIC(5,1) NOT NULL DEFAULT 0,
+    sort_order          INT NOT NULL DEFAULT 100
+);
+
+INSERT INTO leave_types (code, name, is_paid, accrues, annual_days, once_per_employment, max_carry_over, sort_order) VALUES
+    ('annual',    'Annual Leave',    TRUE,  TRUE,  NULL, FALSE, 15, 1),
+    ('sick',      'Sick Leave',      TRUE,  FALSE, 90,   FALSE, 0,  2),
+    ('unpaid',    'Unpaid Leave',    FALSE, FALSE, NULL, FALSE, 0,  3),
+    ('emergency', 'Emergency Leave', TRUE,  FALSE, 5,    FALSE, 0,  4),
+    ('hajj',      'Hajj Leave',      FALSE, FALSE, 30,   TRUE,  0,  5)
+ON CONFLICT (code) DO NOTHING;
+
+-- ── 2. Leave requests ────────────────────────────────────────────
+-- pending → approved | rejected; pending or approved → cancelled.
+-- on_leave_applied: this leave switched the employee to on_leave and
+-- switches them back when it ends or is cancelled.
+
+CREATE TABLE IF NOT EXISTS leave_requests (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id      UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    company_id       UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    leave_type       VARCHAR(20) NOT NULL REFERENCES leave_types(code),
+    start_date       DATE NOT NULL,
+    end_date         DATE NOT NULL,
+    days             INT NOT NULL CHECK (days > 0),
+    status           VARCHAR(20) NOT NULL DEFAULT 'pending'
+                     CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled')),
+    reason           TEXT NOT NULL DEFAULT '',
+    decision_notes   TEXT NOT NULL DEFAULT '',
+    on_leave_applied BOOLEAN NOT NULL DEFAULT FALSE,
+    requested_by     UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_by       UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at       TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_leave_requests_employee ON leave_requests(employee_id, start_date);
+CREATE INDEX IF NOT EXISTS idx_leave_requests_company ON leave_requests(company_id, status);
+CREATE INDEX IF NOT EXISTS idx_leave_requests_active
+    ON leave_requests(start_date, end_date) WHERE status = 'approved';
+
+-- ── 3. Salary deduction ──────────────────────────────────────────
+-- Unpaid leave days in the month, deducted at salary / 30 per day.
+
+ALTER TABLE salary_records ADD COLUMN IF NOT EXISTS unpaid_leave_days INT NOT NULL DEFAULT 0;
@@ -0,0 +1,131 @@
+-- Migration 031: Dependents (family visa sponsorship)
+-- Employees who sponsor a spouse, children or parents carry the same fine
+-- risk on their dependents' visas, Emirates IDs and health insurance.
+-- A dependent belongs to its sponsoring employee; its documents are stored
+-- in documents with dependent_id set and employee_id = the sponsor, so
+-- document_status, employee_compliance, the dashboard and the notifier count
+-- them against the sponsor. Document types apply either to employees or to
+-- dependents (document_types.applies_to); mandatory dependent slots are
+-- seeded from the dependent set. All changes are additive.
+
+-- ── 1. Dependents ────────────────────────────────────────────────
+-- active:    sponsored; mandatory documents are tracked
+-- cancelled: sponsorship cancelled (cancelled_on); documents kept but not required
+
+CREATE TABLE IF NOT EXISTS dependents (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id     UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    name            VARCHAR(255) NOT NULL,
+    relationship    VARCHAR(20) NOT NULL CHECK (relationship IN ('spouse', 'child', 'parent', 'other')),
+    gender          VARCHAR(10),
+    nationality     VARCHAR(100),
+    date_of_birth   DATE,
+    passport_number VARCHAR(50),
+    status          VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'cancelled')),
+    cancelled_on    DATE,
+    notes           TEXT NOT NULL DEFAULT '',
+    created_by      UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dependents_employee ON dependents(employee_id);
+
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS dependent_id UUID REFERENCES dependents(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_documents_dependent ON documents(dependent_id) WHERE dependent_id IS NOT NULL;
+
+-- ── 2. Dependent document types ──────────────────────────────────
+
+ALTER TABLE document_types ADD COLUMN IF NOT EXISTS applies_to VARCHAR(20) NOT NULL DEFAULT 'employee'
+    CHECK (applies_to IN ('employee', 'dependent'));
+
+INSERT INTO document_types (doc_type, display_name, is_mandatory, has_expiry, number_label, number_placeholder,
+    expiry_label, sort_order, metadata_fields, is_system, applies_to,
+    require_document_number, require_issue_date, require_expiry_date, require_file,
+    validity_period, validity_unit)
+VALUES
+    ('dependent_passport',         'Dependent Passport',         TRUE,  TRUE, 'Passport Number',    'e.g. A12345678',          'Expiry Date', 210, '[]'::jsonb, TRUE, 'dependent', TRUE,  TRUE,  TRUE, TRUE, 0,  'months'),
+    ('dependent_visa',             'Dependent Residence Visa',   TRUE,  TRUE, 'Visa Number',        'e.g. 201/2024/1234567',   'Expiry Date', 220, '[]'::jsonb, TRUE, 'dependent', TRUE,  TRUE,  TRUE, TRUE, 24, 'months'),
+    ('dependent_emirates_id',      'Dependent Emirates ID',      TRUE,  TRUE, 'Emirates ID Number', 'e.g. 784-2015-1234567-1', 'Expiry Date', 230, '[]'::jsonb, TRUE, 'dependent', TRUE,  TRUE,  TRUE, TRUE, 24, 'months'),
+    ('dependent_health_insurance', 'Dependent Health Insurance', TRUE,  TRUE, 'Policy Number',      'e.g. POL-2024-12345',     'Expiry Date', 240, '[]'::jsonb, TRUE, 'dependent', FALSE, FALSE, TRUE, TRUE, 12, 'months')
+ON CONFLICT (doc_type) DO NOTHING;
+
+-- Same fines as the sponsor's own documents
+INSERT INTO compliance_rules (company_id, doc_type, grace_period_days, fine_per_day, fine_type, fine_cap)
+SELECT NULL, 'dependent_' || r.doc_type, r.grace_period_days, r.fine_per_day, r.fine_type, r.fine_cap
+FROM compliance_rules r
+WHERE r.company_id IS NULL AND r.doc_type IN ('passport', 'visa', 'emirates_id', 'health_insurance')
+ON CONFLICT (doc_type) WHERE company_id IS NULL DO NOTHING;
+
+-- ── 3. Requirement resolution ────────────────────────────────────
+-- doc_requirement_resolve is the body of doc_required_for (migration 011),
+-- unchanged. doc_required_for now only considers employee document types;
+-- dependent_doc_required_for applies the same precedence to a dependent's
+-- nationality and gender within the sponsor's company. Cancelled
+-- dependents have no required documents.
+
+CREATE OR REPLACE FUNCTION doc_requirement_resolve(
+    p_company_id  UUID,
+    p_doc_type    TEXT,
+    p_nationality TEXT,
+    p_trade       TEXT,
+    p_gender      TEXT,
+    p_status      TEXT
+) RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    WITH conds AS (
+        SELECT c.effect, c.nationalities, c.trades, c.genders, c.statuses
+        FROM document_requirement_conditions c
+        WHERE c.doc_type = p_doc_type
+          AND (c.company_id = p_company_id
+               OR (c.company_id IS NULL AND NOT EXISTS (
+                   SELECT 1 FROM document_requirement_conditions cc
+                   WHERE cc.doc_type = p_doc_type AND cc.company_id = p_company_id)))
+    ), matched AS (
+        SELECT effect FROM conds
+        WHERE (cardinality(nationalities) = 0 OR lower(btrim(COALESCE(p_nationality, ''))) = ANY(nationalities))
+          AND (cardinality(trades)        = 0 OR lower(btrim(COALESCE(p_trade, '')))       = ANY(trades))
+          AND (cardinality(genders)       = 0 OR lower(btrim(COALESCE(p_gender, '')))      = ANY(genders))
+          AND (cardinality(statuses)      = 0 OR lower(btrim(COALESCE(p_status, '')))      = ANY(statuses))
+    )
+    SELECT CASE
+        WHEN NOT EXISTS (SELECT 1 FROM document_types dt WHERE dt.doc_type = p_doc_type AND dt.is_active = TRUE) THEN FALSE
+        WHEN EXISTS (SELECT 1 FROM matched WHERE effect = 'exempt') THEN FALSE
+        WHEN EXISTS (SELECT 1 FROM matched WHERE effect = 'require') THEN TRUE
+        ELSE COALESCE(
+            (SELECT cr.is_mandatory FROM compliance_rules cr WHERE cr.doc_type = p_doc_type AND cr.company_id = p_company_id),
+            (SELECT dt.is_mandatory FROM document_types dt WHERE dt.doc_type = p_doc_type AND dt.is_active = TRUE),
+            FALSE)
+    END
+$$;
+
+CREATE OR REPLACE FUNCTION doc_required_for(
+    p_company_id  UUID,
+    p_doc_type    TEXT,
+    p_nationality TEXT,
+    p_trade       TEXT,
+    p_gender      TEXT,
+    p_status      TEXT
+) RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT NOT EXISTS (
+               SELECT 1 FROM document_types dt
+               WHERE dt.doc_type = p_doc_type AND dt.applies_to = 'dependent')
+       AND doc_requirement_resolve(p_company_id, p_doc_type, p_nationality, p_trade, p_gender, p_status)
+$$;
+
+CREATE OR REPLACE FUNCTION dependent_doc_required_for(
+    p_company_id   UUID,
+    p_doc_type     TEXT,
+    p_dependent_id UUID
+) RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT COALESCE((
+        SELECT dp.status = 'active'
+           AND EXISTS (
+               SELECT 1 FROM document_types dt
+               WHERE dt.doc_type = p_doc_type AND dt.applies_to = 'dependent')
+           AND doc_requirement_resolve(p_company_id, p_doc_type, dp.nationality, NULL, dp.gender, 'active')
+        FROM dependents dp WHERE dp.id = p_dependent_id
+    ), FALSE)
+$$;
@@ -0,0 +1,39 @@
+-- Migration 032: Labour contracts and probation
+-- Records each employee's MOHRE labour contract: limited (fixed end date)
+-- or unlimited, its probation end, notice period and the signed contract
+-- file. A new contract supersedes the current one, so the history is kept.
+-- The current contract's end date and probation end feed the dashboard
+-- expiry alerts and the daily notifier. All changes are additive.
+
+-- ── 1. Contracts ─────────────────────────────────────────────────
+-- current:    the contract in force (at most one per employee)
+-- superseded: replaced by a later contract (superseded_by)
+
+CREATE TABLE IF NOT EXISTS employee_contracts (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id         UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    company_id          UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    contract_type       VARCHAR(20) NOT NULL CHECK (contract_type IN ('limited', 'unlimited')),
+    contract_number     VARCHAR(100),
+    start_date          DATE NOT NULL,
+    end_date            DATE,
+    probation_end_date  DATE,
+    notice_period_days  INT NOT NULL DEFAULT 30 CHECK (notice_period_days BETWEEN 0 AND 365),
+    status              VARCHAR(20) NOT NULL DEFAULT 'current' CHECK (status IN ('current', 'superseded')),
+    superseded_by       UUID REFERENCES employee_contracts(id) ON DELETE SET NULL,
+    file_url            TEXT NOT NULL DEFAULT '',
+    file_name           VARCHAR(255) NOT NULL DEFAULT '',
+    file_size           BIGINT NOT NULL DEFAULT 0,
+    file_type           VARCHAR(100) NOT NULL DEFAULT '',
+    notes               TEXT NOT NULL DEFAULT '',
+    created_by          UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((contract_type = 'limited') = (end_date IS NOT NULL)),
+    CHECK (end_date IS NULL OR end_date > start_date),
+    CHECK (probation_end_date IS NULL OR probation_end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_employee_contracts_employee ON employee_contracts(employee_id, start_date DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_employee_contracts_current ON employee_contracts(employee_id) WHERE status = 'current';
+CREATE INDEX IF NOT EXISTS idx_employee_contracts_end ON employee_contracts(end_date) WHERE status = 'current';
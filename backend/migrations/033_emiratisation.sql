@@ -0,0 +1,47 @@
+-- Migration 033: Emiratisation quota
+-- MOHRE requires mainland companies with 50+ employees to raise their share
+-- of UAE nationals every year, checked at mid-year and year-end, with a fine
+-- per missing Emirati. Targets are configurable per checkpoint date; company
+-- rows replace the global row for the same date (e.g. the 20–49 employee
+-- sectors that must hire a fixed number). Headcount is derived from
+-- employees.nationality (is_uae_national). All changes are additive.
+
+-- ── 1. Targets ───────────────────────────────────────────────────
+-- required nationals = max(floor(workforce × target_percent / 100), min_headcount)
+-- for companies whose workforce is at least min_workforce.
+
+CREATE TABLE IF NOT EXISTS emiratisation_targets (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    company_id       UUID REFERENCES companies(id) ON DELETE CASCADE,
+    checkpoint_date  DATE NOT NULL,
+    target_percent   NUMERIC(5,2) NOT NULL DEFAULT 0 CHECK (target_percent BETWEEN 0 AND 100),
+    min_headcount    INT NOT NULL DEFAULT 0 CHECK (min_headcount >= 0),
+    min_workforce    INT NOT NULL DEFAULT 50 CHECK (min_workforce >= 1),
+    fine_per_missing NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (fine_per_missing >= 0),
+    description      TEXT NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_emiratisation_targets_global
+    ON emiratisation_targets(checkpoint_date) WHERE company_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_emiratisation_targets_company
+    ON emiratisation_targets(company_id, checkpoint_date) WHERE company_id IS NOT NULL;
+
+-- 1% of skilled workforce per half-year towards 10% by the end of 2026; the
+-- fine per missing Emirati rises by AED 12,000 a year (half at mid-year)
+INSERT INTO emiratisation_targets (company_id, checkpoint_date, target_percent, min_workforce, fine_per_missing, description)
+VALUES
+    (NULL, '2025-06-30', 7,  50, 54000,  'Mid-year 2025 checkpoint'),
+    (NULL, '2025-12-31', 8,  50, 108000, 'Year-end 2025 checkpoint'),
+    (NULL, '2026-06-30', 9,  50, 60000,  'Mid-year 2026 checkpoint'),
+    (NULL, '2026-12-31', 10, 50, 120000, 'Year-end 2026 checkpoint')
+ON CONFLICT (checkpoint_date) WHERE company_id IS NULL DO NOTHING;
+
+-- ── 2. UAE nationals ─────────────────────────────────────────────
+-- Same spellings as the GCC visa exemption (migration 011).
+
+CREATE OR REPLACE FUNCTION is_uae_national(p_nationality TEXT) RETURNS BOOLEAN
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT lower(btrim(COALESCE(p_nationality, ''))) IN ('emirati', 'uae', 'united arab emirates')
+$$;
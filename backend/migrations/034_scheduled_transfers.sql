@@ -0,0 +1,25 @@
+-- Migration 034: Scheduled employee transfers
+-- Transfers (migration 026) could only be backdated: the company changed
+-- immediately. A transfer with a future effective date is now stored as
+-- pending and applied by the hourly job once the date arrives in the
+-- employee's current company's timezone. Applied transfers keep the
+-- existing columns; custom_fields holds the values submitted for the new
+-- company until then. All changes are additive.
+
+ALTER TABLE employee_transfers ADD COLUMN IF NOT EXISTS status VARCHAR(10) NOT NULL DEFAULT 'applied';
+ALTER TABLE employee_transfers ADD COLUMN IF NOT EXISTS custom_fields JSONB;
+ALTER TABLE employee_transfers ADD COLUMN IF NOT EXISTS applied_at TIMESTAMPTZ;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'employee_transfers_status_check') THEN
+        ALTER TABLE employee_transfers ADD CONSTRAINT employee_transfers_status_check
+            CHECK (status IN ('pending', 'applied', 'cancelled'));
+    END IF;
+END $$;
+
+UPDATE employee_transfers SET applied_at = created_at WHERE status = 'applied' AND applied_at IS NULL;
+
+-- At most one scheduled transfer per employee
+CREATE UNIQUE INDEX IF NOT EXISTS idx_employee_transfers_pending
+    ON employee_transfers(employee_id) WHERE status = 'pending';